@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
+	"todo-app/internal/domain"
 	"todo-app/internal/infrastructure/container"
+	"todo-app/internal/infrastructure/notify"
+	"todo-app/internal/infrastructure/storage"
+	"todo-app/internal/usecase"
 
 	_ "github.com/lib/pq"
+	// Users' time zones must load even where the system has no zoneinfo
+	_ "time/tzdata"
 )
 
 func main() {
@@ -35,8 +45,38 @@ func main() {
 
 	log.Println("Database connected successfully")
 
+	blobStore, err := newBlobStore()
+	if err != nil {
+		log.Fatal("Failed to set up attachment storage: ", err)
+	}
+
+	notifiers, err := newNotifiers()
+	if err != nil {
+		log.Fatal("Failed to set up reminder delivery: ", err)
+	}
+
 	// Initialize dependency injection container
-	appContainer := container.NewContainer(db)
+	appContainer := container.NewContainer(db, blobStore, notifiers)
+
+	// Purge todos that have been in the trash longer than TRASH_RETENTION (e.g. "720h")
+	trashRetention := usecase.DefaultTrashRetention
+	if value := os.Getenv("TRASH_RETENTION"); value != "" {
+		trashRetention, err = time.ParseDuration(value)
+		if err != nil || trashRetention <= 0 {
+			log.Fatal("Invalid TRASH_RETENTION: ", value)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	appContainer.StartTrashPurger(ctx, trashRetention, usecase.DefaultTrashPurgeInterval)
+	appContainer.StartTokenCleaner(ctx, usecase.DefaultTokenCleanupInterval)
+	appContainer.StartPositionRebalancer(ctx, usecase.DefaultMaxPositionLength, usecase.DefaultPositionRebalanceInterval)
+	appContainer.StartBlobCleaner(ctx, usecase.DefaultBlobCleanupInterval)
+	if len(notifiers) > 0 {
+		appContainer.StartReminderScheduler(ctx, usecase.DefaultReminderInterval)
+	} else {
+		log.Println("Reminders are disabled: set SMTP_HOST or REMINDER_WEBHOOK_URL to deliver them")
+	}
 
 	// Setup routes
 	router := appContainer.GetRouter()
@@ -57,3 +97,75 @@ func main() {
 		log.Fatal("Server failed to start:", err)
 	}
 }
+
+// newBlobStore sets up the attachment storage selected by BLOB_STORE, "local" (default) or "s3"
+func newBlobStore() (usecase.BlobStore, error) {
+	switch store := os.Getenv("BLOB_STORE"); store {
+	case "", "local":
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "data/blobs"
+		}
+		// Download URLs are signed with BLOB_SIGNING_SECRET, or JWT_SECRET when it is not set
+		secret := os.Getenv("BLOB_SIGNING_SECRET")
+		if secret == "" {
+			secret = os.Getenv("JWT_SECRET")
+		}
+		if secret == "" {
+			secret = "your-secret-key-change-in-production"
+		}
+		return storage.NewLocalBlobStore(dir, os.Getenv("PUBLIC_URL"), []byte(secret))
+	case "s3":
+		return storage.NewS3BlobStore(storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", store)
+	}
+}
+
+// newNotifiers sets up the reminder channels that are configured: email with SMTP_HOST
+// and webhook with REMINDER_WEBHOOK_URL
+func newNotifiers() (map[domain.ReminderChannel]usecase.Notifier, error) {
+	notifiers := make(map[domain.ReminderChannel]usecase.Notifier)
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := 0
+		if value := os.Getenv("SMTP_PORT"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SMTP_PORT %q", value)
+			}
+			port = parsed
+		}
+		from := os.Getenv("SMTP_FROM")
+		if from == "" {
+			from = "Todo App <noreply@localhost>"
+		}
+		notifier, err := notify.NewEmailNotifier(notify.SMTPConfig{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifiers[domain.ReminderChannelEmail] = notifier
+	}
+
+	if url := os.Getenv("REMINDER_WEBHOOK_URL"); url != "" {
+		notifier, err := notify.NewWebhookNotifier(url, []byte(os.Getenv("REMINDER_WEBHOOK_SECRET")))
+		if err != nil {
+			return nil, err
+		}
+		notifiers[domain.ReminderChannelWebhook] = notifier
+	}
+
+	return notifiers, nil
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Attachment is a file uploaded to a todo. Its bytes are kept in the blob store under StorageKey.
+type Attachment struct {
+	ID          int
+	TodoID      int
+	Filename    string
+	ContentType string
+	Size        int64
+	StorageKey  string
+	CreatedAt   time.Time
+
+	// URL downloads the file until URLExpiresAt, populated when the attachment is read
+	URL          string
+	URLExpiresAt time.Time
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Comment is a Markdown message on a todo. A comment with a ParentID is a reply.
+type Comment struct {
+	ID       int
+	TodoID   int
+	ParentID *int
+	// AuthorID is nil once the author is deleted
+	AuthorID *int
+	// AuthorName is populated when the comments of a todo are listed
+	AuthorName string
+	Body       string
+	CreatedAt  time.Time
+	// EditedAt is set once the author has changed the body
+	EditedAt *time.Time
+	// DeletedAt is set on a deleted comment kept for its replies; its body is empty
+	DeletedAt *time.Time
+
+	// Replies is populated by ThreadComments, oldest first
+	Replies []*Comment
+}
+
+// IsAuthor reports whether the user wrote the comment
+func (c *Comment) IsAuthor(userID int) bool {
+	return c.AuthorID != nil && *c.AuthorID == userID
+}
+
+// ThreadComments arranges comments listed oldest first into threads and returns the
+// top-level comments. A reply whose parent is missing from the list is shown at the top level.
+func ThreadComments(comments []*Comment) []*Comment {
+	byID := make(map[int]*Comment, len(comments))
+	for _, comment := range comments {
+		byID[comment.ID] = comment
+	}
+
+	threads := make([]*Comment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.ParentID != nil {
+			if parent, ok := byID[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, comment)
+				continue
+			}
+		}
+		threads = append(threads, comment)
+	}
+	return threads
+}
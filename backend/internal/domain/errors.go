@@ -44,12 +44,102 @@ var (
 	ErrEmailExists        = NewAppError("EMAIL_EXISTS", "このメールアドレスは既に登録されています", http.StatusConflict)
 	ErrInvalidCredentials = NewAppError("INVALID_CREDENTIALS", "ユーザー名またはパスワードが正しくありません", http.StatusUnauthorized)
 	ErrPasswordHashFailed = NewAppError("PASSWORD_HASH_FAILED", "パスワードの暗号化に失敗しました", http.StatusInternalServerError)
+	ErrInvalidTimezone    = NewAppError("INVALID_TIMEZONE", "timezoneにはAsia/TokyoのようなIANAのタイムゾーン名を指定してください", http.StatusBadRequest)
 )
 
 // Todo-related errors
 var (
-	ErrTodoNotFound     = NewAppError("TODO_NOT_FOUND", "Todoが見つかりません", http.StatusNotFound)
-	ErrTodoUnauthorized = NewAppError("TODO_UNAUTHORIZED", "このTodoにアクセスする権限がありません", http.StatusForbidden)
+	ErrTodoNotFound         = NewAppError("TODO_NOT_FOUND", "Todoが見つかりません", http.StatusNotFound)
+	ErrTodoUnauthorized     = NewAppError("TODO_UNAUTHORIZED", "このTodoにアクセスする権限がありません", http.StatusForbidden)
+	ErrTodoNotInTrash       = NewAppError("TODO_NOT_IN_TRASH", "ゴミ箱にTodoが見つかりません", http.StatusNotFound)
+	ErrTodoVersionMismatch  = NewAppError("TODO_VERSION_MISMATCH", "Todoは他の操作で更新されています。最新の内容を取得してからやり直してください", http.StatusPreconditionFailed)
+	ErrUnsupportedPatchType = NewAppError("UNSUPPORTED_PATCH_TYPE", "PATCHのContent-Typeにはapplication/merge-patch+jsonを指定してください", http.StatusUnsupportedMediaType)
+	ErrInvalidIfMatch       = NewAppError("INVALID_IF_MATCH", "If-Matchには取得したETagを1つ指定してください", http.StatusBadRequest)
+	ErrInvalidRender        = NewAppError("INVALID_RENDER", "renderにはhtmlを指定してください", http.StatusBadRequest)
+	ErrInvalidDueTime       = NewAppError("INVALID_DUE_TIME", "due_timeはHH:MM形式で、due_dateと一緒に指定してください", http.StatusBadRequest)
+	ErrTodoNotRecurring     = NewAppError("TODO_NOT_RECURRING", "このTodoには繰り返し設定がありません", http.StatusBadRequest)
+	ErrInvalidQuickAdd      = NewAppError("INVALID_QUICK_ADD", "日付や優先度を除いたタイトルを1文字以上100文字以内で入力してください", http.StatusBadRequest)
+	ErrInvalidSnooze        = NewAppError("INVALID_SNOOZE", "untilには3dのような期間、next mondayのような曜日、YYYY-MM-DDの日付かRFC 3339の日時で、1年以内の未来を指定してください", http.StatusBadRequest)
+	ErrInvalidCount         = NewAppError("INVALID_COUNT", "countには1から50までの数値を指定してください", http.StatusBadRequest)
+	ErrInvalidCursor        = NewAppError("INVALID_CURSOR", "cursorが正しくありません。同じsortで取得したnext_cursorを指定してください", http.StatusBadRequest)
+	ErrInvalidPageLimit     = NewAppError("INVALID_PAGE_LIMIT", "limitには1から200までの数値を指定してください", http.StatusBadRequest)
+	ErrInvalidSearchQuery   = NewAppError("INVALID_SEARCH_QUERY", "qには1文字以上100文字以内の検索語を指定してください", http.StatusBadRequest)
+	ErrInvalidUndoCount     = NewAppError("INVALID_UNDO_COUNT", "countには1から20までの数値を指定してください", http.StatusBadRequest)
+	ErrInvalidTodoMove      = NewAppError("INVALID_TODO_MOVE", "after_idかbefore_idに移動するTodo以外のTodoを指定してください。両方指定する場合はafter_idのTodoがbefore_idのTodoより前にある必要があります", http.StatusBadRequest)
+	ErrInvalidBulkAction    = NewAppError("INVALID_BULK_ACTION", "actionにはcomplete, uncomplete, delete, set_priority, set_due_date, moveのいずれかを指定してください", http.StatusBadRequest)
+	ErrInvalidBulkIDs       = NewAppError("INVALID_BULK_IDS", "idsには1件以上100件以内のTodo IDを指定してください", http.StatusBadRequest)
+	ErrInvalidPriority      = NewAppError("INVALID_PRIORITY", "priorityには0から2までの数値を指定してください", http.StatusBadRequest)
+)
+
+// Project-related errors
+var (
+	ErrProjectNotFound          = NewAppError("PROJECT_NOT_FOUND", "プロジェクトが見つかりません", http.StatusNotFound)
+	ErrInboxProjectLocked       = NewAppError("INBOX_PROJECT_LOCKED", "Inboxプロジェクトは削除・アーカイブできません", http.StatusConflict)
+	ErrInvalidProjectDeleteMode = NewAppError("INVALID_PROJECT_DELETE_MODE", "todosにはmoveまたはdeleteを指定してください", http.StatusBadRequest)
+)
+
+// Tag-related errors
+var (
+	ErrTagNotFound      = NewAppError("TAG_NOT_FOUND", "タグが見つかりません", http.StatusNotFound)
+	ErrTagNameExists    = NewAppError("TAG_NAME_EXISTS", "同じ名前のタグが既に存在します", http.StatusConflict)
+	ErrInvalidTagFilter = NewAppError("INVALID_TAG_FILTER", "tagにはタグID、tag_matchにはanyまたはallを指定してください", http.StatusBadRequest)
+)
+
+// Status-related errors
+var (
+	ErrStatusNotFound         = NewAppError("STATUS_NOT_FOUND", "ステータスが見つかりません", http.StatusNotFound)
+	ErrStatusNameExists       = NewAppError("STATUS_NAME_EXISTS", "同じ名前のステータスが既に存在します", http.StatusConflict)
+	ErrStatusCategoryRequired = NewAppError("STATUS_CATEGORY_REQUIRED", "完了のステータスと未完了のステータスはそれぞれ1つ以上必要です", http.StatusConflict)
+	ErrStatusInUse            = NewAppError("STATUS_IN_USE", "Todoがあるステータスは完了・未完了の区分を変更できません", http.StatusConflict)
+	ErrTooManyStatuses        = NewAppError("TOO_MANY_STATUSES", "ステータスは20個までです", http.StatusConflict)
+	ErrInvalidNextStatuses    = NewAppError("INVALID_NEXT_STATUSES", "next_status_idsには自分のステータスのIDを指定してください", http.StatusBadRequest)
+	ErrInvalidStatusOrder     = NewAppError("INVALID_STATUS_ORDER", "status_idsにはすべてのステータスのIDを1回ずつ指定してください", http.StatusBadRequest)
+	ErrTransitionNotAllowed   = NewAppError("TRANSITION_NOT_ALLOWED", "現在のステータスからこのステータスへは移動できません", http.StatusConflict)
+	ErrWIPLimitReached        = NewAppError("WIP_LIMIT_REACHED", "移動先のステータスはWIP制限に達しています", http.StatusConflict)
+)
+
+// Dependency-related errors
+var (
+	ErrTodoBlocked        = NewAppError("TODO_BLOCKED", "未完了のブロッカーがあるため完了にできません。force=trueを指定すると完了にできます", http.StatusConflict)
+	ErrDependencyCycle    = NewAppError("DEPENDENCY_CYCLE", "この依存関係を追加すると循環します", http.StatusConflict)
+	ErrInvalidDependency  = NewAppError("INVALID_DEPENDENCY", "blocker_idにはこのTodo以外のTodoのIDを指定してください", http.StatusBadRequest)
+	ErrDependencyNotFound = NewAppError("DEPENDENCY_NOT_FOUND", "依存関係が見つかりません", http.StatusNotFound)
+	ErrInvalidForce       = NewAppError("INVALID_FORCE", "forceにはtrueまたはfalseを指定してください", http.StatusBadRequest)
+)
+
+// Subtask-related errors
+var (
+	ErrSubtaskNotFound       = NewAppError("SUBTASK_NOT_FOUND", "サブタスクが見つかりません", http.StatusNotFound)
+	ErrTodoHasOpenSubtasks   = NewAppError("TODO_HAS_OPEN_SUBTASKS", "未完了のサブタスクがあるため完了にできません", http.StatusConflict)
+	ErrInvalidSubtaskOrder   = NewAppError("INVALID_SUBTASK_ORDER", "サブタスクの並び順が正しくありません", http.StatusBadRequest)
+	ErrInvalidCompletionMode = NewAppError("INVALID_COMPLETION_MODE", "subtasksにはcascadeまたはrequireを指定してください", http.StatusBadRequest)
+)
+
+// Attachment-related errors
+var (
+	ErrAttachmentNotFound        = NewAppError("ATTACHMENT_NOT_FOUND", "添付ファイルが見つかりません", http.StatusNotFound)
+	ErrAttachmentRequired        = NewAppError("ATTACHMENT_REQUIRED", "fileフィールドにファイルを指定してください", http.StatusBadRequest)
+	ErrAttachmentTooLarge        = NewAppError("ATTACHMENT_TOO_LARGE", "添付ファイルは10MBまでです", http.StatusRequestEntityTooLarge)
+	ErrUnsupportedAttachmentType = NewAppError("UNSUPPORTED_ATTACHMENT_TYPE", "この種類のファイルは添付できません。画像、PDF、テキスト、Officeファイル、ZIPを指定してください", http.StatusUnsupportedMediaType)
+	ErrInvalidBlobSignature      = NewAppError("INVALID_BLOB_SIGNATURE", "ダウンロードURLが無効か、有効期限が切れています", http.StatusForbidden)
+)
+
+// Comment-related errors
+var (
+	ErrCommentNotFound      = NewAppError("COMMENT_NOT_FOUND", "コメントが見つかりません", http.StatusNotFound)
+	ErrCommentForbidden     = NewAppError("COMMENT_FORBIDDEN", "自分のコメントのみ編集・削除できます", http.StatusForbidden)
+	ErrInvalidCommentParent = NewAppError("INVALID_COMMENT_PARENT", "返信先のコメントが見つかりません", http.StatusBadRequest)
+)
+
+// Reminder-related errors
+var (
+	ErrReminderNotFound           = NewAppError("REMINDER_NOT_FOUND", "リマインダーが見つかりません", http.StatusNotFound)
+	ErrInvalidReminder            = NewAppError("INVALID_REMINDER", "remind_atかoffset_minutesのどちらか一方を指定してください", http.StatusBadRequest)
+	ErrReminderInPast             = NewAppError("REMINDER_IN_PAST", "remind_atには未来の日時を指定してください", http.StatusBadRequest)
+	ErrReminderNeedsDueDate       = NewAppError("REMINDER_NEEDS_DUE_DATE", "期限のないTodoに期限からのリマインダーは設定できません", http.StatusBadRequest)
+	ErrInvalidReminderOffset      = NewAppError("INVALID_REMINDER_OFFSET", "offset_minutesは期限の前後30日以内で指定してください", http.StatusBadRequest)
+	ErrReminderChannelUnavailable = NewAppError("REMINDER_CHANNEL_UNAVAILABLE", "この通知方法は利用できません", http.StatusBadRequest)
+	ErrTooManyReminders           = NewAppError("TOO_MANY_REMINDERS", "1つのTodoに設定できるリマインダーは10件までです", http.StatusConflict)
 )
 
 // Authentication errors
@@ -57,12 +147,17 @@ var (
 	ErrUnauthorized = NewAppError("UNAUTHORIZED", "認証が必要です", http.StatusUnauthorized)
 	ErrTokenInvalid = NewAppError("TOKEN_INVALID", "無効なトークンです", http.StatusUnauthorized)
 	ErrTokenExpired = NewAppError("TOKEN_EXPIRED", "トークンの有効期限が切れています", http.StatusUnauthorized)
+	ErrTokenRevoked = NewAppError("TOKEN_REVOKED", "このトークンは無効化されています", http.StatusUnauthorized)
+
+	ErrRefreshTokenInvalid = NewAppError("REFRESH_TOKEN_INVALID", "無効なリフレッシュトークンです", http.StatusUnauthorized)
+	ErrRefreshTokenReused  = NewAppError("REFRESH_TOKEN_REUSED", "リフレッシュトークンが再利用されました。再度ログインしてください", http.StatusUnauthorized)
 )
 
 // Validation errors
 var (
 	ErrValidationFailed = NewAppError("VALIDATION_FAILED", "バリデーションエラーです", http.StatusBadRequest)
 	ErrInvalidJSON      = NewAppError("INVALID_JSON", "無効なJSON形式です", http.StatusBadRequest)
+	ErrInvalidID        = NewAppError("INVALID_ID", "IDの形式が正しくありません", http.StatusBadRequest)
 )
 
 // Database errors
@@ -0,0 +1,101 @@
+package domain
+
+import "strings"
+
+// Positions order todos by hand (sort=manual). A position is a string of positionDigits
+// compared byte by byte and read as a base-36 fraction, so there is always room for
+// another key between two keys and moving a todo only rewrites its own key.
+// Keys never end in '0', since no key would fit between e.g. "a" and "a0".
+const positionDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// positionMiddle starts a new digit in the middle of the range, leaving room on both sides
+const positionMiddle = "i"
+
+// PositionBetween returns a key that sorts after lower and before upper.
+// An empty lower or upper leaves that side open.
+func PositionBetween(lower, upper string) (string, error) {
+	if !validPosition(lower) || !validPosition(upper) || (upper != "" && lower >= upper) {
+		return "", ErrInvalidTodoMove
+	}
+
+	switch {
+	case upper == "":
+		return positionAfter(lower), nil
+	case lower == "":
+		return positionBefore(upper), nil
+	default:
+		return positionMidpoint(lower, upper), nil
+	}
+}
+
+func validPosition(position string) bool {
+	for i := 0; i < len(position); i++ {
+		if strings.IndexByte(positionDigits, position[i]) < 0 {
+			return false
+		}
+	}
+	return position == "" || position[len(position)-1] != '0'
+}
+
+// positionAfter steps up one digit instead of halving the open range,
+// so that adding many todos at the end makes the keys grow slowly
+func positionAfter(position string) string {
+	for i := 0; i < len(position); i++ {
+		if d := positionDigit(position, i); d < len(positionDigits)-1 {
+			return position[:i] + string(positionDigits[d+1])
+		}
+	}
+	return position + positionMiddle
+}
+
+// positionBefore steps down one digit, the counterpart of positionAfter
+func positionBefore(position string) string {
+	for i := 0; i < len(position); i++ {
+		switch d := positionDigit(position, i); {
+		case d > 1:
+			return position[:i] + string(positionDigits[d-1])
+		case d == 1:
+			// Stepping down to '0' would end the key in '0'
+			return position[:i] + "0" + positionMiddle
+		}
+	}
+	// Not reached for valid keys, which contain a digit other than '0'
+	return position + positionMiddle
+}
+
+// positionMidpoint returns a key between lower and upper, lower < upper and upper not empty
+func positionMidpoint(lower, upper string) string {
+	// Skip the common prefix; a missing digit of lower counts as '0'
+	n := 0
+	for n < len(upper) && positionDigit(lower, n) == positionDigit(upper, n) {
+		n++
+	}
+	if n > 0 {
+		return upper[:n] + positionMidpoint(positionSuffix(lower, n), upper[n:])
+	}
+
+	low, high := positionDigit(lower, 0), positionDigit(upper, 0)
+	if high-low > 1 {
+		return string(positionDigits[(low+high+1)/2])
+	}
+	// The first digits are consecutive
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+	return string(positionDigits[low]) + positionAfter(positionSuffix(lower, 1))
+}
+
+// positionDigit returns the value of the i-th digit, 0 past the end of the key
+func positionDigit(position string, i int) int {
+	if i >= len(position) {
+		return 0
+	}
+	return strings.IndexByte(positionDigits, position[i])
+}
+
+func positionSuffix(position string, n int) string {
+	if n >= len(position) {
+		return ""
+	}
+	return position[n:]
+}
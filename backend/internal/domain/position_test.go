@@ -0,0 +1,109 @@
+package domain
+
+import "testing"
+
+func TestPositionBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		lower   string
+		upper   string
+		want    string
+		wantErr bool
+	}{
+		{name: "first key", want: "i"},
+		{name: "after", lower: "i", want: "j"},
+		{name: "after the last digit", lower: "z", want: "zi"},
+		{name: "after a longer key", lower: "iz", want: "j"},
+		{name: "before", upper: "i", want: "h"},
+		{name: "before 1 does not end in 0", upper: "1", want: "0i"},
+		{name: "before a key starting with 0", upper: "01", want: "00i"},
+		{name: "room between the first digits", lower: "a", upper: "c", want: "b"},
+		{name: "consecutive digits", lower: "a", upper: "b", want: "ai"},
+		{name: "upper has more digits", lower: "a", upper: "b5", want: "b"},
+		{name: "common prefix", lower: "a5", upper: "a6", want: "a5i"},
+		{name: "lower is a prefix of upper", lower: "a", upper: "a5", want: "a3"},
+		{name: "lower after upper", lower: "b", upper: "a", wantErr: true},
+		{name: "same key", lower: "a", upper: "a", wantErr: true},
+		{name: "trailing 0", lower: "a0", wantErr: true},
+		{name: "upper case digit", lower: "A", wantErr: true},
+		{name: "unknown digit", upper: "a-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PositionBetween(tt.lower, tt.upper)
+			if tt.wantErr {
+				if err != ErrInvalidTodoMove {
+					t.Fatalf("PositionBetween(%q, %q) = %q, %v, want ErrInvalidTodoMove", tt.lower, tt.upper, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PositionBetween(%q, %q) failed: %v", tt.lower, tt.upper, err)
+			}
+			if got != tt.want {
+				t.Errorf("PositionBetween(%q, %q) = %q, want %q", tt.lower, tt.upper, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionBetweenRepeated(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		// bounds returns where the next key goes, given the last key made
+		bounds func(last string) (string, string)
+		n      int
+		maxLen int
+	}{
+		{
+			name:   "append at the end",
+			start:  "i",
+			bounds: func(last string) (string, string) { return last, "" },
+			n:      100,
+			maxLen: 6,
+		},
+		{
+			name:   "prepend at the start",
+			start:  "i",
+			bounds: func(last string) (string, string) { return "", last },
+			n:      100,
+			maxLen: 6,
+		},
+		{
+			name:   "insert into the same gap from above",
+			start:  "b",
+			bounds: func(last string) (string, string) { return "a", last },
+			n:      200,
+			maxLen: 200,
+		},
+		{
+			name:   "insert into the same gap from below",
+			start:  "a",
+			bounds: func(last string) (string, string) { return last, "b" },
+			n:      200,
+			maxLen: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := tt.start
+			for i := 0; i < tt.n; i++ {
+				lower, upper := tt.bounds(last)
+				got, err := PositionBetween(lower, upper)
+				if err != nil {
+					t.Fatalf("step %d: PositionBetween(%q, %q) failed: %v", i, lower, upper, err)
+				}
+				if !validPosition(got) || (lower != "" && got <= lower) || (upper != "" && got >= upper) {
+					t.Fatalf("step %d: PositionBetween(%q, %q) = %q, not between them", i, lower, upper, got)
+				}
+				if len(got) > tt.maxLen {
+					t.Fatalf("step %d: key %q is longer than %d", i, got, tt.maxLen)
+				}
+				last = got
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Project groups todos of a user into a named list.
+// Each user has at most one Inbox project, which receives the todos of deleted projects.
+type Project struct {
+	ID         int
+	UserID     int
+	Name       string
+	Color      string
+	IsArchived bool
+	IsInbox    bool
+	SortOrder  int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ProjectDeleteMode decides what happens to the todos of a deleted project
+type ProjectDeleteMode string
+
+const (
+	// ProjectDeleteMoveToInbox moves the todos to the user's Inbox project
+	ProjectDeleteMoveToInbox ProjectDeleteMode = "move"
+	// ProjectDeleteTodos moves the todos to the trash together with deleting the project
+	ProjectDeleteTodos ProjectDeleteMode = "delete"
+)
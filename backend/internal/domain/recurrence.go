@@ -0,0 +1,305 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurrenceFrequency is the FREQ part of an RRULE
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "DAILY"
+	RecurrenceWeekly  RecurrenceFrequency = "WEEKLY"
+	RecurrenceMonthly RecurrenceFrequency = "MONTHLY"
+)
+
+// Recurrence is the subset of an RFC 5545 RRULE supported for todos:
+//
+//	FREQ=DAILY;INTERVAL=3
+//	FREQ=WEEKLY;BYDAY=MO,WE,FR
+//	FREQ=MONTHLY;BYMONTHDAY=15 (or -1 for the last day of the month)
+//
+// COUNT and UNTIL may be added to end the series. Weeks start on Monday (WKST=MO)
+// and, as in RFC 5545, months without the requested day are skipped.
+type Recurrence struct {
+	Freq       RecurrenceFrequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+	// Count is the number of occurrences left including the current one, 0 means unlimited
+	Count int
+	Until *time.Time
+	// FromCompletion schedules the next occurrence Interval days after the todo
+	// is completed instead of after its due date. Only valid with FREQ=DAILY.
+	FromCompletion bool
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// maxMonthlySearch bounds the search for a month that has the requested day
+const maxMonthlySearch = 48
+
+func invalidRecurrence(format string, args ...interface{}) *AppError {
+	return NewAppErrorWithDetails("INVALID_RECURRENCE", "繰り返し設定が正しくありません", http.StatusBadRequest, map[string]interface{}{
+		"reason": fmt.Sprintf(format, args...),
+	})
+}
+
+// ParseRecurrenceRule parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,TH".
+// A leading "RRULE:" is accepted.
+func ParseRecurrenceRule(rule string, fromCompletion bool) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return nil, invalidRecurrence("rule is empty")
+	}
+
+	r := &Recurrence{Interval: 1, FromCompletion: fromCompletion}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return nil, invalidRecurrence("malformed part %q", part)
+		}
+		if seen[key] {
+			return nil, invalidRecurrence("%s is given more than once", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			r.Freq = RecurrenceFrequency(value)
+			switch r.Freq {
+			case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+			default:
+				return nil, invalidRecurrence("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > 365 {
+				return nil, invalidRecurrence("INTERVAL must be between 1 and 365")
+			}
+			r.Interval = interval
+		case "BYDAY":
+			// A day given twice is kept once, so the rule is written back without it
+			days := make(map[time.Weekday]bool)
+			for _, code := range strings.Split(value, ",") {
+				weekday, ok := weekdayCodes[strings.TrimSpace(code)]
+				if !ok {
+					return nil, invalidRecurrence("unsupported BYDAY value %q", code)
+				}
+				if !days[weekday] {
+					days[weekday] = true
+					r.ByDay = append(r.ByDay, weekday)
+				}
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day == 0 || day < -1 || day > 31 {
+				return nil, invalidRecurrence("BYMONTHDAY must be between 1 and 31, or -1")
+			}
+			r.ByMonthDay = day
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, invalidRecurrence("COUNT must be a positive number")
+			}
+			r.Count = count
+		case "UNTIL":
+			until, err := parseRecurrenceDate(value)
+			if err != nil {
+				return nil, invalidRecurrence("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+			}
+			r.Until = &until
+		default:
+			return nil, invalidRecurrence("unsupported rule part %s", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, invalidRecurrence("FREQ is required")
+	}
+	if len(r.ByDay) > 0 && r.Freq != RecurrenceWeekly {
+		return nil, invalidRecurrence("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if r.ByMonthDay != 0 && r.Freq != RecurrenceMonthly {
+		return nil, invalidRecurrence("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, invalidRecurrence("COUNT and UNTIL cannot be used together")
+	}
+	if r.FromCompletion && r.Freq != RecurrenceDaily {
+		return nil, invalidRecurrence("from_completion is only supported with FREQ=DAILY")
+	}
+
+	return r, nil
+}
+
+func parseRecurrenceDate(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return dateOnly(t), nil
+	}
+	return time.Parse("20060102", value)
+}
+
+// Rule returns the canonical RRULE value
+func (r *Recurrence) Rule() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, weekday := range r.ByDay {
+			for code, wd := range weekdayCodes {
+				if wd == weekday {
+					codes[i] = code
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence that follows the one on date from.
+// It returns false when the series has ended.
+func (r *Recurrence) Next(from time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	from = dateOnly(from)
+	var next time.Time
+	switch r.Freq {
+	case RecurrenceDaily:
+		next = from.AddDate(0, 0, r.Interval)
+	case RecurrenceWeekly:
+		next = r.nextWeekly(from)
+	case RecurrenceMonthly:
+		var ok bool
+		if next, ok = r.nextMonthly(from); !ok {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Advance returns the recurrence carried by the next occurrence
+func (r *Recurrence) Advance() *Recurrence {
+	next := *r
+	if next.Count > 0 {
+		next.Count--
+	}
+	return &next
+}
+
+// Occurrences lists up to n occurrences following the one on date from
+func (r *Recurrence) Occurrences(from time.Time, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	current := r
+	for len(occurrences) < n {
+		next, ok := current.Next(from)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next)
+		current = current.Advance()
+		from = next
+	}
+	return occurrences
+}
+
+func (r *Recurrence) nextWeekly(from time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return from.AddDate(0, 0, 7*r.Interval)
+	}
+
+	// Offsets from Monday, the start of the week
+	offsets := make([]int, len(r.ByDay))
+	for i, weekday := range r.ByDay {
+		offsets[i] = mondayOffset(weekday)
+	}
+	sort.Ints(offsets)
+
+	fromOffset := mondayOffset(from.Weekday())
+	for _, offset := range offsets {
+		if offset > fromOffset {
+			return from.AddDate(0, 0, offset-fromOffset)
+		}
+	}
+
+	weekStart := from.AddDate(0, 0, -fromOffset)
+	return weekStart.AddDate(0, 0, 7*r.Interval+offsets[0])
+}
+
+func (r *Recurrence) nextMonthly(from time.Time) (time.Time, bool) {
+	day := r.ByMonthDay
+	if day == 0 {
+		day = from.Day()
+	}
+
+	if r.ByMonthDay != 0 {
+		if candidate, ok := monthDay(from.Year(), from.Month(), day); ok && candidate.After(from) {
+			return candidate, true
+		}
+	}
+
+	for i := 1; i <= maxMonthlySearch; i++ {
+		month := time.Date(from.Year(), from.Month()+time.Month(i*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+		if candidate, ok := monthDay(month.Year(), month.Month(), day); ok {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// monthDay resolves day (or -1 for the last day) in the month, and reports
+// false when the month is too short
+func monthDay(year int, month time.Month, day int) (time.Time, bool) {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day == -1 {
+		day = lastDay
+	}
+	if day > lastDay {
+		return time.Time{}, false
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), true
+}
+
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
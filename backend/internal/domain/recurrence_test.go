@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func formatDates(dates []time.Time) []string {
+	formatted := make([]string, len(dates))
+	for i, d := range dates {
+		formatted[i] = d.Format("2006-01-02")
+	}
+	return formatted
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		rule           string
+		fromCompletion bool
+		want           string
+		wantErr        bool
+	}{
+		{name: "daily", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "prefix and lower case", rule: " rrule:freq=weekly;interval=2 ", want: "FREQ=WEEKLY;INTERVAL=2"},
+		{name: "interval 1 is dropped", rule: "FREQ=DAILY;INTERVAL=1", want: "FREQ=DAILY"},
+		{name: "weekly by day", rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR", want: "FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+		{name: "duplicate days are kept once", rule: "FREQ=WEEKLY;BYDAY=MO,MO,TU,MO", want: "FREQ=WEEKLY;BYDAY=MO,TU"},
+		{name: "last day of the month", rule: "FREQ=MONTHLY;BYMONTHDAY=-1", want: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{name: "count", rule: "FREQ=DAILY;COUNT=3", want: "FREQ=DAILY;COUNT=3"},
+		{name: "until date time", rule: "FREQ=DAILY;UNTIL=20261231T235959Z", want: "FREQ=DAILY;UNTIL=20261231"},
+		{name: "from completion", rule: "FREQ=DAILY;INTERVAL=2", fromCompletion: true, want: "FREQ=DAILY;INTERVAL=2"},
+		{name: "empty", rule: "", wantErr: true},
+		{name: "no freq", rule: "INTERVAL=2", wantErr: true},
+		{name: "yearly", rule: "FREQ=YEARLY", wantErr: true},
+		{name: "malformed part", rule: "FREQ=DAILY;INTERVAL", wantErr: true},
+		{name: "part given twice", rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{name: "interval zero", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "interval too large", rule: "FREQ=DAILY;INTERVAL=366", wantErr: true},
+		{name: "unknown day", rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "ordinal day", rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{name: "by day outside weekly", rule: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{name: "month day zero", rule: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: true},
+		{name: "month day 32", rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{name: "month day outside monthly", rule: "FREQ=WEEKLY;BYMONTHDAY=3", wantErr: true},
+		{name: "count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20261231", wantErr: true},
+		{name: "bad until", rule: "FREQ=DAILY;UNTIL=2026-12-31", wantErr: true},
+		{name: "unsupported part", rule: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{name: "from completion outside daily", rule: "FREQ=WEEKLY", fromCompletion: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRecurrenceRule(tt.rule, tt.fromCompletion)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRecurrenceRule(%q) = %q, want an error", tt.rule, r.Rule())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) failed: %v", tt.rule, err)
+			}
+			if got := r.Rule(); got != tt.want {
+				t.Errorf("Rule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		from string
+		n    int
+		want []string
+	}{
+		{
+			name: "every third day",
+			rule: "FREQ=DAILY;INTERVAL=3",
+			from: "2026-02-27",
+			n:    3,
+			want: []string{"2026-03-02", "2026-03-05", "2026-03-08"},
+		},
+		{
+			name: "weekly on the same weekday",
+			rule: "FREQ=WEEKLY",
+			from: "2026-10-14",
+			n:    2,
+			want: []string{"2026-10-21", "2026-10-28"},
+		},
+		{
+			name: "by day within and across weeks",
+			rule: "FREQ=WEEKLY;BYDAY=FR,MO",
+			from: "2026-10-14",
+			n:    4,
+			want: []string{"2026-10-16", "2026-10-19", "2026-10-23", "2026-10-26"},
+		},
+		{
+			name: "by day every other week",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			from: "2026-10-14",
+			n:    3,
+			want: []string{"2026-10-26", "2026-10-28", "2026-11-09"},
+		},
+		{
+			name: "duplicate days do not repeat",
+			rule: "FREQ=WEEKLY;BYDAY=MO,MO",
+			from: "2026-10-12",
+			n:    2,
+			want: []string{"2026-10-19", "2026-10-26"},
+		},
+		{
+			name: "monthly from the 31st skips short months",
+			rule: "FREQ=MONTHLY",
+			from: "2026-01-31",
+			n:    3,
+			want: []string{"2026-03-31", "2026-05-31", "2026-07-31"},
+		},
+		{
+			name: "monthly on the 31st from earlier in the month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31",
+			from: "2026-01-15",
+			n:    2,
+			want: []string{"2026-01-31", "2026-03-31"},
+		},
+		{
+			name: "last day of the month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			from: "2026-01-31",
+			n:    3,
+			want: []string{"2026-02-28", "2026-03-31", "2026-04-30"},
+		},
+		{
+			name: "29 February every year",
+			rule: "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=29",
+			from: "2024-02-29",
+			n:    1,
+			want: []string{"2028-02-29"},
+		},
+		{
+			name: "count includes the current occurrence",
+			rule: "FREQ=DAILY;COUNT=3",
+			from: "2026-10-14",
+			n:    5,
+			want: []string{"2026-10-15", "2026-10-16"},
+		},
+		{
+			name: "until is inclusive",
+			rule: "FREQ=DAILY;UNTIL=20261016",
+			from: "2026-10-14",
+			n:    5,
+			want: []string{"2026-10-15", "2026-10-16"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRecurrenceRule(tt.rule, false)
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) failed: %v", tt.rule, err)
+			}
+			got := formatDates(r.Occurrences(date(tt.from), tt.n))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Occurrences = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Occurrences = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrenceNextEndsSeries(t *testing.T) {
+	r, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, ok := r.Next(date("2026-10-14")); ok {
+		t.Errorf("Next = %v, want the series to end", next)
+	}
+}
@@ -0,0 +1,86 @@
+package domain
+
+import "time"
+
+// ReminderChannel is how a reminder is delivered
+type ReminderChannel string
+
+const (
+	// ReminderChannelEmail mails the reminder to the todo's owner
+	ReminderChannelEmail ReminderChannel = "email"
+	// ReminderChannelWebhook posts the reminder to the webhook configured on the server
+	ReminderChannelWebhook ReminderChannel = "webhook"
+)
+
+const (
+	// MaxRemindersPerTodo caps the reminders of one todo
+	MaxRemindersPerTodo = 10
+	// MaxReminderOffset is the furthest a relative reminder can be from the due moment
+	MaxReminderOffset = 30 * 24 * time.Hour
+	// MaxReminderAttempts is how often delivering a reminder is tried before giving up
+	MaxReminderAttempts = 5
+)
+
+// Reminder notifies the owner of a todo at RemindAt, or OffsetMinutes after the todo
+// is due. A todo without a due time counts as due at 09:00 on its due date in the
+// user's time zone.
+type Reminder struct {
+	ID     int
+	TodoID int
+	// RemindAt is the moment of an absolute reminder
+	RemindAt *time.Time
+	// OffsetMinutes places a relative reminder around the due moment, negative before it
+	OffsetMinutes *int
+	Channel       ReminderChannel
+	// FireAt is when the reminder goes off, nil while a relative reminder's todo has no due date
+	FireAt *time.Time
+	// SentAt is set once the reminder has been delivered
+	SentAt    *time.Time
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+}
+
+// Status is "sent" once the reminder is delivered, "failed" once delivering it has
+// been given up, and "pending" until then
+func (r *Reminder) Status() string {
+	switch {
+	case r.SentAt != nil:
+		return "sent"
+	case r.Attempts >= MaxReminderAttempts:
+		return "failed"
+	default:
+		return "pending"
+	}
+}
+
+// ReminderNotice is what a notifier delivers when a reminder goes off
+type ReminderNotice struct {
+	ReminderID int
+	FireAt     time.Time
+
+	TodoID  int
+	Title   string
+	DueDate *time.Time
+	// DueAt is set when the todo is due at a time of day
+	DueAt *time.Time
+
+	UserID   int
+	Username string
+	Email    string
+	// Location is the user's time zone, in which the due time is shown
+	Location *time.Location
+}
+
+// DueText describes when the todo is due, e.g. "2026-11-01 09:30 (Asia/Tokyo)",
+// or is empty when it has no due date
+func (n *ReminderNotice) DueText() string {
+	switch {
+	case n.DueAt != nil:
+		return n.DueAt.In(n.Location).Format("2006-01-02 15:04") + " (" + n.Location.String() + ")"
+	case n.DueDate != nil:
+		return n.DueDate.Format("2006-01-02")
+	default:
+		return ""
+	}
+}
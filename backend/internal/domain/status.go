@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// StatusCategory groups statuses; todos in a done status count as completed
+type StatusCategory string
+
+const (
+	StatusCategoryTodo       StatusCategory = "todo"
+	StatusCategoryInProgress StatusCategory = "in_progress"
+	StatusCategoryDone       StatusCategory = "done"
+)
+
+// Status is a column of a user's workflow. Every todo is in exactly one status, and
+// its completion flag follows the status: completing or reopening a todo otherwise moves
+// it to the user's first status of the matching kind.
+type Status struct {
+	ID        int
+	UserID    int
+	Name      string
+	Category  StatusCategory
+	SortOrder int
+	// WIPLimit caps the number of todos moved into the status, nil for no limit
+	WIPLimit *int
+	// NextStatusIDs lists the statuses todos can move to from this one; nil allows any
+	NextStatusIDs []int
+	// TodoCount counts the todos in the status outside the trash, populated when listed
+	TodoCount int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsDone reports whether todos in the status count as completed
+func (s *Status) IsDone() bool {
+	return s.Category == StatusCategoryDone
+}
+
+// AllowsTransitionTo reports whether todos can move from the status to the given one
+func (s *Status) AllowsTransitionTo(statusID int) bool {
+	if s.NextStatusIDs == nil {
+		return true
+	}
+	for _, id := range s.NextStatusIDs {
+		if id == statusID {
+			return true
+		}
+	}
+	return false
+}
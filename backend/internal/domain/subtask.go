@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Subtask is a checklist item belonging to a todo
+type Subtask struct {
+	ID          int
+	TodoID      int
+	Title       string
+	IsCompleted bool
+	Position    int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// SubtaskCompletionMode controls how completing a todo treats its open subtasks
+type SubtaskCompletionMode string
+
+const (
+	// SubtaskCompletionIgnore completes the todo without touching its subtasks
+	SubtaskCompletionIgnore SubtaskCompletionMode = ""
+	// SubtaskCompletionCascade completes every open subtask together with the todo
+	SubtaskCompletionCascade SubtaskCompletionMode = "cascade"
+	// SubtaskCompletionRequire refuses to complete a todo that has open subtasks
+	SubtaskCompletionRequire SubtaskCompletionMode = "require"
+)
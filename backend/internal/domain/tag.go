@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Tag is a user-defined label that can be attached to many todos
+type Tag struct {
+	ID        int
+	UserID    int
+	Name      string
+	Color     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -3,12 +3,94 @@ package domain
 import "time"
 
 type Todo struct {
-	ID          int
-	UserID      int
-	Title       string
-	DueDate     *time.Time
+	ID        int
+	UserID    int
+	ProjectID *int
+	Title     string
+	// Notes is free-form Markdown
+	Notes string
+	// DueDate is the day the todo is due in the user's time zone
+	DueDate *time.Time
+	// DueAt is the due moment in UTC when the todo is due at a time of day.
+	// Its date in the user's time zone is DueDate.
+	DueAt       *time.Time
 	Priority    int
 	IsCompleted bool
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	// DeletedAt is set while the todo is in the trash
+	DeletedAt *time.Time
+	// SnoozedUntil hides the todo from the default todo list until that moment
+	SnoozedUntil *time.Time
+	// StatusID is the todo's workflow status, which IsCompleted follows
+	StatusID *int
+	// Position is the todo's key in the manual order
+	Position string
+	// Version is incremented on every change to the todo or its subtasks and is served as its ETag
+	Version    int
+	Tags       []*Tag
+	Recurrence *Recurrence
+
+	// Subtask progress, populated when the todo is read
+	SubtasksDone  int
+	SubtasksTotal int
+	// CommentCount counts the comments that are not deleted, populated when the todo is read
+	CommentCount int
+	// BlockedBy lists the todos that have to be completed before this one, Blocks those
+	// waiting for it, leaving out todos in the trash; populated when the todo is read
+	BlockedBy []int
+	Blocks    []int
+	// OpenBlockers lists the todos of BlockedBy that are not completed yet
+	OpenBlockers []int
+
+	// NextOccurrence is set when completing a recurring todo created the next one
+	NextOccurrence *Todo
+}
+
+// SetDue sets the due date and, unless clock is nil, the due time, a time of day in loc.
+// A time skipped by a clock change is moved past it, e.g. 02:30 to 03:30. A nil date clears both.
+func (t *Todo) SetDue(date *time.Time, clock *time.Time, loc *time.Location) {
+	t.DueDate, t.DueAt = nil, nil
+	if date == nil {
+		return
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	t.DueDate = &day
+	if clock != nil {
+		dueAt := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if dueAt.Hour() != clock.Hour() || dueAt.Minute() != clock.Minute() {
+			// The clocks skip this time when they go forward. Read with the offset in force
+			// before the change, it lands as far past the change as it was meant to be.
+			_, offset := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Zone()
+			wall := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+			dueAt = wall.Add(-time.Duration(offset) * time.Second)
+		}
+		dueAt = dueAt.UTC()
+		t.DueAt = &dueAt
+	}
+}
+
+// DueTime returns the due time of day in loc, or nil when the todo is due on a day only
+func (t *Todo) DueTime(loc *time.Location) *time.Time {
+	if t.DueAt == nil {
+		return nil
+	}
+	clock := t.DueAt.In(loc)
+	return &clock
+}
+
+// IsSnoozed reports whether the todo is still snoozed at now
+func (t *Todo) IsSnoozed(now time.Time) bool {
+	return t.SnoozedUntil != nil && t.SnoozedUntil.After(now)
+}
+
+// IsBlocked reports whether the todo waits for a todo that is not completed yet
+func (t *Todo) IsBlocked() bool {
+	return len(t.OpenBlockers) > 0
+}
+
+// Reschedule moves the todo to another due date and keeps its due time of day in loc
+func (t *Todo) Reschedule(date *time.Time, loc *time.Location) {
+	t.SetDue(date, t.DueTime(loc), loc)
 }
@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// TodoEventAction is the kind of change recorded in a todo's history
+type TodoEventAction string
+
+const (
+	TodoEventCreate  TodoEventAction = "create"
+	TodoEventUpdate  TodoEventAction = "update"
+	TodoEventToggle  TodoEventAction = "toggle"
+	TodoEventDelete  TodoEventAction = "delete"
+	TodoEventRestore TodoEventAction = "restore"
+	// TodoEventUndo records the changes made by undoing an earlier event
+	TodoEventUndo TodoEventAction = "undo"
+	// TodoEventTransition records a move to another workflow status
+	TodoEventTransition TodoEventAction = "transition"
+)
+
+// NextOccurrenceField is recorded on the toggle event that completed a recurring todo,
+// so that undoing it can remove the occurrence it created
+const NextOccurrenceField = "next_occurrence_id"
+
+// Undoable reports whether POST /undo can revert the event
+func (a TodoEventAction) Undoable() bool {
+	return a == TodoEventUpdate || a == TodoEventToggle || a == TodoEventTransition || a == TodoEventDelete
+}
+
+// FieldChange is the value of a field before and after a change; nil means unset
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// TodoEvent is one entry of a todo's history
+type TodoEvent struct {
+	ID     int
+	TodoID int
+	// ActorID is the user who made the change, nil once that user is deleted
+	ActorID   *int
+	Action    TodoEventAction
+	Changes   map[string]FieldChange
+	CreatedAt time.Time
+	// UndoneAt is set once POST /undo has reverted the event
+	UndoneAt *time.Time
+}
+
+// DiffTodos lists the recorded fields whose values differ between before and after.
+// A nil before or after stands for a todo that does not exist yet or any more.
+func DiffTodos(before, after *Todo) map[string]FieldChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := make(map[string]FieldChange)
+	for name, afterValue := range afterFields {
+		if beforeValue := beforeFields[name]; !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[name] = FieldChange{Before: beforeValue, After: afterValue}
+		}
+	}
+	for name, beforeValue := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = FieldChange{Before: beforeValue}
+		}
+	}
+	return changes
+}
+
+// auditFields returns the fields kept in the history, in the shape they are shown
+// in the API; unset values are left out so that they compare equal to nil
+func auditFields(todo *Todo) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if todo == nil {
+		return fields
+	}
+
+	fields["title"] = todo.Title
+	fields["priority"] = todo.Priority
+	fields["is_completed"] = todo.IsCompleted
+	if todo.Notes != "" {
+		fields["notes"] = todo.Notes
+	}
+	if todo.DueDate != nil {
+		fields["due_date"] = todo.DueDate.Format("2006-01-02")
+	}
+	if todo.DueAt != nil {
+		fields["due_at"] = todo.DueAt.UTC().Format(time.RFC3339)
+	}
+	if todo.SnoozedUntil != nil {
+		fields["snoozed_until"] = todo.SnoozedUntil.UTC().Format(time.RFC3339)
+	}
+	if todo.ProjectID != nil {
+		fields["project_id"] = *todo.ProjectID
+	}
+	if todo.StatusID != nil {
+		fields["status_id"] = *todo.StatusID
+	}
+	if todo.Recurrence != nil {
+		fields["recurrence"] = todo.Recurrence.Rule()
+		if todo.Recurrence.FromCompletion {
+			fields["recurrence_from_completion"] = true
+		}
+	}
+	if len(todo.Tags) > 0 {
+		tagIDs := make([]int, len(todo.Tags))
+		for i, tag := range todo.Tags {
+			tagIDs[i] = tag.ID
+		}
+		sort.Ints(tagIDs)
+		fields["tag_ids"] = tagIDs
+	}
+	return fields
+}
+
+// Revert sets the fields changed by the event back to their values before it.
+// Changes read back from storage hold JSON values, so numbers arrive as float64.
+func (e *TodoEvent) Revert(todo *Todo) error {
+	var rule string
+	var fromCompletion bool
+	if todo.Recurrence != nil {
+		rule, fromCompletion = todo.Recurrence.Rule(), todo.Recurrence.FromCompletion
+	}
+
+	for field, change := range e.Changes {
+		before := change.Before
+		var ok bool
+		switch field {
+		case "title":
+			todo.Title, ok = before.(string)
+		case "notes":
+			todo.Notes, ok = "", before == nil
+			if value, isString := before.(string); isString {
+				todo.Notes, ok = value, true
+			}
+		case "priority":
+			var priority float64
+			priority, ok = before.(float64)
+			todo.Priority = int(priority)
+		case "is_completed":
+			todo.IsCompleted, ok = before.(bool)
+		case "due_date":
+			todo.DueDate, ok = nil, before == nil
+			if value, isString := before.(string); isString {
+				dueDate, err := time.Parse("2006-01-02", value)
+				todo.DueDate, ok = &dueDate, err == nil
+			}
+		case "due_at":
+			todo.DueAt, ok = nil, before == nil
+			if value, isString := before.(string); isString {
+				dueAt, err := time.Parse(time.RFC3339, value)
+				dueAt = dueAt.UTC()
+				todo.DueAt, ok = &dueAt, err == nil
+			}
+		case "snoozed_until":
+			todo.SnoozedUntil, ok = nil, before == nil
+			if value, isString := before.(string); isString {
+				snoozedUntil, err := time.Parse(time.RFC3339, value)
+				snoozedUntil = snoozedUntil.UTC()
+				todo.SnoozedUntil, ok = &snoozedUntil, err == nil
+			}
+		case "project_id":
+			todo.ProjectID, ok = nil, before == nil
+			if value, isNumber := before.(float64); isNumber {
+				projectID := int(value)
+				todo.ProjectID, ok = &projectID, true
+			}
+		case "status_id":
+			todo.StatusID, ok = nil, before == nil
+			if value, isNumber := before.(float64); isNumber {
+				statusID := int(value)
+				todo.StatusID, ok = &statusID, true
+			}
+		case "recurrence":
+			rule, ok = "", before == nil
+			if value, isString := before.(string); isString {
+				rule, ok = value, true
+			}
+		case "recurrence_from_completion":
+			fromCompletion, ok = before == true, true
+		case "tag_ids":
+			todo.Tags, ok = nil, before == nil
+			if values, isList := before.([]interface{}); isList {
+				todo.Tags, ok = make([]*Tag, 0, len(values)), true
+				for _, value := range values {
+					tagID, isNumber := value.(float64)
+					if !isNumber {
+						ok = false
+						break
+					}
+					todo.Tags = append(todo.Tags, &Tag{ID: int(tagID), UserID: todo.UserID})
+				}
+			}
+		default:
+			// Not a field of the todo, e.g. NextOccurrenceField
+			ok = true
+		}
+		if !ok {
+			return fmt.Errorf("cannot revert %s to %v", field, before)
+		}
+	}
+
+	todo.Recurrence = nil
+	if rule != "" {
+		recurrence, err := ParseRecurrenceRule(rule, fromCompletion)
+		if err != nil {
+			return err
+		}
+		todo.Recurrence = recurrence
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffTodos(t *testing.T) {
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	project := 3
+	base := func() *Todo {
+		return &Todo{
+			Title:     "write report",
+			Priority:  1,
+			DueDate:   &due,
+			ProjectID: &project,
+			Tags:      []*Tag{{ID: 2}, {ID: 1}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		before *Todo
+		after  func(todo *Todo) *Todo
+		want   map[string]FieldChange
+	}{
+		{
+			name:   "no change",
+			before: base(),
+			after:  func(todo *Todo) *Todo { return todo },
+			want:   map[string]FieldChange{},
+		},
+		{
+			name:   "title and priority",
+			before: base(),
+			after: func(todo *Todo) *Todo {
+				todo.Title = "send report"
+				todo.Priority = 2
+				return todo
+			},
+			want: map[string]FieldChange{
+				"title":    {Before: "write report", After: "send report"},
+				"priority": {Before: 1, After: 2},
+			},
+		},
+		{
+			name:   "cleared due date and project",
+			before: base(),
+			after: func(todo *Todo) *Todo {
+				todo.DueDate = nil
+				todo.ProjectID = nil
+				return todo
+			},
+			want: map[string]FieldChange{
+				"due_date":   {Before: "2026-11-01"},
+				"project_id": {Before: 3},
+			},
+		},
+		{
+			name:   "tags in another order",
+			before: base(),
+			after: func(todo *Todo) *Todo {
+				todo.Tags = []*Tag{{ID: 1}, {ID: 2}}
+				return todo
+			},
+			want: map[string]FieldChange{},
+		},
+		{
+			name:   "tag removed",
+			before: base(),
+			after: func(todo *Todo) *Todo {
+				todo.Tags = []*Tag{{ID: 2}}
+				return todo
+			},
+			want: map[string]FieldChange{"tag_ids": {Before: []int{1, 2}, After: []int{2}}},
+		},
+		{
+			name:   "completed",
+			before: base(),
+			after: func(todo *Todo) *Todo {
+				todo.IsCompleted = true
+				return todo
+			},
+			want: map[string]FieldChange{"is_completed": {Before: false, After: true}},
+		},
+		{
+			name:   "created",
+			before: nil,
+			after: func(*Todo) *Todo {
+				return &Todo{Title: "new", Recurrence: &Recurrence{Freq: RecurrenceDaily, Interval: 2}}
+			},
+			want: map[string]FieldChange{
+				"title":        {After: "new"},
+				"priority":     {After: 0},
+				"is_completed": {After: false},
+				"recurrence":   {After: "FREQ=DAILY;INTERVAL=2"},
+			},
+		},
+		{
+			name:   "deleted",
+			before: &Todo{Title: "old"},
+			after:  func(*Todo) *Todo { return nil },
+			want: map[string]FieldChange{
+				"title":        {Before: "old"},
+				"priority":     {Before: 0},
+				"is_completed": {Before: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var after *Todo
+			if tt.before != nil {
+				copied := *tt.before
+				after = tt.after(&copied)
+			} else {
+				after = tt.after(nil)
+			}
+			if got := DiffTodos(tt.before, after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffTodos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// storedEvent records the change from before to after the way the history keeps it, as JSON
+func storedEvent(t *testing.T, action TodoEventAction, before, after *Todo) *TodoEvent {
+	t.Helper()
+	payload, err := json.Marshal(DiffTodos(before, after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &TodoEvent{Action: action}
+	if err := json.Unmarshal(payload, &event.Changes); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestTodoEventRevertRoundTrip(t *testing.T) {
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+	project, otherProject := 3, 4
+	weekly, err := ParseRecurrenceRule("FREQ=WEEKLY;BYDAY=MO,TH", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily, err := ParseRecurrenceRule("FREQ=DAILY;INTERVAL=3", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		action TodoEventAction
+		before Todo
+		after  Todo
+	}{
+		{
+			name:   "edit every field",
+			action: TodoEventUpdate,
+			before: Todo{Title: "write report", Priority: 1, DueDate: &due, ProjectID: &project, Tags: []*Tag{{ID: 1}, {ID: 2}}, Recurrence: weekly},
+			after:  Todo{Title: "send report", Priority: 2, DueDate: &later, ProjectID: &otherProject, Tags: []*Tag{{ID: 3}}, Recurrence: daily},
+		},
+		{
+			name:   "edit that set unset fields",
+			action: TodoEventUpdate,
+			before: Todo{Title: "write report"},
+			after:  Todo{Title: "write report", DueDate: &due, ProjectID: &project, Tags: []*Tag{{ID: 1}}, Recurrence: weekly},
+		},
+		{
+			name:   "edit that cleared fields",
+			action: TodoEventUpdate,
+			before: Todo{Title: "write report", Priority: 2, DueDate: &due, ProjectID: &project, Tags: []*Tag{{ID: 1}}, Recurrence: daily},
+			after:  Todo{Title: "write report"},
+		},
+		{
+			name:   "toggle",
+			action: TodoEventToggle,
+			before: Todo{Title: "write report", Priority: 1},
+			after:  Todo{Title: "write report", Priority: 1, IsCompleted: true},
+		},
+		{
+			name:   "toggle that completed a recurring todo",
+			action: TodoEventToggle,
+			before: Todo{Title: "water the plants", DueDate: &due, Recurrence: weekly},
+			after:  Todo{Title: "water the plants", DueDate: &due, IsCompleted: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := storedEvent(t, tt.action, &tt.before, &tt.after)
+			if !tt.action.Undoable() {
+				t.Fatalf("%s events cannot be undone", tt.action)
+			}
+
+			reverted := tt.after
+			if err := event.Revert(&reverted); err != nil {
+				t.Fatal(err)
+			}
+			if diff := DiffTodos(&tt.before, &reverted); len(diff) != 0 {
+				t.Errorf("reverted todo differs from the todo before the change: %v", diff)
+			}
+		})
+	}
+}
+
+func TestTodoEventRevertKeepsOtherFields(t *testing.T) {
+	event := storedEvent(t, TodoEventUpdate, &Todo{Title: "old", Priority: 1}, &Todo{Title: "new", Priority: 1})
+	// Changed again after the event, in a field the event did not touch
+	todo := &Todo{Title: "new", Priority: 2, IsCompleted: true}
+
+	if err := event.Revert(todo); err != nil {
+		t.Fatal(err)
+	}
+	if todo.Title != "old" || todo.Priority != 2 || !todo.IsCompleted {
+		t.Errorf("Revert() = %+v, want only the title reverted", todo)
+	}
+}
+
+func TestTodoEventRevertRejectsMalformedChanges(t *testing.T) {
+	tests := []struct {
+		field  string
+		before interface{}
+	}{
+		{field: "title", before: 3.0},
+		{field: "priority", before: "high"},
+		{field: "is_completed", before: "yes"},
+		{field: "due_date", before: "tomorrow"},
+		{field: "project_id", before: "inbox"},
+		{field: "tag_ids", before: []interface{}{"work"}},
+		{field: "recurrence", before: "FREQ=HOURLY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			event := &TodoEvent{Action: TodoEventUpdate, Changes: map[string]FieldChange{tt.field: {Before: tt.before}}}
+			if err := event.Revert(&Todo{Title: "todo"}); err == nil {
+				t.Errorf("Revert() accepted %s = %v", tt.field, tt.before)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetDue(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(hour, minute int) *time.Time {
+		clock := time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC)
+		return &clock
+	}
+
+	tests := []struct {
+		name  string
+		date  time.Time
+		clock *time.Time
+		// wantDueAt is in UTC, empty when the todo is due on a day only
+		wantDueAt string
+	}{
+		{name: "day only", date: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{name: "standard time", date: time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), clock: at(9, 0), wantDueAt: "2026-03-07T14:00:00Z"},
+		{name: "daylight saving time", date: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), clock: at(9, 0), wantDueAt: "2026-03-09T13:00:00Z"},
+		// 02:30 is skipped when the clocks go forward, and becomes 03:30 daylight time
+		{name: "time skipped by the clock change", date: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC), clock: at(2, 30), wantDueAt: "2026-03-08T07:30:00Z"},
+		// 01:30 happens twice when the clocks go back; Go takes the first one
+		{name: "time repeated by the clock change", date: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), clock: at(1, 30), wantDueAt: "2026-11-01T05:30:00Z"},
+		{name: "date in another zone is kept", date: time.Date(2026, 3, 7, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60)), clock: at(9, 0), wantDueAt: "2026-03-07T14:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var todo Todo
+			todo.SetDue(&tt.date, tt.clock, newYork)
+
+			if todo.DueDate == nil || todo.DueDate.Format("2006-01-02") != tt.date.Format("2006-01-02") || todo.DueDate.Location() != time.UTC {
+				t.Errorf("due date = %v, want %s in UTC", todo.DueDate, tt.date.Format("2006-01-02"))
+			}
+			var dueAt string
+			if todo.DueAt != nil {
+				dueAt = todo.DueAt.Format(time.RFC3339)
+			}
+			if dueAt != tt.wantDueAt {
+				t.Errorf("due at = %q, want %q", dueAt, tt.wantDueAt)
+			}
+		})
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var todo Todo
+	nineAM := time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)
+	todo.SetDue(timeAt(2026, 3, 7), &nineAM, newYork)
+
+	// Moving past the clock change keeps 09:00 local time, which is an hour earlier in UTC
+	todo.Reschedule(timeAt(2026, 3, 9), newYork)
+	if got := todo.DueAt.Format(time.RFC3339); got != "2026-03-09T13:00:00Z" {
+		t.Errorf("rescheduled due at = %s, want 2026-03-09T13:00:00Z", got)
+	}
+	if clock := todo.DueTime(newYork); clock.Hour() != 9 || clock.Minute() != 0 {
+		t.Errorf("due time = %v, want 09:00", clock)
+	}
+
+	todo.Reschedule(nil, newYork)
+	if todo.DueDate != nil || todo.DueAt != nil {
+		t.Errorf("clearing the due date left %v at %v", todo.DueDate, todo.DueAt)
+	}
+}
+
+func timeAt(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
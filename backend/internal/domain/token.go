@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// RefreshToken is a long-lived credential used to obtain new access tokens.
+// Tokens issued by rotating the same login share a FamilyID.
+type RefreshToken struct {
+	// TokenID is the hash of the token when read back from the store
+	TokenID   string
+	FamilyID  string
+	UserID    int
+	IsRevoked bool
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// TokenPair is the set of credentials handed to a client on login or refresh
+type TokenPair struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
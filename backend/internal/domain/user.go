@@ -1,12 +1,56 @@
 package domain
 
-import "time"
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTimezone is the time zone of users who have not chosen one
+const DefaultTimezone = "UTC"
 
 type User struct {
 	ID           int
 	Username     string
 	Email        string
 	PasswordHash string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// Timezone is the IANA name of the zone the user reads and enters dates in
+	Timezone  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// TokenGeneration is stamped on the user's access tokens. Logging out everywhere moves it on,
+	// which revokes every access token of an earlier generation.
+	TokenGeneration int
+}
+
+// Location returns the user's time zone, UTC when it is unset or unknown
+func (u *User) Location() *time.Location {
+	loc, err := LoadTimezone(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// locations caches loaded zones, since time.LoadLocation reads the zone database every time
+var locations sync.Map
+
+// LoadTimezone resolves an IANA time zone name such as "Asia/Tokyo"
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	// "Local" would be the server's zone, which means nothing to the user
+	if name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	locations.Store(name, loc)
+	return loc, nil
 }
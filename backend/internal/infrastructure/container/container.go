@@ -1,7 +1,10 @@
 package container
 
 import (
+	"context"
 	"database/sql"
+	"time"
+	"todo-app/internal/domain"
 	"todo-app/internal/infrastructure/persistence"
 	"todo-app/internal/interface/controller"
 	"todo-app/internal/interface/middleware"
@@ -11,29 +14,57 @@ import (
 
 // Container manages dependency injection
 type Container struct {
-	db *sql.DB
+	db        *sql.DB
+	blobStore usecase.BlobStore
+	notifiers map[domain.ReminderChannel]usecase.Notifier
 
 	// Infrastructure layer
-	queries  *persistence.Queries
-	userRepo usecase.UserRepository
-	todoRepo usecase.TodoRepository
+	queries          *persistence.Queries
+	userRepo         usecase.UserRepository
+	todoRepo         usecase.TodoRepository
+	refreshTokenRepo usecase.RefreshTokenRepository
+	blacklistRepo    usecase.TokenBlacklistRepository
+	subtaskRepo      usecase.SubtaskRepository
+	tagRepo          usecase.TagRepository
+	projectRepo      usecase.ProjectRepository
+	statusRepo       usecase.StatusRepository
+	attachmentRepo   usecase.AttachmentRepository
+	commentRepo      usecase.CommentRepository
+	reminderRepo     usecase.ReminderRepository
 
 	// Use case layer
-	userInteractor usecase.UserUseCase
-	todoInteractor usecase.TodoUseCase
+	userInteractor       usecase.UserUseCase
+	todoInteractor       usecase.TodoUseCase
+	subtaskInteractor    usecase.SubtaskUseCase
+	tagInteractor        usecase.TagUseCase
+	projectInteractor    usecase.ProjectUseCase
+	statusInteractor     usecase.StatusUseCase
+	attachmentInteractor usecase.AttachmentUseCase
+	commentInteractor    usecase.CommentUseCase
+	reminderInteractor   usecase.ReminderUseCase
 
 	// Interface layer
-	userController *controller.UserController
-	todoController *controller.TodoController
-	authMiddleware *middleware.AuthMiddleware
-	corsMiddleware *middleware.CORSMiddleware
-	router         *router.Router
+	userController       *controller.UserController
+	todoController       *controller.TodoController
+	subtaskController    *controller.SubtaskController
+	tagController        *controller.TagController
+	projectController    *controller.ProjectController
+	statusController     *controller.StatusController
+	attachmentController *controller.AttachmentController
+	commentController    *controller.CommentController
+	reminderController   *controller.ReminderController
+	authMiddleware       *middleware.AuthMiddleware
+	corsMiddleware       *middleware.CORSMiddleware
+	router               *router.Router
 }
 
-// NewContainer creates a new dependency injection container
-func NewContainer(db *sql.DB) *Container {
+// NewContainer creates a new dependency injection container; attachments are kept in blobStore
+// and reminders are delivered through notifiers, one for each channel the server supports
+func NewContainer(db *sql.DB, blobStore usecase.BlobStore, notifiers map[domain.ReminderChannel]usecase.Notifier) *Container {
 	container := &Container{
-		db: db,
+		db:        db,
+		blobStore: blobStore,
+		notifiers: notifiers,
 	}
 
 	container.buildDependencies()
@@ -45,18 +76,77 @@ func (c *Container) buildDependencies() {
 	// Infrastructure layer
 	c.queries = persistence.New(c.db)
 	c.userRepo = persistence.NewUserPersistence(c.db)
-	c.todoRepo = persistence.NewTodoRepository(c.queries)
+	c.todoRepo = persistence.NewTodoRepository(c.db)
+	c.refreshTokenRepo = persistence.NewRefreshToken(c.db)
+	c.blacklistRepo = persistence.NewCachedTokenBlacklist(persistence.NewTokenBlacklist(c.db), 30*time.Second)
+	c.subtaskRepo = persistence.NewSubtaskPersistence(c.db)
+	c.tagRepo = persistence.NewTagPersistence(c.db)
+	c.projectRepo = persistence.NewProjectPersistence(c.db)
+	c.statusRepo = persistence.NewStatusPersistence(c.db)
+	c.attachmentRepo = persistence.NewAttachmentPersistence(c.db)
+	c.commentRepo = persistence.NewCommentPersistence(c.db)
+	c.reminderRepo = persistence.NewReminderPersistence(c.db)
 
 	// Use case layer
-	c.userInteractor = usecase.NewUserInteractor(c.userRepo)
-	c.todoInteractor = usecase.NewTodoInteractor(c.todoRepo)
+	c.userInteractor = usecase.NewUserInteractor(c.userRepo, c.refreshTokenRepo, c.blacklistRepo)
+	c.todoInteractor = usecase.NewTodoInteractor(c.todoRepo, c.tagRepo, c.projectRepo, c.statusRepo, c.userRepo)
+	c.subtaskInteractor = usecase.NewSubtaskInteractor(c.subtaskRepo, c.todoRepo)
+	c.tagInteractor = usecase.NewTagInteractor(c.tagRepo)
+	c.projectInteractor = usecase.NewProjectInteractor(c.projectRepo)
+	c.statusInteractor = usecase.NewStatusInteractor(c.statusRepo)
+	c.attachmentInteractor = usecase.NewAttachmentInteractor(c.attachmentRepo, c.todoRepo, c.blobStore)
+	c.commentInteractor = usecase.NewCommentInteractor(c.commentRepo, c.todoRepo)
+	channels := make([]domain.ReminderChannel, 0, len(c.notifiers))
+	for channel := range c.notifiers {
+		channels = append(channels, channel)
+	}
+	c.reminderInteractor = usecase.NewReminderInteractor(c.reminderRepo, c.todoRepo, channels)
 
 	// Interface layer
 	c.userController = controller.NewUserController(c.userInteractor)
 	c.todoController = controller.NewTodoController(c.todoInteractor)
+	c.subtaskController = controller.NewSubtaskController(c.subtaskInteractor)
+	c.tagController = controller.NewTagController(c.tagInteractor)
+	c.projectController = controller.NewProjectController(c.projectInteractor)
+	c.statusController = controller.NewStatusController(c.statusInteractor)
+	// Stores without a server of their own, such as the local disk, are served by the API
+	blobOpener, _ := c.blobStore.(usecase.SignedBlobOpener)
+	c.attachmentController = controller.NewAttachmentController(c.attachmentInteractor, blobOpener)
+	c.commentController = controller.NewCommentController(c.commentInteractor)
+	c.reminderController = controller.NewReminderController(c.reminderInteractor)
 	c.authMiddleware = middleware.NewAuthMiddleware(c.userInteractor)
 	c.corsMiddleware = middleware.NewCORSMiddleware(nil) // Use default config
-	c.router = router.NewRouter(c.userController, c.todoController, c.authMiddleware)
+	c.router = router.NewRouter(c.userController, c.todoController, c.subtaskController, c.tagController, c.projectController, c.statusController, c.attachmentController, c.commentController, c.reminderController, c.authMiddleware)
+}
+
+// StartTrashPurger purges expired trash in the background until ctx is cancelled
+func (c *Container) StartTrashPurger(ctx context.Context, retention time.Duration, interval time.Duration) {
+	purger := usecase.NewTrashPurger(c.todoRepo, retention, interval)
+	go purger.Run(ctx)
+}
+
+// StartTokenCleaner deletes expired tokens in the background until ctx is cancelled
+func (c *Container) StartTokenCleaner(ctx context.Context, interval time.Duration) {
+	cleaner := usecase.NewTokenCleaner(c.refreshTokenRepo, c.blacklistRepo, interval)
+	go cleaner.Run(ctx)
+}
+
+// StartPositionRebalancer renumbers overlong manual order keys in the background until ctx is cancelled
+func (c *Container) StartPositionRebalancer(ctx context.Context, maxLength int, interval time.Duration) {
+	rebalancer := usecase.NewPositionRebalancer(c.todoRepo, maxLength, interval)
+	go rebalancer.Run(ctx)
+}
+
+// StartBlobCleaner removes the blobs of deleted attachments in the background until ctx is cancelled
+func (c *Container) StartBlobCleaner(ctx context.Context, interval time.Duration) {
+	cleaner := usecase.NewBlobCleaner(c.attachmentRepo, c.blobStore, interval)
+	go cleaner.Run(ctx)
+}
+
+// StartReminderScheduler delivers due reminders in the background until ctx is cancelled
+func (c *Container) StartReminderScheduler(ctx context.Context, interval time.Duration) {
+	scheduler := usecase.NewReminderScheduler(c.reminderRepo, c.notifiers, interval)
+	go scheduler.Run(ctx)
 }
 
 // GetRouter returns the configured router
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+// SMTPConfig locates the mail server reminders are sent through
+type SMTPConfig struct {
+	Host string
+	// Port defaults to 587, the submission port; local sinks usually listen on 1025
+	Port int
+	// Username and Password are optional. Credentials are only sent over TLS or to localhost.
+	Username string
+	Password string
+	// From is the sender address, e.g. "Todo App <noreply@example.com>"
+	From string
+}
+
+// EmailNotifier mails reminders to the todo's owner. The connection is upgraded with
+// STARTTLS whenever the server offers it.
+type EmailNotifier struct {
+	config SMTPConfig
+	from   *mail.Address
+}
+
+func NewEmailNotifier(config SMTPConfig) (*EmailNotifier, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", config.From, err)
+	}
+
+	return &EmailNotifier{
+		config: config,
+		from:   from,
+	}, nil
+}
+
+var _ usecase.Notifier = (*EmailNotifier)(nil)
+
+func (en *EmailNotifier) Notify(ctx context.Context, notice *domain.ReminderNotice) error {
+	message, err := en.message(notice, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(en.config.Host, strconv.Itoa(en.config.Port)))
+	if err != nil {
+		return err
+	}
+	// net/smtp does not take a context, so the deadline is put on the connection
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, en.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: en.config.Host}); err != nil {
+			return err
+		}
+	}
+	if en.config.Username != "" {
+		auth := smtp.PlainAuth("", en.config.Username, en.config.Password, en.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(en.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(notice.Email); err != nil {
+		return err
+	}
+	body, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := body.Write(message); err != nil {
+		return err
+	}
+	if err := body.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message builds the mail in Japanese, like the rest of the app's messages.
+// The subject is MIME-encoded and the body quoted-printable, so any title is safe to send.
+func (en *EmailNotifier) message(notice *domain.ReminderNotice, now time.Time) ([]byte, error) {
+	to := mail.Address{Name: notice.Username, Address: notice.Email}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%sさん\r\n\r\n", notice.Username)
+	fmt.Fprintf(&text, "「%s」のリマインダーです。\r\n", notice.Title)
+	if due := notice.DueText(); due != "" {
+		fmt.Fprintf(&text, "期限: %s\r\n", due)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", en.from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", to.String())
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", "リマインダー: "+notice.Title))
+	fmt.Fprintf(&message, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <reminder-%d-%d@%s>\r\n", notice.ReminderID, now.UnixNano(), en.config.Host)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&message)
+	if _, err := body.Write([]byte(text.String())); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// smtpMessage is a mail received by the SMTP sink
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPSink runs a minimal SMTP server on localhost that accepts one mail, answers
+// RCPT with rcptReply, and sends what it received on the returned channel
+func startSMTPSink(t *testing.T, rcptReply string) (int, <-chan smtpMessage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+
+		var message smtpMessage
+		defer func() { received <- message }()
+		text.PrintfLine("220 localhost ESMTP sink")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command, argument, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(command) {
+			case "EHLO":
+				text.PrintfLine("250-localhost\r\n250-8BITMIME\r\n250 AUTH PLAIN")
+			case "AUTH":
+				message.auth = argument
+				text.PrintfLine("235 2.7.0 Authentication successful")
+			case "MAIL":
+				message.from = argument
+				text.PrintfLine("250 OK")
+			case "RCPT":
+				message.to = append(message.to, argument)
+				text.PrintfLine(rcptReply)
+			case "DATA":
+				text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				data, err := io.ReadAll(text.DotReader())
+				if err != nil {
+					return
+				}
+				message.data = string(data)
+				text.PrintfLine("250 OK")
+			case "QUIT":
+				text.PrintfLine("221 Bye")
+				return
+			default:
+				text.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func reminderNotice(t *testing.T) *domain.ReminderNotice {
+	t.Helper()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dueDate := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2026, 11, 1, 0, 30, 0, 0, time.UTC)
+	return &domain.ReminderNotice{
+		ReminderID: 42,
+		FireAt:     time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		TodoID:     7,
+		Title:      "牛乳を買う = 2本",
+		DueDate:    &dueDate,
+		DueAt:      &dueAt,
+		UserID:     3,
+		Username:   "alice",
+		Email:      "alice@example.com",
+		Location:   tokyo,
+	}
+}
+
+func TestEmailNotifierNotify(t *testing.T) {
+	port, received := startSMTPSink(t, "250 OK")
+	notifier, err := NewEmailNotifier(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "mailer",
+		Password: "secret",
+		From:     "Todo App <noreply@example.com>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Notify(ctx, reminderNotice(t)); err != nil {
+		t.Fatal(err)
+	}
+	message := <-received
+
+	if auth, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(message.auth, "PLAIN ")); string(auth) != "\x00mailer\x00secret" {
+		t.Errorf("AUTH %q, want PLAIN credentials of mailer", message.auth)
+	}
+	if !strings.HasPrefix(message.from, "FROM:<noreply@example.com>") || len(message.to) != 1 || !strings.HasPrefix(message.to[0], "TO:<alice@example.com>") {
+		t.Errorf("envelope from %q to %q", message.from, message.to)
+	}
+
+	parsed, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(message.data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "リマインダー: 牛乳を買う = 2本" {
+		t.Errorf("subject %q, %v", subject, err)
+	}
+	if to := parsed.Header.Get("To"); to != `"alice" <alice@example.com>` {
+		t.Errorf("To: %q", to)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"aliceさん", "「牛乳を買う = 2本」のリマインダーです。", "期限: 2026-11-01 09:30 (Asia/Tokyo)"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+}
+
+func TestEmailNotifierRejectedRecipient(t *testing.T) {
+	port, received := startSMTPSink(t, "550 5.1.1 No such user")
+	notifier, err := NewEmailNotifier(SMTPConfig{Host: "127.0.0.1", Port: port, From: "noreply@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := notifier.Notify(context.Background(), reminderNotice(t)); err == nil {
+		t.Error("Notify() succeeded, want the rejected recipient reported")
+	}
+	// Without a username nothing is authenticated, and no mail is sent
+	if message := <-received; message.auth != "" || message.data != "" {
+		t.Errorf("sink received %+v", message)
+	}
+}
+
+func TestNewEmailNotifierInvalid(t *testing.T) {
+	for _, config := range []SMTPConfig{
+		{From: "noreply@example.com"},
+		{Host: "localhost", From: "not an address"},
+	} {
+		if _, err := NewEmailNotifier(config); err == nil {
+			t.Errorf("NewEmailNotifier(%+v) succeeded, want an error", config)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+// WebhookNotifier posts reminders as JSON to one URL. With a secret, the body is signed
+// in the X-Reminder-Signature header as "sha256=" and the hex HMAC-SHA256 of the body.
+type WebhookNotifier struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhookNotifier(webhookURL string, secret []byte) (*WebhookNotifier, error) {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q", webhookURL)
+	}
+
+	return &WebhookNotifier{
+		url:    webhookURL,
+		secret: secret,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+var _ usecase.Notifier = (*WebhookNotifier)(nil)
+
+type webhookPayload struct {
+	ReminderID int                `json:"reminder_id"`
+	FireAt     string             `json:"fire_at"`
+	Todo       webhookTodoPayload `json:"todo"`
+	User       webhookUserPayload `json:"user"`
+}
+
+type webhookTodoPayload struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	DueDate string `json:"due_date,omitempty"`
+	DueTime string `json:"due_time,omitempty"`
+	DueAt   string `json:"due_at,omitempty"`
+}
+
+type webhookUserPayload struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Timezone string `json:"timezone"`
+}
+
+// Notify posts the reminder and expects a 2xx answer. A retried delivery carries the
+// same X-Reminder-ID, so that the receiver can drop duplicates.
+func (wn *WebhookNotifier) Notify(ctx context.Context, notice *domain.ReminderNotice) error {
+	body, err := json.Marshal(toWebhookPayload(notice))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, wn.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Reminder-ID", strconv.Itoa(notice.ReminderID))
+	if len(wn.secret) > 0 {
+		mac := hmac.New(sha256.New, wn.secret)
+		mac.Write(body)
+		request.Header.Set("X-Reminder-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	response, err := wn.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", response.Status)
+	}
+	return nil
+}
+
+func toWebhookPayload(notice *domain.ReminderNotice) webhookPayload {
+	payload := webhookPayload{
+		ReminderID: notice.ReminderID,
+		FireAt:     notice.FireAt.UTC().Format(time.RFC3339),
+		Todo: webhookTodoPayload{
+			ID:    notice.TodoID,
+			Title: notice.Title,
+		},
+		User: webhookUserPayload{
+			ID:       notice.UserID,
+			Username: notice.Username,
+			Email:    notice.Email,
+			Timezone: notice.Location.String(),
+		},
+	}
+
+	if notice.DueDate != nil {
+		payload.Todo.DueDate = notice.DueDate.Format("2006-01-02")
+	}
+	if notice.DueAt != nil {
+		payload.Todo.DueTime = notice.DueAt.In(notice.Location).Format("15:04")
+		payload.Todo.DueAt = notice.DueAt.UTC().Format(time.RFC3339)
+	}
+	return payload
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifierNotify(t *testing.T) {
+	secret := []byte("webhook-secret")
+
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		if signature := r.Header.Get("X-Reminder-Signature"); signature != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("X-Reminder-Signature = %q, want the HMAC of the body", signature)
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Reminder-ID") != "42" {
+			t.Errorf("%s with Content-Type %q and X-Reminder-ID %q", r.Method, r.Header.Get("Content-Type"), r.Header.Get("X-Reminder-ID"))
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(server.URL, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), reminderNotice(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := webhookPayload{
+		ReminderID: 42,
+		FireAt:     "2026-11-01T00:00:00Z",
+		Todo: webhookTodoPayload{
+			ID:      7,
+			Title:   "牛乳を買う = 2本",
+			DueDate: "2026-11-01",
+			DueTime: "09:30",
+			DueAt:   "2026-11-01T00:30:00Z",
+		},
+		User: webhookUserPayload{ID: 3, Username: "alice", Email: "alice@example.com", Timezone: "Asia/Tokyo"},
+	}
+	if payload != want {
+		t.Errorf("payload = %+v, want %+v", payload, want)
+	}
+}
+
+func TestWebhookNotifierWithoutDueTime(t *testing.T) {
+	notice := reminderNotice(t)
+	notice.DueAt = nil
+	notice.Location = time.UTC
+
+	payload := toWebhookPayload(notice)
+	if payload.Todo.DueDate != "2026-11-01" || payload.Todo.DueTime != "" || payload.Todo.DueAt != "" || payload.User.Timezone != "UTC" {
+		t.Errorf("todo %+v of user %+v, want only a due date", payload.Todo, payload.User)
+	}
+}
+
+func TestWebhookNotifierUnsigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if signature, ok := r.Header["X-Reminder-Signature"]; ok {
+			t.Errorf("X-Reminder-Signature = %q without a secret", signature)
+		}
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), reminderNotice(t)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookNotifierFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "server error", status: http.StatusInternalServerError},
+		{name: "not modified", status: http.StatusNotModified},
+		{name: "client error", status: http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			notifier, err := NewWebhookNotifier(server.URL, []byte("secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := notifier.Notify(context.Background(), reminderNotice(t)); err == nil {
+				t.Errorf("Notify() succeeded on %d, want an error so that it is retried", tt.status)
+			}
+		})
+	}
+}
+
+func TestNewWebhookNotifierInvalid(t *testing.T) {
+	for _, webhookURL := range []string{"", "ftp://example.com/hook", "http://", "://bad"} {
+		if _, err := NewWebhookNotifier(webhookURL, nil); err == nil {
+			t.Errorf("NewWebhookNotifier(%q) succeeded, want an error", webhookURL)
+		}
+	}
+}
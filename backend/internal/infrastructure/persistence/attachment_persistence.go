@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+type AttachmentPersistence struct {
+	db      *sql.DB
+	queries *Queries
+}
+
+func NewAttachmentPersistence(db *sql.DB) usecase.AttachmentRepository {
+	return &AttachmentPersistence{
+		db:      db,
+		queries: New(db),
+	}
+}
+
+func (ap *AttachmentPersistence) CreateAttachment(ctx context.Context, attachment *domain.Attachment) error {
+	params := CreateAttachmentParams{
+		TodoID:      int32(attachment.TodoID),
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		StorageKey:  attachment.StorageKey,
+	}
+
+	sqlcAttachment, err := ap.queries.CreateAttachment(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*attachment = *toDomainAttachment(sqlcAttachment)
+
+	return nil
+}
+
+func (ap *AttachmentPersistence) GetAttachment(ctx context.Context, todoID int, attachmentID int) (*domain.Attachment, error) {
+	params := GetAttachmentParams{
+		ID:     int32(attachmentID),
+		TodoID: int32(todoID),
+	}
+
+	sqlcAttachment, err := ap.queries.GetAttachment(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainAttachment(sqlcAttachment), nil
+}
+
+func (ap *AttachmentPersistence) GetAttachments(ctx context.Context, todoID int) ([]*domain.Attachment, error) {
+	sqlcAttachments, err := ap.queries.ListAttachments(ctx, int32(todoID))
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*domain.Attachment, len(sqlcAttachments))
+	for i, sqlcAttachment := range sqlcAttachments {
+		attachments[i] = toDomainAttachment(sqlcAttachment)
+	}
+
+	return attachments, nil
+}
+
+func (ap *AttachmentPersistence) DeleteAttachment(ctx context.Context, todoID int, attachmentID int) (bool, error) {
+	params := DeleteAttachmentParams{
+		ID:     int32(attachmentID),
+		TodoID: int32(todoID),
+	}
+
+	rows, err := ap.queries.DeleteAttachment(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (ap *AttachmentPersistence) ListBlobDeletions(ctx context.Context, limit int) ([]string, error) {
+	return ap.queries.ListBlobDeletions(ctx, int32(limit))
+}
+
+func (ap *AttachmentPersistence) CompleteBlobDeletion(ctx context.Context, key string) error {
+	return ap.queries.DeleteBlobDeletion(ctx, key)
+}
+
+func toDomainAttachment(sqlcAttachment Attachment) *domain.Attachment {
+	return &domain.Attachment{
+		ID:          int(sqlcAttachment.ID),
+		TodoID:      int(sqlcAttachment.TodoID),
+		Filename:    sqlcAttachment.Filename,
+		ContentType: sqlcAttachment.ContentType,
+		Size:        sqlcAttachment.Size,
+		StorageKey:  sqlcAttachment.StorageKey,
+		CreatedAt:   fromSQLNullTime(sqlcAttachment.CreatedAt),
+	}
+}
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+type CommentPersistence struct {
+	db      *sql.DB
+	queries *Queries
+}
+
+func NewCommentPersistence(db *sql.DB) usecase.CommentRepository {
+	return &CommentPersistence{
+		db:      db,
+		queries: New(db),
+	}
+}
+
+func (cp *CommentPersistence) CreateComment(ctx context.Context, comment *domain.Comment) error {
+	params := CreateCommentParams{
+		TodoID:   int32(comment.TodoID),
+		ParentID: toSQLNullInt32(comment.ParentID),
+		AuthorID: toSQLNullInt32(comment.AuthorID),
+		Body:     comment.Body,
+	}
+
+	sqlcComment, err := cp.queries.CreateComment(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*comment = *toDomainComment(sqlcComment)
+
+	return nil
+}
+
+func (cp *CommentPersistence) GetComment(ctx context.Context, todoID int, commentID int) (*domain.Comment, error) {
+	params := GetCommentParams{
+		ID:     int32(commentID),
+		TodoID: int32(todoID),
+	}
+
+	row, err := cp.queries.GetComment(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := toDomainComment(row.Comment)
+	comment.AuthorName = row.AuthorName.String
+	return comment, nil
+}
+
+func (cp *CommentPersistence) GetComments(ctx context.Context, todoID int) ([]*domain.Comment, error) {
+	rows, err := cp.queries.ListComments(ctx, int32(todoID))
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]*domain.Comment, len(rows))
+	for i, row := range rows {
+		comments[i] = toDomainComment(row.Comment)
+		comments[i].AuthorName = row.AuthorName.String
+	}
+
+	return comments, nil
+}
+
+func (cp *CommentPersistence) UpdateComment(ctx context.Context, comment *domain.Comment) error {
+	params := UpdateCommentBodyParams{
+		ID:     int32(comment.ID),
+		TodoID: int32(comment.TodoID),
+		Body:   comment.Body,
+	}
+
+	sqlcComment, err := cp.queries.UpdateCommentBody(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*comment = *toDomainComment(sqlcComment)
+
+	return nil
+}
+
+func (cp *CommentPersistence) DeleteComment(ctx context.Context, todoID int, commentID int) error {
+	tx, err := cp.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := removeComment(ctx, cp.queries.WithTx(tx), todoID, commentID); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// removeComment keeps the comment without its body while it has replies. Removing a
+// reply can leave deleted ancestors with no replies, and those are removed as well.
+func removeComment(ctx context.Context, q *Queries, todoID int, commentID int) error {
+	row, err := q.GetComment(ctx, GetCommentParams{ID: int32(commentID), TodoID: int32(todoID)})
+	if err != nil {
+		return err
+	}
+
+	hasReplies, err := q.HasCommentReplies(ctx, row.Comment.ID)
+	if err != nil {
+		return err
+	}
+	if hasReplies {
+		return q.MarkCommentDeleted(ctx, row.Comment.ID)
+	}
+
+	if err := q.DeleteComment(ctx, row.Comment.ID); err != nil {
+		return err
+	}
+	for parentID := row.Comment.ParentID; parentID.Valid; {
+		parentID, err = q.DeleteOrphanedComment(ctx, parentID.Int32)
+		if err == sql.ErrNoRows {
+			// The parent is not deleted, or still has other replies
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toDomainComment(sqlcComment Comment) *domain.Comment {
+	return &domain.Comment{
+		ID:        int(sqlcComment.ID),
+		TodoID:    int(sqlcComment.TodoID),
+		ParentID:  fromSQLNullInt32Ptr(sqlcComment.ParentID),
+		AuthorID:  fromSQLNullInt32Ptr(sqlcComment.AuthorID),
+		Body:      sqlcComment.Body,
+		CreatedAt: fromSQLNullTime(sqlcComment.CreatedAt),
+		EditedAt:  fromSQLNullTimePtr(sqlcComment.EditedAt),
+		DeletedAt: fromSQLNullTimePtr(sqlcComment.DeletedAt),
+	}
+}
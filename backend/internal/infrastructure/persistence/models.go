@@ -6,24 +6,142 @@ package persistence
 
 import (
 	"database/sql"
+	"encoding/json"
 )
 
-type Todo struct {
+type Attachment struct {
+	ID          int32        `json:"id"`
+	TodoID      int32        `json:"todo_id"`
+	Filename    string       `json:"filename"`
+	ContentType string       `json:"content_type"`
+	Size        int64        `json:"size"`
+	StorageKey  string       `json:"storage_key"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type BlobDeletion struct {
+	StorageKey string       `json:"storage_key"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+}
+
+type Comment struct {
+	ID        int32         `json:"id"`
+	TodoID    int32         `json:"todo_id"`
+	ParentID  sql.NullInt32 `json:"parent_id"`
+	AuthorID  sql.NullInt32 `json:"author_id"`
+	Body      string        `json:"body"`
+	CreatedAt sql.NullTime  `json:"created_at"`
+	EditedAt  sql.NullTime  `json:"edited_at"`
+	DeletedAt sql.NullTime  `json:"deleted_at"`
+}
+
+type Project struct {
+	ID         int32        `json:"id"`
+	UserID     int32        `json:"user_id"`
+	Name       string       `json:"name"`
+	Color      string       `json:"color"`
+	IsArchived bool         `json:"is_archived"`
+	IsInbox    bool         `json:"is_inbox"`
+	SortOrder  int32        `json:"sort_order"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+	UpdatedAt  sql.NullTime `json:"updated_at"`
+}
+
+type Reminder struct {
+	ID            int32          `json:"id"`
+	TodoID        int32          `json:"todo_id"`
+	RemindAt      sql.NullTime   `json:"remind_at"`
+	OffsetMinutes sql.NullInt32  `json:"offset_minutes"`
+	Channel       string         `json:"channel"`
+	SentAt        sql.NullTime   `json:"sent_at"`
+	Attempts      int32          `json:"attempts"`
+	LastError     sql.NullString `json:"last_error"`
+	RetryAt       sql.NullTime   `json:"retry_at"`
+	ClaimedUntil  sql.NullTime   `json:"claimed_until"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+}
+
+type Status struct {
+	ID            int32         `json:"id"`
+	UserID        int32         `json:"user_id"`
+	Name          string        `json:"name"`
+	Category      string        `json:"category"`
+	SortOrder     int32         `json:"sort_order"`
+	WipLimit      sql.NullInt32 `json:"wip_limit"`
+	NextStatusIds []int32       `json:"next_status_ids"`
+	CreatedAt     sql.NullTime  `json:"created_at"`
+	UpdatedAt     sql.NullTime  `json:"updated_at"`
+}
+
+type Subtask struct {
 	ID          int32        `json:"id"`
-	UserID      int32        `json:"user_id"`
+	TodoID      int32        `json:"todo_id"`
 	Title       string       `json:"title"`
-	DueDate     sql.NullTime `json:"due_date"`
-	Priority    int32        `json:"priority"`
 	IsCompleted bool         `json:"is_completed"`
+	Position    int32        `json:"position"`
 	CreatedAt   sql.NullTime `json:"created_at"`
 	UpdatedAt   sql.NullTime `json:"updated_at"`
 }
 
+type Tag struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	Name      string       `json:"name"`
+	Color     string       `json:"color"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+type Todo struct {
+	ID                       int32          `json:"id"`
+	UserID                   int32          `json:"user_id"`
+	Title                    string         `json:"title"`
+	DueDate                  sql.NullTime   `json:"due_date"`
+	Priority                 int32          `json:"priority"`
+	IsCompleted              bool           `json:"is_completed"`
+	CreatedAt                sql.NullTime   `json:"created_at"`
+	UpdatedAt                sql.NullTime   `json:"updated_at"`
+	ProjectID                sql.NullInt32  `json:"project_id"`
+	RecurrenceRule           sql.NullString `json:"recurrence_rule"`
+	RecurrenceFromCompletion bool           `json:"recurrence_from_completion"`
+	DeletedAt                sql.NullTime   `json:"deleted_at"`
+	Version                  int32          `json:"version"`
+	Position                 string         `json:"position"`
+	Notes                    string         `json:"notes"`
+	DueAt                    sql.NullTime   `json:"due_at"`
+	SnoozedUntil             sql.NullTime   `json:"snoozed_until"`
+	StatusID                 sql.NullInt32  `json:"status_id"`
+}
+
+type TodoDependency struct {
+	BlockerID int32        `json:"blocker_id"`
+	BlockedID int32        `json:"blocked_id"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type TodoEvent struct {
+	ID          int64           `json:"id"`
+	TodoID      int32           `json:"todo_id"`
+	ActorID     sql.NullInt32   `json:"actor_id"`
+	Action      string          `json:"action"`
+	Changes     json.RawMessage `json:"changes"`
+	CreatedAt   sql.NullTime    `json:"created_at"`
+	OperationID int64           `json:"operation_id"`
+	UndoneAt    sql.NullTime    `json:"undone_at"`
+}
+
+type TodoTag struct {
+	TodoID int32 `json:"todo_id"`
+	TagID  int32 `json:"tag_id"`
+}
+
 type User struct {
-	ID           int32        `json:"id"`
-	Username     string       `json:"username"`
-	Email        string       `json:"email"`
-	PasswordHash string       `json:"password_hash"`
-	CreatedAt    sql.NullTime `json:"created_at"`
-	UpdatedAt    sql.NullTime `json:"updated_at"`
+	ID              int32        `json:"id"`
+	Username        string       `json:"username"`
+	Email           string       `json:"email"`
+	PasswordHash    string       `json:"password_hash"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+	TokenGeneration int32        `json:"token_generation"`
+	Timezone        string       `json:"timezone"`
 }
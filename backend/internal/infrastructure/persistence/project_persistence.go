@@ -0,0 +1,177 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+type ProjectPersistence struct {
+	db      *sql.DB
+	queries *Queries
+}
+
+func NewProjectPersistence(db *sql.DB) usecase.ProjectRepository {
+	return &ProjectPersistence{
+		db:      db,
+		queries: New(db),
+	}
+}
+
+func (pp *ProjectPersistence) CreateProject(ctx context.Context, project *domain.Project) error {
+	params := CreateProjectParams{
+		UserID:     int32(project.UserID),
+		Name:       project.Name,
+		Color:      project.Color,
+		IsArchived: project.IsArchived,
+		SortOrder:  int32(project.SortOrder),
+	}
+
+	sqlcProject, err := pp.queries.CreateProject(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*project = *toDomainProject(sqlcProject)
+
+	return nil
+}
+
+func (pp *ProjectPersistence) GetProject(ctx context.Context, userID int, projectID int) (*domain.Project, error) {
+	params := GetProjectParams{
+		ID:     int32(projectID),
+		UserID: int32(userID),
+	}
+
+	sqlcProject, err := pp.queries.GetProject(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainProject(sqlcProject), nil
+}
+
+func (pp *ProjectPersistence) GetProjects(ctx context.Context, userID int, includeArchived bool) ([]*domain.Project, error) {
+	params := ListProjectsParams{
+		UserID:          int32(userID),
+		IncludeArchived: includeArchived,
+	}
+
+	sqlcProjects, err := pp.queries.ListProjects(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]*domain.Project, len(sqlcProjects))
+	for i, sqlcProject := range sqlcProjects {
+		projects[i] = toDomainProject(sqlcProject)
+	}
+
+	return projects, nil
+}
+
+func (pp *ProjectPersistence) GetOrCreateInbox(ctx context.Context, userID int) (*domain.Project, error) {
+	sqlcProject, err := getOrCreateInbox(ctx, pp.queries, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainProject(sqlcProject), nil
+}
+
+func (pp *ProjectPersistence) UpdateProject(ctx context.Context, project *domain.Project) error {
+	params := UpdateProjectParams{
+		ID:         int32(project.ID),
+		UserID:     int32(project.UserID),
+		Name:       project.Name,
+		Color:      project.Color,
+		IsArchived: project.IsArchived,
+		SortOrder:  int32(project.SortOrder),
+	}
+
+	sqlcProject, err := pp.queries.UpdateProject(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*project = *toDomainProject(sqlcProject)
+
+	return nil
+}
+
+func (pp *ProjectPersistence) DeleteProject(ctx context.Context, userID int, projectID int, deleteTodos bool) error {
+	tx, err := pp.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := pp.deleteProject(ctx, pp.queries.WithTx(tx), userID, projectID, deleteTodos); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (pp *ProjectPersistence) deleteProject(ctx context.Context, q *Queries, userID int, projectID int, deleteTodos bool) error {
+	if deleteTodos {
+		todoIDs, err := q.TrashProjectTodos(ctx, int32(projectID))
+		if err != nil {
+			return err
+		}
+		for _, todoID := range todoIDs {
+			if err := recordTodoEvent(ctx, q, int(todoID), userID, domain.TodoEventDelete, nil); err != nil {
+				return err
+			}
+		}
+	} else {
+		inbox, err := getOrCreateInbox(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+
+		params := MoveProjectTodosParams{
+			ToProjectID:   inbox.ID,
+			FromProjectID: int32(projectID),
+		}
+		if err := q.MoveProjectTodos(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	params := DeleteProjectParams{
+		ID:     int32(projectID),
+		UserID: int32(userID),
+	}
+	return q.DeleteProject(ctx, params)
+}
+
+func getOrCreateInbox(ctx context.Context, q *Queries, userID int) (Project, error) {
+	sqlcProject, err := q.GetInboxProject(ctx, int32(userID))
+	if err != sql.ErrNoRows {
+		return sqlcProject, err
+	}
+
+	if err := q.CreateInboxProject(ctx, int32(userID)); err != nil {
+		return Project{}, err
+	}
+
+	return q.GetInboxProject(ctx, int32(userID))
+}
+
+func toDomainProject(sqlcProject Project) *domain.Project {
+	return &domain.Project{
+		ID:         int(sqlcProject.ID),
+		UserID:     int(sqlcProject.UserID),
+		Name:       sqlcProject.Name,
+		Color:      sqlcProject.Color,
+		IsArchived: sqlcProject.IsArchived,
+		IsInbox:    sqlcProject.IsInbox,
+		SortOrder:  int(sqlcProject.SortOrder),
+		CreatedAt:  fromSQLNullTime(sqlcProject.CreatedAt),
+		UpdatedAt:  fromSQLNullTime(sqlcProject.UpdatedAt),
+	}
+}
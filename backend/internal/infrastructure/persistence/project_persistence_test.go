@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var projectColumns = []string{"id", "user_id", "name", "color", "is_archived", "is_inbox", "sort_order", "created_at", "updated_at"}
+
+func inboxRow(id int32) *sqlmock.Rows {
+	return sqlmock.NewRows(projectColumns).AddRow(id, 1, "Inbox", "", false, true, 0, nil, nil)
+}
+
+func TestDeleteProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteTodos bool
+		expect      func(mock sqlmock.Sqlmock)
+		wantErr     bool
+	}{
+		{
+			name: "moves todos to the Inbox",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("-- name: GetInboxProject :one").WithArgs(1).WillReturnRows(inboxRow(9))
+				mock.ExpectExec("-- name: MoveProjectTodos :exec").WithArgs(9, 5).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("-- name: DeleteProject :exec").WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "creates the Inbox when the user has none yet",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("-- name: GetInboxProject :one").WithArgs(1).WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("-- name: CreateInboxProject :exec").WithArgs(1).WillReturnResult(sqlmock.NewResult(9, 1))
+				mock.ExpectQuery("-- name: GetInboxProject :one").WithArgs(1).WillReturnRows(inboxRow(9))
+				mock.ExpectExec("-- name: MoveProjectTodos :exec").WithArgs(9, 5).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("-- name: DeleteProject :exec").WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:        "moves todos to the trash on request",
+			deleteTodos: true,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("-- name: TrashProjectTodos :many").WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+				// The history of each todo records the deletion
+				mock.ExpectExec("-- name: CreateTodoEvent :exec").WithArgs(11, 1, "delete", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("-- name: CreateTodoEvent :exec").WithArgs(12, 1, "delete", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("-- name: DeleteProject :exec").WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "keeps the project when the todos cannot be moved",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("-- name: GetInboxProject :one").WithArgs(1).WillReturnRows(inboxRow(9))
+				mock.ExpectExec("-- name: MoveProjectTodos :exec").WillReturnError(errors.New("connection lost"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			tt.expect(mock)
+
+			err = NewProjectPersistence(db).DeleteProject(context.Background(), 1, 5, tt.deleteTodos)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteProject() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
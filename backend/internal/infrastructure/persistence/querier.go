@@ -6,22 +6,168 @@ package persistence
 
 import (
 	"context"
+	"database/sql"
 )
 
 type Querier interface {
+	AddTodoTags(ctx context.Context, arg AddTodoTagsParams) error
+	// 通知時刻を過ぎたリマインダーをclaimed_untilまで確保して返す。
+	// SKIP LOCKEDで他のレプリカが確保中の行を飛ばすので、同じリマインダーが二重に送られない。
+	// 完了したTodoとゴミ箱のTodoのリマインダーは送らない
+	ClaimDueReminders(ctx context.Context, arg ClaimDueRemindersParams) ([]ClaimDueRemindersRow, error)
+	ClearTodoRecurrence(ctx context.Context, arg ClearTodoRecurrenceParams) (int64, error)
+	// Todoに付けるタグを入れ替える（他ユーザーのタグは無視される）
+	ClearTodoTags(ctx context.Context, todoID int32) error
+	// 親Todo完了時に子をまとめて完了にする
+	CompleteAllSubtasks(ctx context.Context, todoID int32) error
+	// 繰り返しの次の回には、期限からの相対リマインダーだけを引き継ぐ
+	CopyReminders(ctx context.Context, arg CopyRemindersParams) error
+	// 繰り返しTodoの次の回へサブタスクを未完了の状態でコピーする
+	CopySubtasks(ctx context.Context, arg CopySubtasksParams) error
+	// WIP制限と比べる件数。ゴミ箱のTodoは数えない
+	CountStatusTodos(ctx context.Context, statusID sql.NullInt32) (int64, error)
+	// ListTodosと同じ絞り込み条件で件数を数える
+	CountTodos(ctx context.Context, arg CountTodosParams) (int64, error)
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error)
+	CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error)
+	// 同時に作成されても部分ユニークインデックスで1件に保たれる
+	CreateInboxProject(ctx context.Context, userID int32) error
+	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
+	CreateReminder(ctx context.Context, arg CreateReminderParams) (Reminder, error)
+	// 新しいステータスは並び順の最後に置く
+	CreateStatus(ctx context.Context, arg CreateStatusParams) (Status, error)
+	CreateSubtask(ctx context.Context, arg CreateSubtaskParams) (Subtask, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
+	// status_idとis_completedはトリガーで揃える。status_idがNULLならis_completedに合う最初のステータスに入る
 	CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error)
+	// 既にある依存関係なら何もしない
+	CreateTodoDependency(ctx context.Context, arg CreateTodoDependencyParams) (int64, error)
+	CreateTodoEvent(ctx context.Context, arg CreateTodoEventParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteTodo(ctx context.Context, arg DeleteTodoParams) error
-	GetTodo(ctx context.Context, id int32) (Todo, error)
+	// 行を消すとトリガーでblob_deletionsに積まれ、BlobCleanerがストアから消す
+	DeleteAttachment(ctx context.Context, arg DeleteAttachmentParams) (int64, error)
+	DeleteBlobDeletion(ctx context.Context, storageKey string) error
+	DeleteComment(ctx context.Context, id int32) error
+	// 返信がなくなった削除済みコメントを消し、その親を返す
+	DeleteOrphanedComment(ctx context.Context, id int32) (sql.NullInt32, error)
+	DeleteProject(ctx context.Context, arg DeleteProjectParams) error
+	DeleteReminder(ctx context.Context, arg DeleteReminderParams) (int64, error)
+	// ステータスのTodoはトリガーで同じ完了区分の最初のステータスへ移る
+	DeleteStatus(ctx context.Context, arg DeleteStatusParams) error
+	DeleteSubtask(ctx context.Context, arg DeleteSubtaskParams) error
+	DeleteTag(ctx context.Context, arg DeleteTagParams) error
+	DeleteTodoDependency(ctx context.Context, arg DeleteTodoDependencyParams) (int64, error)
+	GetAttachment(ctx context.Context, arg GetAttachmentParams) (Attachment, error)
+	GetComment(ctx context.Context, arg GetCommentParams) (GetCommentRow, error)
+	// 新しいTodoは手動の並び順の先頭に置くため、いちばん前の位置キーを返す
+	GetFirstTodoPosition(ctx context.Context, userID int32) (string, error)
+	GetInboxProject(ctx context.Context, userID int32) (Project, error)
+	// positionの直後にあるTodoの位置キー（移動するTodo自身は除く）
+	GetNextTodoPosition(ctx context.Context, arg GetNextTodoPositionParams) (string, error)
+	// positionの直前にあるTodoの位置キー（移動するTodo自身は除く）
+	GetPreviousTodoPosition(ctx context.Context, arg GetPreviousTodoPositionParams) (string, error)
+	GetProject(ctx context.Context, arg GetProjectParams) (Project, error)
+	// 通知する時刻はremind_atか、Todoの期限からoffset_minutes後。
+	// 時刻のないTodoはユーザーのタイムゾーンで期限日の9:00を期限とみなす。期限がなければNULL
+	GetReminder(ctx context.Context, arg GetReminderParams) (GetReminderRow, error)
+	GetStatus(ctx context.Context, arg GetStatusParams) (Status, error)
+	// WIP制限の確認から移動までの間に他の移動が割り込まないよう行をロックする
+	GetStatusForUpdate(ctx context.Context, arg GetStatusForUpdateParams) (Status, error)
+	GetSubtask(ctx context.Context, arg GetSubtaskParams) (Subtask, error)
+	GetTag(ctx context.Context, arg GetTagParams) (Tag, error)
+	GetTagByName(ctx context.Context, arg GetTagByNameParams) (Tag, error)
+	GetTodo(ctx context.Context, id int32) (GetTodoRow, error)
+	// 変更前の状態を履歴に残すため、更新と同じトランザクションで行をロックして読む
+	GetTodoForUpdate(ctx context.Context, arg GetTodoForUpdateParams) (Todo, error)
+	// 移動先の基準にするTodoの位置キー
+	GetTodoPosition(ctx context.Context, arg GetTodoPositionParams) (string, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id int32) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
-	ListTodos(ctx context.Context, userID int32) ([]Todo, error)
-	// ソート機能付きリスト取得
-	ListTodosWithSort(ctx context.Context, arg ListTodosWithSortParams) ([]Todo, error)
-	// 完了切り替え専用クエリ
-	ToggleTodoComplete(ctx context.Context, arg ToggleTodoCompleteParams) (Todo, error)
+	HasCommentReplies(ctx context.Context, parentID int32) (bool, error)
+	// from_idがブロックしているTodoを辿ってto_idに着くか。ゴミ箱のTodoも辿る（復元すると循環になるため）
+	HasDependencyPath(ctx context.Context, arg HasDependencyPathParams) (bool, error)
+	ListAttachments(ctx context.Context, todoID int32) ([]Attachment, error)
+	// 古いものから順に、ストアから消す必要があるBlobのキーを返す
+	ListBlobDeletions(ctx context.Context, limit int32) ([]string, error)
+	// Todoごとの削除されていないコメント数をまとめて取得
+	ListCommentCounts(ctx context.Context, todoIds []int32) ([]ListCommentCountsRow, error)
+	// 投稿者のユーザー名と一緒に古い順で返す。スレッドへの組み立ては呼び出し側で行う
+	ListComments(ctx context.Context, todoID int32) ([]ListCommentsRow, error)
+	// ゴミ箱にない相手との依存関係を持つTodoを、手動の並び順で返す
+	ListDependencyTodos(ctx context.Context, userID int32) ([]Todo, error)
+	// Inboxを先頭に、アーカイブ済みはinclude_archivedがtrueのときだけ返す
+	ListProjects(ctx context.Context, arg ListProjectsParams) ([]Project, error)
+	ListReminders(ctx context.Context, todoID int32) ([]ListRemindersRow, error)
+	// 並び順に、ゴミ箱にないTodoの件数を付けて返す
+	ListStatuses(ctx context.Context, userID int32) ([]ListStatusesRow, error)
+	// Todoごとの進捗（完了数/総数）をまとめて取得
+	ListSubtaskProgress(ctx context.Context, todoIds []int32) ([]ListSubtaskProgressRow, error)
+	ListSubtasks(ctx context.Context, todoID int32) ([]Subtask, error)
+	ListTags(ctx context.Context, userID int32) ([]Tag, error)
+	ListTagsByIDs(ctx context.Context, arg ListTagsByIDsParams) ([]Tag, error)
+	// todo_idsのいずれかがブロックしている・ブロックされている依存関係。ゴミ箱のTodoとの依存関係は除く
+	ListTodoDependencies(ctx context.Context, todoIds []int32) ([]ListTodoDependenciesRow, error)
+	ListTodoEvents(ctx context.Context, todoID int32) ([]TodoEvent, error)
+	ListTodoTagIDs(ctx context.Context, todoID int32) ([]int32, error)
+	// タグはJSON配列として同じクエリで取得する（N+1を避ける）
+	// NULLの条件は絞り込みに使わない。日付・日時の範囲は from 以上 before 未満
+	// overdue_atを渡すと期限切れのみ。時刻付きはdue_atで、日付だけのものはoverdue_date（ユーザーの今日）で判定する
+	// awake_atを渡すとその時点でスヌーズ中のものを除き、snoozed_atを渡すとその時点でスヌーズ中のものだけを返す
+	// tag_idsが空なら絞り込みなし、match_all_tagsがtrueなら全タグを持つTodoのみ
+	// text_termsはすべてタイトルに含まれる必要がある（LIKEの特殊文字はエスケープ済み）
+	// sort_byが空なら作成日時の新しい順、manualなら位置キーの順
+	// 並び順はsort_keyの各列の昇順。after_*を渡すとその位置より後ろだけを返す（キーセットページング）
+	// page_limitがNULLなら全件
+	ListTodos(ctx context.Context, arg ListTodosParams) ([]ListTodosRow, error)
+	// ゴミ箱の一覧。削除日時の新しい順
+	ListTrashedTodos(ctx context.Context, userID int32) ([]ListTrashedTodosRow, error)
+	// ユーザーの直近page_limit件の操作（トランザクション単位）のうち、まだ取り消していないイベントを新しい順に返す
+	// 同じ操作を二重に取り消さないよう行をロックする
+	ListUndoableTodoEvents(ctx context.Context, arg ListUndoableTodoEventsParams) ([]TodoEvent, error)
+	// 依存関係の追加をユーザーごとに直列化し、同時に追加された依存関係で循環ができないようにする
+	LockTodoDependencies(ctx context.Context, userID int32) error
+	// 返信が残っているコメントは本文を消して削除済みとして残す
+	MarkCommentDeleted(ctx context.Context, id int32) error
+	// 試行回数がmax_attemptsに達したリマインダーはClaimDueRemindersで選ばれなくなる
+	MarkReminderFailed(ctx context.Context, arg MarkReminderFailedParams) error
+	MarkReminderSent(ctx context.Context, arg MarkReminderSentParams) error
+	MarkTodoEventsUndone(ctx context.Context, ids []int64) error
+	// プロジェクト削除時にTodoを別のプロジェクト（Inbox）へ移す
+	MoveProjectTodos(ctx context.Context, arg MoveProjectTodosParams) error
+	// ゴミ箱にあるTodoだけを完全に削除する
+	PurgeTodo(ctx context.Context, arg PurgeTodoParams) (int64, error)
+	// 保持期間を過ぎたゴミ箱のTodoを全ユーザー分まとめて削除する
+	PurgeTrash(ctx context.Context, deletedAt sql.NullTime) (int64, error)
+	// 位置キーがmax_lengthより長くなったユーザーのTodoを、今の並び順のまま固定長のキーで振り直す
+	// 奇数を16進にしているので、キーの末尾が0になることはない
+	RebalanceTodoPositions(ctx context.Context, maxLength int32) (int64, error)
+	// 削除したステータスを他のステータスの移動先から外す
+	RemoveNextStatus(ctx context.Context, arg RemoveNextStatusParams) error
+	RestoreTodo(ctx context.Context, arg RestoreTodoParams) (int64, error)
+	// 時刻付きの期日は瞬間(due_at)を保ったまま、新しいタイムゾーンでの日付にdue_dateを合わせる
+	RezoneTodoDueDates(ctx context.Context, arg RezoneTodoDueDatesParams) error
+	// タイトル検索。patternは最長の検索語で、pg_trgmのインデックスを使うためWHEREに直接置く
+	// 残りの検索語もすべて含むもの、または検索文字列全体と語単位で似ているもの（<%）を返す
+	// 全語を含むものを先に、その中では類似度の高い順
+	SearchTodos(ctx context.Context, arg SearchTodosParams) ([]SearchTodosRow, error)
+	// このトランザクションの間、Todoを更新してもupdated_atとversionを変えない(並び順を変えない位置キーの振り直し用)
+	SkipTodoTouchTriggers(ctx context.Context) error
+	ToggleSubtaskComplete(ctx context.Context, arg ToggleSubtaskCompleteParams) (Subtask, error)
+	// プロジェクトのTodoはゴミ箱へ移す。履歴を残すため対象のIDを返す
+	TrashProjectTodos(ctx context.Context, projectID int32) ([]int32, error)
+	// 削除はゴミ箱へ移すだけ。完全に消すのはPurgeTodo/PurgeTrash
+	TrashTodo(ctx context.Context, arg TrashTodoParams) (int64, error)
+	UpdateCommentBody(ctx context.Context, arg UpdateCommentBodyParams) (Comment, error)
+	UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error)
+	UpdateStatus(ctx context.Context, arg UpdateStatusParams) (Status, error)
+	UpdateStatusSortOrder(ctx context.Context, arg UpdateStatusSortOrderParams) error
+	UpdateSubtaskPosition(ctx context.Context, arg UpdateSubtaskPositionParams) error
+	UpdateSubtaskTitle(ctx context.Context, arg UpdateSubtaskTitleParams) (Subtask, error)
+	UpdateTag(ctx context.Context, arg UpdateTagParams) (Tag, error)
 	UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error)
+	// 移動は1行の位置キーを書き換えるだけ
+	UpdateTodoPosition(ctx context.Context, arg UpdateTodoPositionParams) (int64, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 }
 
@@ -2,74 +2,103 @@ package persistence
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
 )
 
+// RefreshToken stores refresh tokens as their SHA-256 hash. Tokens are random 256-bit values,
+// so an unsalted hash cannot be reversed, and lookups hash the token the client presents.
 type RefreshToken struct {
 	db *sql.DB
 }
 
-func NewRefreshToken(db *sql.DB) *RefreshToken {
+func NewRefreshToken(db *sql.DB) usecase.RefreshTokenRepository {
 	return &RefreshToken{db: db}
 }
 
-func (rt *RefreshToken) StoreRefreshToken(ctx context.Context, tokenID string, userID int, expiresAt time.Time) error {
+func (rt *RefreshToken) StoreRefreshToken(ctx context.Context, tokenID, familyID string, userID int, expiresAt time.Time) error {
 	query := `
-		INSERT INTO refresh_tokens (token_id, user_id, expires_at) 
-		VALUES ($1, $2, $3)
+		INSERT INTO refresh_tokens (token_hash, family_id, user_id, expires_at)
+		VALUES ($1, $2, $3, $4)
 	`
-	_, err := rt.db.ExecContext(ctx, query, tokenID, userID, expiresAt)
+	_, err := rt.db.ExecContext(ctx, query, hashRefreshToken(tokenID), familyID, userID, expiresAt)
 	if err != nil {
 		return fmt.Errorf("failed to store refresh token: %w", err)
 	}
 	return nil
 }
 
-func (rt *RefreshToken) GetRefreshToken(ctx context.Context, tokenID string) (int, bool, error) {
+// GetRefreshToken returns the stored token regardless of its state, or nil if it does not exist
+func (rt *RefreshToken) GetRefreshToken(ctx context.Context, tokenID string) (*domain.RefreshToken, error) {
 	query := `
-		SELECT user_id, is_revoked, expires_at 
-		FROM refresh_tokens 
-		WHERE token_id = $1
+		SELECT token_hash, family_id, user_id, is_revoked, expires_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
 	`
-	var userID int
-	var isRevoked bool
-	var expiresAt time.Time
-
-	err := rt.db.QueryRowContext(ctx, query, tokenID).Scan(&userID, &isRevoked, &expiresAt)
+	token, err := scanRefreshToken(rt.db.QueryRowContext(ctx, query, hashRefreshToken(tokenID)))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return 0, false, nil
+			return nil, nil
 		}
-		return 0, false, fmt.Errorf("failed to get refresh token: %w", err)
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
 	}
+	return token, nil
+}
 
-	// Check if token is expired or revoked
-	if isRevoked || time.Now().After(expiresAt) {
-		return 0, false, nil
+// ConsumeRefreshToken atomically revokes a live token and returns it.
+// It returns nil if the token is unknown, already revoked or expired.
+func (rt *RefreshToken) ConsumeRefreshToken(ctx context.Context, tokenID string) (*domain.RefreshToken, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET is_revoked = TRUE
+		WHERE token_hash = $1 AND is_revoked = FALSE AND expires_at > NOW()
+		RETURNING token_hash, family_id, user_id, is_revoked, expires_at, created_at
+	`
+	token, err := scanRefreshToken(rt.db.QueryRowContext(ctx, query, hashRefreshToken(tokenID)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume refresh token: %w", err)
 	}
-
-	return userID, true, nil
+	return token, nil
 }
 
 func (rt *RefreshToken) RevokeRefreshToken(ctx context.Context, tokenID string) error {
 	query := `
-		UPDATE refresh_tokens 
-		SET is_revoked = TRUE 
-		WHERE token_id = $1
+		UPDATE refresh_tokens
+		SET is_revoked = TRUE
+		WHERE token_hash = $1
 	`
-	_, err := rt.db.ExecContext(ctx, query, tokenID)
+	_, err := rt.db.ExecContext(ctx, query, hashRefreshToken(tokenID))
 	if err != nil {
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
 	}
 	return nil
 }
 
+func (rt *RefreshToken) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET is_revoked = TRUE
+		WHERE family_id = $1 AND is_revoked = FALSE
+	`
+	_, err := rt.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
 func (rt *RefreshToken) RevokeAllUserTokens(ctx context.Context, userID int) error {
 	query := `
-		UPDATE refresh_tokens 
-		SET is_revoked = TRUE 
+		UPDATE refresh_tokens
+		SET is_revoked = TRUE
 		WHERE user_id = $1 AND is_revoked = FALSE
 	`
 	_, err := rt.db.ExecContext(ctx, query, userID)
@@ -79,10 +108,12 @@ func (rt *RefreshToken) RevokeAllUserTokens(ctx context.Context, userID int) err
 	return nil
 }
 
+// CleanupExpiredTokens removes expired tokens. Revoked tokens are kept until they
+// expire so that replaying a rotated token can still be detected.
 func (rt *RefreshToken) CleanupExpiredTokens(ctx context.Context) error {
 	query := `
-		DELETE FROM refresh_tokens 
-		WHERE expires_at <= NOW() OR is_revoked = TRUE
+		DELETE FROM refresh_tokens
+		WHERE expires_at <= NOW()
 	`
 	_, err := rt.db.ExecContext(ctx, query)
 	if err != nil {
@@ -90,3 +121,27 @@ func (rt *RefreshToken) CleanupExpiredTokens(ctx context.Context) error {
 	}
 	return nil
 }
+
+// hashRefreshToken returns the hex SHA-256 hash under which a token is stored
+func hashRefreshToken(tokenID string) string {
+	sum := sha256.Sum256([]byte(tokenID))
+	return hex.EncodeToString(sum[:])
+}
+
+func scanRefreshToken(row *sql.Row) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	var createdAt sql.NullTime
+	err := row.Scan(
+		&token.TokenID,
+		&token.FamilyID,
+		&token.UserID,
+		&token.IsRevoked,
+		&token.ExpiresAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	token.CreatedAt = fromSQLNullTime(createdAt)
+	return &token, nil
+}
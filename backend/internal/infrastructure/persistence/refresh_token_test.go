@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRefreshTokenIsStoredHashed(t *testing.T) {
+	const token = "refresh-token"
+	// SHA-256 of token, hex encoded
+	const hash = "0eb17643d4e9261163783a420859c92c7d212fa9624106a12b510afbec266120"
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		call   func(ctx context.Context, rt *RefreshToken) error
+	}{
+		{
+			name: "store",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO refresh_tokens \\(token_hash,").
+					WithArgs(hash, "family", 1, expiresAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, rt *RefreshToken) error {
+				return rt.StoreRefreshToken(ctx, token, "family", 1, expiresAt)
+			},
+		},
+		{
+			name: "get",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("WHERE token_hash = \\$1").WithArgs(hash).
+					WillReturnRows(sqlmock.NewRows([]string{"token_hash"}))
+			},
+			call: func(ctx context.Context, rt *RefreshToken) error {
+				_, err := rt.GetRefreshToken(ctx, token)
+				return err
+			},
+		},
+		{
+			name: "consume",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("WHERE token_hash = \\$1 AND is_revoked = FALSE").WithArgs(hash).
+					WillReturnRows(sqlmock.NewRows([]string{"token_hash"}))
+			},
+			call: func(ctx context.Context, rt *RefreshToken) error {
+				_, err := rt.ConsumeRefreshToken(ctx, token)
+				return err
+			},
+		},
+		{
+			name: "revoke",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("WHERE token_hash = \\$1").WithArgs(hash).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func(ctx context.Context, rt *RefreshToken) error {
+				return rt.RevokeRefreshToken(ctx, token)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			tt.expect(mock)
+			if err := tt.call(context.Background(), &RefreshToken{db: db}); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
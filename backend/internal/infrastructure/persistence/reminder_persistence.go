@@ -0,0 +1,155 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+type ReminderPersistence struct {
+	queries *Queries
+}
+
+func NewReminderPersistence(db *sql.DB) usecase.ReminderRepository {
+	return &ReminderPersistence{
+		queries: New(db),
+	}
+}
+
+func (rp *ReminderPersistence) CreateReminder(ctx context.Context, reminder *domain.Reminder) error {
+	params := CreateReminderParams{
+		TodoID:        int32(reminder.TodoID),
+		RemindAt:      toSQLNullTime(reminder.RemindAt),
+		OffsetMinutes: toSQLNullInt32(reminder.OffsetMinutes),
+		Channel:       string(reminder.Channel),
+	}
+
+	sqlcReminder, err := rp.queries.CreateReminder(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*reminder = *toDomainReminder(sqlcReminder)
+
+	return nil
+}
+
+func (rp *ReminderPersistence) GetReminder(ctx context.Context, todoID int, reminderID int) (*domain.Reminder, error) {
+	params := GetReminderParams{
+		ID:     int32(reminderID),
+		TodoID: int32(todoID),
+	}
+
+	row, err := rp.queries.GetReminder(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder := toDomainReminder(row.Reminder)
+	reminder.FireAt = fromSQLNullTimePtr(row.FireAt)
+	return reminder, nil
+}
+
+func (rp *ReminderPersistence) GetReminders(ctx context.Context, todoID int) ([]*domain.Reminder, error) {
+	rows, err := rp.queries.ListReminders(ctx, int32(todoID))
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]*domain.Reminder, len(rows))
+	for i, row := range rows {
+		reminders[i] = toDomainReminder(row.Reminder)
+		reminders[i].FireAt = fromSQLNullTimePtr(row.FireAt)
+	}
+
+	return reminders, nil
+}
+
+func (rp *ReminderPersistence) DeleteReminder(ctx context.Context, todoID int, reminderID int) (bool, error) {
+	params := DeleteReminderParams{
+		ID:     int32(reminderID),
+		TodoID: int32(todoID),
+	}
+
+	rows, err := rp.queries.DeleteReminder(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (rp *ReminderPersistence) ClaimDueReminders(ctx context.Context, now time.Time, until time.Time, limit int) ([]*usecase.DueReminder, error) {
+	params := ClaimDueRemindersParams{
+		MaxAttempts:  domain.MaxReminderAttempts,
+		Now:          now,
+		BatchSize:    int32(limit),
+		ClaimedUntil: until,
+	}
+
+	rows, err := rp.queries.ClaimDueReminders(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	due := make([]*usecase.DueReminder, len(rows))
+	for i, row := range rows {
+		// Due times are shown in the user's time zone
+		location, err := domain.LoadTimezone(row.Timezone)
+		if err != nil {
+			location = time.UTC
+		}
+		notice := &domain.ReminderNotice{
+			ReminderID: int(row.ID),
+			FireAt:     fromSQLNullTime(row.FireAt),
+			TodoID:     int(row.TodoID),
+			Title:      row.Title,
+			DueDate:    fromSQLNullTimePtr(row.DueDate),
+			DueAt:      fromSQLNullTimePtr(row.DueAt),
+			UserID:     int(row.UserID),
+			Username:   row.Username,
+			Email:      row.Email,
+			Location:   location,
+		}
+		due[i] = &usecase.DueReminder{
+			Notice:   notice,
+			Channel:  domain.ReminderChannel(row.Channel),
+			Attempts: int(row.Attempts),
+		}
+	}
+
+	return due, nil
+}
+
+func (rp *ReminderPersistence) MarkReminderSent(ctx context.Context, reminderID int, sentAt time.Time) error {
+	params := MarkReminderSentParams{
+		ID:     int32(reminderID),
+		SentAt: toSQLNullTime(&sentAt),
+	}
+	return rp.queries.MarkReminderSent(ctx, params)
+}
+
+func (rp *ReminderPersistence) MarkReminderFailed(ctx context.Context, reminderID int, message string, retryAt *time.Time) error {
+	params := MarkReminderFailedParams{
+		ID:        int32(reminderID),
+		LastError: sql.NullString{String: message, Valid: true},
+		RetryAt:   toSQLNullTime(retryAt),
+	}
+	return rp.queries.MarkReminderFailed(ctx, params)
+}
+
+func toDomainReminder(sqlcReminder Reminder) *domain.Reminder {
+	return &domain.Reminder{
+		ID:            int(sqlcReminder.ID),
+		TodoID:        int(sqlcReminder.TodoID),
+		RemindAt:      fromSQLNullTimePtr(sqlcReminder.RemindAt),
+		OffsetMinutes: fromSQLNullInt32Ptr(sqlcReminder.OffsetMinutes),
+		Channel:       domain.ReminderChannel(sqlcReminder.Channel),
+		SentAt:        fromSQLNullTimePtr(sqlcReminder.SentAt),
+		Attempts:      int(sqlcReminder.Attempts),
+		LastError:     sqlcReminder.LastError.String,
+		CreatedAt:     fromSQLNullTime(sqlcReminder.CreatedAt),
+	}
+}
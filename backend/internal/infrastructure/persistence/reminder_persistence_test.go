@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestClaimDueReminders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	now := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	until := now.Add(10 * time.Minute)
+	dueAt := time.Date(2026, 10, 17, 3, 30, 0, 0, time.UTC)
+	dueDate := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	columns := []string{"id", "todo_id", "channel", "attempts", "fire_at", "user_id", "title", "due_date", "due_at", "username", "email", "timezone"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 10, "email", 2, now.Add(-time.Minute), 7, "買い物", dueDate, dueAt, "alice", "alice@example.com", "Asia/Tokyo").
+		AddRow(2, 11, "webhook", 0, now, 8, "掃除", nil, nil, "bob", "bob@example.com", "Not/AZone")
+
+	// Rows claimed by another replica are skipped rather than waited for
+	mock.ExpectQuery(`-- name: ClaimDueReminders :many(?s).*FOR UPDATE OF reminders SKIP LOCKED.*SET claimed_until`).
+		WithArgs(domain.MaxReminderAttempts, now, 20, until).
+		WillReturnRows(rows)
+
+	due, err := NewReminderPersistence(db).ClaimDueReminders(context.Background(), now, until, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("claimed %d reminders, want 2", len(due))
+	}
+
+	first := due[0]
+	if first.Channel != domain.ReminderChannelEmail || first.Attempts != 2 {
+		t.Errorf("first reminder = %+v, want an email on its third attempt", first)
+	}
+	notice := first.Notice
+	if notice.ReminderID != 1 || notice.TodoID != 10 || notice.UserID != 7 || notice.Title != "買い物" || notice.Email != "alice@example.com" {
+		t.Errorf("first notice = %+v", notice)
+	}
+	if notice.Location.String() != "Asia/Tokyo" || notice.DueAt == nil || !notice.DueAt.Equal(dueAt) || notice.DueDate == nil {
+		t.Errorf("first notice is due %v on %v in %v", notice.DueAt, notice.DueDate, notice.Location)
+	}
+
+	// A time zone the server does not know falls back to UTC
+	second := due[1].Notice
+	if second.Location != time.UTC || second.DueAt != nil || second.DueDate != nil {
+		t.Errorf("second notice = %+v, want no due date in UTC", second)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMarkReminder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	sentAt := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	retryAt := sentAt.Add(2 * time.Minute)
+
+	mock.ExpectExec("-- name: MarkReminderSent :exec").WithArgs(1, sentAt).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("-- name: MarkReminderFailed :exec").WithArgs(2, "timeout", retryAt).WillReturnResult(sqlmock.NewResult(0, 1))
+	// A reminder that is given up has no retry time
+	mock.ExpectExec("-- name: MarkReminderFailed :exec").WithArgs(3, "timeout", nil).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	reminderRepo := NewReminderPersistence(db)
+	if err := reminderRepo.MarkReminderSent(context.Background(), 1, sentAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := reminderRepo.MarkReminderFailed(context.Background(), 2, "timeout", &retryAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := reminderRepo.MarkReminderFailed(context.Background(), 3, "timeout", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
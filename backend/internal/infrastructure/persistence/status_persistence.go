@@ -0,0 +1,194 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+type StatusPersistence struct {
+	db      *sql.DB
+	queries *Queries
+}
+
+func NewStatusPersistence(db *sql.DB) usecase.StatusRepository {
+	return &StatusPersistence{
+		db:      db,
+		queries: New(db),
+	}
+}
+
+func (sp *StatusPersistence) execTx(ctx context.Context, fn func(q *Queries) error) error {
+	tx, err := sp.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(sp.queries.WithTx(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (sp *StatusPersistence) CreateStatus(ctx context.Context, status *domain.Status) error {
+	params := CreateStatusParams{
+		UserID:        int32(status.UserID),
+		Name:          status.Name,
+		Category:      string(status.Category),
+		WipLimit:      toSQLNullInt32(status.WIPLimit),
+		NextStatusIds: toNullableInt32Slice(status.NextStatusIDs),
+	}
+
+	sqlcStatus, err := sp.queries.CreateStatus(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*status = *toDomainStatus(sqlcStatus)
+
+	return nil
+}
+
+func (sp *StatusPersistence) GetStatus(ctx context.Context, userID int, statusID int) (*domain.Status, error) {
+	params := GetStatusParams{
+		ID:     int32(statusID),
+		UserID: int32(userID),
+	}
+
+	sqlcStatus, err := sp.queries.GetStatus(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainStatus(sqlcStatus), nil
+}
+
+func (sp *StatusPersistence) GetStatuses(ctx context.Context, userID int) ([]*domain.Status, error) {
+	rows, err := sp.queries.ListStatuses(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*domain.Status, len(rows))
+	for i, row := range rows {
+		statuses[i] = toDomainStatus(row.Status)
+		statuses[i].TodoCount = int(row.TodoCount)
+	}
+
+	return statuses, nil
+}
+
+func (sp *StatusPersistence) UpdateStatus(ctx context.Context, status *domain.Status) error {
+	params := UpdateStatusParams{
+		ID:            int32(status.ID),
+		UserID:        int32(status.UserID),
+		Name:          status.Name,
+		Category:      string(status.Category),
+		WipLimit:      toSQLNullInt32(status.WIPLimit),
+		NextStatusIds: toNullableInt32Slice(status.NextStatusIDs),
+	}
+
+	sqlcStatus, err := sp.queries.UpdateStatus(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*status = *toDomainStatus(sqlcStatus)
+
+	return nil
+}
+
+func (sp *StatusPersistence) ReorderStatuses(ctx context.Context, userID int, statusIDs []int) error {
+	return sp.execTx(ctx, func(q *Queries) error {
+		for i, statusID := range statusIDs {
+			params := UpdateStatusSortOrderParams{
+				ID:        int32(statusID),
+				UserID:    int32(userID),
+				SortOrder: int32(i),
+			}
+			if err := q.UpdateStatusSortOrder(ctx, params); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (sp *StatusPersistence) DeleteStatus(ctx context.Context, userID int, statusID int) error {
+	return sp.execTx(ctx, func(q *Queries) error {
+		removeParams := RemoveNextStatusParams{
+			StatusID: int32(statusID),
+			UserID:   int32(userID),
+		}
+		if err := q.RemoveNextStatus(ctx, removeParams); err != nil {
+			return err
+		}
+
+		params := DeleteStatusParams{
+			ID:     int32(statusID),
+			UserID: int32(userID),
+		}
+		return q.DeleteStatus(ctx, params)
+	})
+}
+
+// reserveStatusSlot locks the status a todo is about to move into and checks that it is
+// below its WIP limit, so that concurrent moves into it cannot overfill it
+func reserveStatusSlot(ctx context.Context, q *Queries, userID int, statusID int) error {
+	params := GetStatusForUpdateParams{
+		ID:     int32(statusID),
+		UserID: int32(userID),
+	}
+	sqlcStatus, err := q.GetStatusForUpdate(ctx, params)
+	if err == sql.ErrNoRows {
+		return domain.ErrStatusNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !sqlcStatus.WipLimit.Valid {
+		return nil
+	}
+
+	count, err := q.CountStatusTodos(ctx, sql.NullInt32{Int32: sqlcStatus.ID, Valid: true})
+	if err != nil {
+		return err
+	}
+	if count >= int64(sqlcStatus.WipLimit.Int32) {
+		return domain.ErrWIPLimitReached
+	}
+	return nil
+}
+
+// toNullableInt32Slice keeps nil apart from an empty list, which the database stores as NULL and '{}'
+func toNullableInt32Slice(ints []int) []int32 {
+	if ints == nil {
+		return nil
+	}
+	return toInt32Slice(ints)
+}
+
+func toDomainStatus(sqlcStatus Status) *domain.Status {
+	status := &domain.Status{
+		ID:        int(sqlcStatus.ID),
+		UserID:    int(sqlcStatus.UserID),
+		Name:      sqlcStatus.Name,
+		Category:  domain.StatusCategory(sqlcStatus.Category),
+		SortOrder: int(sqlcStatus.SortOrder),
+		WIPLimit:  fromSQLNullInt32Ptr(sqlcStatus.WipLimit),
+		CreatedAt: fromSQLNullTime(sqlcStatus.CreatedAt),
+		UpdatedAt: fromSQLNullTime(sqlcStatus.UpdatedAt),
+	}
+	if sqlcStatus.NextStatusIds != nil {
+		status.NextStatusIDs = make([]int, len(sqlcStatus.NextStatusIds))
+		for i, id := range sqlcStatus.NextStatusIds {
+			status.NextStatusIDs[i] = int(id)
+		}
+	}
+	return status
+}
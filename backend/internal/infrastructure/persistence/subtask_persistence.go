@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+type SubtaskPersistence struct {
+	db      *sql.DB
+	queries *Queries
+}
+
+func NewSubtaskPersistence(db *sql.DB) usecase.SubtaskRepository {
+	return &SubtaskPersistence{
+		db:      db,
+		queries: New(db),
+	}
+}
+
+func (sp *SubtaskPersistence) CreateSubtask(ctx context.Context, subtask *domain.Subtask) error {
+	params := CreateSubtaskParams{
+		TodoID: int32(subtask.TodoID),
+		Title:  subtask.Title,
+	}
+
+	sqlcSubtask, err := sp.queries.CreateSubtask(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*subtask = *toDomainSubtask(sqlcSubtask)
+
+	return nil
+}
+
+func (sp *SubtaskPersistence) GetSubtask(ctx context.Context, todoID int, subtaskID int) (*domain.Subtask, error) {
+	params := GetSubtaskParams{
+		ID:     int32(subtaskID),
+		TodoID: int32(todoID),
+	}
+
+	sqlcSubtask, err := sp.queries.GetSubtask(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainSubtask(sqlcSubtask), nil
+}
+
+func (sp *SubtaskPersistence) GetSubtasks(ctx context.Context, todoID int) ([]*domain.Subtask, error) {
+	sqlcSubtasks, err := sp.queries.ListSubtasks(ctx, int32(todoID))
+	if err != nil {
+		return nil, err
+	}
+
+	subtasks := make([]*domain.Subtask, len(sqlcSubtasks))
+	for i, sqlcSubtask := range sqlcSubtasks {
+		subtasks[i] = toDomainSubtask(sqlcSubtask)
+	}
+
+	return subtasks, nil
+}
+
+func (sp *SubtaskPersistence) UpdateSubtask(ctx context.Context, subtask *domain.Subtask) error {
+	params := UpdateSubtaskTitleParams{
+		ID:     int32(subtask.ID),
+		TodoID: int32(subtask.TodoID),
+		Title:  subtask.Title,
+	}
+
+	sqlcSubtask, err := sp.queries.UpdateSubtaskTitle(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*subtask = *toDomainSubtask(sqlcSubtask)
+
+	return nil
+}
+
+func (sp *SubtaskPersistence) ToggleSubtaskComplete(ctx context.Context, todoID int, subtaskID int) (*domain.Subtask, error) {
+	params := ToggleSubtaskCompleteParams{
+		ID:     int32(subtaskID),
+		TodoID: int32(todoID),
+	}
+
+	sqlcSubtask, err := sp.queries.ToggleSubtaskComplete(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainSubtask(sqlcSubtask), nil
+}
+
+// ReorderSubtasks assigns positions following the order of subtaskIDs in one transaction
+func (sp *SubtaskPersistence) ReorderSubtasks(ctx context.Context, todoID int, subtaskIDs []int) error {
+	tx, err := sp.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := sp.queries.WithTx(tx)
+	for position, subtaskID := range subtaskIDs {
+		params := UpdateSubtaskPositionParams{
+			ID:       int32(subtaskID),
+			TodoID:   int32(todoID),
+			Position: int32(position),
+		}
+		if err := q.UpdateSubtaskPosition(ctx, params); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return rbErr
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (sp *SubtaskPersistence) DeleteSubtask(ctx context.Context, todoID int, subtaskID int) error {
+	params := DeleteSubtaskParams{
+		ID:     int32(subtaskID),
+		TodoID: int32(todoID),
+	}
+
+	return sp.queries.DeleteSubtask(ctx, params)
+}
+
+func toDomainSubtask(sqlcSubtask Subtask) *domain.Subtask {
+	return &domain.Subtask{
+		ID:          int(sqlcSubtask.ID),
+		TodoID:      int(sqlcSubtask.TodoID),
+		Title:       sqlcSubtask.Title,
+		IsCompleted: sqlcSubtask.IsCompleted,
+		Position:    int(sqlcSubtask.Position),
+		CreatedAt:   fromSQLNullTime(sqlcSubtask.CreatedAt),
+		UpdatedAt:   fromSQLNullTime(sqlcSubtask.UpdatedAt),
+	}
+}
@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+type TagPersistence struct {
+	queries *Queries
+}
+
+func NewTagPersistence(db *sql.DB) usecase.TagRepository {
+	return &TagPersistence{
+		queries: New(db),
+	}
+}
+
+func (tp *TagPersistence) CreateTag(ctx context.Context, tag *domain.Tag) error {
+	params := CreateTagParams{
+		UserID: int32(tag.UserID),
+		Name:   tag.Name,
+		Color:  tag.Color,
+	}
+
+	sqlcTag, err := tp.queries.CreateTag(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*tag = *toDomainTag(sqlcTag)
+
+	return nil
+}
+
+func (tp *TagPersistence) GetTag(ctx context.Context, userID int, tagID int) (*domain.Tag, error) {
+	params := GetTagParams{
+		ID:     int32(tagID),
+		UserID: int32(userID),
+	}
+
+	sqlcTag, err := tp.queries.GetTag(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainTag(sqlcTag), nil
+}
+
+func (tp *TagPersistence) GetTagByName(ctx context.Context, userID int, name string) (*domain.Tag, error) {
+	params := GetTagByNameParams{
+		UserID: int32(userID),
+		Name:   name,
+	}
+
+	sqlcTag, err := tp.queries.GetTagByName(ctx, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return toDomainTag(sqlcTag), nil
+}
+
+func (tp *TagPersistence) GetTags(ctx context.Context, userID int) ([]*domain.Tag, error) {
+	sqlcTags, err := tp.queries.ListTags(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainTags(sqlcTags), nil
+}
+
+// GetTagsByIDs returns only the tags owned by the user; unknown IDs are skipped
+func (tp *TagPersistence) GetTagsByIDs(ctx context.Context, userID int, tagIDs []int) ([]*domain.Tag, error) {
+	params := ListTagsByIDsParams{
+		UserID: int32(userID),
+		TagIds: toInt32Slice(tagIDs),
+	}
+
+	sqlcTags, err := tp.queries.ListTagsByIDs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainTags(sqlcTags), nil
+}
+
+func (tp *TagPersistence) UpdateTag(ctx context.Context, tag *domain.Tag) error {
+	params := UpdateTagParams{
+		ID:     int32(tag.ID),
+		UserID: int32(tag.UserID),
+		Name:   tag.Name,
+		Color:  tag.Color,
+	}
+
+	sqlcTag, err := tp.queries.UpdateTag(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	*tag = *toDomainTag(sqlcTag)
+
+	return nil
+}
+
+func (tp *TagPersistence) DeleteTag(ctx context.Context, userID int, tagID int) error {
+	params := DeleteTagParams{
+		ID:     int32(tagID),
+		UserID: int32(userID),
+	}
+
+	return tp.queries.DeleteTag(ctx, params)
+}
+
+func toDomainTag(sqlcTag Tag) *domain.Tag {
+	return &domain.Tag{
+		ID:        int(sqlcTag.ID),
+		UserID:    int(sqlcTag.UserID),
+		Name:      sqlcTag.Name,
+		Color:     sqlcTag.Color,
+		CreatedAt: fromSQLNullTime(sqlcTag.CreatedAt),
+		UpdatedAt: fromSQLNullTime(sqlcTag.UpdatedAt),
+	}
+}
+
+func toDomainTags(sqlcTags []Tag) []*domain.Tag {
+	tags := make([]*domain.Tag, len(sqlcTags))
+	for i, sqlcTag := range sqlcTags {
+		tags[i] = toDomainTag(sqlcTag)
+	}
+	return tags
+}
+
+// toInt32Slice never returns nil, because pq.Array(nil) is sent as NULL
+func toInt32Slice(ids []int) []int32 {
+	result := make([]int32, len(ids))
+	for i, id := range ids {
+		result[i] = int32(id)
+	}
+	return result
+}
@@ -8,28 +8,155 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
 )
 
+const clearTodoRecurrence = `-- name: ClearTodoRecurrence :execrows
+UPDATE todos
+SET recurrence_rule = NULL,
+    recurrence_from_completion = FALSE
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+`
+
+type ClearTodoRecurrenceParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) ClearTodoRecurrence(ctx context.Context, arg ClearTodoRecurrenceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, clearTodoRecurrence, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countTodos = `-- name: CountTodos :one
+SELECT COUNT(*) FROM todos
+WHERE todos.user_id = $1
+  AND todos.deleted_at IS NULL
+  AND (
+    cardinality($2::int[]) = 0
+    OR (
+        SELECT COUNT(*) FROM todo_tags
+        WHERE todo_tags.todo_id = todos.id AND todo_tags.tag_id = ANY($2::int[])
+    ) >= CASE WHEN $3::bool THEN cardinality($2::int[]) ELSE 1 END
+  )
+  AND ($4::int IS NULL OR todos.project_id = $4::int)
+  AND ($5::int IS NULL OR todos.status_id = $5::int)
+  AND ($6::bool IS NULL OR todos.is_completed = $6::bool)
+  AND ($7::int IS NULL OR todos.priority >= $7::int)
+  AND ($8::int IS NULL OR todos.priority <= $8::int)
+  AND (NOT $9::bool OR todos.due_date IS NULL)
+  AND ($10::date IS NULL OR todos.due_date >= $10::date)
+  AND ($11::date IS NULL OR todos.due_date < $11::date)
+  AND ($12::timestamptz IS NULL OR CASE
+    WHEN todos.due_at IS NOT NULL THEN todos.due_at < $12::timestamptz
+    ELSE todos.due_date < $13::date
+  END)
+  AND ($14::timestamptz IS NULL OR todos.created_at >= $14::timestamptz)
+  AND ($15::timestamptz IS NULL OR todos.created_at < $15::timestamptz)
+  AND ($16::timestamptz IS NULL OR todos.updated_at >= $16::timestamptz)
+  AND ($17::timestamptz IS NULL OR todos.updated_at < $17::timestamptz)
+  AND ($18::timestamptz IS NULL OR todos.snoozed_until IS NULL OR todos.snoozed_until <= $18::timestamptz)
+  AND ($19::timestamptz IS NULL OR todos.snoozed_until > $19::timestamptz)
+  AND NOT EXISTS (
+    SELECT 1 FROM unnest($20::text[]) AS term
+    WHERE todos.title NOT ILIKE '%' || term || '%'
+  )
+`
+
+type CountTodosParams struct {
+	UserID        int32         `json:"user_id"`
+	TagIds        []int32       `json:"tag_ids"`
+	MatchAllTags  bool          `json:"match_all_tags"`
+	ProjectID     sql.NullInt32 `json:"project_id"`
+	StatusID      sql.NullInt32 `json:"status_id"`
+	IsCompleted   sql.NullBool  `json:"is_completed"`
+	PriorityMin   sql.NullInt32 `json:"priority_min"`
+	PriorityMax   sql.NullInt32 `json:"priority_max"`
+	NoDueDate     bool          `json:"no_due_date"`
+	DueFrom       sql.NullTime  `json:"due_from"`
+	DueBefore     sql.NullTime  `json:"due_before"`
+	OverdueAt     sql.NullTime  `json:"overdue_at"`
+	OverdueDate   sql.NullTime  `json:"overdue_date"`
+	CreatedFrom   sql.NullTime  `json:"created_from"`
+	CreatedBefore sql.NullTime  `json:"created_before"`
+	UpdatedFrom   sql.NullTime  `json:"updated_from"`
+	UpdatedBefore sql.NullTime  `json:"updated_before"`
+	AwakeAt       sql.NullTime  `json:"awake_at"`
+	SnoozedAt     sql.NullTime  `json:"snoozed_at"`
+	TextTerms     []string      `json:"text_terms"`
+}
+
+// ListTodosと同じ絞り込み条件で件数を数える
+func (q *Queries) CountTodos(ctx context.Context, arg CountTodosParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTodos,
+		arg.UserID,
+		pq.Array(arg.TagIds),
+		arg.MatchAllTags,
+		arg.ProjectID,
+		arg.StatusID,
+		arg.IsCompleted,
+		arg.PriorityMin,
+		arg.PriorityMax,
+		arg.NoDueDate,
+		arg.DueFrom,
+		arg.DueBefore,
+		arg.OverdueAt,
+		arg.OverdueDate,
+		arg.CreatedFrom,
+		arg.CreatedBefore,
+		arg.UpdatedFrom,
+		arg.UpdatedBefore,
+		arg.AwakeAt,
+		arg.SnoozedAt,
+		pq.Array(arg.TextTerms),
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTodo = `-- name: CreateTodo :one
 INSERT INTO todos (
     user_id,
     title,
     due_date,
     priority,
-    is_completed
+    is_completed,
+    project_id,
+    recurrence_rule,
+    recurrence_from_completion,
+    position,
+    notes,
+    due_at,
+    snoozed_until,
+    status_id
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, user_id, title, due_date, priority, is_completed, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING id, user_id, title, due_date, priority, is_completed, created_at, updated_at, project_id, recurrence_rule, recurrence_from_completion, deleted_at, version, position, notes, due_at, snoozed_until, status_id
 `
 
 type CreateTodoParams struct {
-	UserID      int32        `json:"user_id"`
-	Title       string       `json:"title"`
-	DueDate     sql.NullTime `json:"due_date"`
-	Priority    int32        `json:"priority"`
-	IsCompleted bool         `json:"is_completed"`
+	UserID                   int32          `json:"user_id"`
+	Title                    string         `json:"title"`
+	DueDate                  sql.NullTime   `json:"due_date"`
+	Priority                 int32          `json:"priority"`
+	IsCompleted              bool           `json:"is_completed"`
+	ProjectID                sql.NullInt32  `json:"project_id"`
+	RecurrenceRule           sql.NullString `json:"recurrence_rule"`
+	RecurrenceFromCompletion bool           `json:"recurrence_from_completion"`
+	Position                 string         `json:"position"`
+	Notes                    string         `json:"notes"`
+	DueAt                    sql.NullTime   `json:"due_at"`
+	SnoozedUntil             sql.NullTime   `json:"snoozed_until"`
+	StatusID                 sql.NullInt32  `json:"status_id"`
 }
 
+// status_idとis_completedはトリガーで揃える。status_idがNULLならis_completedに合う最初のステータスに入る
 func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error) {
 	row := q.db.QueryRowContext(ctx, createTodo,
 		arg.UserID,
@@ -37,6 +164,14 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		arg.DueDate,
 		arg.Priority,
 		arg.IsCompleted,
+		arg.ProjectID,
+		arg.RecurrenceRule,
+		arg.RecurrenceFromCompletion,
+		arg.Position,
+		arg.Notes,
+		arg.DueAt,
+		arg.SnoozedUntil,
+		arg.StatusID,
 	)
 	var i Todo
 	err := row.Scan(
@@ -48,32 +183,137 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		&i.IsCompleted,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.RecurrenceRule,
+		&i.RecurrenceFromCompletion,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Position,
+		&i.Notes,
+		&i.DueAt,
+		&i.SnoozedUntil,
+		&i.StatusID,
 	)
 	return i, err
 }
 
-const deleteTodo = `-- name: DeleteTodo :exec
-DELETE FROM todos
-WHERE id = $1 AND user_id = $2
+const getFirstTodoPosition = `-- name: GetFirstTodoPosition :one
+SELECT position FROM todos
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY position ASC
+LIMIT 1
 `
 
-type DeleteTodoParams struct {
-	ID     int32 `json:"id"`
-	UserID int32 `json:"user_id"`
+// 新しいTodoは手動の並び順の先頭に置くため、いちばん前の位置キーを返す
+func (q *Queries) GetFirstTodoPosition(ctx context.Context, userID int32) (string, error) {
+	row := q.db.QueryRowContext(ctx, getFirstTodoPosition, userID)
+	var position string
+	err := row.Scan(&position)
+	return position, err
 }
 
-func (q *Queries) DeleteTodo(ctx context.Context, arg DeleteTodoParams) error {
-	_, err := q.db.ExecContext(ctx, deleteTodo, arg.ID, arg.UserID)
-	return err
+const getNextTodoPosition = `-- name: GetNextTodoPosition :one
+SELECT position FROM todos
+WHERE user_id = $1 AND deleted_at IS NULL
+  AND position > $2::text COLLATE "C" AND id <> $3
+ORDER BY position ASC
+LIMIT 1
+`
+
+type GetNextTodoPositionParams struct {
+	UserID    int32  `json:"user_id"`
+	Position  string `json:"position"`
+	ExcludeID int32  `json:"exclude_id"`
+}
+
+// positionの直後にあるTodoの位置キー（移動するTodo自身は除く）
+func (q *Queries) GetNextTodoPosition(ctx context.Context, arg GetNextTodoPositionParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getNextTodoPosition, arg.UserID, arg.Position, arg.ExcludeID)
+	var position string
+	err := row.Scan(&position)
+	return position, err
+}
+
+const getPreviousTodoPosition = `-- name: GetPreviousTodoPosition :one
+SELECT position FROM todos
+WHERE user_id = $1 AND deleted_at IS NULL
+  AND position < $2::text COLLATE "C" AND id <> $3
+ORDER BY position DESC
+LIMIT 1
+`
+
+type GetPreviousTodoPositionParams struct {
+	UserID    int32  `json:"user_id"`
+	Position  string `json:"position"`
+	ExcludeID int32  `json:"exclude_id"`
+}
+
+// positionの直前にあるTodoの位置キー（移動するTodo自身は除く）
+func (q *Queries) GetPreviousTodoPosition(ctx context.Context, arg GetPreviousTodoPositionParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getPreviousTodoPosition, arg.UserID, arg.Position, arg.ExcludeID)
+	var position string
+	err := row.Scan(&position)
+	return position, err
 }
 
 const getTodo = `-- name: GetTodo :one
-SELECT id, user_id, title, due_date, priority, is_completed, created_at, updated_at FROM todos
-WHERE id = $1 LIMIT 1
+SELECT todos.id, todos.user_id, todos.title, todos.due_date, todos.priority, todos.is_completed, todos.created_at, todos.updated_at, todos.project_id, todos.recurrence_rule, todos.recurrence_from_completion, todos.deleted_at, todos.version, todos.position, todos.notes, todos.due_at, todos.snoozed_until, todos.status_id, COALESCE(tag_list.tags, '[]'::json)::json AS tags
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+WHERE todos.id = $1 AND todos.deleted_at IS NULL LIMIT 1
 `
 
-func (q *Queries) GetTodo(ctx context.Context, id int32) (Todo, error) {
+type GetTodoRow struct {
+	Todo Todo            `json:"todo"`
+	Tags json.RawMessage `json:"tags"`
+}
+
+func (q *Queries) GetTodo(ctx context.Context, id int32) (GetTodoRow, error) {
 	row := q.db.QueryRowContext(ctx, getTodo, id)
+	var i GetTodoRow
+	err := row.Scan(
+		&i.Todo.ID,
+		&i.Todo.UserID,
+		&i.Todo.Title,
+		&i.Todo.DueDate,
+		&i.Todo.Priority,
+		&i.Todo.IsCompleted,
+		&i.Todo.CreatedAt,
+		&i.Todo.UpdatedAt,
+		&i.Todo.ProjectID,
+		&i.Todo.RecurrenceRule,
+		&i.Todo.RecurrenceFromCompletion,
+		&i.Todo.DeletedAt,
+		&i.Todo.Version,
+		&i.Todo.Position,
+		&i.Todo.Notes,
+		&i.Todo.DueAt,
+		&i.Todo.SnoozedUntil,
+		&i.Todo.StatusID,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getTodoForUpdate = `-- name: GetTodoForUpdate :one
+SELECT id, user_id, title, due_date, priority, is_completed, created_at, updated_at, project_id, recurrence_rule, recurrence_from_completion, deleted_at, version, position, notes, due_at, snoozed_until, status_id FROM todos
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+type GetTodoForUpdateParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// 変更前の状態を履歴に残すため、更新と同じトランザクションで行をロックして読む
+func (q *Queries) GetTodoForUpdate(ctx context.Context, arg GetTodoForUpdateParams) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, getTodoForUpdate, arg.ID, arg.UserID)
 	var i Todo
 	err := row.Scan(
 		&i.ID,
@@ -84,34 +324,228 @@ func (q *Queries) GetTodo(ctx context.Context, id int32) (Todo, error) {
 		&i.IsCompleted,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.RecurrenceRule,
+		&i.RecurrenceFromCompletion,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Position,
+		&i.Notes,
+		&i.DueAt,
+		&i.SnoozedUntil,
+		&i.StatusID,
 	)
 	return i, err
 }
 
+const getTodoPosition = `-- name: GetTodoPosition :one
+SELECT position FROM todos
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+`
+
+type GetTodoPositionParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// 移動先の基準にするTodoの位置キー
+func (q *Queries) GetTodoPosition(ctx context.Context, arg GetTodoPositionParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getTodoPosition, arg.ID, arg.UserID)
+	var position string
+	err := row.Scan(&position)
+	return position, err
+}
+
 const listTodos = `-- name: ListTodos :many
-SELECT id, user_id, title, due_date, priority, is_completed, created_at, updated_at FROM todos
-WHERE user_id = $1
-ORDER BY created_at DESC
+SELECT todos.id, todos.user_id, todos.title, todos.due_date, todos.priority, todos.is_completed, todos.created_at, todos.updated_at, todos.project_id, todos.recurrence_rule, todos.recurrence_from_completion, todos.deleted_at, todos.version, todos.position, todos.notes, todos.due_at, todos.snoozed_until, todos.status_id, COALESCE(tag_list.tags, '[]'::json)::json AS tags,
+    sort_key.sort_group, sort_key.sort_position, sort_key.sort_value, sort_key.sort_completed, sort_key.sort_created, sort_key.sort_id
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+CROSS JOIN LATERAL (
+    SELECT
+        -- 期日順ではNULLの位置を決める（昇順なら最後、降順なら最初）
+        (CASE $1::text
+            WHEN 'due_date_asc' THEN CASE WHEN todos.due_date IS NULL THEN 1 ELSE 0 END
+            WHEN 'due_date_desc' THEN CASE WHEN todos.due_date IS NULL THEN 0 ELSE 1 END
+            ELSE 0
+        END)::int AS sort_group,
+        (CASE WHEN $1::text = 'manual' THEN todos.position ELSE '' END) COLLATE "C" AS sort_position,
+        -- 降順の列は符号を反転して昇順に揃える
+        -- 期日順は日付ごとに4日分の秒の幅を取り、同じ日の中では時刻付きを期日の瞬間順に並べ、日付だけのものをその後に置く。
+        -- due_atは時差（最大±14時間）があってもその日付のUTC 0時の前後1日半に収まる
+        (CASE $1::text
+            WHEN 'due_date_asc' THEN COALESCE((todos.due_date - DATE '1970-01-01')::bigint * 345600
+                + COALESCE(floor(EXTRACT(EPOCH FROM todos.due_at))::bigint - (todos.due_date - DATE '1970-01-01')::bigint * 86400 + 86400, 259200), 0)
+            WHEN 'due_date_desc' THEN -COALESCE((todos.due_date - DATE '1970-01-01')::bigint * 345600
+                + COALESCE(floor(EXTRACT(EPOCH FROM todos.due_at))::bigint - (todos.due_date - DATE '1970-01-01')::bigint * 86400 + 86400, 259200), 0)
+            WHEN 'priority_desc' THEN -todos.priority
+            WHEN 'created_desc' THEN -COALESCE(floor(EXTRACT(EPOCH FROM todos.created_at) * 1000000), 0)
+            ELSE 0
+        END)::bigint AS sort_value,
+        (CASE WHEN $1::text <> '' AND todos.is_completed THEN 1 ELSE 0 END)::int AS sort_completed,
+        (-COALESCE(floor(EXTRACT(EPOCH FROM todos.created_at) * 1000000), 0))::bigint AS sort_created,
+        -todos.id AS sort_id
+) sort_key
+WHERE todos.user_id = $2
+  AND todos.deleted_at IS NULL
+  AND (
+    cardinality($3::int[]) = 0
+    OR (
+        SELECT COUNT(*) FROM todo_tags
+        WHERE todo_tags.todo_id = todos.id AND todo_tags.tag_id = ANY($3::int[])
+    ) >= CASE WHEN $4::bool THEN cardinality($3::int[]) ELSE 1 END
+  )
+  AND ($5::int IS NULL OR todos.project_id = $5::int)
+  AND ($6::int IS NULL OR todos.status_id = $6::int)
+  AND ($7::bool IS NULL OR todos.is_completed = $7::bool)
+  AND ($8::int IS NULL OR todos.priority >= $8::int)
+  AND ($9::int IS NULL OR todos.priority <= $9::int)
+  AND (NOT $10::bool OR todos.due_date IS NULL)
+  AND ($11::date IS NULL OR todos.due_date >= $11::date)
+  AND ($12::date IS NULL OR todos.due_date < $12::date)
+  AND ($13::timestamptz IS NULL OR CASE
+    WHEN todos.due_at IS NOT NULL THEN todos.due_at < $13::timestamptz
+    ELSE todos.due_date < $14::date
+  END)
+  AND ($15::timestamptz IS NULL OR todos.created_at >= $15::timestamptz)
+  AND ($16::timestamptz IS NULL OR todos.created_at < $16::timestamptz)
+  AND ($17::timestamptz IS NULL OR todos.updated_at >= $17::timestamptz)
+  AND ($18::timestamptz IS NULL OR todos.updated_at < $18::timestamptz)
+  AND ($19::timestamptz IS NULL OR todos.snoozed_until IS NULL OR todos.snoozed_until <= $19::timestamptz)
+  AND ($20::timestamptz IS NULL OR todos.snoozed_until > $20::timestamptz)
+  AND NOT EXISTS (
+    SELECT 1 FROM unnest($21::text[]) AS term
+    WHERE todos.title NOT ILIKE '%' || term || '%'
+  )
+  AND (
+    $22::int IS NULL
+    OR (sort_key.sort_group, sort_key.sort_position, sort_key.sort_value, sort_key.sort_completed, sort_key.sort_created, sort_key.sort_id)
+        > ($23::int, $24::text COLLATE "C", $25::bigint, $26::int, $27::bigint, $22::int)
+  )
+ORDER BY sort_key.sort_group, sort_key.sort_position, sort_key.sort_value, sort_key.sort_completed, sort_key.sort_created, sort_key.sort_id
+LIMIT $28::int
 `
 
-func (q *Queries) ListTodos(ctx context.Context, userID int32) ([]Todo, error) {
-	rows, err := q.db.QueryContext(ctx, listTodos, userID)
+type ListTodosParams struct {
+	SortBy         string         `json:"sort_by"`
+	UserID         int32          `json:"user_id"`
+	TagIds         []int32        `json:"tag_ids"`
+	MatchAllTags   bool           `json:"match_all_tags"`
+	ProjectID      sql.NullInt32  `json:"project_id"`
+	StatusID       sql.NullInt32  `json:"status_id"`
+	IsCompleted    sql.NullBool   `json:"is_completed"`
+	PriorityMin    sql.NullInt32  `json:"priority_min"`
+	PriorityMax    sql.NullInt32  `json:"priority_max"`
+	NoDueDate      bool           `json:"no_due_date"`
+	DueFrom        sql.NullTime   `json:"due_from"`
+	DueBefore      sql.NullTime   `json:"due_before"`
+	OverdueAt      sql.NullTime   `json:"overdue_at"`
+	OverdueDate    sql.NullTime   `json:"overdue_date"`
+	CreatedFrom    sql.NullTime   `json:"created_from"`
+	CreatedBefore  sql.NullTime   `json:"created_before"`
+	UpdatedFrom    sql.NullTime   `json:"updated_from"`
+	UpdatedBefore  sql.NullTime   `json:"updated_before"`
+	AwakeAt        sql.NullTime   `json:"awake_at"`
+	SnoozedAt      sql.NullTime   `json:"snoozed_at"`
+	TextTerms      []string       `json:"text_terms"`
+	AfterID        sql.NullInt32  `json:"after_id"`
+	AfterGroup     sql.NullInt32  `json:"after_group"`
+	AfterPosition  sql.NullString `json:"after_position"`
+	AfterValue     sql.NullInt64  `json:"after_value"`
+	AfterCompleted sql.NullInt32  `json:"after_completed"`
+	AfterCreated   sql.NullInt64  `json:"after_created"`
+	PageLimit      sql.NullInt32  `json:"page_limit"`
+}
+
+type ListTodosRow struct {
+	Todo          Todo            `json:"todo"`
+	Tags          json.RawMessage `json:"tags"`
+	SortGroup     int32           `json:"sort_group"`
+	SortPosition  string          `json:"sort_position"`
+	SortValue     int64           `json:"sort_value"`
+	SortCompleted int32           `json:"sort_completed"`
+	SortCreated   int64           `json:"sort_created"`
+	SortID        int32           `json:"sort_id"`
+}
+
+// タグはJSON配列として同じクエリで取得する（N+1を避ける）
+// NULLの条件は絞り込みに使わない。日付・日時の範囲は from 以上 before 未満
+// overdue_atを渡すと期限切れのみ。時刻付きはdue_atで、日付だけのものはoverdue_date（ユーザーの今日）で判定する
+// awake_atを渡すとその時点でスヌーズ中のものを除き、snoozed_atを渡すとその時点でスヌーズ中のものだけを返す
+// tag_idsが空なら絞り込みなし、match_all_tagsがtrueなら全タグを持つTodoのみ
+// text_termsはすべてタイトルに含まれる必要がある（LIKEの特殊文字はエスケープ済み）
+// sort_byが空なら作成日時の新しい順、manualなら位置キーの順
+// 並び順はsort_keyの各列の昇順。after_*を渡すとその位置より後ろだけを返す（キーセットページング）
+// page_limitがNULLなら全件
+func (q *Queries) ListTodos(ctx context.Context, arg ListTodosParams) ([]ListTodosRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTodos,
+		arg.SortBy,
+		arg.UserID,
+		pq.Array(arg.TagIds),
+		arg.MatchAllTags,
+		arg.ProjectID,
+		arg.StatusID,
+		arg.IsCompleted,
+		arg.PriorityMin,
+		arg.PriorityMax,
+		arg.NoDueDate,
+		arg.DueFrom,
+		arg.DueBefore,
+		arg.OverdueAt,
+		arg.OverdueDate,
+		arg.CreatedFrom,
+		arg.CreatedBefore,
+		arg.UpdatedFrom,
+		arg.UpdatedBefore,
+		arg.AwakeAt,
+		arg.SnoozedAt,
+		pq.Array(arg.TextTerms),
+		arg.AfterID,
+		arg.AfterGroup,
+		arg.AfterPosition,
+		arg.AfterValue,
+		arg.AfterCompleted,
+		arg.AfterCreated,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Todo
+	var items []ListTodosRow
 	for rows.Next() {
-		var i Todo
+		var i ListTodosRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Title,
-			&i.DueDate,
-			&i.Priority,
-			&i.IsCompleted,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.Todo.ID,
+			&i.Todo.UserID,
+			&i.Todo.Title,
+			&i.Todo.DueDate,
+			&i.Todo.Priority,
+			&i.Todo.IsCompleted,
+			&i.Todo.CreatedAt,
+			&i.Todo.UpdatedAt,
+			&i.Todo.ProjectID,
+			&i.Todo.RecurrenceRule,
+			&i.Todo.RecurrenceFromCompletion,
+			&i.Todo.DeletedAt,
+			&i.Todo.Version,
+			&i.Todo.Position,
+			&i.Todo.Notes,
+			&i.Todo.DueAt,
+			&i.Todo.SnoozedUntil,
+			&i.Todo.StatusID,
+			&i.Tags,
+			&i.SortGroup,
+			&i.SortPosition,
+			&i.SortValue,
+			&i.SortCompleted,
+			&i.SortCreated,
+			&i.SortID,
 		); err != nil {
 			return nil, err
 		}
@@ -126,42 +560,54 @@ func (q *Queries) ListTodos(ctx context.Context, userID int32) ([]Todo, error) {
 	return items, nil
 }
 
-const listTodosWithSort = `-- name: ListTodosWithSort :many
-SELECT id, user_id, title, due_date, priority, is_completed, created_at, updated_at FROM todos
-WHERE user_id = $1
-ORDER BY
-    CASE WHEN $2 = 'due_date_asc' THEN due_date END ASC,
-    CASE WHEN $2 = 'due_date_desc' THEN due_date END DESC,
-    CASE WHEN $2 = 'priority_desc' THEN priority END DESC,
-    CASE WHEN $2 = 'created_desc' THEN created_at END DESC,
-    is_completed ASC,
-    created_at DESC
+const listTrashedTodos = `-- name: ListTrashedTodos :many
+SELECT todos.id, todos.user_id, todos.title, todos.due_date, todos.priority, todos.is_completed, todos.created_at, todos.updated_at, todos.project_id, todos.recurrence_rule, todos.recurrence_from_completion, todos.deleted_at, todos.version, todos.position, todos.notes, todos.due_at, todos.snoozed_until, todos.status_id, COALESCE(tag_list.tags, '[]'::json)::json AS tags
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+WHERE todos.user_id = $1 AND todos.deleted_at IS NOT NULL
+ORDER BY todos.deleted_at DESC, todos.id DESC
 `
 
-type ListTodosWithSortParams struct {
-	UserID  int32       `json:"user_id"`
-	Column2 interface{} `json:"column_2"`
+type ListTrashedTodosRow struct {
+	Todo Todo            `json:"todo"`
+	Tags json.RawMessage `json:"tags"`
 }
 
-// ソート機能付きリスト取得
-func (q *Queries) ListTodosWithSort(ctx context.Context, arg ListTodosWithSortParams) ([]Todo, error) {
-	rows, err := q.db.QueryContext(ctx, listTodosWithSort, arg.UserID, arg.Column2)
+// ゴミ箱の一覧。削除日時の新しい順
+func (q *Queries) ListTrashedTodos(ctx context.Context, userID int32) ([]ListTrashedTodosRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedTodos, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Todo
+	var items []ListTrashedTodosRow
 	for rows.Next() {
-		var i Todo
+		var i ListTrashedTodosRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Title,
-			&i.DueDate,
-			&i.Priority,
-			&i.IsCompleted,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.Todo.ID,
+			&i.Todo.UserID,
+			&i.Todo.Title,
+			&i.Todo.DueDate,
+			&i.Todo.Priority,
+			&i.Todo.IsCompleted,
+			&i.Todo.CreatedAt,
+			&i.Todo.UpdatedAt,
+			&i.Todo.ProjectID,
+			&i.Todo.RecurrenceRule,
+			&i.Todo.RecurrenceFromCompletion,
+			&i.Todo.DeletedAt,
+			&i.Todo.Version,
+			&i.Todo.Position,
+			&i.Todo.Notes,
+			&i.Todo.DueAt,
+			&i.Todo.SnoozedUntil,
+			&i.Todo.StatusID,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -176,34 +622,203 @@ func (q *Queries) ListTodosWithSort(ctx context.Context, arg ListTodosWithSortPa
 	return items, nil
 }
 
-const toggleTodoComplete = `-- name: ToggleTodoComplete :one
+const purgeTodo = `-- name: PurgeTodo :execrows
+DELETE FROM todos
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+`
+
+type PurgeTodoParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// ゴミ箱にあるTodoだけを完全に削除する
+func (q *Queries) PurgeTodo(ctx context.Context, arg PurgeTodoParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTodo, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeTrash = `-- name: PurgeTrash :execrows
+DELETE FROM todos
+WHERE deleted_at < $1
+`
+
+// 保持期間を過ぎたゴミ箱のTodoを全ユーザー分まとめて削除する
+func (q *Queries) PurgeTrash(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTrash, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rebalanceTodoPositions = `-- name: RebalanceTodoPositions :execrows
+UPDATE todos SET position = ranked.new_position
+FROM (
+    SELECT id, lpad(to_hex(2 * row_number() OVER (PARTITION BY user_id ORDER BY position, id) + 1), 8, '0') AS new_position
+    FROM todos
+    WHERE user_id IN (SELECT user_id FROM todos WHERE length(position) > $1::int)
+) ranked
+WHERE todos.id = ranked.id
+`
+
+// 位置キーがmax_lengthより長くなったユーザーのTodoを、今の並び順のまま固定長のキーで振り直す
+// 奇数を16進にしているので、キーの末尾が0になることはない
+func (q *Queries) RebalanceTodoPositions(ctx context.Context, maxLength int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rebalanceTodoPositions, maxLength)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreTodo = `-- name: RestoreTodo :execrows
 UPDATE todos
-SET is_completed = NOT is_completed,
-    updated_at = CURRENT_TIMESTAMP
-WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, title, due_date, priority, is_completed, created_at, updated_at
+SET deleted_at = NULL
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
 `
 
-type ToggleTodoCompleteParams struct {
+type RestoreTodoParams struct {
 	ID     int32 `json:"id"`
 	UserID int32 `json:"user_id"`
 }
 
-// 完了切り替え専用クエリ
-func (q *Queries) ToggleTodoComplete(ctx context.Context, arg ToggleTodoCompleteParams) (Todo, error) {
-	row := q.db.QueryRowContext(ctx, toggleTodoComplete, arg.ID, arg.UserID)
-	var i Todo
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.Title,
-		&i.DueDate,
-		&i.Priority,
-		&i.IsCompleted,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+func (q *Queries) RestoreTodo(ctx context.Context, arg RestoreTodoParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreTodo, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchTodos = `-- name: SearchTodos :many
+SELECT todos.id, todos.user_id, todos.title, todos.due_date, todos.priority, todos.is_completed, todos.created_at, todos.updated_at, todos.project_id, todos.recurrence_rule, todos.recurrence_from_completion, todos.deleted_at, todos.version, todos.position, todos.notes, todos.due_at, todos.snoozed_until, todos.status_id, COALESCE(tag_list.tags, '[]'::json)::json AS tags,
+    hit.all_terms, hit.score
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+CROSS JOIN LATERAL (
+    SELECT
+        (todos.title ILIKE $1::text AND NOT EXISTS (
+            SELECT 1 FROM unnest($2::text[]) AS term
+            WHERE todos.title NOT ILIKE '%' || term || '%'
+        ))::bool AS all_terms,
+        word_similarity($3::text, todos.title)::float8 AS score
+) hit
+WHERE todos.user_id = $4
+  AND todos.deleted_at IS NULL
+  AND (
+    (todos.title ILIKE $1::text AND hit.all_terms)
+    OR $3::text <% todos.title
+  )
+ORDER BY hit.all_terms DESC, hit.score DESC, todos.updated_at DESC, todos.id DESC
+LIMIT $5::int
+`
+
+type SearchTodosParams struct {
+	Pattern   string   `json:"pattern"`
+	Terms     []string `json:"terms"`
+	Query     string   `json:"query"`
+	UserID    int32    `json:"user_id"`
+	PageLimit int32    `json:"page_limit"`
+}
+
+type SearchTodosRow struct {
+	Todo     Todo            `json:"todo"`
+	Tags     json.RawMessage `json:"tags"`
+	AllTerms bool            `json:"all_terms"`
+	Score    float64         `json:"score"`
+}
+
+// タイトル検索。patternは最長の検索語で、pg_trgmのインデックスを使うためWHEREに直接置く
+// 残りの検索語もすべて含むもの、または検索文字列全体と語単位で似ているもの（<%）を返す
+// 全語を含むものを先に、その中では類似度の高い順
+func (q *Queries) SearchTodos(ctx context.Context, arg SearchTodosParams) ([]SearchTodosRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTodos,
+		arg.Pattern,
+		pq.Array(arg.Terms),
+		arg.Query,
+		arg.UserID,
+		arg.PageLimit,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchTodosRow
+	for rows.Next() {
+		var i SearchTodosRow
+		if err := rows.Scan(
+			&i.Todo.ID,
+			&i.Todo.UserID,
+			&i.Todo.Title,
+			&i.Todo.DueDate,
+			&i.Todo.Priority,
+			&i.Todo.IsCompleted,
+			&i.Todo.CreatedAt,
+			&i.Todo.UpdatedAt,
+			&i.Todo.ProjectID,
+			&i.Todo.RecurrenceRule,
+			&i.Todo.RecurrenceFromCompletion,
+			&i.Todo.DeletedAt,
+			&i.Todo.Version,
+			&i.Todo.Position,
+			&i.Todo.Notes,
+			&i.Todo.DueAt,
+			&i.Todo.SnoozedUntil,
+			&i.Todo.StatusID,
+			&i.Tags,
+			&i.AllTerms,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const skipTodoTouchTriggers = `-- name: SkipTodoTouchTriggers :exec
+SELECT set_config('todo_app.renumbering_positions', 'on', true)
+`
+
+// このトランザクションの間、Todoを更新してもupdated_atとversionを変えない(並び順を変えない位置キーの振り直し用)
+func (q *Queries) SkipTodoTouchTriggers(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, skipTodoTouchTriggers)
+	return err
+}
+
+const trashTodo = `-- name: TrashTodo :execrows
+UPDATE todos
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+`
+
+type TrashTodoParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+// 削除はゴミ箱へ移すだけ。完全に消すのはPurgeTodo/PurgeTrash
+func (q *Queries) TrashTodo(ctx context.Context, arg TrashTodoParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, trashTodo, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const updateTodo = `-- name: UpdateTodo :one
@@ -211,18 +826,32 @@ UPDATE todos
 SET title = $2,
     due_date = $3,
     priority = $4,
-    is_completed = $5
-WHERE id = $1 AND user_id = $6
-RETURNING id, user_id, title, due_date, priority, is_completed, created_at, updated_at
+    is_completed = $5,
+    project_id = $7,
+    recurrence_rule = $8,
+    recurrence_from_completion = $9,
+    notes = $10,
+    due_at = $11,
+    snoozed_until = $12,
+    status_id = $13
+WHERE id = $1 AND user_id = $6 AND deleted_at IS NULL
+RETURNING id, user_id, title, due_date, priority, is_completed, created_at, updated_at, project_id, recurrence_rule, recurrence_from_completion, deleted_at, version, position, notes, due_at, snoozed_until, status_id
 `
 
 type UpdateTodoParams struct {
-	ID          int32        `json:"id"`
-	Title       string       `json:"title"`
-	DueDate     sql.NullTime `json:"due_date"`
-	Priority    int32        `json:"priority"`
-	IsCompleted bool         `json:"is_completed"`
-	UserID      int32        `json:"user_id"`
+	ID                       int32          `json:"id"`
+	Title                    string         `json:"title"`
+	DueDate                  sql.NullTime   `json:"due_date"`
+	Priority                 int32          `json:"priority"`
+	IsCompleted              bool           `json:"is_completed"`
+	UserID                   int32          `json:"user_id"`
+	ProjectID                sql.NullInt32  `json:"project_id"`
+	RecurrenceRule           sql.NullString `json:"recurrence_rule"`
+	RecurrenceFromCompletion bool           `json:"recurrence_from_completion"`
+	Notes                    string         `json:"notes"`
+	DueAt                    sql.NullTime   `json:"due_at"`
+	SnoozedUntil             sql.NullTime   `json:"snoozed_until"`
+	StatusID                 sql.NullInt32  `json:"status_id"`
 }
 
 func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, error) {
@@ -233,6 +862,13 @@ func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, e
 		arg.Priority,
 		arg.IsCompleted,
 		arg.UserID,
+		arg.ProjectID,
+		arg.RecurrenceRule,
+		arg.RecurrenceFromCompletion,
+		arg.Notes,
+		arg.DueAt,
+		arg.SnoozedUntil,
+		arg.StatusID,
 	)
 	var i Todo
 	err := row.Scan(
@@ -244,6 +880,37 @@ func (q *Queries) UpdateTodo(ctx context.Context, arg UpdateTodoParams) (Todo, e
 		&i.IsCompleted,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.RecurrenceRule,
+		&i.RecurrenceFromCompletion,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Position,
+		&i.Notes,
+		&i.DueAt,
+		&i.SnoozedUntil,
+		&i.StatusID,
 	)
 	return i, err
 }
+
+const updateTodoPosition = `-- name: UpdateTodoPosition :execrows
+UPDATE todos
+SET position = $1
+WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL
+`
+
+type UpdateTodoPositionParams struct {
+	Position string `json:"position"`
+	ID       int32  `json:"id"`
+	UserID   int32  `json:"user_id"`
+}
+
+// 移動は1行の位置キーを書き換えるだけ
+func (q *Queries) UpdateTodoPosition(ctx context.Context, arg UpdateTodoPositionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateTodoPosition, arg.Position, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,104 @@
+package persistence
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+// AddTodoDependency records that blockerID blocks blockedID. The user's dependencies are
+// locked while the new one is checked, so that concurrent additions cannot close a cycle.
+func (tr *TodoRepository) AddTodoDependency(ctx context.Context, userID int, blockerID int, blockedID int) error {
+	return tr.execTx(ctx, func(q *Queries) error {
+		if err := q.LockTodoDependencies(ctx, int32(userID)); err != nil {
+			return err
+		}
+
+		// The new dependency closes a cycle when the blocked todo already blocks the blocker
+		pathParams := HasDependencyPathParams{
+			FromID: int32(blockedID),
+			ToID:   int32(blockerID),
+		}
+		cycle, err := q.HasDependencyPath(ctx, pathParams)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return domain.ErrDependencyCycle
+		}
+
+		params := CreateTodoDependencyParams{
+			BlockerID: int32(blockerID),
+			BlockedID: int32(blockedID),
+		}
+		_, err = q.CreateTodoDependency(ctx, params)
+		return err
+	})
+}
+
+func (tr *TodoRepository) RemoveTodoDependency(ctx context.Context, userID int, blockerID int, blockedID int) (bool, error) {
+	params := DeleteTodoDependencyParams{
+		BlockerID: int32(blockerID),
+		BlockedID: int32(blockedID),
+		UserID:    int32(userID),
+	}
+
+	rows, err := tr.queries.DeleteTodoDependency(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (tr *TodoRepository) GetDependencyTodos(ctx context.Context, userID int) ([]*domain.Todo, error) {
+	rows, err := tr.queries.ListDependencyTodos(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]*domain.Todo, len(rows))
+	for i, row := range rows {
+		if todos[i], err = toDomainTodo(row); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tr.attachDependencies(ctx, tr.queries, todos); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// attachDependencies fills the blockers and blocked todos of all todos with a single query
+func (tr *TodoRepository) attachDependencies(ctx context.Context, q *Queries, todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]int32, len(todos))
+	byID := make(map[int]*domain.Todo, len(todos))
+	for i, todo := range todos {
+		ids[i] = int32(todo.ID)
+		byID[todo.ID] = todo
+	}
+
+	rows, err := q.ListTodoDependencies(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if blocked, ok := byID[int(row.BlockedID)]; ok {
+			blocked.BlockedBy = append(blocked.BlockedBy, int(row.BlockerID))
+			if !row.BlockerCompleted {
+				blocked.OpenBlockers = append(blocked.OpenBlockers, int(row.BlockerID))
+			}
+		}
+		if blocker, ok := byID[int(row.BlockerID)]; ok {
+			blocker.Blocks = append(blocker.Blocks, int(row.BlockedID))
+		}
+	}
+
+	return nil
+}
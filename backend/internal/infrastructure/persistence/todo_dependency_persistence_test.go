@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"todo-app/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAddTodoDependency(t *testing.T) {
+	tests := []struct {
+		name    string
+		cycle   bool
+		wantErr error
+	}{
+		{name: "new dependency"},
+		{name: "cycle", cycle: true, wantErr: domain.ErrDependencyCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("-- name: LockTodoDependencies :exec").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+			// Todo 3 is to block todo 4, which closes a cycle when todo 4 already leads to todo 3
+			mock.ExpectQuery("-- name: HasDependencyPath :one").WithArgs(4, 3).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.cycle))
+			if tt.cycle {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec("-- name: CreateTodoDependency :execrows").WithArgs(3, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+
+			err = NewTodoRepository(db).AddTodoDependency(context.Background(), 1, 3, 4)
+			if err != tt.wantErr {
+				t.Fatalf("AddTodoDependency() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"todo-app/internal/domain"
+)
+
+// recordTodoEvent appends an entry to the todo's history. It takes the Queries of the
+// transaction that makes the change, so the history never disagrees with the todo.
+func recordTodoEvent(ctx context.Context, q *Queries, todoID int, actorID int, action domain.TodoEventAction, changes map[string]domain.FieldChange) error {
+	if changes == nil {
+		changes = map[string]domain.FieldChange{}
+	}
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	params := CreateTodoEventParams{
+		TodoID:  int32(todoID),
+		ActorID: toSQLNullInt32(&actorID),
+		Action:  string(action),
+		Changes: payload,
+	}
+	return q.CreateTodoEvent(ctx, params)
+}
+
+// lockTodo reads the todo with its tag IDs and locks the row until the transaction ends
+func lockTodo(ctx context.Context, q *Queries, userID int, todoID int) (*domain.Todo, error) {
+	params := GetTodoForUpdateParams{
+		ID:     int32(todoID),
+		UserID: int32(userID),
+	}
+
+	sqlcTodo, err := q.GetTodoForUpdate(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := toDomainTodo(sqlcTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs, err := q.ListTodoTagIDs(ctx, sqlcTodo.ID)
+	if err != nil {
+		return nil, err
+	}
+	todo.Tags = make([]*domain.Tag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		todo.Tags[i] = &domain.Tag{ID: int(tagID), UserID: userID}
+	}
+
+	return todo, nil
+}
+
+func toDomainTodoEvent(sqlcEvent TodoEvent) (*domain.TodoEvent, error) {
+	event := &domain.TodoEvent{
+		ID:        int(sqlcEvent.ID),
+		TodoID:    int(sqlcEvent.TodoID),
+		ActorID:   fromSQLNullInt32Ptr(sqlcEvent.ActorID),
+		Action:    domain.TodoEventAction(sqlcEvent.Action),
+		CreatedAt: fromSQLNullTime(sqlcEvent.CreatedAt),
+		UndoneAt:  fromSQLNullTimePtr(sqlcEvent.UndoneAt),
+	}
+
+	if err := json.Unmarshal(sqlcEvent.Changes, &event.Changes); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
@@ -3,31 +3,78 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"strings"
 	"time"
 	"todo-app/internal/domain"
 	"todo-app/internal/usecase"
+	"unicode/utf8"
 )
 
 type TodoRepository struct {
+	db      *sql.DB
 	queries *Queries
 }
 
-func NewTodoRepository(queries *Queries) usecase.TodoRepository {
+func NewTodoRepository(db *sql.DB) usecase.TodoRepository {
 	return &TodoRepository{
-		queries: queries,
+		db:      db,
+		queries: New(db),
 	}
 }
 
+// execTx runs fn inside a transaction and commits it if fn succeeds
+func (tr *TodoRepository) execTx(ctx context.Context, fn func(q *Queries) error) error {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tr.queries.WithTx(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (tr *TodoRepository) CreateTodo(ctx context.Context, userID int, todo *domain.Todo) error {
+	return tr.execTx(ctx, func(q *Queries) error {
+		return insertTodo(ctx, q, userID, todo)
+	})
+}
+
+// insertTodo creates the todo together with its tag links, first in the manual order
+func insertTodo(ctx context.Context, q *Queries, userID int, todo *domain.Todo) error {
+	first, err := q.GetFirstTodoPosition(ctx, int32(userID))
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	position, err := domain.PositionBetween("", first)
+	if err != nil {
+		return err
+	}
+
+	rule, fromCompletion := toSQLRecurrence(todo.Recurrence)
 	params := CreateTodoParams{
-		UserID:      int32(userID),
-		Title:       todo.Title,
-		DueDate:     toSQLNullTime(todo.DueDate),
-		Priority:    int32(todo.Priority),
-		IsCompleted: todo.IsCompleted,
+		UserID:                   int32(userID),
+		Title:                    todo.Title,
+		DueDate:                  toSQLNullTime(todo.DueDate),
+		Priority:                 int32(todo.Priority),
+		IsCompleted:              todo.IsCompleted,
+		ProjectID:                toSQLNullInt32(todo.ProjectID),
+		RecurrenceRule:           rule,
+		RecurrenceFromCompletion: fromCompletion,
+		Position:                 position,
+		Notes:                    todo.Notes,
+		DueAt:                    toSQLNullTime(todo.DueAt),
+		SnoozedUntil:             toSQLNullTime(todo.SnoozedUntil),
+		StatusID:                 toSQLNullInt32(todo.StatusID),
 	}
 
-	sqlcTodo, err := tr.queries.CreateTodo(ctx, params)
+	sqlcTodo, err := q.CreateTodo(ctx, params)
 	if err != nil {
 		return err
 	}
@@ -36,117 +83,860 @@ func (tr *TodoRepository) CreateTodo(ctx context.Context, userID int, todo *doma
 	todo.UserID = int(sqlcTodo.UserID)
 	todo.CreatedAt = fromSQLNullTime(sqlcTodo.CreatedAt)
 	todo.UpdatedAt = fromSQLNullTime(sqlcTodo.UpdatedAt)
+	todo.Version = int(sqlcTodo.Version)
+	todo.Position = sqlcTodo.Position
+	// The status and the completion flag are settled by the database
+	todo.StatusID = fromSQLNullInt32Ptr(sqlcTodo.StatusID)
+	todo.IsCompleted = sqlcTodo.IsCompleted
 
-	return nil
+	if err := replaceTodoTags(ctx, q, userID, todo); err != nil {
+		return err
+	}
+
+	return recordTodoEvent(ctx, q, todo.ID, userID, domain.TodoEventCreate, domain.DiffTodos(nil, todo))
 }
 
 func (tr *TodoRepository) GetTodo(ctx context.Context, userID int, todoID int) (*domain.Todo, error) {
-	sqlcTodo, err := tr.queries.GetTodo(ctx, int32(todoID))
+	row, err := tr.queries.GetTodo(ctx, int32(todoID))
 	if err != nil {
 		return nil, err
 	}
 
-	if int(sqlcTodo.UserID) != userID {
+	if int(row.Todo.UserID) != userID {
 		return nil, sql.ErrNoRows
 	}
 
-	return &domain.Todo{
-		ID:          int(sqlcTodo.ID),
-		UserID:      int(sqlcTodo.UserID),
-		Title:       sqlcTodo.Title,
-		DueDate:     fromSQLNullTimePtr(sqlcTodo.DueDate),
-		Priority:    int(sqlcTodo.Priority),
-		IsCompleted: sqlcTodo.IsCompleted,
-		CreatedAt:   fromSQLNullTime(sqlcTodo.CreatedAt),
-		UpdatedAt:   fromSQLNullTime(sqlcTodo.UpdatedAt),
-	}, nil
+	todo, err := toDomainTodoWithTags(row.Todo, row.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := tr.attachCounts(ctx, tr.queries, []*domain.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
 }
 
-func (tr *TodoRepository) GetTodos(ctx context.Context, userID int, sortBy string) ([]*domain.Todo, error) {
-	var sqlcTodos []Todo
-	var err error
+func (tr *TodoRepository) GetTodos(ctx context.Context, userID int, sortBy string, filter usecase.TodoFilter) ([]*domain.Todo, error) {
+	rows, err := tr.queries.ListTodos(ctx, toListTodosParams(userID, sortBy, filter))
+	if err != nil {
+		return nil, err
+	}
 
-	if sortBy != "" {
-		params := ListTodosWithSortParams{
-			UserID:  int32(userID),
-			Column2: sortBy,
+	return tr.toDomainTodoList(ctx, rows)
+}
+
+func (tr *TodoRepository) GetTodoPage(ctx context.Context, userID int, sortBy string, filter usecase.TodoFilter, limit int, after *usecase.TodoSortKey) ([]*domain.Todo, *usecase.TodoSortKey, error) {
+	params := toListTodosParams(userID, sortBy, filter)
+	// One extra row tells whether another page follows
+	params.PageLimit = sql.NullInt32{Int32: int32(limit + 1), Valid: true}
+	if after != nil {
+		params.AfterGroup = sql.NullInt32{Int32: int32(after.Group), Valid: true}
+		params.AfterPosition = sql.NullString{String: after.Position, Valid: true}
+		params.AfterValue = sql.NullInt64{Int64: after.Value, Valid: true}
+		params.AfterCompleted = sql.NullInt32{Int32: int32(after.Completed), Valid: true}
+		params.AfterCreated = sql.NullInt64{Int64: after.Created, Valid: true}
+		params.AfterID = sql.NullInt32{Int32: int32(after.ID), Valid: true}
+	}
+
+	rows, err := tr.queries.ListTodos(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *usecase.TodoSortKey
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		next = &usecase.TodoSortKey{
+			Group:     int(last.SortGroup),
+			Position:  last.SortPosition,
+			Value:     last.SortValue,
+			Completed: int(last.SortCompleted),
+			Created:   last.SortCreated,
+			ID:        int(last.SortID),
+		}
+	}
+
+	todos, err := tr.toDomainTodoList(ctx, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return todos, next, nil
+}
+
+func (tr *TodoRepository) CountTodos(ctx context.Context, userID int, filter usecase.TodoFilter) (int, error) {
+	params := toListTodosParams(userID, "", filter)
+	count, err := tr.queries.CountTodos(ctx, CountTodosParams{
+		UserID:        params.UserID,
+		TagIds:        params.TagIds,
+		MatchAllTags:  params.MatchAllTags,
+		ProjectID:     params.ProjectID,
+		StatusID:      params.StatusID,
+		IsCompleted:   params.IsCompleted,
+		PriorityMin:   params.PriorityMin,
+		PriorityMax:   params.PriorityMax,
+		NoDueDate:     params.NoDueDate,
+		DueFrom:       params.DueFrom,
+		DueBefore:     params.DueBefore,
+		OverdueAt:     params.OverdueAt,
+		OverdueDate:   params.OverdueDate,
+		CreatedFrom:   params.CreatedFrom,
+		CreatedBefore: params.CreatedBefore,
+		UpdatedFrom:   params.UpdatedFrom,
+		UpdatedBefore: params.UpdatedBefore,
+		AwakeAt:       params.AwakeAt,
+		SnoozedAt:     params.SnoozedAt,
+		TextTerms:     params.TextTerms,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (tr *TodoRepository) SearchTodos(ctx context.Context, userID int, query string, terms []string, limit int) ([]*usecase.TodoSearchResult, error) {
+	// The longest term drives the trigram index, the others are checked per row
+	longest := terms[0]
+	escaped := make([]string, len(terms))
+	for i, term := range terms {
+		escaped[i] = escapeLikePattern(term)
+		if utf8.RuneCountInString(term) > utf8.RuneCountInString(longest) {
+			longest = term
 		}
-		sqlcTodos, err = tr.queries.ListTodosWithSort(ctx, params)
-	} else {
-		sqlcTodos, err = tr.queries.ListTodos(ctx, int32(userID))
 	}
 
+	rows, err := tr.queries.SearchTodos(ctx, SearchTodosParams{
+		Pattern:   "%" + escapeLikePattern(longest) + "%",
+		Terms:     escaped,
+		Query:     query,
+		UserID:    int32(userID),
+		PageLimit: int32(limit),
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	todos := make([]*domain.Todo, len(sqlcTodos))
-	for i, sqlcTodo := range sqlcTodos {
-		todos[i] = &domain.Todo{
-			ID:          int(sqlcTodo.ID),
-			UserID:      int(sqlcTodo.UserID),
-			Title:       sqlcTodo.Title,
-			DueDate:     fromSQLNullTimePtr(sqlcTodo.DueDate),
-			Priority:    int(sqlcTodo.Priority),
-			IsCompleted: sqlcTodo.IsCompleted,
-			CreatedAt:   fromSQLNullTime(sqlcTodo.CreatedAt),
-			UpdatedAt:   fromSQLNullTime(sqlcTodo.UpdatedAt),
+	todos := make([]*domain.Todo, len(rows))
+	results := make([]*usecase.TodoSearchResult, len(rows))
+	for i, row := range rows {
+		if todos[i], err = toDomainTodoWithTags(row.Todo, row.Tags); err != nil {
+			return nil, err
+		}
+		results[i] = &usecase.TodoSearchResult{
+			Todo:     todos[i],
+			AllTerms: row.AllTerms,
+			Score:    row.Score,
 		}
 	}
 
+	if err := tr.attachCounts(ctx, tr.queries, todos); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (tr *TodoRepository) toDomainTodoList(ctx context.Context, rows []ListTodosRow) ([]*domain.Todo, error) {
+	todos := make([]*domain.Todo, len(rows))
+	for i, row := range rows {
+		todo, err := toDomainTodoWithTags(row.Todo, row.Tags)
+		if err != nil {
+			return nil, err
+		}
+		todos[i] = todo
+	}
+
+	if err := tr.attachCounts(ctx, tr.queries, todos); err != nil {
+		return nil, err
+	}
+
 	return todos, nil
 }
 
 func (tr *TodoRepository) UpdateTodo(ctx context.Context, userID int, todo *domain.Todo) error {
+	return tr.execTx(ctx, func(q *Queries) error {
+		changes, err := saveTodo(ctx, q, userID, todo)
+		if err != nil || len(changes) == 0 {
+			return err
+		}
+		return recordTodoEvent(ctx, q, todo.ID, userID, domain.TodoEventUpdate, changes)
+	})
+}
+
+// saveTodo saves the todo with its tags and returns the changed fields for the history.
+// todo.Version must be the stored version, so that changes made since it was read are not overwritten.
+func saveTodo(ctx context.Context, q *Queries, userID int, todo *domain.Todo) (map[string]domain.FieldChange, error) {
+	before, err := lockTodo(ctx, q, userID, todo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTodoVersion(before, &todo.Version); err != nil {
+		return nil, err
+	}
+	// Every way into a status counts against its WIP limit
+	if todo.StatusID != nil && (before.StatusID == nil || *before.StatusID != *todo.StatusID) {
+		if err := reserveStatusSlot(ctx, q, userID, *todo.StatusID); err != nil {
+			return nil, err
+		}
+	}
+
+	rule, fromCompletion := toSQLRecurrence(todo.Recurrence)
 	params := UpdateTodoParams{
-		ID:          int32(todo.ID),
-		Title:       todo.Title,
-		DueDate:     toSQLNullTime(todo.DueDate),
-		Priority:    int32(todo.Priority),
-		IsCompleted: todo.IsCompleted,
-		UserID:      int32(userID),
+		ID:                       int32(todo.ID),
+		Title:                    todo.Title,
+		DueDate:                  toSQLNullTime(todo.DueDate),
+		Priority:                 int32(todo.Priority),
+		IsCompleted:              todo.IsCompleted,
+		UserID:                   int32(userID),
+		ProjectID:                toSQLNullInt32(todo.ProjectID),
+		RecurrenceRule:           rule,
+		RecurrenceFromCompletion: fromCompletion,
+		Notes:                    todo.Notes,
+		DueAt:                    toSQLNullTime(todo.DueAt),
+		SnoozedUntil:             toSQLNullTime(todo.SnoozedUntil),
+		StatusID:                 toSQLNullInt32(todo.StatusID),
 	}
 
-	sqlcTodo, err := tr.queries.UpdateTodo(ctx, params)
+	sqlcTodo, err := q.UpdateTodo(ctx, params)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	todo.UpdatedAt = fromSQLNullTime(sqlcTodo.UpdatedAt)
+	todo.Version = int(sqlcTodo.Version)
+	todo.StatusID = fromSQLNullInt32Ptr(sqlcTodo.StatusID)
+	todo.IsCompleted = sqlcTodo.IsCompleted
 
-	return nil
+	if err := replaceTodoTags(ctx, q, userID, todo); err != nil {
+		return nil, err
+	}
+
+	return domain.DiffTodos(before, todo), nil
+}
+
+func (tr *TodoRepository) DeleteTodo(ctx context.Context, userID int, todoID int, version *int) error {
+	params := TrashTodoParams{
+		ID:     int32(todoID),
+		UserID: int32(userID),
+	}
+
+	return tr.execTx(ctx, func(q *Queries) error {
+		if version != nil {
+			current, err := lockTodo(ctx, q, userID, todoID)
+			if err == sql.ErrNoRows {
+				return domain.ErrTodoNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if err := checkTodoVersion(current, version); err != nil {
+				return err
+			}
+		}
+
+		rows, err := q.TrashTodo(ctx, params)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrTodoNotFound
+		}
+		return recordTodoEvent(ctx, q, todoID, userID, domain.TodoEventDelete, nil)
+	})
+}
+
+func (tr *TodoRepository) GetTrashedTodos(ctx context.Context, userID int) ([]*domain.Todo, error) {
+	rows, err := tr.queries.ListTrashedTodos(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]*domain.Todo, len(rows))
+	for i, row := range rows {
+		if todos[i], err = toDomainTodoWithTags(row.Todo, row.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tr.attachCounts(ctx, tr.queries, todos); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
 }
 
-func (tr *TodoRepository) DeleteTodo(ctx context.Context, userID int, todoID int) error {
-	params := DeleteTodoParams{
+func (tr *TodoRepository) RestoreTodo(ctx context.Context, userID int, todoID int) (bool, error) {
+	params := RestoreTodoParams{
 		ID:     int32(todoID),
 		UserID: int32(userID),
 	}
 
-	return tr.queries.DeleteTodo(ctx, params)
+	var restored bool
+	err := tr.execTx(ctx, func(q *Queries) error {
+		rows, err := q.RestoreTodo(ctx, params)
+		if err != nil || rows == 0 {
+			return err
+		}
+		restored = true
+		return recordTodoEvent(ctx, q, todoID, userID, domain.TodoEventRestore, nil)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return restored, nil
 }
 
-func (tr *TodoRepository) ToggleTodoComplete(ctx context.Context, userID int, todoID int) (*domain.Todo, error) {
-	params := ToggleTodoCompleteParams{
+func (tr *TodoRepository) PurgeTodo(ctx context.Context, userID int, todoID int) (bool, error) {
+	params := PurgeTodoParams{
 		ID:     int32(todoID),
 		UserID: int32(userID),
 	}
 
-	sqlcTodo, err := tr.queries.ToggleTodoComplete(ctx, params)
+	rows, err := tr.queries.PurgeTodo(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (tr *TodoRepository) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return tr.queries.PurgeTrash(ctx, sql.NullTime{Time: deletedBefore, Valid: true})
+}
+
+func (tr *TodoRepository) StopRecurrence(ctx context.Context, userID int, todoID int) error {
+	params := ClearTodoRecurrenceParams{
+		ID:     int32(todoID),
+		UserID: int32(userID),
+	}
+
+	return tr.execTx(ctx, func(q *Queries) error {
+		before, err := lockTodo(ctx, q, userID, todoID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.ClearTodoRecurrence(ctx, params); err != nil {
+			return err
+		}
+
+		after := *before
+		after.Recurrence = nil
+		return recordTodoEvent(ctx, q, todoID, userID, domain.TodoEventUpdate, domain.DiffTodos(before, &after))
+	})
+}
+
+func (tr *TodoRepository) MoveTodo(ctx context.Context, userID int, todoID int, version *int, afterID *int, beforeID *int) error {
+	return tr.execTx(ctx, func(q *Queries) error {
+		current, err := lockTodo(ctx, q, userID, todoID)
+		if err != nil {
+			return err
+		}
+		if err := checkTodoVersion(current, version); err != nil {
+			return err
+		}
+
+		var lower, upper string
+		if afterID != nil {
+			lower, err = q.GetTodoPosition(ctx, GetTodoPositionParams{ID: int32(*afterID), UserID: int32(userID)})
+			if err != nil {
+				return err
+			}
+		}
+		if beforeID != nil {
+			upper, err = q.GetTodoPosition(ctx, GetTodoPositionParams{ID: int32(*beforeID), UserID: int32(userID)})
+			if err != nil {
+				return err
+			}
+		}
+
+		// With a single anchor, the todo goes between it and its neighbour on the other side
+		switch {
+		case beforeID == nil:
+			upper, err = q.GetNextTodoPosition(ctx, GetNextTodoPositionParams{UserID: int32(userID), Position: lower, ExcludeID: int32(todoID)})
+		case afterID == nil:
+			lower, err = q.GetPreviousTodoPosition(ctx, GetPreviousTodoPositionParams{UserID: int32(userID), Position: upper, ExcludeID: int32(todoID)})
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		position, err := domain.PositionBetween(lower, upper)
+		if err != nil {
+			return err
+		}
+
+		params := UpdateTodoPositionParams{
+			Position: position,
+			ID:       int32(todoID),
+			UserID:   int32(userID),
+		}
+		_, err = q.UpdateTodoPosition(ctx, params)
+		return err
+	})
+}
+
+// RebalanceTodoPositions keeps updated_at and version, since the renumbered todos stay in the same order
+func (tr *TodoRepository) RebalanceTodoPositions(ctx context.Context, maxLength int) (int64, error) {
+	var renumbered int64
+	err := tr.execTx(ctx, func(q *Queries) error {
+		if err := q.SkipTodoTouchTriggers(ctx); err != nil {
+			return err
+		}
+		var err error
+		renumbered, err = q.RebalanceTodoPositions(ctx, int32(maxLength))
+		return err
+	})
+	return renumbered, err
+}
+
+func (tr *TodoRepository) ApplyTodoChanges(ctx context.Context, userID int, changes []*usecase.TodoChange) error {
+	return tr.execTx(ctx, func(q *Queries) error {
+		for _, change := range changes {
+			err := applyTodoChange(ctx, q, userID, change)
+			if err == sql.ErrNoRows {
+				// Deleted since it was read
+				return domain.ErrTodoVersionMismatch
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func applyTodoChange(ctx context.Context, q *Queries, userID int, change *usecase.TodoChange) error {
+	todo := change.Todo
+	if change.Delete {
+		current, err := lockTodo(ctx, q, userID, todo.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkTodoVersion(current, &todo.Version); err != nil {
+			return err
+		}
+
+		params := TrashTodoParams{
+			ID:     int32(todo.ID),
+			UserID: int32(userID),
+		}
+		if _, err := q.TrashTodo(ctx, params); err != nil {
+			return err
+		}
+		return recordTodoEvent(ctx, q, todo.ID, userID, change.Action, nil)
+	}
+
+	changes, err := saveTodo(ctx, q, userID, todo)
+	if err != nil {
+		return err
+	}
+	if change.CompleteSubtasks {
+		if err := q.CompleteAllSubtasks(ctx, int32(todo.ID)); err != nil {
+			return err
+		}
+	}
+
+	if change.Next != nil {
+		if err := insertTodo(ctx, q, userID, change.Next); err != nil {
+			return err
+		}
+		copyParams := CopySubtasksParams{
+			ToTodoID:   int32(change.Next.ID),
+			FromTodoID: int32(todo.ID),
+		}
+		if err := q.CopySubtasks(ctx, copyParams); err != nil {
+			return err
+		}
+		reminderParams := CopyRemindersParams{
+			ToTodoID:   int32(change.Next.ID),
+			FromTodoID: int32(todo.ID),
+		}
+		if err := q.CopyReminders(ctx, reminderParams); err != nil {
+			return err
+		}
+		changes[domain.NextOccurrenceField] = domain.FieldChange{After: change.Next.ID}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return recordTodoEvent(ctx, q, todo.ID, userID, change.Action, changes)
+}
+
+// UndoOperations reverts the user's latest count operations made since the given time.
+// An operation is everything one transaction changed, so a bulk change is undone as a whole.
+func (tr *TodoRepository) UndoOperations(ctx context.Context, userID int, since time.Time, count int) (int, []*domain.Todo, error) {
+	var operations int
+	var todos []*domain.Todo
+	err := tr.execTx(ctx, func(q *Queries) error {
+		params := ListUndoableTodoEventsParams{
+			ActorID:   int32(userID),
+			Since:     sql.NullTime{Time: since, Valid: true},
+			PageLimit: int32(count),
+		}
+		sqlcEvents, err := q.ListUndoableTodoEvents(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		operationIDs := make(map[int64]bool)
+		eventIDs := make([]int64, 0, len(sqlcEvents))
+		var todoIDs []int
+		seen := make(map[int]bool)
+
+		// Newest first, so that each event is reverted on top of the state it produced
+		for _, sqlcEvent := range sqlcEvents {
+			event, err := toDomainTodoEvent(sqlcEvent)
+			if err != nil {
+				return err
+			}
+
+			reverted, err := undoTodoEvent(ctx, q, userID, event)
+			if err != nil {
+				return err
+			}
+
+			operationIDs[sqlcEvent.OperationID] = true
+			eventIDs = append(eventIDs, sqlcEvent.ID)
+			if reverted && !seen[event.TodoID] {
+				seen[event.TodoID] = true
+				todoIDs = append(todoIDs, event.TodoID)
+			}
+		}
+
+		if err := q.MarkTodoEventsUndone(ctx, eventIDs); err != nil {
+			return err
+		}
+		operations = len(operationIDs)
+
+		todos = make([]*domain.Todo, 0, len(todoIDs))
+		for _, todoID := range todoIDs {
+			row, err := q.GetTodo(ctx, int32(todoID))
+			if err == sql.ErrNoRows {
+				// Trashed again by an older event of the same undo
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			todo, err := toDomainTodoWithTags(row.Todo, row.Tags)
+			if err != nil {
+				return err
+			}
+			todos = append(todos, todo)
+		}
+		return tr.attachCounts(ctx, q, todos)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return operations, todos, nil
+}
+
+// undoTodoEvent reverts a single event. It reports false when there was nothing
+// left to revert, e.g. the todo has been moved to the trash since.
+func undoTodoEvent(ctx context.Context, q *Queries, userID int, event *domain.TodoEvent) (bool, error) {
+	if event.Action == domain.TodoEventDelete {
+		params := RestoreTodoParams{
+			ID:     int32(event.TodoID),
+			UserID: int32(userID),
+		}
+		rows, err := q.RestoreTodo(ctx, params)
+		if err != nil || rows == 0 {
+			return false, err
+		}
+		return true, recordTodoEvent(ctx, q, event.TodoID, userID, domain.TodoEventRestore, nil)
+	}
+
+	todo, err := lockTodo(ctx, q, userID, event.TodoID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := event.Revert(todo); err != nil {
+		return false, err
+	}
+	changes, err := saveTodo(ctx, q, userID, todo)
+	if err != nil {
+		return false, err
+	}
+	if len(changes) > 0 {
+		if err := recordTodoEvent(ctx, q, todo.ID, userID, domain.TodoEventUndo, changes); err != nil {
+			return false, err
+		}
+	}
+
+	// Completing a recurring todo created its next occurrence, which goes away again
+	if change, ok := event.Changes[domain.NextOccurrenceField]; ok {
+		if nextID, ok := change.After.(float64); ok {
+			params := TrashTodoParams{
+				ID:     int32(nextID),
+				UserID: int32(userID),
+			}
+			rows, err := q.TrashTodo(ctx, params)
+			if err != nil {
+				return false, err
+			}
+			if rows > 0 {
+				if err := recordTodoEvent(ctx, q, int(nextID), userID, domain.TodoEventUndo, nil); err != nil {
+					return false, err
+				}
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// GetTodoEvents returns the todo's history, newest first
+func (tr *TodoRepository) GetTodoEvents(ctx context.Context, todoID int) ([]*domain.TodoEvent, error) {
+	sqlcEvents, err := tr.queries.ListTodoEvents(ctx, int32(todoID))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*domain.TodoEvent, len(sqlcEvents))
+	for i, sqlcEvent := range sqlcEvents {
+		if events[i], err = toDomainTodoEvent(sqlcEvent); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// attachCounts fills the subtask progress, comment counts and dependencies of the todos
+func (tr *TodoRepository) attachCounts(ctx context.Context, q *Queries, todos []*domain.Todo) error {
+	if err := tr.attachSubtaskProgress(ctx, q, todos); err != nil {
+		return err
+	}
+	if err := tr.attachCommentCounts(ctx, q, todos); err != nil {
+		return err
+	}
+	return tr.attachDependencies(ctx, q, todos)
+}
+
+// attachSubtaskProgress fills subtask counts for all todos with a single query
+func (tr *TodoRepository) attachSubtaskProgress(ctx context.Context, q *Queries, todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]int32, len(todos))
+	for i, todo := range todos {
+		ids[i] = int32(todo.ID)
+	}
+
+	rows, err := q.ListSubtaskProgress(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	progress := make(map[int]ListSubtaskProgressRow, len(rows))
+	for _, row := range rows {
+		progress[int(row.TodoID)] = row
+	}
+
+	for _, todo := range todos {
+		if row, ok := progress[todo.ID]; ok {
+			todo.SubtasksDone = int(row.Done)
+			todo.SubtasksTotal = int(row.Total)
+		}
+	}
+
+	return nil
+}
+
+// attachCommentCounts fills comment counts for all todos with a single query
+func (tr *TodoRepository) attachCommentCounts(ctx context.Context, q *Queries, todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]int32, len(todos))
+	for i, todo := range todos {
+		ids[i] = int32(todo.ID)
+	}
+
+	rows, err := q.ListCommentCounts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[int(row.TodoID)] = int(row.Count)
+	}
+
+	for _, todo := range todos {
+		todo.CommentCount = counts[todo.ID]
+	}
+
+	return nil
+}
+
+// toListTodosParams maps the filter onto the ListTodos parameters; unset conditions become NULL.
+// The result lists every matching todo, GetTodoPage adds the page bounds.
+func toListTodosParams(userID int, sortBy string, filter usecase.TodoFilter) ListTodosParams {
+	textTerms := make([]string, len(filter.TextTerms))
+	for i, term := range filter.TextTerms {
+		textTerms[i] = escapeLikePattern(term)
+	}
+
+	// Todos without a due time are overdue from the day after their due date in the user's time zone
+	var overdueDate *time.Time
+	if filter.OverdueAt != nil {
+		today := time.Date(filter.OverdueAt.Year(), filter.OverdueAt.Month(), filter.OverdueAt.Day(), 0, 0, 0, 0, time.UTC)
+		overdueDate = &today
+	}
+
+	return ListTodosParams{
+		UserID:        int32(userID),
+		TagIds:        toInt32Slice(filter.TagIDs),
+		MatchAllTags:  filter.MatchAllTags,
+		ProjectID:     toSQLNullInt32(filter.ProjectID),
+		StatusID:      toSQLNullInt32(filter.StatusID),
+		IsCompleted:   toSQLNullBool(filter.Completed),
+		PriorityMin:   toSQLNullInt32(filter.PriorityMin),
+		PriorityMax:   toSQLNullInt32(filter.PriorityMax),
+		NoDueDate:     filter.NoDueDate,
+		DueFrom:       toSQLNullTime(filter.DueFrom),
+		DueBefore:     toSQLNullTime(filter.DueBefore),
+		OverdueAt:     toSQLNullTime(filter.OverdueAt),
+		OverdueDate:   toSQLNullTime(overdueDate),
+		CreatedFrom:   toSQLNullTime(filter.CreatedFrom),
+		CreatedBefore: toSQLNullTime(filter.CreatedBefore),
+		UpdatedFrom:   toSQLNullTime(filter.UpdatedFrom),
+		UpdatedBefore: toSQLNullTime(filter.UpdatedBefore),
+		AwakeAt:       toSQLNullTime(filter.AwakeAt),
+		SnoozedAt:     toSQLNullTime(filter.SnoozedAt),
+		TextTerms:     textTerms,
+		SortBy:        sortBy,
+	}
+}
+
+// escapeLikePattern escapes the LIKE wildcards so that a term is matched literally
+func escapeLikePattern(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
+
+// replaceTodoTags makes the stored tags of the todo match todo.Tags.
+// Tags that do not belong to the user are ignored by AddTodoTags.
+func replaceTodoTags(ctx context.Context, q *Queries, userID int, todo *domain.Todo) error {
+	if err := q.ClearTodoTags(ctx, int32(todo.ID)); err != nil {
+		return err
+	}
+	if len(todo.Tags) == 0 {
+		return nil
+	}
+
+	tagIDs := make([]int32, len(todo.Tags))
+	for i, tag := range todo.Tags {
+		tagIDs[i] = int32(tag.ID)
+	}
+
+	params := AddTodoTagsParams{
+		TodoID: int32(todo.ID),
+		UserID: int32(userID),
+		TagIds: tagIDs,
+	}
+	return q.AddTodoTags(ctx, params)
+}
+
+// todoTagJSON is the shape of each element aggregated by json_agg in todo.sql
+type todoTagJSON struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func toDomainTodoWithTags(sqlcTodo Todo, rawTags json.RawMessage) (*domain.Todo, error) {
+	todo, err := toDomainTodo(sqlcTodo)
 	if err != nil {
 		return nil, err
 	}
 
-	return &domain.Todo{
+	var tags []todoTagJSON
+	if len(rawTags) > 0 {
+		if err := json.Unmarshal(rawTags, &tags); err != nil {
+			return nil, err
+		}
+	}
+
+	todo.Tags = make([]*domain.Tag, len(tags))
+	for i, tag := range tags {
+		todo.Tags[i] = &domain.Tag{
+			ID:     tag.ID,
+			UserID: todo.UserID,
+			Name:   tag.Name,
+			Color:  tag.Color,
+		}
+	}
+
+	return todo, nil
+}
+
+// checkTodoVersion fails with ErrTodoVersionMismatch when version is given and the
+// locked todo has been changed since that version was read
+func checkTodoVersion(todo *domain.Todo, version *int) error {
+	if version != nil && todo.Version != *version {
+		return domain.ErrTodoVersionMismatch
+	}
+	return nil
+}
+
+func toDomainTodo(sqlcTodo Todo) (*domain.Todo, error) {
+	todo := &domain.Todo{
 		ID:          int(sqlcTodo.ID),
 		UserID:      int(sqlcTodo.UserID),
+		ProjectID:   fromSQLNullInt32Ptr(sqlcTodo.ProjectID),
+		StatusID:    fromSQLNullInt32Ptr(sqlcTodo.StatusID),
 		Title:       sqlcTodo.Title,
+		Notes:       sqlcTodo.Notes,
 		DueDate:     fromSQLNullTimePtr(sqlcTodo.DueDate),
+		DueAt:       fromSQLNullTimePtr(sqlcTodo.DueAt),
 		Priority:    int(sqlcTodo.Priority),
 		IsCompleted: sqlcTodo.IsCompleted,
 		CreatedAt:   fromSQLNullTime(sqlcTodo.CreatedAt),
 		UpdatedAt:   fromSQLNullTime(sqlcTodo.UpdatedAt),
-	}, nil
+		DeletedAt:   fromSQLNullTimePtr(sqlcTodo.DeletedAt),
+		Version:     int(sqlcTodo.Version),
+		Position:    sqlcTodo.Position,
+	}
+	if todo.DueAt != nil {
+		dueAt := todo.DueAt.UTC()
+		todo.DueAt = &dueAt
+	}
+	if sqlcTodo.SnoozedUntil.Valid {
+		snoozedUntil := sqlcTodo.SnoozedUntil.Time.UTC()
+		todo.SnoozedUntil = &snoozedUntil
+	}
+
+	if sqlcTodo.RecurrenceRule.Valid {
+		recurrence, err := domain.ParseRecurrenceRule(sqlcTodo.RecurrenceRule.String, sqlcTodo.RecurrenceFromCompletion)
+		if err != nil {
+			return nil, err
+		}
+		todo.Recurrence = recurrence
+	}
+
+	return todo, nil
+}
+
+func toSQLRecurrence(recurrence *domain.Recurrence) (sql.NullString, bool) {
+	if recurrence == nil {
+		return sql.NullString{Valid: false}, false
+	}
+	return sql.NullString{String: recurrence.Rule(), Valid: true}, recurrence.FromCompletion
 }
 
 func toSQLNullTime(t *time.Time) sql.NullTime {
@@ -169,3 +959,25 @@ func fromSQLNullTimePtr(nt sql.NullTime) *time.Time {
 	}
 	return &nt.Time
 }
+
+func toSQLNullInt32(i *int) sql.NullInt32 {
+	if i == nil {
+		return sql.NullInt32{Valid: false}
+	}
+	return sql.NullInt32{Int32: int32(*i), Valid: true}
+}
+
+func toSQLNullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{Valid: false}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
+
+func fromSQLNullInt32Ptr(ni sql.NullInt32) *int {
+	if !ni.Valid {
+		return nil
+	}
+	i := int(ni.Int32)
+	return &i
+}
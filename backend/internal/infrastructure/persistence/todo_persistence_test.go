@@ -0,0 +1,284 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+	"todo-app/internal/usecase"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestToListTodosParams(t *testing.T) {
+	completed := false
+	dueBefore := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		sortBy string
+		filter usecase.TodoFilter
+		want   ListTodosParams
+	}{
+		{
+			name: "no filter",
+			want: ListTodosParams{UserID: 1, TagIds: []int32{}, TextTerms: []string{}},
+		},
+		{
+			name:   "all tags, sorted",
+			sortBy: "priority_desc",
+			filter: usecase.TodoFilter{TagIDs: []int{1, 2}, MatchAllTags: true},
+			want:   ListTodosParams{UserID: 1, TagIds: []int32{1, 2}, MatchAllTags: true, TextTerms: []string{}, SortBy: "priority_desc"},
+		},
+		{
+			name:   "project",
+			filter: usecase.TodoFilter{ProjectID: intPtr(5)},
+			want:   ListTodosParams{UserID: 1, TagIds: []int32{}, ProjectID: sql.NullInt32{Int32: 5, Valid: true}, TextTerms: []string{}},
+		},
+		{
+			name:   "status",
+			filter: usecase.TodoFilter{StatusID: intPtr(4)},
+			want:   ListTodosParams{UserID: 1, TagIds: []int32{}, StatusID: sql.NullInt32{Int32: 4, Valid: true}, TextTerms: []string{}},
+		},
+		{
+			name:   "open, priority and due date",
+			filter: usecase.TodoFilter{Completed: &completed, PriorityMin: intPtr(1), DueBefore: &dueBefore},
+			want: ListTodosParams{
+				UserID:      1,
+				TagIds:      []int32{},
+				IsCompleted: sql.NullBool{Bool: false, Valid: true},
+				PriorityMin: sql.NullInt32{Int32: 1, Valid: true},
+				DueBefore:   sql.NullTime{Time: dueBefore, Valid: true},
+				TextTerms:   []string{},
+			},
+		},
+		{
+			name:   "snoozed todos left out",
+			filter: usecase.TodoFilter{AwakeAt: &dueBefore},
+			want:   ListTodosParams{UserID: 1, TagIds: []int32{}, AwakeAt: sql.NullTime{Time: dueBefore, Valid: true}, TextTerms: []string{}},
+		},
+		{
+			name:   "only snoozed todos",
+			filter: usecase.TodoFilter{SnoozedAt: &dueBefore},
+			want:   ListTodosParams{UserID: 1, TagIds: []int32{}, SnoozedAt: sql.NullTime{Time: dueBefore, Valid: true}, TextTerms: []string{}},
+		},
+		{
+			name:   "text is matched literally",
+			filter: usecase.TodoFilter{TextTerms: []string{"100%", `a_b\c`}},
+			want:   ListTodosParams{UserID: 1, TagIds: []int32{}, TextTerms: []string{`100\%`, `a\_b\\c`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toListTodosParams(1, tt.sortBy, tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toListTodosParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToDomainTodoWithTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawTags  string
+		wantTags []string
+	}{
+		{name: "no tags", rawTags: `[]`, wantTags: []string{}},
+		{name: "missing column", rawTags: ``, wantTags: []string{}},
+		{
+			name:     "tags",
+			rawTags:  `[{"id":1,"name":"home","color":"#00ff00"},{"id":2,"name":"work","color":"#0000ff"}]`,
+			wantTags: []string{"home", "work"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo, err := toDomainTodoWithTags(Todo{ID: 1, UserID: 7}, json.RawMessage(tt.rawTags))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(todo.Tags) != len(tt.wantTags) {
+				t.Fatalf("got %d tags, want %d", len(todo.Tags), len(tt.wantTags))
+			}
+			for i, tag := range todo.Tags {
+				if tag.Name != tt.wantTags[i] || tag.UserID != 7 {
+					t.Errorf("tag %d = %+v, want %q of user 7", i, tag, tt.wantTags[i])
+				}
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// todoColumns lists the columns of the todos table
+func todoColumns() []string {
+	todoType := reflect.TypeOf(Todo{})
+	var columns []string
+	for i := 0; i < todoType.NumField(); i++ {
+		columns = append(columns, todoType.Field(i).Tag.Get("json"))
+	}
+	return columns
+}
+
+// todoValues is a todos row with the given ID and version, and zero or NULL elsewhere
+func todoValues(id int32, version int32) []driver.Value {
+	todoType := reflect.TypeOf(Todo{})
+	scanner := reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+	var values []driver.Value
+	for i := 0; i < todoType.NumField(); i++ {
+		field := todoType.Field(i)
+		switch {
+		case field.Name == "ID":
+			values = append(values, id)
+		case field.Name == "Version":
+			values = append(values, version)
+		case reflect.PtrTo(field.Type).Implements(scanner):
+			values = append(values, nil)
+		default:
+			values = append(values, reflect.Zero(field.Type).Interface())
+		}
+	}
+	return values
+}
+
+// todoRows builds rows of the todos table, one per ID, at the given version
+func todoRows(version int32, ids ...int32) *sqlmock.Rows {
+	rows := sqlmock.NewRows(todoColumns())
+	for _, id := range ids {
+		rows.AddRow(todoValues(id, version)...)
+	}
+	return rows
+}
+
+// todoPageRows builds ListTodos rows for todos with the given IDs. Every todo has the
+// same sort values up to the ID, like todos that share a due date or priority.
+func todoPageRows(ids ...int32) *sqlmock.Rows {
+	columns := append(todoColumns(), "tags", "sort_group", "sort_position", "sort_value", "sort_completed", "sort_created", "sort_id")
+	rows := sqlmock.NewRows(columns)
+	for _, id := range ids {
+		values := append(todoValues(id, 0), []byte("[]"), 0, "", int64(-20000), 0, int64(-1700000000000000), -id)
+		rows.AddRow(values...)
+	}
+	return rows
+}
+
+func TestGetTodoPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		after   *usecase.TodoSortKey
+		rows    []int32
+		wantIDs []int
+		// wantNext is the ID in the key of the next page, 0 when this is the last page
+		wantNext int
+	}{
+		{
+			name:     "first page of todos that tie on the sort value",
+			rows:     []int32{9, 8, 7},
+			wantIDs:  []int{9, 8},
+			wantNext: 8,
+		},
+		{
+			name:     "page after a tie",
+			after:    &usecase.TodoSortKey{Value: -20000, Created: -1700000000000000, ID: -8},
+			rows:     []int32{7, 6, 5},
+			wantIDs:  []int{7, 6},
+			wantNext: 6,
+		},
+		{
+			name:    "last page that is full",
+			after:   &usecase.TodoSortKey{Value: -20000, Created: -1700000000000000, ID: -6},
+			rows:    []int32{5, 4},
+			wantIDs: []int{5, 4},
+		},
+		{
+			name:    "empty page",
+			after:   &usecase.TodoSortKey{Value: -20000, Created: -1700000000000000, ID: -4},
+			wantIDs: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			// The page bounds are the last parameters of ListTodos
+			args := make([]driver.Value, reflect.TypeOf(ListTodosParams{}).NumField()-7)
+			for i := range args {
+				args[i] = sqlmock.AnyArg()
+			}
+			after := []driver.Value{nil, nil, nil, nil, nil, nil}
+			if tt.after != nil {
+				after = []driver.Value{int64(tt.after.ID), int64(tt.after.Group), tt.after.Position, tt.after.Value, int64(tt.after.Completed), tt.after.Created}
+			}
+			// One row more than the page size tells that another page follows
+			args = append(append(args, after...), int64(3))
+
+			mock.ExpectQuery("-- name: ListTodos :many").WithArgs(args...).WillReturnRows(todoPageRows(tt.rows...))
+			if len(tt.wantIDs) > 0 {
+				mock.ExpectQuery("-- name: ListSubtaskProgress :many").WillReturnRows(sqlmock.NewRows([]string{"todo_id", "done", "total"}))
+				mock.ExpectQuery("-- name: ListCommentCounts :many").WillReturnRows(sqlmock.NewRows([]string{"todo_id", "count"}))
+				mock.ExpectQuery("-- name: ListTodoDependencies :many").WillReturnRows(sqlmock.NewRows([]string{"blocker_id", "blocked_id", "blocker_completed"}))
+			}
+
+			todos, next, err := NewTodoRepository(db).GetTodoPage(context.Background(), 1, "priority_desc", usecase.TodoFilter{}, 2, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]int, len(todos))
+			for i, todo := range todos {
+				ids[i] = todo.ID
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("page = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.wantNext == 0 {
+				if next != nil {
+					t.Errorf("next = %+v, want the last page", next)
+				}
+			} else if next == nil || next.ID != -tt.wantNext || next.Value != -20000 {
+				t.Errorf("next = %+v, want the key of todo %d", next, tt.wantNext)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestRebalanceTodoPositions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Renumbering must not bump versions, so the triggers are skipped first, in the same transaction
+	mock.ExpectBegin()
+	mock.ExpectExec("-- name: SkipTodoTouchTriggers :exec").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("-- name: RebalanceTodoPositions :execrows").WithArgs(32).WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectCommit()
+
+	renumbered, err := NewTodoRepository(db).RebalanceTodoPositions(context.Background(), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renumbered != 12 {
+		t.Errorf("RebalanceTodoPositions() = %d, want 12", renumbered)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
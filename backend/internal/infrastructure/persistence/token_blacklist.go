@@ -5,13 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+	"todo-app/internal/usecase"
 )
 
 type TokenBlacklist struct {
 	db *sql.DB
 }
 
-func NewTokenBlacklist(db *sql.DB) *TokenBlacklist {
+func NewTokenBlacklist(db *sql.DB) usecase.TokenBlacklistRepository {
 	return &TokenBlacklist{db: db}
 }
 
@@ -43,6 +44,29 @@ func (tb *TokenBlacklist) IsTokenBlacklisted(ctx context.Context, tokenID string
 	return exists, nil
 }
 
+func (tb *TokenBlacklist) RevokeUserTokens(ctx context.Context, userID int) error {
+	query := `UPDATE users SET token_generation = token_generation + 1 WHERE id = $1`
+	_, err := tb.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// GetUserTokenGeneration returns 0 for an unknown user, whose tokens are rejected anyway
+func (tb *TokenBlacklist) GetUserTokenGeneration(ctx context.Context, userID int) (int, error) {
+	query := `SELECT token_generation FROM users WHERE id = $1`
+	var generation int
+	err := tb.db.QueryRowContext(ctx, query, userID).Scan(&generation)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get user token generation: %w", err)
+	}
+	return generation, nil
+}
+
 func (tb *TokenBlacklist) CleanupExpiredTokens(ctx context.Context) error {
 	query := `DELETE FROM token_blacklist WHERE expires_at <= NOW()`
 	_, err := tb.db.ExecContext(ctx, query)
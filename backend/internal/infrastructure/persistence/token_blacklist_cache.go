@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todo-app/internal/usecase"
+)
+
+// CachedTokenBlacklist keeps recent blacklist lookups in memory so that
+// authenticating a request does not hit the database every time.
+// Revocations made through this instance take effect immediately; revocations
+// made by other replicas are picked up once the cached entry expires, so logging
+// out everywhere can take up to ttl to reach requests served by another replica.
+type CachedTokenBlacklist struct {
+	next usecase.TokenBlacklistRepository
+	ttl  time.Duration
+
+	mu        sync.RWMutex
+	tokens    map[string]cachedBlacklistEntry
+	users     map[int]cachedGenerationEntry
+	lastSweep time.Time
+}
+
+type cachedBlacklistEntry struct {
+	blacklisted bool
+	cachedUntil time.Time
+}
+
+type cachedGenerationEntry struct {
+	generation  int
+	cachedUntil time.Time
+}
+
+func NewCachedTokenBlacklist(next usecase.TokenBlacklistRepository, ttl time.Duration) usecase.TokenBlacklistRepository {
+	return &CachedTokenBlacklist{
+		next:      next,
+		ttl:       ttl,
+		tokens:    make(map[string]cachedBlacklistEntry),
+		users:     make(map[int]cachedGenerationEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *CachedTokenBlacklist) AddToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if err := c.next.AddToken(ctx, tokenID, expiresAt); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A blacklisted token stays blacklisted until it expires
+	c.tokens[tokenID] = cachedBlacklistEntry{blacklisted: true, cachedUntil: expiresAt}
+	return nil
+}
+
+func (c *CachedTokenBlacklist) IsTokenBlacklisted(ctx context.Context, tokenID string) (bool, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.tokens[tokenID]
+	c.mu.RUnlock()
+	if ok && now.Before(entry.cachedUntil) {
+		return entry.blacklisted, nil
+	}
+
+	blacklisted, err := c.next.IsTokenBlacklisted(ctx, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweepLocked(now)
+	c.tokens[tokenID] = cachedBlacklistEntry{blacklisted: blacklisted, cachedUntil: now.Add(c.ttl)}
+	return blacklisted, nil
+}
+
+func (c *CachedTokenBlacklist) RevokeUserTokens(ctx context.Context, userID int) error {
+	if err := c.next.RevokeUserTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	// The next lookup reads the new generation
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, userID)
+	return nil
+}
+
+func (c *CachedTokenBlacklist) GetUserTokenGeneration(ctx context.Context, userID int) (int, error) {
+	now := time.Now()
+
+	c.mu.RLock()
+	entry, ok := c.users[userID]
+	c.mu.RUnlock()
+	if ok && now.Before(entry.cachedUntil) {
+		return entry.generation, nil
+	}
+
+	generation, err := c.next.GetUserTokenGeneration(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweepLocked(now)
+	c.users[userID] = cachedGenerationEntry{generation: generation, cachedUntil: now.Add(c.ttl)}
+	return generation, nil
+}
+
+func (c *CachedTokenBlacklist) CleanupExpiredTokens(ctx context.Context) error {
+	c.mu.Lock()
+	c.sweepLocked(time.Now())
+	c.mu.Unlock()
+
+	return c.next.CleanupExpiredTokens(ctx)
+}
+
+// sweepLocked drops stale entries at most once per ttl. The caller must hold mu.
+func (c *CachedTokenBlacklist) sweepLocked(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+
+	for tokenID, entry := range c.tokens {
+		if !now.Before(entry.cachedUntil) {
+			delete(c.tokens, tokenID)
+		}
+	}
+	for userID, entry := range c.users {
+		if !now.Before(entry.cachedUntil) {
+			delete(c.users, userID)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingBlacklist stands in for the database behind the cache
+type countingBlacklist struct {
+	blacklisted map[string]bool
+	generations map[int]int
+	lookups     int
+}
+
+func (b *countingBlacklist) AddToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	b.blacklisted[tokenID] = true
+	return nil
+}
+
+func (b *countingBlacklist) IsTokenBlacklisted(ctx context.Context, tokenID string) (bool, error) {
+	b.lookups++
+	return b.blacklisted[tokenID], nil
+}
+
+func (b *countingBlacklist) RevokeUserTokens(ctx context.Context, userID int) error {
+	b.generations[userID]++
+	return nil
+}
+
+func (b *countingBlacklist) GetUserTokenGeneration(ctx context.Context, userID int) (int, error) {
+	b.lookups++
+	return b.generations[userID], nil
+}
+
+func (b *countingBlacklist) CleanupExpiredTokens(ctx context.Context) error {
+	return nil
+}
+
+func TestCachedTokenBlacklist(t *testing.T) {
+	const ttl = 50 * time.Millisecond
+
+	tests := []struct {
+		name string
+		// revoke revokes through the cache itself, or else directly in the database as another replica would
+		revoke         func(ctx context.Context, db *countingBlacklist, cached *CachedTokenBlacklist) error
+		wantGeneration int
+		// wantAfterTTL is the generation read once the cached entries have expired
+		wantAfterTTL int
+	}{
+		{
+			name: "through this instance",
+			revoke: func(ctx context.Context, db *countingBlacklist, cached *CachedTokenBlacklist) error {
+				return cached.RevokeUserTokens(ctx, 1)
+			},
+			wantGeneration: 1,
+			wantAfterTTL:   1,
+		},
+		{
+			name: "through another replica",
+			revoke: func(ctx context.Context, db *countingBlacklist, cached *CachedTokenBlacklist) error {
+				return db.RevokeUserTokens(ctx, 1)
+			},
+			wantGeneration: 0,
+			wantAfterTTL:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := &countingBlacklist{blacklisted: map[string]bool{}, generations: map[int]int{}}
+			cached := NewCachedTokenBlacklist(db, ttl).(*CachedTokenBlacklist)
+
+			if generation, _ := cached.GetUserTokenGeneration(ctx, 1); generation != 0 {
+				t.Fatalf("generation = %d before any revocation", generation)
+			}
+			if err := tt.revoke(ctx, db, cached); err != nil {
+				t.Fatal(err)
+			}
+			if generation, _ := cached.GetUserTokenGeneration(ctx, 1); generation != tt.wantGeneration {
+				t.Errorf("generation = %d right after the revocation, want %d", generation, tt.wantGeneration)
+			}
+			time.Sleep(ttl)
+			if generation, _ := cached.GetUserTokenGeneration(ctx, 1); generation != tt.wantAfterTTL {
+				t.Errorf("generation = %d after the cache expired, want %d", generation, tt.wantAfterTTL)
+			}
+		})
+	}
+}
+
+func TestCachedTokenBlacklistTokens(t *testing.T) {
+	ctx := context.Background()
+	db := &countingBlacklist{blacklisted: map[string]bool{}, generations: map[int]int{}}
+	cached := NewCachedTokenBlacklist(db, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if blacklisted, _ := cached.IsTokenBlacklisted(ctx, "token"); blacklisted {
+			t.Fatal("token blacklisted before logout")
+		}
+	}
+	if db.lookups != 1 {
+		t.Errorf("looked up %d times, want 1", db.lookups)
+	}
+
+	// Logging out through the cache takes effect at once, even though the entry was cached
+	if err := cached.AddToken(ctx, "token", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if blacklisted, _ := cached.IsTokenBlacklisted(ctx, "token"); !blacklisted {
+		t.Error("token not blacklisted after logout")
+	}
+}
@@ -0,0 +1,136 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"todo-app/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// trashQueries work on trashed todos on purpose, or create todos. Rebalancing
+// renumbers trashed todos too, so that a restored todo keeps its place.
+var trashQueries = map[string]bool{
+	"CreateTodo":             true,
+	"ListTrashedTodos":       true,
+	"RestoreTodo":            true,
+	"PurgeTodo":              true,
+	"PurgeTrash":             true,
+	"SkipTodoTouchTriggers":  true,
+	"RebalanceTodoPositions": true,
+}
+
+func TestTodoQueriesExcludeTrash(t *testing.T) {
+	source, err := os.ReadFile("../../interface/repository/todo.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := regexp.MustCompile(`^-- name: (\w+)`)
+	for _, query := range regexp.MustCompile(`(?m)^-- name: `).Split(string(source), -1)[1:] {
+		query = "-- name: " + query
+		queryName := name.FindStringSubmatch(query)[1]
+		if trashQueries[queryName] {
+			continue
+		}
+		if !strings.Contains(query, "deleted_at IS NULL") {
+			t.Errorf("%s does not exclude trashed todos", queryName)
+		}
+	}
+}
+
+func TestDeleteTodo(t *testing.T) {
+	tests := []struct {
+		name    string
+		version *int
+		// locked is the version of the locked todo, nil when the user has no such todo
+		locked  *int
+		rows    int64
+		wantErr error
+	}{
+		{name: "moves the todo to the trash and records it", rows: 1},
+		{name: "unknown, another user's or trashed todo", rows: 0, wantErr: domain.ErrTodoNotFound},
+		{name: "expected version", version: intPtr(3), locked: intPtr(3), rows: 1},
+		{name: "stale version", version: intPtr(2), locked: intPtr(3), wantErr: domain.ErrTodoVersionMismatch},
+		{name: "version of an unknown todo", version: intPtr(3), wantErr: domain.ErrTodoNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			if tt.version != nil {
+				lock := mock.ExpectQuery("-- name: GetTodoForUpdate :one").WithArgs(5, 1)
+				if tt.locked == nil {
+					lock.WillReturnError(sql.ErrNoRows)
+				} else {
+					lock.WillReturnRows(todoRows(int32(*tt.locked), 5))
+					mock.ExpectQuery("-- name: ListTodoTagIDs :many").WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"tag_id"}))
+				}
+			}
+			if tt.version == nil || tt.locked != nil && *tt.locked == *tt.version {
+				mock.ExpectExec("-- name: TrashTodo :execrows").WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, tt.rows))
+			}
+			if tt.wantErr == nil {
+				mock.ExpectExec("-- name: CreateTodoEvent :exec").WithArgs(5, 1, "delete", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			if err := NewTodoRepository(db).DeleteTodo(context.Background(), 1, 5, tt.version); err != tt.wantErr {
+				t.Errorf("DeleteTodo() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestUndoDelete(t *testing.T) {
+	tests := []struct {
+		name         string
+		rows         int64
+		wantReverted bool
+	}{
+		{name: "restores the todo and records it", rows: 1, wantReverted: true},
+		{name: "todo no longer in the trash", rows: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectExec("-- name: RestoreTodo :execrows").WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, tt.rows))
+			if tt.wantReverted {
+				mock.ExpectExec("-- name: CreateTodoEvent :exec").WithArgs(5, 1, "restore", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			event := &domain.TodoEvent{TodoID: 5, Action: domain.TodoEventDelete, Changes: map[string]domain.FieldChange{}}
+			reverted, err := undoTodoEvent(context.Background(), New(db), 1, event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reverted != tt.wantReverted {
+				t.Errorf("undoTodoEvent() = %v, want %v", reverted, tt.wantReverted)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -16,7 +16,7 @@ INSERT INTO users (
     password_hash
 ) VALUES (
     $1, $2, $3
-) RETURNING id, username, email, password_hash, created_at, updated_at
+) RETURNING id, username, email, password_hash, created_at, updated_at, token_generation, timezone
 `
 
 type CreateUserParams struct {
@@ -35,12 +35,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenGeneration,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, token_generation, timezone FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -54,12 +56,14 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenGeneration,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, token_generation, timezone FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -73,12 +77,14 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenGeneration,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, token_generation, timezone FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -92,18 +98,39 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenGeneration,
+		&i.Timezone,
 	)
 	return i, err
 }
 
+const rezoneTodoDueDates = `-- name: RezoneTodoDueDates :exec
+UPDATE todos
+SET due_date = (due_at AT TIME ZONE $1::text)::date
+WHERE user_id = $2 AND due_at IS NOT NULL
+  AND due_date IS DISTINCT FROM (due_at AT TIME ZONE $1::text)::date
+`
+
+type RezoneTodoDueDatesParams struct {
+	UserID   int32  `json:"user_id"`
+	Timezone string `json:"timezone"`
+}
+
+// 時刻付きの期日は瞬間(due_at)を保ったまま、新しいタイムゾーンでの日付にdue_dateを合わせる
+func (q *Queries) RezoneTodoDueDates(ctx context.Context, arg RezoneTodoDueDatesParams) error {
+	_, err := q.db.ExecContext(ctx, rezoneTodoDueDates, arg.UserID, arg.Timezone)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET username = $2,
     email = $3,
     password_hash = $4,
+    timezone = $5,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, username, email, password_hash, created_at, updated_at
+RETURNING id, username, email, password_hash, created_at, updated_at, token_generation, timezone
 `
 
 type UpdateUserParams struct {
@@ -111,6 +138,7 @@ type UpdateUserParams struct {
 	Username     string `json:"username"`
 	Email        string `json:"email"`
 	PasswordHash string `json:"password_hash"`
+	Timezone     string `json:"timezone"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -119,6 +147,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Username,
 		arg.Email,
 		arg.PasswordHash,
+		arg.Timezone,
 	)
 	var i User
 	err := row.Scan(
@@ -128,6 +157,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TokenGeneration,
+		&i.Timezone,
 	)
 	return i, err
 }
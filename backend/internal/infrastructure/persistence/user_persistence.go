@@ -8,11 +8,13 @@ import (
 )
 
 type UserPersistence struct {
+	db      *sql.DB
 	queries *Queries
 }
 
 func NewUserPersistence(db *sql.DB) usecase.UserRepository {
 	return &UserPersistence{
+		db:      db,
 		queries: New(db),
 	}
 }
@@ -31,6 +33,7 @@ func (up *UserPersistence) CreateUser(ctx context.Context, user *domain.User) er
 	}
 
 	user.ID = int(sqlcUser.ID)
+	user.Timezone = sqlcUser.Timezone
 	user.CreatedAt = sqlcUser.CreatedAt.Time
 	user.UpdatedAt = sqlcUser.UpdatedAt.Time
 
@@ -48,12 +51,14 @@ func (up *UserPersistence) GetUserByUsername(ctx context.Context, username strin
 	}
 
 	user := &domain.User{
-		ID:           int(sqlcUser.ID),
-		Username:     sqlcUser.Username,
-		Email:        sqlcUser.Email,
-		PasswordHash: sqlcUser.PasswordHash,
-		CreatedAt:    sqlcUser.CreatedAt.Time,
-		UpdatedAt:    sqlcUser.UpdatedAt.Time,
+		ID:              int(sqlcUser.ID),
+		Username:        sqlcUser.Username,
+		Email:           sqlcUser.Email,
+		PasswordHash:    sqlcUser.PasswordHash,
+		Timezone:        sqlcUser.Timezone,
+		CreatedAt:       sqlcUser.CreatedAt.Time,
+		UpdatedAt:       sqlcUser.UpdatedAt.Time,
+		TokenGeneration: int(sqlcUser.TokenGeneration),
 	}
 
 	return user, nil
@@ -70,12 +75,14 @@ func (up *UserPersistence) GetUserByEmail(ctx context.Context, email string) (*d
 	}
 
 	user := &domain.User{
-		ID:           int(sqlcUser.ID),
-		Username:     sqlcUser.Username,
-		Email:        sqlcUser.Email,
-		PasswordHash: sqlcUser.PasswordHash,
-		CreatedAt:    sqlcUser.CreatedAt.Time,
-		UpdatedAt:    sqlcUser.UpdatedAt.Time,
+		ID:              int(sqlcUser.ID),
+		Username:        sqlcUser.Username,
+		Email:           sqlcUser.Email,
+		PasswordHash:    sqlcUser.PasswordHash,
+		Timezone:        sqlcUser.Timezone,
+		CreatedAt:       sqlcUser.CreatedAt.Time,
+		UpdatedAt:       sqlcUser.UpdatedAt.Time,
+		TokenGeneration: int(sqlcUser.TokenGeneration),
 	}
 
 	return user, nil
@@ -92,31 +99,65 @@ func (up *UserPersistence) GetUserByID(ctx context.Context, id int) (*domain.Use
 	}
 
 	user := &domain.User{
-		ID:           int(sqlcUser.ID),
-		Username:     sqlcUser.Username,
-		Email:        sqlcUser.Email,
-		PasswordHash: sqlcUser.PasswordHash,
-		CreatedAt:    sqlcUser.CreatedAt.Time,
-		UpdatedAt:    sqlcUser.UpdatedAt.Time,
+		ID:              int(sqlcUser.ID),
+		Username:        sqlcUser.Username,
+		Email:           sqlcUser.Email,
+		PasswordHash:    sqlcUser.PasswordHash,
+		Timezone:        sqlcUser.Timezone,
+		CreatedAt:       sqlcUser.CreatedAt.Time,
+		UpdatedAt:       sqlcUser.UpdatedAt.Time,
+		TokenGeneration: int(sqlcUser.TokenGeneration),
 	}
 
 	return user, nil
 }
 
+// UpdateUser saves the user. Due dates of todos with a due time are their dates in the
+// user's time zone, so changing the time zone moves them in the same transaction.
 func (up *UserPersistence) UpdateUser(ctx context.Context, user *domain.User) error {
 
+	tx, err := up.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := up.updateUser(ctx, up.queries.WithTx(tx), user); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (up *UserPersistence) updateUser(ctx context.Context, q *Queries, user *domain.User) error {
+	timezone := user.Timezone
+	if timezone == "" {
+		timezone = domain.DefaultTimezone
+	}
 	params := UpdateUserParams{
 		ID:           int32(user.ID),
 		Username:     user.Username,
 		Email:        user.Email,
 		PasswordHash: user.PasswordHash,
+		Timezone:     timezone,
 	}
 
-	sqlcUser, err := up.queries.UpdateUser(ctx, params)
+	sqlcUser, err := q.UpdateUser(ctx, params)
 	if err != nil {
 		return err
 	}
 
+	rezoneParams := RezoneTodoDueDatesParams{
+		UserID:   sqlcUser.ID,
+		Timezone: sqlcUser.Timezone,
+	}
+	if err := q.RezoneTodoDueDates(ctx, rezoneParams); err != nil {
+		return err
+	}
+
+	user.Timezone = sqlcUser.Timezone
 	user.UpdatedAt = sqlcUser.UpdatedAt.Time
 
 	return nil
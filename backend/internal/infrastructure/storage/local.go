@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+// LocalBlobPath is where the API serves the signed URLs of a LocalBlobStore; it must match the route
+const LocalBlobPath = "/api/v1/blobs/"
+
+// LocalBlobStore keeps blobs as files under a directory. Its signed URLs point back at
+// the API, which checks the HMAC signature and serves the file.
+type LocalBlobStore struct {
+	root string
+	// baseURL is prepended to LocalBlobPath, e.g. "https://api.example.com"; empty gives relative URLs
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalBlobStore(root string, baseURL string, secret []byte) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalBlobStore{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+	}, nil
+}
+
+var (
+	_ usecase.BlobStore        = (*LocalBlobStore)(nil)
+	_ usecase.SignedBlobOpener = (*LocalBlobStore)(nil)
+)
+
+func (ls *LocalBlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a failed upload never leaves a partial blob
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	written, err := io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return errors.New("blob size does not match the upload")
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (ls *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (ls *LocalBlobStore) SignedURL(ctx context.Context, key string, download usecase.BlobDownload, expiry time.Duration) (string, error) {
+	if _, err := ls.path(key); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(time.Now().Add(expiry).Unix(), 10))
+	query.Set("filename", download.Filename)
+	query.Set("type", download.ContentType)
+	query.Set("signature", ls.sign(key, query))
+
+	return ls.baseURL + LocalBlobPath + key + "?" + query.Encode(), nil
+}
+
+func (ls *LocalBlobStore) OpenSigned(ctx context.Context, key string, query url.Values) (io.ReadSeekCloser, usecase.BlobDownload, error) {
+	download := usecase.BlobDownload{
+		Filename:    query.Get("filename"),
+		ContentType: query.Get("type"),
+	}
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return nil, download, domain.ErrInvalidBlobSignature
+	}
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		return nil, download, domain.ErrInvalidBlobSignature
+	}
+	expected, _ := hex.DecodeString(ls.sign(key, query))
+	if !hmac.Equal(signature, expected) {
+		return nil, download, domain.ErrInvalidBlobSignature
+	}
+
+	path, err := ls.path(key)
+	if err != nil {
+		return nil, download, domain.ErrInvalidBlobSignature
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, download, domain.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, download, err
+	}
+	return file, download, nil
+}
+
+// sign returns the HMAC of the key and the query fields that describe the download
+func (ls *LocalBlobStore) sign(key string, query url.Values) string {
+	mac := hmac.New(sha256.New, ls.secret)
+	for _, part := range []string{key, query.Get("expires"), query.Get("filename"), query.Get("type")} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps a key to a file under root, refusing keys that would leave it
+func (ls *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", errors.New("invalid blob key")
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.HasPrefix(segment, ".") {
+			return "", errors.New("invalid blob key")
+		}
+	}
+	return filepath.Join(ls.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+func newTestLocalBlobStore(t *testing.T, secret string) *LocalBlobStore {
+	t.Helper()
+	store, err := NewLocalBlobStore(t.TempDir(), "https://api.example.com/", []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// signedQuery returns the query of a signed URL for key, after checking where the URL points
+func signedQuery(t *testing.T, store *LocalBlobStore, key string, expiry time.Duration) url.Values {
+	t.Helper()
+	download := usecase.BlobDownload{Filename: "receipt.pdf", ContentType: "application/pdf"}
+	signed, err := store.SignedURL(context.Background(), key, download, expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://api.example.com" + LocalBlobPath + key; parsed.Scheme+"://"+parsed.Host+parsed.Path != want {
+		t.Fatalf("SignedURL() = %s, want it to point at %s", signed, want)
+	}
+	return parsed.Query()
+}
+
+func TestLocalBlobStoreOpenSigned(t *testing.T) {
+	const key = "todos/1/abc"
+	tests := []struct {
+		name string
+		// expiry is the validity the URL is signed for
+		expiry time.Duration
+		// tamper changes the signed query, or the key presented with it
+		tamper  func(query url.Values) string
+		wantErr error
+	}{
+		{name: "valid URL", expiry: time.Minute},
+		{name: "expired URL", expiry: -time.Second, wantErr: domain.ErrInvalidBlobSignature},
+		{
+			name:   "extended expiry",
+			expiry: time.Minute,
+			tamper: func(query url.Values) string {
+				query.Set("expires", "9999999999")
+				return key
+			},
+			wantErr: domain.ErrInvalidBlobSignature,
+		},
+		{
+			name:   "another filename",
+			expiry: time.Minute,
+			tamper: func(query url.Values) string {
+				query.Set("filename", "invoice.pdf")
+				return key
+			},
+			wantErr: domain.ErrInvalidBlobSignature,
+		},
+		{
+			name:   "another content type",
+			expiry: time.Minute,
+			tamper: func(query url.Values) string {
+				query.Set("type", "text/html")
+				return key
+			},
+			wantErr: domain.ErrInvalidBlobSignature,
+		},
+		{
+			name:   "another key",
+			expiry: time.Minute,
+			tamper: func(query url.Values) string {
+				return "todos/2/abc"
+			},
+			wantErr: domain.ErrInvalidBlobSignature,
+		},
+		{
+			name:   "malformed signature",
+			expiry: time.Minute,
+			tamper: func(query url.Values) string {
+				query.Set("signature", "not hex")
+				return key
+			},
+			wantErr: domain.ErrInvalidBlobSignature,
+		},
+		{
+			name:   "missing signature",
+			expiry: time.Minute,
+			tamper: func(query url.Values) string {
+				query.Del("signature")
+				return key
+			},
+			wantErr: domain.ErrInvalidBlobSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTestLocalBlobStore(t, "secret")
+			if err := store.Put(ctx, key, strings.NewReader("%PDF"), 4, "application/pdf"); err != nil {
+				t.Fatal(err)
+			}
+
+			query := signedQuery(t, store, key, tt.expiry)
+			presented := key
+			if tt.tamper != nil {
+				presented = tt.tamper(query)
+			}
+
+			blob, download, err := store.OpenSigned(ctx, presented, query)
+			if err != tt.wantErr {
+				t.Fatalf("OpenSigned() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer blob.Close()
+			body, err := io.ReadAll(blob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != "%PDF" || download.Filename != "receipt.pdf" || download.ContentType != "application/pdf" {
+				t.Errorf("OpenSigned() = %q as %+v, want the stored PDF as receipt.pdf", body, download)
+			}
+		})
+	}
+}
+
+func TestLocalBlobStoreOpenSignedOtherSecret(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalBlobStore(t, "secret")
+	query := signedQuery(t, store, "todos/1/abc", time.Minute)
+
+	other := newTestLocalBlobStore(t, "another secret")
+	if _, _, err := other.OpenSigned(ctx, "todos/1/abc", query); err != domain.ErrInvalidBlobSignature {
+		t.Errorf("OpenSigned() with another secret: got %v, want ErrInvalidBlobSignature", err)
+	}
+}
+
+func TestLocalBlobStoreOpenSignedDeletedBlob(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalBlobStore(t, "secret")
+	if err := store.Put(ctx, "todos/1/abc", strings.NewReader("%PDF"), 4, "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	query := signedQuery(t, store, "todos/1/abc", time.Minute)
+	if err := store.Delete(ctx, "todos/1/abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := store.OpenSigned(ctx, "todos/1/abc", query); err != domain.ErrAttachmentNotFound {
+		t.Errorf("OpenSigned() of a deleted blob: got %v, want ErrAttachmentNotFound", err)
+	}
+}
+
+func TestLocalBlobStorePath(t *testing.T) {
+	store := newTestLocalBlobStore(t, "secret")
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "todos/1/abc"},
+		{key: "abc"},
+		{key: "", wantErr: true},
+		{key: "/etc/passwd", wantErr: true},
+		{key: "../secret", wantErr: true},
+		{key: "todos/../../secret", wantErr: true},
+		{key: "todos/./abc", wantErr: true},
+		{key: "todos//abc", wantErr: true},
+		{key: "todos/1/", wantErr: true},
+		{key: "todos/.upload-123", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		path, err := store.path(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("path(%q) error = %v, want error %v", tt.key, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !strings.HasPrefix(path, store.root+string(filepath.Separator)) {
+			t.Errorf("path(%q) = %s, want a file under %s", tt.key, path, store.root)
+		}
+	}
+}
+
+func TestLocalBlobStorePutRejectsTraversal(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalBlobStore(t, "secret")
+
+	if err := store.Put(ctx, "../escaped", strings.NewReader("x"), 1, "text/plain"); err == nil {
+		t.Fatal("Put() accepted a key outside the root")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(store.root), "escaped")); !os.IsNotExist(err) {
+		t.Errorf("a file was written outside the root: %v", err)
+	}
+	if _, err := store.SignedURL(ctx, "../escaped", usecase.BlobDownload{}, time.Minute); err == nil {
+		t.Error("SignedURL() signed a key outside the root")
+	}
+}
+
+func TestLocalBlobStorePutSizeMismatch(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalBlobStore(t, "secret")
+
+	if err := store.Put(ctx, "todos/1/abc", strings.NewReader("short"), 10, "text/plain"); err == nil {
+		t.Fatal("Put() accepted a body shorter than its size")
+	}
+	entries, err := os.ReadDir(filepath.Join(store.root, "todos", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("a failed upload left %d files behind", len(entries))
+	}
+}
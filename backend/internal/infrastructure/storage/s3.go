@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/usecase"
+)
+
+const (
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3DateFormat    = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3MaxExpiry is the longest validity of a presigned URL allowed by Signature Version 4
+	s3MaxExpiry = 7 * 24 * time.Hour
+)
+
+// S3Config locates a bucket on AWS S3 or an S3-compatible service such as MinIO
+type S3Config struct {
+	// Endpoint is the service URL, e.g. "https://s3.ap-northeast-1.amazonaws.com" or "http://localhost:9000"
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3BlobStore keeps blobs in a bucket of an S3-compatible service. Requests are signed
+// with AWS Signature Version 4 and use path-style URLs, which every such service accepts.
+type S3BlobStore struct {
+	endpoint *url.URL
+	config   S3Config
+	client   *http.Client
+}
+
+func NewS3BlobStore(config S3Config) (*S3BlobStore, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("S3 bucket and credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return &S3BlobStore{
+		endpoint: endpoint,
+		config:   config,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+var _ usecase.BlobStore = (*S3BlobStore)(nil)
+
+func (ss *S3BlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	response, err := ss.do(ctx, http.MethodPut, key, body, size, contentType)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return s3Error(response)
+	}
+	return nil
+}
+
+func (ss *S3BlobStore) Delete(ctx context.Context, key string) error {
+	response, err := ss.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// S3 answers 204 whether or not the object existed; other services may answer 404
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		return s3Error(response)
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of the object. S3 serves it with the Content-Type and
+// Content-Disposition given by the response-* parameters, which are signed as well.
+func (ss *S3BlobStore) SignedURL(ctx context.Context, key string, download usecase.BlobDownload, expiry time.Duration) (string, error) {
+	if expiry > s3MaxExpiry {
+		expiry = s3MaxExpiry
+	}
+	now := time.Now().UTC()
+	path := ss.objectPath(key)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":              s3Algorithm,
+		"X-Amz-Credential":             ss.config.AccessKeyID + "/" + ss.scope(now),
+		"X-Amz-Date":                   now.Format(s3DateFormat),
+		"X-Amz-Expires":                strconv.Itoa(int(expiry.Seconds())),
+		"X-Amz-SignedHeaders":          "host",
+		"response-content-disposition": download.ContentDisposition(),
+		"response-content-type":        download.ContentType,
+	}
+	canonicalQuery := canonicalQueryString(query)
+	_, signature := ss.sign(http.MethodGet, path, canonicalQuery, map[string]string{"host": ss.endpoint.Host}, unsignedPayload, now)
+
+	return ss.endpoint.Scheme + "://" + ss.endpoint.Host + path + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+// do sends a request for the object signed in the Authorization header. The payload is
+// not hashed, so that uploads can be streamed; TLS protects it when the endpoint is https.
+func (ss *S3BlobStore) do(ctx context.Context, method string, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	now := time.Now().UTC()
+	path := ss.objectPath(key)
+
+	request, err := http.NewRequestWithContext(ctx, method, ss.endpoint.Scheme+"://"+ss.endpoint.Host+path, body)
+	if err != nil {
+		return nil, err
+	}
+	request.ContentLength = size
+
+	headers := map[string]string{
+		"host":                 ss.endpoint.Host,
+		"x-amz-content-sha256": unsignedPayload,
+		"x-amz-date":           now.Format(s3DateFormat),
+	}
+	if contentType != "" {
+		headers["content-type"] = contentType
+	}
+	signedHeaders, signature := ss.sign(method, path, "", headers, unsignedPayload, now)
+
+	for name, value := range headers {
+		if name != "host" {
+			request.Header.Set(name, value)
+		}
+	}
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, ss.config.AccessKeyID, ss.scope(now), signedHeaders, signature))
+
+	return ss.client.Do(request)
+}
+
+// objectPath is the URI-encoded path of the object, e.g. /bucket/todos/1/abc
+func (ss *S3BlobStore) objectPath(key string) string {
+	return strings.TrimSuffix(ss.endpoint.Path, "/") + "/" + uriEncode(ss.config.Bucket, true) + "/" + uriEncode(key, false)
+}
+
+func (ss *S3BlobStore) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + ss.config.Region + "/s3/aws4_request"
+}
+
+// sign computes a Signature Version 4 signature and returns it with the signed header names.
+// headers must have lower-case names.
+func (ss *S3BlobStore) sign(method string, path string, query string, headers map[string]string, payloadHash string, now time.Time) (string, string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{method, path, query, canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, now.Format(s3DateFormat), ss.scope(now), hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+ss.config.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, ss.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQueryString sorts and encodes the parameters as Signature Version 4 requires
+func canonicalQueryString(query map[string]string) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = uriEncode(name, true) + "=" + uriEncode(query[name], true)
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and '/' unless encodeSlash is set
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+// s3Error reads the error code from an S3 XML error response
+func s3Error(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	code := string(body)
+	if start := strings.Index(code, "<Code>"); start >= 0 {
+		code = code[start+len("<Code>"):]
+		if end := strings.Index(code, "</Code>"); end >= 0 {
+			code = code[:end]
+		}
+	}
+	return fmt.Errorf("S3 %s: %s", response.Status, strings.TrimSpace(code))
+}
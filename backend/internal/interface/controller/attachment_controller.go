@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/interface/middleware"
+	"todo-app/internal/usecase"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers around the file
+const multipartOverhead = 1 << 20
+
+type AttachmentController struct {
+	attachmentUseCase usecase.AttachmentUseCase
+	// blobOpener serves signed URLs when the blob store has no server of its own; nil otherwise
+	blobOpener usecase.SignedBlobOpener
+}
+
+type AttachmentResponse struct {
+	ID          int    `json:"id"`
+	TodoID      int    `json:"todo_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	CreatedAt   string `json:"created_at"`
+	// URL downloads the file without the Authorization header until URLExpiresAt
+	URL          string `json:"url"`
+	URLExpiresAt string `json:"url_expires_at"`
+}
+
+func NewAttachmentController(attachmentUseCase usecase.AttachmentUseCase, blobOpener usecase.SignedBlobOpener) *AttachmentController {
+	return &AttachmentController{
+		attachmentUseCase: attachmentUseCase,
+		blobOpener:        blobOpener,
+	}
+}
+
+// UploadAttachment takes the file in the "file" field of a multipart form, /api/v1/todos/{id}/attachments
+func (ac *AttachmentController) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, usecase.MaxAttachmentSize+multipartOverhead)
+	// Files above 1MB are buffered on disk rather than in memory
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			handleErrorResponse(w, domain.ErrAttachmentTooLarge)
+			return
+		}
+		handleErrorResponse(w, domain.ErrAttachmentRequired)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		handleErrorResponse(w, domain.ErrAttachmentRequired)
+		return
+	}
+	defer file.Close()
+
+	// The type is taken from the content, as the one sent by the client cannot be trusted
+	detected, err := mimetype.DetectReader(file)
+	if err != nil {
+		handleErrorResponse(w, domain.ErrAttachmentRequired)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	upload := usecase.AttachmentUpload{
+		Filename:    header.Filename,
+		ContentType: detected.String(),
+		Size:        header.Size,
+		Body:        file,
+	}
+	attachment, err := ac.attachmentUseCase.UploadAttachment(r.Context(), userID, todoID, upload)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, attachmentToResponse(attachment), http.StatusCreated)
+}
+
+func (ac *AttachmentController) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	attachments, err := ac.attachmentUseCase.GetAttachments(r.Context(), userID, todoID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	responses := make([]AttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		responses[i] = attachmentToResponse(attachment)
+	}
+	writeJSONResponse(w, responses, http.StatusOK)
+}
+
+// GetAttachment returns the attachment with a fresh download URL
+func (ac *AttachmentController) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	attachmentID, err := parsePathID(r.URL.Path, "attachments")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	attachment, err := ac.attachmentUseCase.GetAttachment(r.Context(), userID, todoID, attachmentID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, attachmentToResponse(attachment), http.StatusOK)
+}
+
+func (ac *AttachmentController) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	attachmentID, err := parsePathID(r.URL.Path, "attachments")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := ac.attachmentUseCase.DeleteAttachment(r.Context(), userID, todoID, attachmentID); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DownloadBlob serves a signed URL of the local blob store, /api/v1/blobs/{key}.
+// The signature stands in for authentication, so that the URL works in a plain link.
+func (ac *AttachmentController) DownloadBlob(w http.ResponseWriter, r *http.Request) {
+	if ac.blobOpener == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/api/v1/blobs/")
+	blob, download, err := ac.blobOpener.OpenSigned(r.Context(), key, r.URL.Query())
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", download.ContentType)
+	w.Header().Set("Content-Disposition", download.ContentDisposition())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private")
+	http.ServeContent(w, r, "", time.Time{}, blob)
+}
+
+func attachmentToResponse(attachment *domain.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:           attachment.ID,
+		TodoID:       attachment.TodoID,
+		Filename:     attachment.Filename,
+		ContentType:  attachment.ContentType,
+		Size:         attachment.Size,
+		CreatedAt:    attachment.CreatedAt.Format(time.RFC3339),
+		URL:          attachment.URL,
+		URLExpiresAt: attachment.URLExpiresAt.Format(time.RFC3339),
+	}
+}
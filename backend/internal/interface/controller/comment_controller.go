@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"todo-app/internal/domain"
+	"todo-app/internal/interface/middleware"
+	"todo-app/internal/usecase"
+)
+
+type CommentController struct {
+	commentUseCase usecase.CommentUseCase
+	validate       *validator.Validate
+}
+
+type CreateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+	// ParentID makes the comment a reply
+	ParentID *int `json:"parent_id" validate:"omitempty,min=1"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+type CommentAuthorResponse struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+type CommentResponse struct {
+	ID       int  `json:"id"`
+	TodoID   int  `json:"todo_id"`
+	ParentID *int `json:"parent_id"`
+	// Author is null once the author is deleted
+	Author   *CommentAuthorResponse `json:"author"`
+	Body     string                 `json:"body"`
+	BodyHTML *string                `json:"body_html,omitempty"`
+	// IsDeleted marks a deleted comment kept for its replies
+	IsDeleted bool              `json:"is_deleted"`
+	CreatedAt string            `json:"created_at"`
+	EditedAt  *string           `json:"edited_at"`
+	Replies   []CommentResponse `json:"replies"`
+}
+
+func NewCommentController(commentUseCase usecase.CommentUseCase) *CommentController {
+	return &CommentController{
+		commentUseCase: commentUseCase,
+		validate:       validator.New(),
+	}
+}
+
+// GetComments returns the todo's threads, each comment with its replies
+func (cc *CommentController) GetComments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	renderHTML, err := wantsRenderedHTML(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	comments, err := cc.commentUseCase.GetComments(r.Context(), userID, todoID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	responses, err := commentsToResponse(comments, renderHTML)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	writeJSONResponse(w, responses, http.StatusOK)
+}
+
+func (cc *CommentController) CreateComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	renderHTML, err := wantsRenderedHTML(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if err := cc.validate.Struct(req); err != nil {
+		handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	comment := &domain.Comment{
+		TodoID:   todoID,
+		ParentID: req.ParentID,
+		Body:     req.Body,
+	}
+
+	if err := cc.commentUseCase.CreateComment(r.Context(), userID, comment); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	response, err := commentToResponse(comment, renderHTML)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	writeJSONResponse(w, response, http.StatusCreated)
+}
+
+// UpdateComment changes the body of the user's own comment
+func (cc *CommentController) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	commentID, err := parsePathID(r.URL.Path, "comments")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	renderHTML, err := wantsRenderedHTML(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if err := cc.validate.Struct(req); err != nil {
+		handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	comment := &domain.Comment{
+		ID:     commentID,
+		TodoID: todoID,
+		Body:   req.Body,
+	}
+
+	if err := cc.commentUseCase.UpdateComment(r.Context(), userID, comment); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	response, err := commentToResponse(comment, renderHTML)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	writeJSONResponse(w, response, http.StatusOK)
+}
+
+// DeleteComment deletes the user's own comment
+func (cc *CommentController) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	commentID, err := parsePathID(r.URL.Path, "comments")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := cc.commentUseCase.DeleteComment(r.Context(), userID, todoID, commentID); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func commentsToResponse(comments []*domain.Comment, renderHTML bool) ([]CommentResponse, error) {
+	responses := make([]CommentResponse, len(comments))
+	for i, comment := range comments {
+		response, err := commentToResponse(comment, renderHTML)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = response
+	}
+	return responses, nil
+}
+
+// commentToResponse converts the comment and its replies; renderHTML adds body_html
+func commentToResponse(comment *domain.Comment, renderHTML bool) (CommentResponse, error) {
+	response := CommentResponse{
+		ID:        comment.ID,
+		TodoID:    comment.TodoID,
+		ParentID:  comment.ParentID,
+		Body:      comment.Body,
+		IsDeleted: comment.DeletedAt != nil,
+		CreatedAt: comment.CreatedAt.Format(time.RFC3339),
+	}
+
+	if comment.AuthorID != nil {
+		response.Author = &CommentAuthorResponse{
+			ID:       *comment.AuthorID,
+			Username: comment.AuthorName,
+		}
+	}
+	if comment.EditedAt != nil {
+		editedAt := comment.EditedAt.Format(time.RFC3339)
+		response.EditedAt = &editedAt
+	}
+	if renderHTML {
+		html, err := renderMarkdown(comment.Body)
+		if err != nil {
+			return CommentResponse{}, err
+		}
+		response.BodyHTML = &html
+	}
+
+	replies, err := commentsToResponse(comment.Replies, renderHTML)
+	if err != nil {
+		return CommentResponse{}, err
+	}
+	response.Replies = replies
+	return response, nil
+}
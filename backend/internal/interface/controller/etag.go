@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-app/internal/domain"
+)
+
+// todoETag is the entity tag of a todo, its quoted version
+func todoETag(todo *domain.Todo) string {
+	return `"` + strconv.Itoa(todo.Version) + `"`
+}
+
+// ifMatchVersion reads the version expected by If-Match. It returns nil when the
+// header is absent or "*", which any existing todo matches. If-Match compares
+// strongly, so a weak tag never matches and fails with ErrTodoVersionMismatch.
+func ifMatchVersion(r *http.Request) (*int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(value, "W/") {
+		return nil, domain.ErrTodoVersionMismatch
+	}
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return nil, domain.ErrInvalidIfMatch
+	}
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil {
+		return nil, domain.ErrInvalidIfMatch
+	}
+	return &version, nil
+}
+
+// noneMatch reports whether If-None-Match lists etag, or is "*"
+func noneMatch(r *http.Request, etag string) bool {
+	value := r.Header.Get("If-None-Match")
+	if value == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
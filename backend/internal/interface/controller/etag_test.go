@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    *int
+		wantErr error
+	}{
+		{name: "absent"},
+		{name: "any", header: "*"},
+		{name: "strong tag", header: `"3"`, want: intPtr(3)},
+		{name: "surrounding space", header: ` "3" `, want: intPtr(3)},
+		{name: "weak tag never matches", header: `W/"3"`, wantErr: domain.ErrTodoVersionMismatch},
+		{name: "unquoted", header: "3", wantErr: domain.ErrInvalidIfMatch},
+		{name: "not a version", header: `"abc"`, wantErr: domain.ErrInvalidIfMatch},
+		{name: "several tags", header: `"3", "4"`, wantErr: domain.ErrInvalidIfMatch},
+		{name: "lone quote", header: `"`, wantErr: domain.ErrInvalidIfMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/api/todos/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := ifMatchVersion(r)
+			if err != tt.wantErr {
+				t.Fatalf("ifMatchVersion(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("ifMatchVersion(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	etag := todoETag(&domain.Todo{Version: 3})
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: `"3"`, want: true},
+		{header: `"2"`, want: false},
+		{header: `W/"3"`, want: true},
+		{header: `"1", "3"`, want: true},
+		{header: `"1","2"`, want: false},
+		{header: "*", want: true},
+		{header: "3", want: false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/todos/1", nil)
+		if tt.header != "" {
+			r.Header.Set("If-None-Match", tt.header)
+		}
+		if got := noneMatch(r, etag); got != tt.want {
+			t.Errorf("noneMatch(%q, %s) = %v, want %v", tt.header, etag, got, tt.want)
+		}
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"todo-app/internal/domain"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdown renders notes and comments as GitHub Flavored Markdown. Raw HTML in the
+// source is left out by goldmark, and the output is sanitised again by markdownPolicy.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// markdownPolicy allows the formatting users can write in Markdown and drops scripts,
+// event handler attributes and javascript: URLs, so clients can insert the HTML as is
+var markdownPolicy = func() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	// Task list items are rendered as disabled checkboxes
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	policy.RequireNoReferrerOnLinks(true)
+	return policy
+}()
+
+// renderMarkdown converts Markdown to sanitised HTML
+func renderMarkdown(source string) (string, error) {
+	var html bytes.Buffer
+	if err := markdown.Convert([]byte(source), &html); err != nil {
+		return "", err
+	}
+	return markdownPolicy.Sanitize(html.String()), nil
+}
+
+// wantsRenderedHTML reads ?render=, which only takes html
+func wantsRenderedHTML(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("render") {
+	case "":
+		return false, nil
+	case "html":
+		return true, nil
+	default:
+		return false, domain.ErrInvalidRender
+	}
+}
+
+// addNotesHTML sets notes_html on the responses
+func addNotesHTML(responses []TodoResponse) error {
+	for i := range responses {
+		html, err := renderMarkdown(responses[i].Notes)
+		if err != nil {
+			return err
+		}
+		responses[i].NotesHTML = &html
+	}
+	return nil
+}
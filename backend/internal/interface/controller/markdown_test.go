@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		// want must appear in the HTML, and none of dropped may
+		want    []string
+		dropped []string
+	}{
+		{
+			name:   "formatting",
+			source: "**milk** and _eggs_\n\n- [x] oat\n- [ ] soy",
+			want:   []string{"<strong>milk</strong>", "<em>eggs</em>", `<input checked="" disabled="" type="checkbox"`, "<li>"},
+		},
+		{
+			name:   "safe link",
+			source: "[shop](https://example.com/milk)",
+			want:   []string{`<a href="https://example.com/milk" rel="nofollow noreferrer">shop</a>`},
+		},
+		{
+			name:    "script element",
+			source:  "before\n\n<script>alert(1)</script>\n\nafter",
+			want:    []string{"<p>before</p>", "<p>after</p>"},
+			dropped: []string{"<script", "alert(1)"},
+		},
+		{
+			name:    "event handler attribute",
+			source:  `<img src="x.png" onerror="alert(1)">`,
+			dropped: []string{"onerror", "alert(1)"},
+		},
+		{
+			name:    "inline raw HTML",
+			source:  `hello <b onclick="alert(1)">there</b> <iframe src="https://example.com"></iframe>`,
+			want:    []string{"hello"},
+			dropped: []string{"<b", "onclick", "<iframe"},
+		},
+		{
+			name:    "javascript URL",
+			source:  "[click](javascript:alert(1))",
+			want:    []string{"click"},
+			dropped: []string{"javascript:", "href"},
+		},
+		{
+			name:    "data URL",
+			source:  "[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)",
+			want:    []string{"click"},
+			dropped: []string{"data:", "href"},
+		},
+		{
+			name:    "data URL image",
+			source:  "![x](data:image/svg+xml;base64,PHN2ZyBvbmxvYWQ9YWxlcnQoMSk+)",
+			dropped: []string{"data:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := renderMarkdown(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("renderMarkdown(%q) = %q, want it to contain %q", tt.source, html, want)
+				}
+			}
+			for _, dropped := range tt.dropped {
+				if strings.Contains(html, dropped) {
+					t.Errorf("renderMarkdown(%q) = %q, want %q dropped", tt.source, html, dropped)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"todo-app/internal/domain"
+	"todo-app/internal/interface/middleware"
+	"todo-app/internal/usecase"
+)
+
+const defaultProjectColor = "#808080"
+
+type ProjectController struct {
+	projectUseCase usecase.ProjectUseCase
+	validate       *validator.Validate
+}
+
+type ProjectRequest struct {
+	Name       string `json:"name" validate:"required,min=1,max=100"`
+	Color      string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	IsArchived bool   `json:"is_archived"`
+	SortOrder  int    `json:"sort_order"`
+}
+
+type ProjectResponse struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Color      string `json:"color"`
+	IsArchived bool   `json:"is_archived"`
+	IsInbox    bool   `json:"is_inbox"`
+	SortOrder  int    `json:"sort_order"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+func NewProjectController(projectUseCase usecase.ProjectUseCase) *ProjectController {
+	return &ProjectController{
+		projectUseCase: projectUseCase,
+		validate:       validator.New(),
+	}
+}
+
+func (pc *ProjectController) GetProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+
+	projects, err := pc.projectUseCase.GetProjects(r.Context(), userID, includeArchived)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	responses := make([]ProjectResponse, len(projects))
+	for i, project := range projects {
+		responses[i] = pc.projectToResponse(project)
+	}
+
+	writeJSONResponse(w, responses, http.StatusOK)
+}
+
+func (pc *ProjectController) GetProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	projectID, err := parsePathID(r.URL.Path, "projects")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	project, err := pc.projectUseCase.GetProject(r.Context(), userID, projectID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, pc.projectToResponse(project), http.StatusOK)
+}
+
+func (pc *ProjectController) CreateProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	project, err := pc.decodeProjectRequest(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := pc.projectUseCase.CreateProject(r.Context(), userID, project); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, pc.projectToResponse(project), http.StatusCreated)
+}
+
+func (pc *ProjectController) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	projectID, err := parsePathID(r.URL.Path, "projects")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	project, err := pc.decodeProjectRequest(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	project.ID = projectID
+
+	if err := pc.projectUseCase.UpdateProject(r.Context(), userID, project); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, pc.projectToResponse(project), http.StatusOK)
+}
+
+func (pc *ProjectController) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	projectID, err := parsePathID(r.URL.Path, "projects")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	// ?todos=delete deletes the project's todos, otherwise they are moved to the Inbox
+	mode := domain.ProjectDeleteMode(r.URL.Query().Get("todos"))
+	if mode == "" {
+		mode = domain.ProjectDeleteMoveToInbox
+	}
+
+	if err := pc.projectUseCase.DeleteProject(r.Context(), userID, projectID, mode); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (pc *ProjectController) decodeProjectRequest(r *http.Request) (*domain.Project, error) {
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, domain.ErrInvalidJSON
+	}
+
+	if err := pc.validate.Struct(req); err != nil {
+		return nil, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest)
+	}
+
+	color := req.Color
+	if color == "" {
+		color = defaultProjectColor
+	}
+
+	return &domain.Project{
+		Name:       req.Name,
+		Color:      color,
+		IsArchived: req.IsArchived,
+		SortOrder:  req.SortOrder,
+	}, nil
+}
+
+func (pc *ProjectController) projectToResponse(project *domain.Project) ProjectResponse {
+	return ProjectResponse{
+		ID:         project.ID,
+		Name:       project.Name,
+		Color:      project.Color,
+		IsArchived: project.IsArchived,
+		IsInbox:    project.IsInbox,
+		SortOrder:  project.SortOrder,
+		CreatedAt:  project.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  project.UpdatedAt.Format(time.RFC3339),
+	}
+}
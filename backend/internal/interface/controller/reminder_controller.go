@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"todo-app/internal/domain"
+	"todo-app/internal/interface/middleware"
+	"todo-app/internal/usecase"
+)
+
+type ReminderController struct {
+	reminderUseCase usecase.ReminderUseCase
+	validate        *validator.Validate
+}
+
+// CreateReminderRequest takes either remind_at, an RFC 3339 time, or offset_minutes,
+// minutes from the todo's due moment (negative before it)
+type CreateReminderRequest struct {
+	RemindAt      *time.Time `json:"remind_at"`
+	OffsetMinutes *int       `json:"offset_minutes"`
+	Channel       string     `json:"channel" validate:"omitempty,oneof=email webhook"`
+}
+
+type ReminderResponse struct {
+	ID            int     `json:"id"`
+	TodoID        int     `json:"todo_id"`
+	RemindAt      *string `json:"remind_at"`
+	OffsetMinutes *int    `json:"offset_minutes"`
+	Channel       string  `json:"channel"`
+	// FireAt is null while a relative reminder's todo has no due date
+	FireAt *string `json:"fire_at"`
+	// Status is pending, sent or failed
+	Status    string  `json:"status"`
+	SentAt    *string `json:"sent_at"`
+	LastError string  `json:"last_error,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+func NewReminderController(reminderUseCase usecase.ReminderUseCase) *ReminderController {
+	return &ReminderController{
+		reminderUseCase: reminderUseCase,
+		validate:        validator.New(),
+	}
+}
+
+func (rc *ReminderController) GetReminders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	reminders, err := rc.reminderUseCase.GetReminders(r.Context(), userID, todoID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	responses := make([]ReminderResponse, len(reminders))
+	for i, reminder := range reminders {
+		responses[i] = reminderToResponse(reminder)
+	}
+	writeJSONResponse(w, responses, http.StatusOK)
+}
+
+func (rc *ReminderController) CreateReminder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	var req CreateReminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	if err := rc.validate.Struct(req); err != nil {
+		handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	reminder := &domain.Reminder{
+		TodoID:        todoID,
+		RemindAt:      req.RemindAt,
+		OffsetMinutes: req.OffsetMinutes,
+		Channel:       domain.ReminderChannel(req.Channel),
+	}
+
+	if err := rc.reminderUseCase.CreateReminder(r.Context(), userID, reminder); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, reminderToResponse(reminder), http.StatusCreated)
+}
+
+func (rc *ReminderController) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	reminderID, err := parsePathID(r.URL.Path, "reminders")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := rc.reminderUseCase.DeleteReminder(r.Context(), userID, todoID, reminderID); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func reminderToResponse(reminder *domain.Reminder) ReminderResponse {
+	return ReminderResponse{
+		ID:            reminder.ID,
+		TodoID:        reminder.TodoID,
+		RemindAt:      formatOptionalTime(reminder.RemindAt),
+		OffsetMinutes: reminder.OffsetMinutes,
+		Channel:       string(reminder.Channel),
+		FireAt:        formatOptionalTime(reminder.FireAt),
+		Status:        reminder.Status(),
+		SentAt:        formatOptionalTime(reminder.SentAt),
+		LastError:     reminder.LastError,
+		CreatedAt:     reminder.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// formatOptionalTime formats the time in UTC as RFC 3339, keeping nil as null
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
+}
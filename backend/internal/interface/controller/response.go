@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-app/internal/domain"
+)
+
+// extractPathSegment returns the path segment that follows name,
+// e.g. extractPathSegment("/api/v1/todos/3/subtasks/7", "subtasks") returns "7"
+func extractPathSegment(path, name string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if part == name && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// parsePathID parses the numeric ID that follows name in the path
+func parsePathID(path, name string) (int, error) {
+	id, err := strconv.Atoi(extractPathSegment(path, name))
+	if err != nil || id <= 0 {
+		return 0, domain.ErrInvalidID
+	}
+	return id, nil
+}
+
+func writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// handleErrorResponse handles domain errors appropriately
+func handleErrorResponse(w http.ResponseWriter, err error) {
+	if appErr, ok := domain.IsAppError(err); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(appErr.HTTPCode)
+
+		if encodeErr := json.NewEncoder(w).Encode(appErr); encodeErr != nil {
+			http.Error(w, "Failed to encode error response", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Fallback for non-AppError types
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+
+	fallbackErr := domain.NewAppError("INTERNAL_ERROR", "内部エラーが発生しました", http.StatusInternalServerError)
+	if encodeErr := json.NewEncoder(w).Encode(fallbackErr); encodeErr != nil {
+		http.Error(w, "Failed to encode error response", http.StatusInternalServerError)
+	}
+}
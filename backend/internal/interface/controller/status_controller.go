@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"todo-app/internal/domain"
+	"todo-app/internal/interface/middleware"
+	"todo-app/internal/usecase"
+)
+
+type StatusController struct {
+	statusUseCase usecase.StatusUseCase
+	validate      *validator.Validate
+}
+
+// StatusRequest creates or replaces a status. A null next_status_ids allows moves to any status,
+// an empty list none.
+type StatusRequest struct {
+	Name          string `json:"name" validate:"required,min=1,max=50"`
+	Category      string `json:"category" validate:"required,oneof=todo in_progress done"`
+	WIPLimit      *int   `json:"wip_limit" validate:"omitempty,min=1"`
+	NextStatusIDs []int  `json:"next_status_ids"`
+}
+
+type ReorderStatusesRequest struct {
+	StatusIDs []int `json:"status_ids" validate:"required"`
+}
+
+type StatusResponse struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Category      string `json:"category"`
+	SortOrder     int    `json:"sort_order"`
+	WIPLimit      *int   `json:"wip_limit"`
+	NextStatusIDs []int  `json:"next_status_ids"`
+	TodoCount     int    `json:"todo_count"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+func NewStatusController(statusUseCase usecase.StatusUseCase) *StatusController {
+	return &StatusController{
+		statusUseCase: statusUseCase,
+		validate:      validator.New(),
+	}
+}
+
+func (sc *StatusController) GetStatuses(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	statuses, err := sc.statusUseCase.GetStatuses(r.Context(), userID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, statusesToResponse(statuses), http.StatusOK)
+}
+
+func (sc *StatusController) CreateStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	status, err := sc.decodeStatusRequest(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := sc.statusUseCase.CreateStatus(r.Context(), userID, status); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, statusToResponse(status), http.StatusCreated)
+}
+
+func (sc *StatusController) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	statusID, err := parsePathID(r.URL.Path, "statuses")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	status, err := sc.decodeStatusRequest(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	status.ID = statusID
+
+	if err := sc.statusUseCase.UpdateStatus(r.Context(), userID, status); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, statusToResponse(status), http.StatusOK)
+}
+
+func (sc *StatusController) ReorderStatuses(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	var req ReorderStatusesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	if err := sc.validate.Struct(req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidStatusOrder)
+		return
+	}
+
+	statuses, err := sc.statusUseCase.ReorderStatuses(r.Context(), userID, req.StatusIDs)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, statusesToResponse(statuses), http.StatusOK)
+}
+
+// DeleteStatus removes a status; its todos move to the first status of the same kind
+func (sc *StatusController) DeleteStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	statusID, err := parsePathID(r.URL.Path, "statuses")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := sc.statusUseCase.DeleteStatus(r.Context(), userID, statusID); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (sc *StatusController) decodeStatusRequest(r *http.Request) (*domain.Status, error) {
+	var req StatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, domain.ErrInvalidJSON
+	}
+
+	if err := sc.validate.Struct(req); err != nil {
+		return nil, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest)
+	}
+
+	return &domain.Status{
+		Name:          req.Name,
+		Category:      domain.StatusCategory(req.Category),
+		WIPLimit:      req.WIPLimit,
+		NextStatusIDs: req.NextStatusIDs,
+	}, nil
+}
+
+func statusesToResponse(statuses []*domain.Status) []StatusResponse {
+	responses := make([]StatusResponse, len(statuses))
+	for i, status := range statuses {
+		responses[i] = statusToResponse(status)
+	}
+	return responses
+}
+
+func statusToResponse(status *domain.Status) StatusResponse {
+	return StatusResponse{
+		ID:            status.ID,
+		Name:          status.Name,
+		Category:      string(status.Category),
+		SortOrder:     status.SortOrder,
+		WIPLimit:      status.WIPLimit,
+		NextStatusIDs: status.NextStatusIDs,
+		TodoCount:     status.TodoCount,
+		CreatedAt:     status.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     status.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"todo-app/internal/domain"
+	"todo-app/internal/interface/middleware"
+	"todo-app/internal/usecase"
+)
+
+type SubtaskController struct {
+	subtaskUseCase usecase.SubtaskUseCase
+	validate       *validator.Validate
+}
+
+type SubtaskRequest struct {
+	Title string `json:"title" validate:"required,min=1,max=100"`
+}
+
+type ReorderSubtasksRequest struct {
+	SubtaskIDs []int `json:"subtask_ids" validate:"required"`
+}
+
+type SubtaskResponse struct {
+	ID          int    `json:"id"`
+	TodoID      int    `json:"todo_id"`
+	Title       string `json:"title"`
+	IsCompleted bool   `json:"is_completed"`
+	Position    int    `json:"position"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+func NewSubtaskController(subtaskUseCase usecase.SubtaskUseCase) *SubtaskController {
+	return &SubtaskController{
+		subtaskUseCase: subtaskUseCase,
+		validate:       validator.New(),
+	}
+}
+
+func (sc *SubtaskController) GetSubtasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	subtasks, err := sc.subtaskUseCase.GetSubtasks(r.Context(), userID, todoID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, sc.subtasksToResponse(subtasks), http.StatusOK)
+}
+
+func (sc *SubtaskController) CreateSubtask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	var req SubtaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	if err := sc.validate.Struct(req); err != nil {
+		handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	subtask := &domain.Subtask{
+		TodoID: todoID,
+		Title:  req.Title,
+	}
+
+	if err := sc.subtaskUseCase.CreateSubtask(r.Context(), userID, subtask); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, sc.subtaskToResponse(subtask), http.StatusCreated)
+}
+
+func (sc *SubtaskController) UpdateSubtask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	subtaskID, err := parsePathID(r.URL.Path, "subtasks")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	var req SubtaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	if err := sc.validate.Struct(req); err != nil {
+		handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	subtask := &domain.Subtask{
+		ID:     subtaskID,
+		TodoID: todoID,
+		Title:  req.Title,
+	}
+
+	if err := sc.subtaskUseCase.UpdateSubtask(r.Context(), userID, subtask); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, sc.subtaskToResponse(subtask), http.StatusOK)
+}
+
+func (sc *SubtaskController) ToggleSubtaskComplete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	subtaskID, err := parsePathID(r.URL.Path, "subtasks")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	subtask, err := sc.subtaskUseCase.ToggleSubtaskComplete(r.Context(), userID, todoID, subtaskID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, sc.subtaskToResponse(subtask), http.StatusOK)
+}
+
+func (sc *SubtaskController) ReorderSubtasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	var req ReorderSubtasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	if err := sc.validate.Struct(req); err != nil {
+		handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	subtasks, err := sc.subtaskUseCase.ReorderSubtasks(r.Context(), userID, todoID, req.SubtaskIDs)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, sc.subtasksToResponse(subtasks), http.StatusOK)
+}
+
+func (sc *SubtaskController) DeleteSubtask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	subtaskID, err := parsePathID(r.URL.Path, "subtasks")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := sc.subtaskUseCase.DeleteSubtask(r.Context(), userID, todoID, subtaskID); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (sc *SubtaskController) subtaskToResponse(subtask *domain.Subtask) SubtaskResponse {
+	return SubtaskResponse{
+		ID:          subtask.ID,
+		TodoID:      subtask.TodoID,
+		Title:       subtask.Title,
+		IsCompleted: subtask.IsCompleted,
+		Position:    subtask.Position,
+		CreatedAt:   subtask.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   subtask.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (sc *SubtaskController) subtasksToResponse(subtasks []*domain.Subtask) []SubtaskResponse {
+	responses := make([]SubtaskResponse, len(subtasks))
+	for i, subtask := range subtasks {
+		responses[i] = sc.subtaskToResponse(subtask)
+	}
+	return responses
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"todo-app/internal/domain"
+	"todo-app/internal/interface/middleware"
+	"todo-app/internal/usecase"
+)
+
+const defaultTagColor = "#808080"
+
+type TagController struct {
+	tagUseCase usecase.TagUseCase
+	validate   *validator.Validate
+}
+
+type TagRequest struct {
+	Name  string `json:"name" validate:"required,min=1,max=50"`
+	Color string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+}
+
+type TagResponse struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func NewTagController(tagUseCase usecase.TagUseCase) *TagController {
+	return &TagController{
+		tagUseCase: tagUseCase,
+		validate:   validator.New(),
+	}
+}
+
+func (tc *TagController) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	tags, err := tc.tagUseCase.GetTags(r.Context(), userID)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, tagsToResponse(tags), http.StatusOK)
+}
+
+func (tc *TagController) CreateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	tag, err := tc.decodeTagRequest(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := tc.tagUseCase.CreateTag(r.Context(), userID, tag); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, tagToResponse(tag), http.StatusCreated)
+}
+
+func (tc *TagController) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	tagID, err := parsePathID(r.URL.Path, "tags")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	tag, err := tc.decodeTagRequest(r)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	tag.ID = tagID
+
+	if err := tc.tagUseCase.UpdateTag(r.Context(), userID, tag); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	writeJSONResponse(w, tagToResponse(tag), http.StatusOK)
+}
+
+func (tc *TagController) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	tagID, err := parsePathID(r.URL.Path, "tags")
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	if err := tc.tagUseCase.DeleteTag(r.Context(), userID, tagID); err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (tc *TagController) decodeTagRequest(r *http.Request) (*domain.Tag, error) {
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, domain.ErrInvalidJSON
+	}
+
+	if err := tc.validate.Struct(req); err != nil {
+		return nil, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest)
+	}
+
+	color := req.Color
+	if color == "" {
+		color = defaultTagColor
+	}
+
+	return &domain.Tag{
+		Name:  req.Name,
+		Color: color,
+	}, nil
+}
+
+func tagToResponse(tag *domain.Tag) TagResponse {
+	return TagResponse{
+		ID:    tag.ID,
+		Name:  tag.Name,
+		Color: tag.Color,
+	}
+}
+
+func tagsToResponse(tags []*domain.Tag) []TagResponse {
+	responses := make([]TagResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = tagToResponse(tag)
+	}
+	return responses
+}
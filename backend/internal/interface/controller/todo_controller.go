@@ -2,9 +2,9 @@ package controller
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -19,29 +19,236 @@ type TodoController struct {
 }
 
 type CreateTodoRequest struct {
-	Title    string `json:"title" validate:"required,min=1,max=100"`
-	DueDate  string `json:"due_date,omitempty"`
-	Priority int    `json:"priority" validate:"min=0,max=2"`
+	Title     string `json:"title" validate:"required,min=1,max=100"`
+	Notes     string `json:"notes,omitempty" validate:"max=10000"`
+	DueDate   string `json:"due_date,omitempty"`
+	DueTime   string `json:"due_time,omitempty"`
+	Priority  int    `json:"priority" validate:"min=0,max=2"`
+	TagIDs    []int  `json:"tag_ids,omitempty"`
+	ProjectID *int   `json:"project_id,omitempty"`
+
+	Recurrence *RecurrenceRequest `json:"recurrence,omitempty"`
 }
 
+// UpdateTodoRequest is the body of PUT, which replaces the todo as a whole:
+// an omitted field is reset, e.g. no tag_ids removes every tag. PATCH changes single fields.
 type UpdateTodoRequest struct {
-	Title       string `json:"title,omitempty" validate:"omitempty,min=1,max=100"`
-	DueDate     string `json:"due_date,omitempty"`
-	Priority    int    `json:"priority,omitempty" validate:"omitempty,min=0,max=2"`
-	IsCompleted bool   `json:"is_completed,omitempty"`
+	Title       string             `json:"title" validate:"required,min=1,max=100"`
+	Notes       string             `json:"notes,omitempty" validate:"max=10000"`
+	DueDate     string             `json:"due_date,omitempty"`
+	DueTime     string             `json:"due_time,omitempty"`
+	Priority    int                `json:"priority" validate:"min=0,max=2"`
+	IsCompleted bool               `json:"is_completed"`
+	TagIDs      []int              `json:"tag_ids,omitempty"`
+	ProjectID   *int               `json:"project_id,omitempty" validate:"omitempty,min=1"`
+	Recurrence  *RecurrenceRequest `json:"recurrence,omitempty"`
+}
+
+// QuickAddTodoRequest creates a todo from one line, e.g. "請求書を送る 明日 15時 !high".
+// With dry_run the line is only parsed, for a live preview.
+type QuickAddTodoRequest struct {
+	Text   string `json:"text" validate:"required,max=500"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// RecurrenceRequest takes an RFC 5545 RRULE value, e.g. "FREQ=WEEKLY;BYDAY=MO,WE"
+type RecurrenceRequest struct {
+	Rule           string `json:"rule" validate:"required"`
+	FromCompletion bool   `json:"from_completion"`
 }
 
 type TodoResponse struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"user_id"`
+	ProjectID *int   `json:"project_id"`
+	StatusID  *int   `json:"status_id"`
+	Title     string `json:"title"`
+	// Notes is the raw Markdown; NotesHTML is only present with ?render=html
+	Notes     string  `json:"notes"`
+	NotesHTML *string `json:"notes_html,omitempty"`
+	DueDate   string  `json:"due_date,omitempty"`
+	// DueTime is the due time of day in the user's time zone, DueAt the same moment in UTC
+	DueTime     string `json:"due_time,omitempty"`
+	DueAt       string `json:"due_at,omitempty"`
+	Priority    int    `json:"priority"`
+	IsCompleted bool   `json:"is_completed"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	// DeletedAt is only present for todos in the trash
+	DeletedAt string `json:"deleted_at,omitempty"`
+	// SnoozedUntil is only present while the todo is snoozed
+	SnoozedUntil string `json:"snoozed_until,omitempty"`
+	// Version is the todo's ETag without quotes
+	Version int `json:"version"`
+	// Position is the todo's key in the manual order (sort=manual); keys compare byte by byte
+	Position string `json:"position"`
+
+	SubtasksDone  int `json:"subtasks_done"`
+	SubtasksTotal int `json:"subtasks_total"`
+	CommentCount  int `json:"comment_count"`
+
+	// BlockedBy are the todos that have to be completed before this one, Blocks those waiting for it.
+	// IsBlocked is set while any todo of BlockedBy is open.
+	BlockedBy []int `json:"blocked_by"`
+	Blocks    []int `json:"blocks"`
+	IsBlocked bool  `json:"is_blocked"`
+
+	Tags []TagResponse `json:"tags"`
+
+	Recurrence     *RecurrenceResponse `json:"recurrence"`
+	NextOccurrence *TodoResponse       `json:"next_occurrence,omitempty"`
+}
+
+type RecurrenceResponse struct {
+	Rule           string `json:"rule"`
+	FromCompletion bool   `json:"from_completion"`
+}
+
+// TodoPageResponse is returned by the todo listings when limit or cursor is given
+type TodoPageResponse struct {
+	Items []TodoResponse `json:"items"`
+	// NextCursor is null on the last page
+	NextCursor *string `json:"next_cursor"`
+}
+
+type TodoSearchResponse struct {
+	Query   string                  `json:"query"`
+	Results []TodoSearchHitResponse `json:"results"`
+}
+
+type TodoSearchHitResponse struct {
+	Todo TodoResponse `json:"todo"`
+	// Snippet is HTML: the title is escaped and matches are wrapped in <mark>
+	Snippet  string  `json:"snippet"`
+	Score    float64 `json:"score"`
+	AllTerms bool    `json:"all_terms"`
+}
+
+type TodoEventResponse struct {
+	ID        int                            `json:"id"`
+	Action    string                         `json:"action"`
+	ActorID   *int                           `json:"actor_id"`
+	Changes   map[string]FieldChangeResponse `json:"changes"`
+	CreatedAt string                         `json:"created_at"`
+}
+
+// BulkTodoRequest applies one action to many todos, e.g. {"action": "complete", "ids": [1, 2]}
+type BulkTodoRequest struct {
+	Action string `json:"action"`
+	IDs    []int  `json:"ids"`
+	// Priority is required for set_priority
+	Priority *int `json:"priority,omitempty"`
+	// DueDate is the new due date for set_due_date; omitted or null clears it
+	DueDate *string `json:"due_date,omitempty"`
+	// ProjectID is the destination of move; omitted or null takes the todos out of their project
+	ProjectID *int `json:"project_id,omitempty"`
+	// Subtasks treats the open subtasks of completed todos like ?subtasks= on toggle
+	Subtasks string `json:"subtasks,omitempty"`
+	// Force lets complete finish todos whose blockers are still open
+	Force bool `json:"force,omitempty"`
+}
+
+type BulkTodoResponse struct {
+	// Applied is false when any item failed; nothing is changed then
+	Applied bool                   `json:"applied"`
+	Results []BulkTodoItemResponse `json:"results"`
+}
+
+type BulkTodoItemResponse struct {
+	ID      int              `json:"id"`
+	Status  string           `json:"status"`
+	Changed bool             `json:"changed"`
+	Todo    *TodoResponse    `json:"todo,omitempty"`
+	Error   *domain.AppError `json:"error,omitempty"`
+}
+
+// MoveTodoRequest places a todo in the manual order; at least one of the IDs is required
+type MoveTodoRequest struct {
+	AfterID  *int `json:"after_id,omitempty"`
+	BeforeID *int `json:"before_id,omitempty"`
+}
+
+// SnoozeTodoRequest hides a todo until e.g. "3d", "tomorrow", "next monday", a date or a time
+type SnoozeTodoRequest struct {
+	Until string `json:"until" validate:"required"`
+}
+
+// TransitionTodoRequest moves a todo to another of the user's statuses
+type TransitionTodoRequest struct {
+	StatusID int `json:"status_id" validate:"required,gt=0"`
+}
+
+// AddTodoBlockerRequest declares that blocker_id has to be completed before the todo
+type AddTodoBlockerRequest struct {
+	BlockerID int `json:"blocker_id" validate:"required,gt=0"`
+}
+
+type TodoGraphResponse struct {
+	Nodes []TodoGraphNodeResponse `json:"nodes"`
+	Edges []TodoGraphEdgeResponse `json:"edges"`
+	// Order lists the open todos to do next: those that can be done now, then the ones they unblock
+	Order []int `json:"order"`
+}
+
+type TodoGraphNodeResponse struct {
 	ID          int    `json:"id"`
-	UserID      int    `json:"user_id"`
 	Title       string `json:"title"`
 	DueDate     string `json:"due_date,omitempty"`
 	Priority    int    `json:"priority"`
 	IsCompleted bool   `json:"is_completed"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	IsBlocked   bool   `json:"is_blocked"`
+}
+
+// TodoGraphEdgeResponse means blocker_id has to be completed before blocked_id
+type TodoGraphEdgeResponse struct {
+	BlockerID int `json:"blocker_id"`
+	BlockedID int `json:"blocked_id"`
+}
+
+// UndoRequest is the optional body of POST /undo; count defaults to 1
+type UndoRequest struct {
+	Count *int `json:"count,omitempty"`
+}
+
+type UndoResponse struct {
+	Operations int            `json:"operations"`
+	Todos      []TodoResponse `json:"todos"`
+}
+
+type FieldChangeResponse struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type OccurrencesResponse struct {
+	TodoID      int      `json:"todo_id"`
+	Occurrences []string `json:"occurrences"`
+}
+
+type QuickAddResponse struct {
+	Parsed QuickAddParsedResponse `json:"parsed"`
+	// Todo is the created todo, null on a dry run
+	Todo *TodoResponse `json:"todo"`
+}
+
+type QuickAddParsedResponse struct {
+	Title    string `json:"title"`
+	DueDate  string `json:"due_date,omitempty"`
+	DueTime  string `json:"due_time,omitempty"`
+	Priority int    `json:"priority"`
+	// Tokens are the parts of the text read as something other than the title
+	Tokens []QuickAddTokenResponse `json:"tokens"`
 }
 
+type QuickAddTokenResponse struct {
+	Text string `json:"text"`
+	// Field is due_date, due_time or priority
+	Field string `json:"field"`
+}
+
+// totalCountHeader carries the number of todos matching the filter across all pages
+const totalCountHeader = "X-Total-Count"
+
 func NewTodoController(todoUseCase usecase.TodoUseCase) *TodoController {
 	return &TodoController{
 		todoUseCase: todoUseCase,
@@ -50,13 +257,7 @@ func NewTodoController(todoUseCase usecase.TodoUseCase) *TodoController {
 }
 
 func extractIDFromPath(path string) string {
-	parts := strings.Split(path, "/")
-	for i, part := range parts {
-		if part == "todos" && i+1 < len(parts) {
-			return parts[i+1]
-		}
-	}
-	return ""
+	return extractPathSegment(path, "todos")
 }
 
 func (tc *TodoController) CreateTodo(w http.ResponseWriter, r *http.Request) {
@@ -78,10 +279,19 @@ func (tc *TodoController) CreateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
 	todo := &domain.Todo{
 		Title:       req.Title,
+		Notes:       req.Notes,
 		Priority:    req.Priority,
 		IsCompleted: false,
+		ProjectID:   req.ProjectID,
+		Tags:        tagRefs(req.TagIDs),
 	}
 
 	if req.DueDate != "" {
@@ -91,7 +301,20 @@ func (tc *TodoController) CreateTodo(w http.ResponseWriter, r *http.Request) {
 			tc.handleErrorResponse(w, dateErr)
 			return
 		}
-		todo.DueDate = &dueDate
+		todo.SetDue(&dueDate, nil, loc)
+	}
+	if err := setDueTime(todo, req.DueTime, loc); err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	if req.Recurrence != nil {
+		recurrence, err := domain.ParseRecurrenceRule(req.Recurrence.Rule, req.Recurrence.FromCompletion)
+		if err != nil {
+			tc.handleErrorResponse(w, err)
+			return
+		}
+		todo.Recurrence = recurrence
 	}
 
 	if err := tc.todoUseCase.CreateTodo(r.Context(), userID, todo); err != nil {
@@ -99,33 +322,185 @@ func (tc *TodoController) CreateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := tc.todoToResponse(todo)
+	tc.writeTodoResponse(w, todo, loc, http.StatusCreated)
+}
+
+// QuickAddTodo creates a todo from one line of Japanese or English, POST /api/v1/todos/quick
+func (tc *TodoController) QuickAddTodo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	var req QuickAddTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	if err := tc.validate.Struct(req); err != nil {
+		validationErr := domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest)
+		tc.handleErrorResponse(w, validationErr)
+		return
+	}
+
+	parsed, todo, err := tc.todoUseCase.QuickAddTodo(r.Context(), userID, req.Text, req.DryRun)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	response := QuickAddResponse{Parsed: quickAddToResponse(parsed)}
+	if todo == nil {
+		tc.writeJSONResponse(w, response, http.StatusOK)
+		return
+	}
+
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	todoResponse := tc.todoToResponse(todo, loc)
+	response.Todo = &todoResponse
+	w.Header().Set("ETag", todoETag(todo))
 	tc.writeJSONResponse(w, response, http.StatusCreated)
 }
 
+func quickAddToResponse(parsed *usecase.QuickAdd) QuickAddParsedResponse {
+	response := QuickAddParsedResponse{
+		Title:    parsed.Title,
+		Priority: parsed.Priority,
+		Tokens:   make([]QuickAddTokenResponse, len(parsed.Tokens)),
+	}
+	if parsed.DueDate != nil {
+		response.DueDate = parsed.DueDate.Format("2006-01-02")
+	}
+	if parsed.DueTime != nil {
+		response.DueTime = parsed.DueTime.Format(dueTimeLayout)
+	}
+	for i, token := range parsed.Tokens {
+		response.Tokens[i] = QuickAddTokenResponse{Text: token.Text, Field: token.Field}
+	}
+	return response
+}
+
 func (tc *TodoController) GetTodos(w http.ResponseWriter, r *http.Request) {
+	tc.listTodos(w, r, nil)
+}
+
+// GetProjectTodos lists the todos of the project in the path, /api/v1/projects/{id}/todos
+func (tc *TodoController) GetProjectTodos(w http.ResponseWriter, r *http.Request) {
+	projectID, err := parsePathID(r.URL.Path, "projects")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.listTodos(w, r, &projectID)
+}
+
+// listTodos lists the todos matching the query, within the project when projectID is set
+func (tc *TodoController) listTodos(w http.ResponseWriter, r *http.Request, projectID *int) {
 	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
 	if !ok {
 		tc.writeErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	sortBy := r.URL.Query().Get("sort")
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	filter, err := parseTodoFilter(r, loc)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	if projectID != nil {
+		filter.ProjectID = projectID
+	}
+
+	query := r.URL.Query()
+	sortBy := query.Get("sort")
+	renderHTML, err := wantsRenderedHTML(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	// limit or cursor opts into pagination; without them the whole list is returned as an array
+	if query.Has("limit") || query.Has("cursor") {
+		tc.listTodoPage(w, r, userID, loc, sortBy, filter, renderHTML)
+		return
+	}
 
-	todos, err := tc.todoUseCase.GetTodos(r.Context(), userID, sortBy)
+	todos, err := tc.todoUseCase.GetTodos(r.Context(), userID, sortBy, filter)
 	if err != nil {
+		if appErr, ok := domain.IsAppError(err); ok && appErr.HTTPCode != http.StatusInternalServerError {
+			tc.handleErrorResponse(w, err)
+			return
+		}
 		tc.writeErrorResponse(w, "Failed to get todos", http.StatusInternalServerError)
 		return
 	}
 
-	responses := make([]TodoResponse, len(todos))
-	for i, todo := range todos {
-		responses[i] = tc.todoToResponse(todo)
+	responses := tc.todosToResponse(todos, loc)
+	if renderHTML {
+		if err := addNotesHTML(responses); err != nil {
+			tc.writeErrorResponse(w, "Failed to render notes", http.StatusInternalServerError)
+			return
+		}
 	}
 
+	w.Header().Set(totalCountHeader, strconv.Itoa(len(todos)))
 	tc.writeJSONResponse(w, responses, http.StatusOK)
 }
 
+func (tc *TodoController) listTodoPage(w http.ResponseWriter, r *http.Request, userID int, loc *time.Location, sortBy string, filter usecase.TodoFilter, renderHTML bool) {
+	query := r.URL.Query()
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			tc.handleErrorResponse(w, domain.ErrInvalidPageLimit)
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := tc.todoUseCase.GetTodoPage(r.Context(), userID, sortBy, filter, limit, query.Get("cursor"))
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	response := TodoPageResponse{Items: tc.todosToResponse(page.Todos, loc)}
+	if renderHTML {
+		if err := addNotesHTML(response.Items); err != nil {
+			tc.writeErrorResponse(w, "Failed to render notes", http.StatusInternalServerError)
+			return
+		}
+	}
+	if page.NextCursor != "" {
+		response.NextCursor = &page.NextCursor
+	}
+
+	w.Header().Set(totalCountHeader, strconv.Itoa(page.Total))
+	tc.writeJSONResponse(w, response, http.StatusOK)
+}
+
+func (tc *TodoController) todosToResponse(todos []*domain.Todo, loc *time.Location) []TodoResponse {
+	responses := make([]TodoResponse, len(todos))
+	for i, todo := range todos {
+		responses[i] = tc.todoToResponse(todo, loc)
+	}
+	return responses
+}
+
 func (tc *TodoController) GetTodo(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
 	if !ok {
@@ -144,14 +519,40 @@ func (tc *TodoController) GetTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	renderHTML, err := wantsRenderedHTML(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
 	todo, err := tc.todoUseCase.GetTodo(r.Context(), userID, todoID)
 	if err != nil {
 		tc.writeErrorResponse(w, "Todo not found", http.StatusNotFound)
 		return
 	}
 
-	response := tc.todoToResponse(todo)
-	tc.writeJSONResponse(w, response, http.StatusOK)
+	if etag := todoETag(todo); noneMatch(r, etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	if !renderHTML {
+		tc.writeTodoResponse(w, todo, loc, http.StatusOK)
+		return
+	}
+	responses := []TodoResponse{tc.todoToResponse(todo, loc)}
+	if err := addNotesHTML(responses); err != nil {
+		tc.writeErrorResponse(w, "Failed to render notes", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", todoETag(todo))
+	tc.writeJSONResponse(w, responses[0], http.StatusOK)
 }
 
 func (tc *TodoController) UpdateTodo(w http.ResponseWriter, r *http.Request) {
@@ -172,6 +573,13 @@ func (tc *TodoController) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Completing the todo takes the options of a toggle, along with If-Match
+	opts, err := toggleOptions(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
 	var req UpdateTodoRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		tc.writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
@@ -188,59 +596,123 @@ func (tc *TodoController) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 		tc.writeErrorResponse(w, "Todo not found", http.StatusNotFound)
 		return
 	}
+	// Without If-Match the update still fails if the todo changes after it was read here
+	if opts.Version != nil {
+		existingTodo.Version = *opts.Version
+	}
 
-	if req.Title != "" {
-		existingTodo.Title = req.Title
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
 	}
+
+	existingTodo.Title = req.Title
+	existingTodo.Notes = req.Notes
+	existingTodo.SetDue(nil, nil, loc)
 	if req.DueDate != "" {
 		dueDate, err := time.Parse("2006-01-02", req.DueDate)
 		if err != nil {
 			tc.writeErrorResponse(w, "Invalid due_date format. Use YYYY-MM-DD", http.StatusBadRequest)
 			return
 		}
-		existingTodo.DueDate = &dueDate
+		existingTodo.SetDue(&dueDate, nil, loc)
 	}
-	if req.Priority != 0 {
-		existingTodo.Priority = req.Priority
+	if err := setDueTime(existingTodo, req.DueTime, loc); err != nil {
+		tc.handleErrorResponse(w, err)
+		return
 	}
+	existingTodo.Priority = req.Priority
 	existingTodo.IsCompleted = req.IsCompleted
-
-	if err := tc.todoUseCase.UpdateTodo(r.Context(), userID, existingTodo); err != nil {
-		tc.writeErrorResponse(w, "Failed to update todo", http.StatusInternalServerError)
-		return
+	existingTodo.Tags = tagRefs(req.TagIDs)
+	existingTodo.ProjectID = req.ProjectID
+	existingTodo.Recurrence = nil
+	if req.Recurrence != nil {
+		recurrence, err := domain.ParseRecurrenceRule(req.Recurrence.Rule, req.Recurrence.FromCompletion)
+		if err != nil {
+			tc.handleErrorResponse(w, err)
+			return
+		}
+		existingTodo.Recurrence = recurrence
 	}
 
-	response := tc.todoToResponse(existingTodo)
-	tc.writeJSONResponse(w, response, http.StatusOK)
+	tc.saveTodo(w, r, userID, loc, existingTodo, opts)
 }
 
-func (tc *TodoController) DeleteTodo(w http.ResponseWriter, r *http.Request) {
+// PatchTodo changes single fields of a todo, /api/v1/todos/{id}.
+// The body is a JSON Merge Patch (RFC 7396): omitted fields stay as they are and null clears a field.
+func (tc *TodoController) PatchTodo(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
 	if !ok {
-		tc.writeErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
 		return
 	}
 
-	todoIDStr := extractIDFromPath(r.URL.Path)
-	if todoIDStr == "" {
-		tc.writeErrorResponse(w, "Invalid todo ID", http.StatusBadRequest)
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
 		return
 	}
-	todoID, err := strconv.Atoi(todoIDStr)
+
+	if !isMergePatch(r) {
+		tc.handleErrorResponse(w, domain.ErrUnsupportedPatchType)
+		return
+	}
+
+	// Completing the todo takes the options of a toggle, along with If-Match
+	opts, err := toggleOptions(r)
 	if err != nil {
-		tc.writeErrorResponse(w, "Invalid todo ID", http.StatusBadRequest)
+		tc.handleErrorResponse(w, err)
 		return
 	}
 
-	if err := tc.todoUseCase.DeleteTodo(r.Context(), userID, todoID); err != nil {
-		tc.writeErrorResponse(w, "Failed to delete todo", http.StatusInternalServerError)
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	todo, err := tc.todoUseCase.GetTodo(r.Context(), userID, todoID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	if opts.Version != nil {
+		todo.Version = *opts.Version
+	}
+
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	if err := applyTodoMergePatch(todo, patch, loc); err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.saveTodo(w, r, userID, loc, todo, opts)
 }
 
-func (tc *TodoController) ToggleTodoComplete(w http.ResponseWriter, r *http.Request) {
+// saveTodo stores the changed todo for PUT and PATCH and writes the result
+func (tc *TodoController) saveTodo(w http.ResponseWriter, r *http.Request, userID int, loc *time.Location, todo *domain.Todo, opts usecase.ToggleOptions) {
+	if err := tc.todoUseCase.UpdateTodo(r.Context(), userID, todo, opts); err != nil {
+		if err == domain.ErrTodoVersionMismatch {
+			tc.writeVersionMismatch(w, r, userID, todo.ID)
+			return
+		}
+		if appErr, ok := domain.IsAppError(err); ok && appErr.HTTPCode != http.StatusInternalServerError {
+			tc.handleErrorResponse(w, err)
+			return
+		}
+		tc.writeErrorResponse(w, "Failed to update todo", http.StatusInternalServerError)
+		return
+	}
+
+	tc.writeTodoResponse(w, todo, loc, http.StatusOK)
+}
+
+func (tc *TodoController) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
 	if !ok {
 		tc.writeErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
@@ -258,40 +730,799 @@ func (tc *TodoController) ToggleTodoComplete(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	todo, err := tc.todoUseCase.ToggleTodoComplete(r.Context(), userID, todoID)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		tc.writeErrorResponse(w, "Failed to toggle todo completion", http.StatusInternalServerError)
+		tc.handleErrorResponse(w, err)
 		return
 	}
 
-	response := tc.todoToResponse(todo)
-	tc.writeJSONResponse(w, response, http.StatusOK)
+	if err := tc.todoUseCase.DeleteTodo(r.Context(), userID, todoID, version); err != nil {
+		if err == domain.ErrTodoVersionMismatch {
+			tc.writeVersionMismatch(w, r, userID, todoID)
+			return
+		}
+		tc.writeErrorResponse(w, "Failed to delete todo", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTrash lists the deleted todos, /api/v1/trash
+func (tc *TodoController) GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todos, err := tc.todoUseCase.GetTrash(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.writeJSONResponse(w, tc.todosToResponse(todos, loc), http.StatusOK)
+}
+
+// RestoreTodo takes a todo out of the trash, /api/v1/todos/{id}/restore
+func (tc *TodoController) RestoreTodo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	todo, err := tc.todoUseCase.RestoreTodo(r.Context(), userID, todoID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.writeTodoResponse(w, todo, loc, http.StatusOK)
+}
+
+// PurgeTodo permanently deletes a todo in the trash, /api/v1/trash/{id}
+func (tc *TodoController) PurgeTodo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "trash")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	if err := tc.todoUseCase.PurgeTodo(r.Context(), userID, todoID); err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (tc *TodoController) ToggleTodoComplete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.writeErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoIDStr := extractIDFromPath(r.URL.Path)
+	if todoIDStr == "" {
+		tc.writeErrorResponse(w, "Invalid todo ID", http.StatusBadRequest)
+		return
+	}
+	todoID, err := strconv.Atoi(todoIDStr)
+	if err != nil {
+		tc.writeErrorResponse(w, "Invalid todo ID", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := toggleOptions(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	todo, err := tc.todoUseCase.ToggleTodoComplete(r.Context(), userID, todoID, opts)
+	if err != nil {
+		if err == domain.ErrTodoVersionMismatch {
+			tc.writeVersionMismatch(w, r, userID, todoID)
+			return
+		}
+		if appErr, ok := domain.IsAppError(err); ok && appErr.HTTPCode != http.StatusInternalServerError {
+			tc.handleErrorResponse(w, err)
+			return
+		}
+		tc.writeErrorResponse(w, "Failed to toggle todo completion", http.StatusInternalServerError)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.writeTodoResponse(w, todo, loc, http.StatusOK)
+}
+
+// SearchTodos ranks todos by title, /api/v1/todos/search?q=word&limit=N
+func (tc *TodoController) SearchTodos(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			tc.handleErrorResponse(w, domain.ErrInvalidPageLimit)
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := tc.todoUseCase.SearchTodos(r.Context(), userID, query.Get("q"), limit)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	response := TodoSearchResponse{
+		Query:   query.Get("q"),
+		Results: make([]TodoSearchHitResponse, len(results)),
+	}
+	for i, result := range results {
+		response.Results[i] = TodoSearchHitResponse{
+			Todo:     tc.todoToResponse(result.Todo, loc),
+			Snippet:  result.Snippet,
+			Score:    result.Score,
+			AllTerms: result.AllTerms,
+		}
+	}
+
+	tc.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// GetOccurrences previews the next due dates of a recurring todo, /api/v1/todos/{id}/occurrences?count=N
+func (tc *TodoController) GetOccurrences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	count := 5
+	if value := r.URL.Query().Get("count"); value != "" {
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 1 || count > 50 {
+			tc.handleErrorResponse(w, domain.ErrInvalidCount)
+			return
+		}
+	}
+
+	occurrences, err := tc.todoUseCase.PreviewOccurrences(r.Context(), userID, todoID, count)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	response := OccurrencesResponse{
+		TodoID:      todoID,
+		Occurrences: make([]string, len(occurrences)),
+	}
+	for i, occurrence := range occurrences {
+		response.Occurrences[i] = occurrence.Format("2006-01-02")
+	}
+
+	tc.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// GetTodoHistory lists the changes made to a todo, /api/v1/todos/{id}/history
+func (tc *TodoController) GetTodoHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	events, err := tc.todoUseCase.GetTodoHistory(r.Context(), userID, todoID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	responses := make([]TodoEventResponse, len(events))
+	for i, event := range events {
+		changes := make(map[string]FieldChangeResponse, len(event.Changes))
+		for field, change := range event.Changes {
+			changes[field] = FieldChangeResponse{Before: change.Before, After: change.After}
+		}
+		responses[i] = TodoEventResponse{
+			ID:        event.ID,
+			Action:    string(event.Action),
+			ActorID:   event.ActorID,
+			Changes:   changes,
+			CreatedAt: event.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	tc.writeJSONResponse(w, responses, http.StatusOK)
+}
+
+// BulkUpdateTodos changes many todos in one transaction, /api/v1/todos/bulk
+func (tc *TodoController) BulkUpdateTodos(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	var req BulkTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+
+	op := usecase.BulkTodoOperation{
+		Action:      usecase.BulkAction(req.Action),
+		IDs:         req.IDs,
+		Priority:    req.Priority,
+		ProjectID:   req.ProjectID,
+		SubtaskMode: domain.SubtaskCompletionMode(req.Subtasks),
+		Force:       req.Force,
+	}
+	if req.DueDate != nil {
+		dueDate, err := time.Parse("2006-01-02", *req.DueDate)
+		if err != nil {
+			dateErr := domain.NewAppError("INVALID_DATE_FORMAT", "日付の形式が正しくありません。YYYY-MM-DD形式で入力してください", http.StatusBadRequest)
+			tc.handleErrorResponse(w, dateErr)
+			return
+		}
+		op.DueDate = &dueDate
+	}
+
+	result, err := tc.todoUseCase.BulkUpdateTodos(r.Context(), userID, op)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	response := BulkTodoResponse{
+		Applied: result.Applied,
+		Results: make([]BulkTodoItemResponse, len(result.Items)),
+	}
+	for i, item := range result.Items {
+		itemResponse := BulkTodoItemResponse{ID: item.ID, Status: "ok", Changed: item.Changed}
+		if item.Todo != nil {
+			todo := tc.todoToResponse(item.Todo, loc)
+			itemResponse.Todo = &todo
+		}
+		if item.Err != nil {
+			itemResponse.Status = "error"
+			if appErr, ok := domain.IsAppError(item.Err); ok {
+				itemResponse.Error = appErr
+			}
+		}
+		response.Results[i] = itemResponse
+	}
+
+	statusCode := http.StatusOK
+	if !result.Applied {
+		statusCode = http.StatusUnprocessableEntity
+	}
+	tc.writeJSONResponse(w, response, statusCode)
+}
+
+// Undo reverts the caller's latest operations, /api/v1/undo
+func (tc *TodoController) Undo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	var req UndoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+	count := 1
+	if req.Count != nil {
+		count = *req.Count
+	}
+
+	result, err := tc.todoUseCase.Undo(r.Context(), userID, count)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	response := UndoResponse{
+		Operations: result.Operations,
+		Todos:      tc.todosToResponse(result.Todos, loc),
+	}
+	tc.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// StopRecurrence ends the series of a recurring todo, /api/v1/todos/{id}/recurrence
+func (tc *TodoController) StopRecurrence(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	todo, err := tc.todoUseCase.StopRecurrence(r.Context(), userID, todoID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.writeTodoResponse(w, todo, loc, http.StatusOK)
+}
+
+// MoveTodo places a todo in the manual order, /api/v1/todos/{id}/move
+func (tc *TodoController) MoveTodo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	var req MoveTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	todo, err := tc.todoUseCase.MoveTodo(r.Context(), userID, todoID, version, req.AfterID, req.BeforeID)
+	if err == domain.ErrTodoVersionMismatch {
+		tc.writeVersionMismatch(w, r, userID, todoID)
+		return
+	}
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.writeTodoResponse(w, todo, loc, http.StatusOK)
+}
+
+// SnoozeTodo hides a todo from the default todo list for a while, POST /api/v1/todos/{id}/snooze
+func (tc *TodoController) SnoozeTodo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	var req SnoozeTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+	if err := tc.validate.Struct(req); err != nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidSnooze)
+		return
+	}
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	todo, err := tc.todoUseCase.SnoozeTodo(r.Context(), userID, todoID, req.Until, version)
+	tc.writeTodoResult(w, r, userID, todoID, todo, err)
+}
+
+// UnsnoozeTodo ends a todo's snooze, DELETE /api/v1/todos/{id}/snooze
+func (tc *TodoController) UnsnoozeTodo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	todo, err := tc.todoUseCase.UnsnoozeTodo(r.Context(), userID, todoID, version)
+	tc.writeTodoResult(w, r, userID, todoID, todo, err)
+}
+
+// TransitionTodo moves a todo to another workflow status, POST /api/v1/todos/{id}/transition.
+// Moving into a done status takes ?subtasks= and ?force= like a toggle.
+func (tc *TodoController) TransitionTodo(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	var req TransitionTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+	if err := tc.validate.Struct(req); err != nil {
+		tc.handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+	opts, err := toggleOptions(r)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	todo, err := tc.todoUseCase.TransitionTodo(r.Context(), userID, todoID, req.StatusID, opts)
+	tc.writeTodoResult(w, r, userID, todoID, todo, err)
+}
+
+// AddTodoBlocker makes another todo a blocker of the todo, POST /api/v1/todos/{id}/blockers
+func (tc *TodoController) AddTodoBlocker(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	var req AddTodoBlockerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		tc.handleErrorResponse(w, domain.ErrInvalidJSON)
+		return
+	}
+	if err := tc.validate.Struct(req); err != nil {
+		tc.handleErrorResponse(w, domain.NewAppError("VALIDATION_FAILED", "バリデーションエラーです: "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	todo, err := tc.todoUseCase.AddTodoBlocker(r.Context(), userID, todoID, req.BlockerID)
+	tc.writeTodoResult(w, r, userID, todoID, todo, err)
+}
+
+// RemoveTodoBlocker removes a blocker, DELETE /api/v1/todos/{id}/blockers/{blockerId}
+func (tc *TodoController) RemoveTodoBlocker(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	todoID, err := parsePathID(r.URL.Path, "todos")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	blockerID, err := parsePathID(r.URL.Path, "blockers")
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	if err := tc.todoUseCase.RemoveTodoBlocker(r.Context(), userID, todoID, blockerID); err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTodoGraph returns the dependency graph, GET /api/v1/todos/graph
+func (tc *TodoController) GetTodoGraph(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		tc.handleErrorResponse(w, domain.ErrUnauthorized)
+		return
+	}
+
+	graph, err := tc.todoUseCase.GetTodoGraph(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	response := TodoGraphResponse{
+		Nodes: make([]TodoGraphNodeResponse, len(graph.Todos)),
+		Edges: make([]TodoGraphEdgeResponse, len(graph.Dependencies)),
+		Order: graph.Order,
+	}
+	for i, todo := range graph.Todos {
+		node := TodoGraphNodeResponse{
+			ID:          todo.ID,
+			Title:       todo.Title,
+			Priority:    todo.Priority,
+			IsCompleted: todo.IsCompleted,
+			IsBlocked:   todo.IsBlocked(),
+		}
+		if todo.DueDate != nil {
+			node.DueDate = todo.DueDate.Format("2006-01-02")
+		}
+		response.Nodes[i] = node
+	}
+	for i, dependency := range graph.Dependencies {
+		response.Edges[i] = TodoGraphEdgeResponse{
+			BlockerID: dependency.BlockerID,
+			BlockedID: dependency.BlockedID,
+		}
+	}
+
+	tc.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// toggleOptions reads the If-Match version, ?subtasks= and ?force=. With subtasks, cascade
+// completes open subtasks and require refuses while any are open; force=true completes the todo
+// even while its blockers are open.
+func toggleOptions(r *http.Request) (usecase.ToggleOptions, error) {
+	opts := usecase.ToggleOptions{
+		SubtaskMode: domain.SubtaskCompletionMode(r.URL.Query().Get("subtasks")),
+	}
+	switch opts.SubtaskMode {
+	case domain.SubtaskCompletionIgnore, domain.SubtaskCompletionCascade, domain.SubtaskCompletionRequire:
+	default:
+		return opts, domain.ErrInvalidCompletionMode
+	}
+	if force := r.URL.Query().Get("force"); force != "" {
+		value, err := strconv.ParseBool(force)
+		if err != nil {
+			return opts, domain.ErrInvalidForce
+		}
+		opts.Force = value
+	}
+
+	version, err := ifMatchVersion(r)
+	opts.Version = version
+	return opts, err
+}
+
+func (tc *TodoController) writeTodoResult(w http.ResponseWriter, r *http.Request, userID int, todoID int, todo *domain.Todo, err error) {
+	if err == domain.ErrTodoVersionMismatch {
+		tc.writeVersionMismatch(w, r, userID, todoID)
+		return
+	}
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+
+	tc.writeTodoResponse(w, todo, loc, http.StatusOK)
 }
 
-func (tc *TodoController) todoToResponse(todo *domain.Todo) TodoResponse {
+// todoToResponse shows the todo with its due time in loc, the user's time zone
+func (tc *TodoController) todoToResponse(todo *domain.Todo, loc *time.Location) TodoResponse {
 	response := TodoResponse{
 		ID:          todo.ID,
 		UserID:      todo.UserID,
+		ProjectID:   todo.ProjectID,
+		StatusID:    todo.StatusID,
 		Title:       todo.Title,
+		Notes:       todo.Notes,
 		Priority:    todo.Priority,
 		IsCompleted: todo.IsCompleted,
 		CreatedAt:   todo.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   todo.UpdatedAt.Format(time.RFC3339),
+		Version:     todo.Version,
+		Position:    todo.Position,
+
+		SubtasksDone:  todo.SubtasksDone,
+		SubtasksTotal: todo.SubtasksTotal,
+		CommentCount:  todo.CommentCount,
+
+		BlockedBy: idsOrEmpty(todo.BlockedBy),
+		Blocks:    idsOrEmpty(todo.Blocks),
+		IsBlocked: todo.IsBlocked(),
+
+		Tags: tagsToResponse(todo.Tags),
 	}
 
 	if todo.DueDate != nil {
 		response.DueDate = todo.DueDate.Format("2006-01-02")
 	}
+	if dueTime := todo.DueTime(loc); dueTime != nil {
+		response.DueTime = dueTime.Format(dueTimeLayout)
+		response.DueAt = todo.DueAt.UTC().Format(time.RFC3339)
+	}
+	if todo.DeletedAt != nil {
+		response.DeletedAt = todo.DeletedAt.Format(time.RFC3339)
+	}
+	if todo.IsSnoozed(time.Now()) {
+		response.SnoozedUntil = todo.SnoozedUntil.UTC().Format(time.RFC3339)
+	}
+	if todo.Recurrence != nil {
+		response.Recurrence = &RecurrenceResponse{
+			Rule:           todo.Recurrence.Rule(),
+			FromCompletion: todo.Recurrence.FromCompletion,
+		}
+	}
+	if todo.NextOccurrence != nil {
+		next := tc.todoToResponse(todo.NextOccurrence, loc)
+		response.NextOccurrence = &next
+	}
 
 	return response
 }
 
-func (tc *TodoController) writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+// parseTodoFilter reads the filter query parameters; see parseTodoFilterParams.
+// Relative dates such as due=today are the user's dates, in loc.
+func parseTodoFilter(r *http.Request, loc *time.Location) (usecase.TodoFilter, error) {
+	return parseTodoFilterParams(r.URL.Query(), time.Now().In(loc))
+}
+
+// dueTimeLayout is the format of due_time, a time of day in the user's time zone
+const dueTimeLayout = "15:04"
+
+// parseDueTime reads a due_time value, e.g. "09:30"
+func parseDueTime(value string) (*time.Time, error) {
+	clock, err := time.Parse(dueTimeLayout, value)
+	if err != nil {
+		return nil, domain.ErrInvalidDueTime
+	}
+	return &clock, nil
+}
+
+// setDueTime gives the todo the due time of a create or update request, if any.
+// A due time needs a due date to be on.
+func setDueTime(todo *domain.Todo, value string, loc *time.Location) error {
+	if value == "" {
+		return nil
 	}
+	clock, err := parseDueTime(value)
+	if err != nil || todo.DueDate == nil {
+		return domain.ErrInvalidDueTime
+	}
+	todo.SetDue(todo.DueDate, clock, loc)
+	return nil
+}
+
+// idsOrEmpty keeps an empty ID list from being encoded as null
+func idsOrEmpty(ids []int) []int {
+	if ids == nil {
+		return []int{}
+	}
+	return ids
+}
+
+// tagRefs builds tag references from IDs; the usecase resolves the rest
+func tagRefs(tagIDs []int) []*domain.Tag {
+	tags := make([]*domain.Tag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		tags[i] = &domain.Tag{ID: tagID}
+	}
+	return tags
+}
+
+// writeTodoResponse writes a single todo along with its ETag
+func (tc *TodoController) writeTodoResponse(w http.ResponseWriter, todo *domain.Todo, loc *time.Location, statusCode int) {
+	w.Header().Set("ETag", todoETag(todo))
+	tc.writeJSONResponse(w, tc.todoToResponse(todo, loc), statusCode)
+}
+
+// writeVersionMismatch answers a write whose If-Match is out of date with 412 and the
+// todo as it is now, so that the client can merge its changes and retry
+func (tc *TodoController) writeVersionMismatch(w http.ResponseWriter, r *http.Request, userID int, todoID int) {
+	current, err := tc.todoUseCase.GetTodo(r.Context(), userID, todoID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	loc, err := tc.todoUseCase.GetUserLocation(r.Context(), userID)
+	if err != nil {
+		tc.handleErrorResponse(w, err)
+		return
+	}
+	tc.writeTodoResponse(w, current, loc, http.StatusPreconditionFailed)
+}
+
+func (tc *TodoController) writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	writeJSONResponse(w, data, statusCode)
 }
 
 func (tc *TodoController) writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
@@ -306,22 +1537,5 @@ func (tc *TodoController) writeErrorResponse(w http.ResponseWriter, message stri
 
 // handleErrorResponse handles domain errors appropriately
 func (tc *TodoController) handleErrorResponse(w http.ResponseWriter, err error) {
-	if appErr, ok := domain.IsAppError(err); ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(appErr.HTTPCode)
-
-		if encodeErr := json.NewEncoder(w).Encode(appErr); encodeErr != nil {
-			http.Error(w, "Failed to encode error response", http.StatusInternalServerError)
-		}
-		return
-	}
-
-	// Fallback for non-AppError types
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusInternalServerError)
-
-	fallbackErr := domain.NewAppError("INTERNAL_ERROR", "内部エラーが発生しました", http.StatusInternalServerError)
-	if encodeErr := json.NewEncoder(w).Encode(fallbackErr); encodeErr != nil {
-		http.Error(w, "Failed to encode error response", http.StatusInternalServerError)
-	}
+	handleErrorResponse(w, err)
 }
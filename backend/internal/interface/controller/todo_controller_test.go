@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func TestParseTodoFilterTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantTagIDs   []int
+		wantMatchAll bool
+		wantErr      error
+	}{
+		{name: "no filter", query: ""},
+		{name: "repeated parameter", query: "tag=1&tag=2", wantTagIDs: []int{1, 2}},
+		{name: "comma separated", query: "tag=1,%202,", wantTagIDs: []int{1, 2}},
+		{name: "duplicates", query: "tag=2,1&tag=2", wantTagIDs: []int{2, 1}},
+		{name: "match all", query: "tag=1,2&tag_match=all", wantTagIDs: []int{1, 2}, wantMatchAll: true},
+		{name: "match any", query: "tag=1&tag_match=any", wantTagIDs: []int{1}},
+		{name: "not a number", query: "tag=home", wantErr: domain.ErrInvalidTagFilter},
+		{name: "not positive", query: "tag=0", wantErr: domain.ErrInvalidTagFilter},
+		{name: "unknown match", query: "tag=1&tag_match=none", wantErr: domain.ErrInvalidTagFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/todos?"+tt.query, nil)
+			filter, err := parseTodoFilter(r, time.UTC)
+			if err != tt.wantErr {
+				t.Fatalf("parseTodoFilter() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(filter.TagIDs, tt.wantTagIDs) || filter.MatchAllTags != tt.wantMatchAll {
+				t.Errorf("parseTodoFilter() = %+v, want tags %v, match all %v", filter, tt.wantTagIDs, tt.wantMatchAll)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"time"
+	"todo-app/internal/domain"
+	"unicode/utf8"
+)
+
+// isMergePatch reports whether the body is declared as a JSON Merge Patch.
+// Plain application/json is accepted too, as it is what most clients send by default.
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/merge-patch+json" || mediaType == "application/json"
+}
+
+// isNull reports whether a member of the patch is JSON null, which clears the field
+func isNull(value json.RawMessage) bool {
+	return string(value) == "null"
+}
+
+// applyTodoMergePatch applies an RFC 7396 merge patch to the todo. Fields that cannot
+// be cleared (title, priority and is_completed) reject null, and every invalid field
+// is reported in one validation error. Due times are read in loc, the user's time zone.
+func applyTodoMergePatch(todo *domain.Todo, patch map[string]json.RawMessage, loc *time.Location) error {
+	errors := make(map[string]string)
+
+	for field, value := range patch {
+		switch field {
+		case "title":
+			var title string
+			if isNull(value) || json.Unmarshal(value, &title) != nil {
+				errors[field] = "タイトルは必須です"
+			} else if length := utf8.RuneCountInString(title); length < 1 || length > 100 {
+				errors[field] = "タイトルは1-100文字で入力してください"
+			} else {
+				todo.Title = title
+			}
+
+		case "notes":
+			var notes string
+			if !isNull(value) && json.Unmarshal(value, &notes) != nil {
+				errors[field] = "notesには文字列を指定してください"
+			} else if utf8.RuneCountInString(notes) > 10000 {
+				errors[field] = "notesは10000文字以内で入力してください"
+			} else {
+				todo.Notes = notes
+			}
+
+		case "due_date":
+			if isNull(value) {
+				todo.SetDue(nil, nil, loc)
+				continue
+			}
+			var text string
+			var dueDate time.Time
+			err := json.Unmarshal(value, &text)
+			if err == nil {
+				dueDate, err = time.Parse("2006-01-02", text)
+			}
+			if err != nil {
+				errors[field] = "日付はYYYY-MM-DD形式で入力してください"
+			} else {
+				todo.Reschedule(&dueDate, loc)
+			}
+
+		case "due_time":
+			// Applied after the loop, once due_date has been changed
+
+		case "priority":
+			var priority int
+			if isNull(value) || json.Unmarshal(value, &priority) != nil || priority < 0 || priority > 2 {
+				errors[field] = "優先度は0から2までの数値で入力してください"
+			} else {
+				todo.Priority = priority
+			}
+
+		case "is_completed":
+			var completed bool
+			if isNull(value) || json.Unmarshal(value, &completed) != nil {
+				errors[field] = "is_completedにはtrueかfalseを指定してください"
+			} else {
+				todo.IsCompleted = completed
+			}
+
+		case "tag_ids":
+			var tagIDs []int
+			if !isNull(value) && json.Unmarshal(value, &tagIDs) != nil {
+				errors[field] = "tag_idsにはタグIDの配列を指定してください"
+			} else {
+				todo.Tags = tagRefs(tagIDs)
+			}
+
+		case "project_id":
+			if isNull(value) {
+				todo.ProjectID = nil
+				continue
+			}
+			var projectID int
+			if json.Unmarshal(value, &projectID) != nil || projectID < 1 {
+				errors[field] = "project_idにはプロジェクトIDを指定してください"
+			} else {
+				todo.ProjectID = &projectID
+			}
+
+		case "recurrence":
+			if isNull(value) {
+				todo.Recurrence = nil
+				continue
+			}
+			if message := patchRecurrence(todo, value); message != "" {
+				errors[field] = message
+			}
+
+		default:
+			errors[field] = "変更できないフィールドです"
+		}
+	}
+
+	if value, ok := patch["due_time"]; ok && errors["due_date"] == "" {
+		if message := patchDueTime(todo, value, loc); message != "" {
+			errors["due_time"] = message
+		}
+	}
+
+	if len(errors) > 0 {
+		return domain.NewValidationError(errors)
+	}
+	return nil
+}
+
+// patchDueTime sets or, for null, clears the todo's due time,
+// and returns a message when the value is not a time on a due date
+func patchDueTime(todo *domain.Todo, value json.RawMessage, loc *time.Location) string {
+	if isNull(value) {
+		todo.SetDue(todo.DueDate, nil, loc)
+		return ""
+	}
+
+	var text string
+	if json.Unmarshal(value, &text) != nil {
+		return domain.ErrInvalidDueTime.Message
+	}
+	clock, err := parseDueTime(text)
+	if err != nil || todo.DueDate == nil {
+		return domain.ErrInvalidDueTime.Message
+	}
+	todo.SetDue(todo.DueDate, clock, loc)
+	return ""
+}
+
+// patchRecurrence merges the recurrence object into the todo's current recurrence,
+// and returns a message when the result is not a valid rule
+func patchRecurrence(todo *domain.Todo, value json.RawMessage) string {
+	var patch struct {
+		Rule           *string `json:"rule"`
+		FromCompletion *bool   `json:"from_completion"`
+	}
+	if err := json.Unmarshal(value, &patch); err != nil {
+		return "recurrenceにはruleとfrom_completionを持つオブジェクトを指定してください"
+	}
+
+	var rule string
+	var fromCompletion bool
+	if todo.Recurrence != nil {
+		rule, fromCompletion = todo.Recurrence.Rule(), todo.Recurrence.FromCompletion
+	}
+	if patch.Rule != nil {
+		rule = *patch.Rule
+	}
+	if patch.FromCompletion != nil {
+		fromCompletion = *patch.FromCompletion
+	}
+	if rule == "" {
+		return "recurrence.ruleは必須です"
+	}
+
+	recurrence, err := domain.ParseRecurrenceRule(rule, fromCompletion)
+	if err != nil {
+		return err.Error()
+	}
+	todo.Recurrence = recurrence
+	return ""
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// patchedTodo is the todo every patch is applied to
+func patchedTodo(t *testing.T) *domain.Todo {
+	t.Helper()
+	dueDate := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	projectID := 4
+	recurrence, err := domain.ParseRecurrenceRule("FREQ=DAILY;INTERVAL=2", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &domain.Todo{
+		ID:         1,
+		Title:      "Buy milk",
+		DueDate:    &dueDate,
+		Priority:   2,
+		ProjectID:  &projectID,
+		Tags:       tagRefs([]int{1, 2}),
+		Recurrence: recurrence,
+	}
+}
+
+func TestApplyTodoMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		// want changes the unpatched todo into the expected one
+		want func(todo *domain.Todo)
+	}{
+		{name: "empty patch", patch: `{}`, want: func(todo *domain.Todo) {}},
+		{
+			name:  "absent fields are kept",
+			patch: `{"title":"Buy oat milk"}`,
+			want:  func(todo *domain.Todo) { todo.Title = "Buy oat milk" },
+		},
+		{
+			name:  "null clears the due date",
+			patch: `{"due_date":null}`,
+			want:  func(todo *domain.Todo) { todo.DueDate = nil },
+		},
+		{
+			name:  "due date",
+			patch: `{"due_date":"2026-11-01"}`,
+			want: func(todo *domain.Todo) {
+				dueDate := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+				todo.DueDate = &dueDate
+			},
+		},
+		{
+			name:  "null clears the project",
+			patch: `{"project_id":null}`,
+			want:  func(todo *domain.Todo) { todo.ProjectID = nil },
+		},
+		{
+			name:  "null clears the tags",
+			patch: `{"tag_ids":null}`,
+			want:  func(todo *domain.Todo) { todo.Tags = tagRefs(nil) },
+		},
+		{
+			name:  "null stops the recurrence",
+			patch: `{"recurrence":null}`,
+			want:  func(todo *domain.Todo) { todo.Recurrence = nil },
+		},
+		{
+			name:  "recurrence rule alone keeps from_completion",
+			patch: `{"recurrence":{"rule":"FREQ=DAILY;INTERVAL=3"}}`,
+			want: func(todo *domain.Todo) {
+				todo.Recurrence, _ = domain.ParseRecurrenceRule("FREQ=DAILY;INTERVAL=3", true)
+			},
+		},
+		{
+			name:  "from_completion alone keeps the rule",
+			patch: `{"recurrence":{"from_completion":false}}`,
+			want: func(todo *domain.Todo) {
+				todo.Recurrence, _ = domain.ParseRecurrenceRule("FREQ=DAILY;INTERVAL=2", false)
+			},
+		},
+		{
+			name:  "several fields",
+			patch: `{"priority":0,"is_completed":true,"tag_ids":[3]}`,
+			want: func(todo *domain.Todo) {
+				todo.Priority = 0
+				todo.IsCompleted = true
+				todo.Tags = tagRefs([]int{3})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			got, want := patchedTodo(t), patchedTodo(t)
+			tt.want(want)
+
+			if err := applyTodoMergePatch(got, patch, time.UTC); err != nil {
+				t.Fatalf("applyTodoMergePatch(%s) error = %v", tt.patch, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("applyTodoMergePatch(%s) = %+v, want %+v", tt.patch, got, want)
+			}
+		})
+	}
+}
+
+func TestApplyTodoMergePatchInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		patch      string
+		wantFields []string
+	}{
+		{name: "null title", patch: `{"title":null}`, wantFields: []string{"title"}},
+		{name: "empty title", patch: `{"title":""}`, wantFields: []string{"title"}},
+		{name: "null priority", patch: `{"priority":null}`, wantFields: []string{"priority"}},
+		{name: "priority out of range", patch: `{"priority":3}`, wantFields: []string{"priority"}},
+		{name: "null is_completed", patch: `{"is_completed":null}`, wantFields: []string{"is_completed"}},
+		{name: "due date format", patch: `{"due_date":"20/10/2026"}`, wantFields: []string{"due_date"}},
+		{name: "project id", patch: `{"project_id":0}`, wantFields: []string{"project_id"}},
+		{name: "recurrence rule", patch: `{"recurrence":{"rule":"FREQ=HOURLY"}}`, wantFields: []string{"recurrence"}},
+		{
+			name:       "merged recurrence is checked as a whole",
+			patch:      `{"recurrence":{"rule":"FREQ=WEEKLY"}}`,
+			wantFields: []string{"recurrence"},
+		},
+		{name: "read-only field", patch: `{"id":2}`, wantFields: []string{"id"}},
+		{
+			name:       "every invalid field is reported",
+			patch:      `{"title":null,"priority":null,"is_completed":"yes","due_date":"2026-11-01"}`,
+			wantFields: []string{"is_completed", "priority", "title"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+
+			err := applyTodoMergePatch(patchedTodo(t), patch, time.UTC)
+			appErr, ok := domain.IsAppError(err)
+			if !ok {
+				t.Fatalf("applyTodoMergePatch(%s) error = %v, want a validation error", tt.patch, err)
+			}
+			var fields []string
+			for field := range appErr.Details {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("applyTodoMergePatch(%s) rejected %v, want %v", tt.patch, fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestApplyTodoMergePatchDueTime(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		// wantDueDate and wantDueAt are formatted as 2006-01-02 and RFC 3339, empty when unset
+		wantDueDate string
+		wantDueAt   string
+		// wantFields lists the rejected fields
+		wantFields []string
+	}{
+		{name: "new due date keeps the due time", patch: `{"due_date":"2026-11-01"}`, wantDueDate: "2026-11-01", wantDueAt: "2026-11-01T00:00:00Z"},
+		{name: "new due time", patch: `{"due_time":"18:30"}`, wantDueDate: "2026-10-20", wantDueAt: "2026-10-20T09:30:00Z"},
+		{
+			name:        "due time is set on the new due date",
+			patch:       `{"due_time":"18:30","due_date":"2026-11-01"}`,
+			wantDueDate: "2026-11-01",
+			wantDueAt:   "2026-11-01T09:30:00Z",
+		},
+		{name: "null clears the due time only", patch: `{"due_time":null}`, wantDueDate: "2026-10-20"},
+		{name: "null due date clears the due time too", patch: `{"due_date":null}`},
+		{name: "due time without a due date", patch: `{"due_time":"09:30","due_date":null}`, wantFields: []string{"due_time"}},
+		{name: "invalid due time", patch: `{"due_time":"25:00"}`, wantFields: []string{"due_time"}},
+		{
+			name:       "invalid due date leaves the due time unchecked",
+			patch:      `{"due_date":"2026-13-01","due_time":"09:30"}`,
+			wantFields: []string{"due_date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			todo := &domain.Todo{ID: 1, Title: "Call the bank"}
+			// Due on 2026-10-20 at 09:00 in Tokyo
+			todo.SetDue(timePtr(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)), timePtr(time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)), tokyo)
+
+			err := applyTodoMergePatch(todo, patch, tokyo)
+			if tt.wantFields != nil {
+				appErr, ok := domain.IsAppError(err)
+				if !ok {
+					t.Fatalf("applyTodoMergePatch(%s) error = %v, want a validation error", tt.patch, err)
+				}
+				var fields []string
+				for field := range appErr.Details {
+					fields = append(fields, field)
+				}
+				if !reflect.DeepEqual(fields, tt.wantFields) {
+					t.Errorf("applyTodoMergePatch(%s) rejected %v, want %v", tt.patch, fields, tt.wantFields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyTodoMergePatch(%s) error = %v", tt.patch, err)
+			}
+
+			var dueDate, dueAt string
+			if todo.DueDate != nil {
+				dueDate = todo.DueDate.Format("2006-01-02")
+			}
+			if todo.DueAt != nil {
+				dueAt = todo.DueAt.Format(time.RFC3339)
+			}
+			if dueDate != tt.wantDueDate || dueAt != tt.wantDueAt {
+				t.Errorf("applyTodoMergePatch(%s) = due %q at %q, want %q at %q", tt.patch, dueDate, dueAt, tt.wantDueDate, tt.wantDueAt)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,427 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/usecase"
+)
+
+// parseTodoFilterParams builds a usecase.TodoFilter from the query parameters of GET /api/v1/todos:
+//
+//	completed=true|false
+//	include_snoozed=true|false
+//	priority_min=N, priority_max=N
+//	due=overdue|today|tomorrow|this_week|none, due_from=YYYY-MM-DD, due_to=YYYY-MM-DD
+//	created_from, created_to, updated_from, updated_to (YYYY-MM-DD or RFC 3339)
+//	text=word
+//	tag=1&tag=2 (or tag=1,2), tag_match=any|all, project_id=N, status_id=N
+//	q=compact query, see parseTodoQuery
+//
+// The _to bounds are inclusive, and snoozed todos are left out unless include_snoozed=true
+// or is:snoozed asks for them. now is given in the user's time zone; it decides what
+// "today" means for relative due dates and the day covered by a date in created and updated.
+func parseTodoFilterParams(params url.Values, now time.Time) (usecase.TodoFilter, error) {
+	var filter usecase.TodoFilter
+	p := &todoFilterParser{
+		filter: &filter,
+		now:    now,
+		today:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		tagIDs: make(map[int]bool),
+	}
+
+	for _, value := range params["tag"] {
+		if err := p.addTagIDs(value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+
+	switch params.Get("tag_match") {
+	case "", "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		return usecase.TodoFilter{}, domain.ErrInvalidTagFilter
+	}
+
+	if value := params.Get("project_id"); value != "" {
+		if err := p.setProject(value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+
+	if value := params.Get("status_id"); value != "" {
+		if err := p.setStatus(value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+
+	if value := params.Get("completed"); value != "" {
+		completed, err := strconv.ParseBool(value)
+		if err != nil {
+			return usecase.TodoFilter{}, invalidFilter("completed must be true or false")
+		}
+		filter.Completed = &completed
+	}
+
+	includeSnoozed := false
+	if value := params.Get("include_snoozed"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return usecase.TodoFilter{}, invalidFilter("include_snoozed must be true or false")
+		}
+		includeSnoozed = parsed
+	}
+
+	if value := params.Get("priority_min"); value != "" {
+		if err := p.applyPriority(">=" + value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+	if value := params.Get("priority_max"); value != "" {
+		if err := p.applyPriority("<=" + value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+
+	if value := params.Get("due"); value != "" {
+		if err := p.applyDue(value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+	if value := params.Get("due_from"); value != "" {
+		if err := p.applyDue(">=" + value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+	if value := params.Get("due_to"); value != "" {
+		if err := p.applyDue("<=" + value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+
+	timeRanges := []struct {
+		param string
+		apply func(string) error
+	}{
+		{"created_from", func(v string) error {
+			return p.applyTime("created", &filter.CreatedFrom, &filter.CreatedBefore, ">="+v)
+		}},
+		{"created_to", func(v string) error {
+			return p.applyTime("created", &filter.CreatedFrom, &filter.CreatedBefore, "<="+v)
+		}},
+		{"updated_from", func(v string) error {
+			return p.applyTime("updated", &filter.UpdatedFrom, &filter.UpdatedBefore, ">="+v)
+		}},
+		{"updated_to", func(v string) error {
+			return p.applyTime("updated", &filter.UpdatedFrom, &filter.UpdatedBefore, "<="+v)
+		}},
+	}
+	for _, tr := range timeRanges {
+		if value := params.Get(tr.param); value != "" {
+			if err := tr.apply(value); err != nil {
+				return usecase.TodoFilter{}, err
+			}
+		}
+	}
+
+	if value := strings.TrimSpace(params.Get("text")); value != "" {
+		filter.TextTerms = append(filter.TextTerms, value)
+	}
+
+	if value := params.Get("q"); value != "" {
+		if err := p.parseTodoQuery(value); err != nil {
+			return usecase.TodoFilter{}, err
+		}
+	}
+
+	if !includeSnoozed && filter.SnoozedAt == nil {
+		now := p.now
+		filter.AwakeAt = &now
+	}
+
+	return filter, nil
+}
+
+type todoFilterParser struct {
+	filter *usecase.TodoFilter
+	now    time.Time
+	// today is the user's current date, in UTC like the due dates
+	today  time.Time
+	tagIDs map[int]bool
+}
+
+// parseTodoQuery applies a compact query such as
+//
+//	priority:>=1 due:<2026-11-01 is:open tag:3 "weekly report"
+//
+// Supported keys are is (open, done, overdue, snoozed), priority, due, created, updated,
+// tag, project, status and text. Values of priority, due, created and updated may start
+// with =, >, >=, < or <=. Words without a known key are matched against the title;
+// double quotes keep several words together.
+func (p *todoFilterParser) parseTodoQuery(query string) error {
+	tokens, err := tokenizeTodoQuery(query)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		key, value, found := strings.Cut(token, ":")
+		if !found || value == "" {
+			p.filter.TextTerms = append(p.filter.TextTerms, token)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "is":
+			err = p.applyIs(value)
+		case "priority", "p":
+			err = p.applyPriority(value)
+		case "due":
+			err = p.applyDue(value)
+		case "created":
+			err = p.applyTime("created", &p.filter.CreatedFrom, &p.filter.CreatedBefore, value)
+		case "updated":
+			err = p.applyTime("updated", &p.filter.UpdatedFrom, &p.filter.UpdatedBefore, value)
+		case "tag":
+			err = p.addTagIDs(value)
+		case "project":
+			err = p.setProject(value)
+		case "status":
+			err = p.setStatus(value)
+		case "text":
+			p.filter.TextTerms = append(p.filter.TextTerms, value)
+		default:
+			// Not a filter, e.g. "10:30" in a title
+			p.filter.TextTerms = append(p.filter.TextTerms, token)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tokenizeTodoQuery splits the query on whitespace outside double quotes and drops the quotes
+func tokenizeTodoQuery(query string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+
+	flush := func() {
+		if token := strings.TrimSpace(current.String()); token != "" {
+			tokens = append(tokens, token)
+		}
+		current.Reset()
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, invalidFilter("unterminated quote in q")
+	}
+	flush()
+
+	return tokens, nil
+}
+
+func (p *todoFilterParser) applyIs(value string) error {
+	switch strings.ToLower(value) {
+	case "open":
+		p.filter.Completed = boolPtr(false)
+	case "done", "completed":
+		p.filter.Completed = boolPtr(true)
+	case "overdue":
+		return p.applyDue("overdue")
+	case "snoozed":
+		now := p.now
+		p.filter.SnoozedAt = &now
+	default:
+		return invalidFilter("is must be open, done, overdue or snoozed")
+	}
+	return nil
+}
+
+func (p *todoFilterParser) applyPriority(value string) error {
+	op, rest := splitComparison(value)
+	priority, err := strconv.Atoi(rest)
+	if err != nil || priority < 0 || priority > 2 {
+		return invalidFilter("priority must be between 0 and 2")
+	}
+
+	switch op {
+	case "=":
+		setMin(&p.filter.PriorityMin, priority)
+		setMax(&p.filter.PriorityMax, priority)
+	case ">=":
+		setMin(&p.filter.PriorityMin, priority)
+	case ">":
+		setMin(&p.filter.PriorityMin, priority+1)
+	case "<=":
+		setMax(&p.filter.PriorityMax, priority)
+	case "<":
+		setMax(&p.filter.PriorityMax, priority-1)
+	}
+	return nil
+}
+
+func (p *todoFilterParser) applyDue(value string) error {
+	f := p.filter
+	switch strings.ToLower(value) {
+	case "today":
+		applyRange(&f.DueFrom, &f.DueBefore, "=", p.today, 24*time.Hour)
+	case "tomorrow":
+		applyRange(&f.DueFrom, &f.DueBefore, "=", p.today.AddDate(0, 0, 1), 24*time.Hour)
+	case "overdue":
+		now := p.now
+		f.OverdueAt = &now
+		f.Completed = boolPtr(false)
+	case "this_week", "week":
+		// Weeks start on Monday
+		monday := p.today.AddDate(0, 0, -((int(p.today.Weekday()) + 6) % 7))
+		setFrom(&f.DueFrom, monday)
+		setBefore(&f.DueBefore, monday.AddDate(0, 0, 7))
+	case "none":
+		f.NoDueDate = true
+	default:
+		op, rest := splitComparison(value)
+		date, err := time.Parse("2006-01-02", rest)
+		if err != nil {
+			return invalidFilter("due must be overdue, today, tomorrow, this_week, none or a YYYY-MM-DD date")
+		}
+		applyRange(&f.DueFrom, &f.DueBefore, op, date, 24*time.Hour)
+	}
+	return nil
+}
+
+// applyTime narrows a timestamp range. Dates cover the whole day in the user's time zone.
+func (p *todoFilterParser) applyTime(name string, from, before **time.Time, value string) error {
+	op, rest := splitComparison(value)
+	if date, err := time.ParseInLocation("2006-01-02", rest, p.now.Location()); err == nil {
+		// Days are not always 24 hours long where the clocks change
+		start, end := date, date.AddDate(0, 0, 1)
+		applyRange(from, before, op, start, end.Sub(start))
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, rest)
+	if err != nil {
+		return invalidFilter("%s must be a YYYY-MM-DD date or an RFC 3339 time", name)
+	}
+	// PostgreSQL keeps microseconds
+	applyRange(from, before, op, t, time.Microsecond)
+	return nil
+}
+
+func (p *todoFilterParser) addTagIDs(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tagID, err := strconv.Atoi(part)
+		if err != nil || tagID <= 0 {
+			return domain.ErrInvalidTagFilter
+		}
+		// Duplicates would break the count used by tag_match=all
+		if !p.tagIDs[tagID] {
+			p.tagIDs[tagID] = true
+			p.filter.TagIDs = append(p.filter.TagIDs, tagID)
+		}
+	}
+	return nil
+}
+
+func (p *todoFilterParser) setProject(value string) error {
+	projectID, err := strconv.Atoi(value)
+	if err != nil || projectID <= 0 {
+		return domain.ErrInvalidID
+	}
+	p.filter.ProjectID = &projectID
+	return nil
+}
+
+func (p *todoFilterParser) setStatus(value string) error {
+	statusID, err := strconv.Atoi(value)
+	if err != nil || statusID <= 0 {
+		return domain.ErrInvalidID
+	}
+	p.filter.StatusID = &statusID
+	return nil
+}
+
+// The setters below only ever narrow a condition, so the same bound can be
+// given both as a query parameter and in the compact query.
+
+func setMin(bound **int, v int) {
+	if *bound == nil || v > **bound {
+		*bound = &v
+	}
+}
+
+func setMax(bound **int, v int) {
+	if *bound == nil || v < **bound {
+		*bound = &v
+	}
+}
+
+func setFrom(bound **time.Time, t time.Time) {
+	if *bound == nil || t.After(**bound) {
+		*bound = &t
+	}
+}
+
+func setBefore(bound **time.Time, t time.Time) {
+	if *bound == nil || t.Before(**bound) {
+		*bound = &t
+	}
+}
+
+// applyRange narrows [from, before) by "op t", where unit is the precision of t
+func applyRange(from, before **time.Time, op string, t time.Time, unit time.Duration) {
+	switch op {
+	case "=":
+		setFrom(from, t)
+		setBefore(before, t.Add(unit))
+	case ">=":
+		setFrom(from, t)
+	case ">":
+		setFrom(from, t.Add(unit))
+	case "<":
+		setBefore(before, t)
+	case "<=":
+		setBefore(before, t.Add(unit))
+	}
+}
+
+// splitComparison splits a leading comparison operator off value; no operator means "="
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, strings.TrimSpace(value[len(op):])
+		}
+	}
+	return "=", value
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func invalidFilter(format string, args ...interface{}) *domain.AppError {
+	return domain.NewAppErrorWithDetails("INVALID_TODO_FILTER", "絞り込み条件が正しくありません", http.StatusBadRequest, map[string]interface{}{
+		"reason": fmt.Sprintf(format, args...),
+	})
+}
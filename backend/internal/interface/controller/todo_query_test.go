@@ -0,0 +1,348 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/usecase"
+)
+
+// queryNow is a Wednesday afternoon
+var queryNow = time.Date(2026, 10, 14, 15, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+
+// describeFilter renders the conditions set on a filter, leaving out AwakeAt
+func describeFilter(f usecase.TodoFilter) map[string]string {
+	got := map[string]string{}
+	setInt := func(key string, v *int) {
+		if v != nil {
+			got[key] = fmt.Sprint(*v)
+		}
+	}
+	setTime := func(key string, v *time.Time) {
+		if v != nil {
+			got[key] = v.Format(time.RFC3339Nano)
+		}
+	}
+
+	if len(f.TagIDs) > 0 {
+		got["tags"] = fmt.Sprint(f.TagIDs)
+	}
+	if f.MatchAllTags {
+		got["match_all_tags"] = "true"
+	}
+	setInt("project", f.ProjectID)
+	setInt("status", f.StatusID)
+	if f.Completed != nil {
+		got["completed"] = fmt.Sprint(*f.Completed)
+	}
+	setInt("priority_min", f.PriorityMin)
+	setInt("priority_max", f.PriorityMax)
+	if f.NoDueDate {
+		got["no_due_date"] = "true"
+	}
+	setTime("due_from", f.DueFrom)
+	setTime("due_before", f.DueBefore)
+	setTime("overdue_at", f.OverdueAt)
+	setTime("created_from", f.CreatedFrom)
+	setTime("created_before", f.CreatedBefore)
+	setTime("updated_from", f.UpdatedFrom)
+	setTime("updated_before", f.UpdatedBefore)
+	setTime("snoozed_at", f.SnoozedAt)
+	if len(f.TextTerms) > 0 {
+		got["text"] = strings.Join(f.TextTerms, "|")
+	}
+	return got
+}
+
+func TestTokenizeTodoQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "words", query: "is:open tag:3", want: []string{"is:open", "tag:3"}},
+		{name: "repeated whitespace", query: "  a \t b\n c  ", want: []string{"a", "b", "c"}},
+		{name: "quotes keep words together", query: `"weekly report" due:today`, want: []string{"weekly report", "due:today"}},
+		{name: "quoted value", query: `text:"two words"`, want: []string{"text:two words"}},
+		{name: "empty quotes", query: `"" a`, want: []string{"a"}},
+		{name: "only whitespace", query: "   ", want: nil},
+		{name: "unterminated quote", query: `"weekly report`, wantErr: true},
+		{name: "unterminated quote after a closed one", query: `"a" "b`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenizeTodoQuery(tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("tokenizeTodoQuery(%q) = %q, want an error", tt.query, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tokenizeTodoQuery(%q) failed: %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeTodoQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTodoFilterParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "no conditions", params: "", want: map[string]string{}},
+		{
+			name:   "priority at least",
+			params: "q=priority:>=1",
+			want:   map[string]string{"priority_min": "1"},
+		},
+		{
+			name:   "priority strict bounds",
+			params: "q=p:>0 p:<2",
+			want:   map[string]string{"priority_min": "1", "priority_max": "1"},
+		},
+		{
+			name:   "priority equal",
+			params: "q=priority:2",
+			want:   map[string]string{"priority_min": "2", "priority_max": "2"},
+		},
+		{
+			name:   "query narrows the parameters",
+			params: "priority_min=0&q=priority:>=2",
+			want:   map[string]string{"priority_min": "2"},
+		},
+		{
+			name:   "due before a date",
+			params: "q=due:<2026-11-01",
+			want:   map[string]string{"due_before": "2026-11-01T00:00:00Z"},
+		},
+		{
+			name:   "due on or before a date",
+			params: "q=due:<=2026-11-01",
+			want:   map[string]string{"due_before": "2026-11-02T00:00:00Z"},
+		},
+		{
+			name:   "due after a date",
+			params: "q=due:>2026-11-01",
+			want:   map[string]string{"due_from": "2026-11-02T00:00:00Z"},
+		},
+		{
+			name:   "due on a date",
+			params: "q=due:2026-11-01",
+			want:   map[string]string{"due_from": "2026-11-01T00:00:00Z", "due_before": "2026-11-02T00:00:00Z"},
+		},
+		{
+			name:   "due_to and a tighter query bound",
+			params: "due_to=2026-11-30&q=due:<2026-11-01",
+			want:   map[string]string{"due_before": "2026-11-01T00:00:00Z"},
+		},
+		{
+			name:   "due today uses the user's date",
+			params: "due=today",
+			want:   map[string]string{"due_from": "2026-10-14T00:00:00Z", "due_before": "2026-10-15T00:00:00Z"},
+		},
+		{
+			name:   "due this week starts on Monday",
+			params: "q=due:this_week",
+			want:   map[string]string{"due_from": "2026-10-12T00:00:00Z", "due_before": "2026-10-19T00:00:00Z"},
+		},
+		{
+			name:   "no due date",
+			params: "q=due:none",
+			want:   map[string]string{"no_due_date": "true"},
+		},
+		{
+			name:   "overdue",
+			params: "q=is:overdue",
+			want:   map[string]string{"completed": "false", "overdue_at": "2026-10-14T15:30:00+09:00"},
+		},
+		{
+			name:   "done",
+			params: "q=IS:DONE",
+			want:   map[string]string{"completed": "true"},
+		},
+		{
+			name:   "snoozed",
+			params: "q=is:snoozed",
+			want:   map[string]string{"snoozed_at": "2026-10-14T15:30:00+09:00"},
+		},
+		{
+			name:   "created on a day in the user's time zone",
+			params: "q=created:2026-10-14",
+			want:   map[string]string{"created_from": "2026-10-14T00:00:00+09:00", "created_before": "2026-10-15T00:00:00+09:00"},
+		},
+		{
+			name:   "updated after a time",
+			params: "q=updated:>2026-10-14T10:00:00Z",
+			want:   map[string]string{"updated_from": "2026-10-14T10:00:00.000001Z"},
+		},
+		{
+			name:   "updated_to is inclusive",
+			params: "updated_to=2026-10-14",
+			want:   map[string]string{"updated_before": "2026-10-15T00:00:00+09:00"},
+		},
+		{
+			name:   "tags are kept once",
+			params: "tag=3&q=tag:3,4 tag:4",
+			want:   map[string]string{"tags": "[3 4]"},
+		},
+		{
+			name:   "project and status",
+			params: "q=project:7 status:2",
+			want:   map[string]string{"project": "7", "status": "2"},
+		},
+		{
+			name:   "status_id",
+			params: "status_id=4",
+			want:   map[string]string{"status": "4"},
+		},
+		{
+			name:   "words without a known key are text",
+			params: `q="weekly report" 10:30 due: text:plan`,
+			want:   map[string]string{"text": "weekly report|10:30|due:|plan"},
+		},
+		{name: "priority out of range", params: "q=priority:3", wantErr: true},
+		{name: "priority not a number", params: "q=priority:>high", wantErr: true},
+		{name: "unknown due keyword", params: "q=due:someday", wantErr: true},
+		{name: "unknown is", params: "q=is:maybe", wantErr: true},
+		{name: "bad created", params: "q=created:yesterday", wantErr: true},
+		{name: "bad tag", params: "q=tag:x", wantErr: true},
+		{name: "bad project", params: "q=project:0", wantErr: true},
+		{name: "bad status", params: "q=status:done", wantErr: true},
+		{name: "bad status_id", params: "status_id=-1", wantErr: true},
+		{name: "bad tag_match", params: "tag_match=some", wantErr: true},
+		{name: "bad include_snoozed", params: "include_snoozed=maybe", wantErr: true},
+		{name: "unterminated quote", params: `q="weekly report`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(strings.ReplaceAll(tt.params, " ", "+"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter, err := parseTodoFilterParams(params, queryNow)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseTodoFilterParams(%q) = %v, want an error", tt.params, describeFilter(filter))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTodoFilterParams(%q) failed: %v", tt.params, err)
+			}
+			if got := describeFilter(filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTodoFilterParams(%q) = %v, want %v", tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTodoFilterParamsSnoozed(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      string
+		wantAwake   bool
+		wantSnoozed bool
+	}{
+		{name: "snoozed todos are hidden by default", params: "", wantAwake: true},
+		{name: "include_snoozed", params: "include_snoozed=true"},
+		{name: "include_snoozed=false", params: "include_snoozed=false", wantAwake: true},
+		{name: "only snoozed", params: "q=is:snoozed", wantSnoozed: true},
+		{name: "only snoozed wins over include_snoozed=false", params: "include_snoozed=false&q=is:snoozed", wantSnoozed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(strings.ReplaceAll(tt.params, " ", "+"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter, err := parseTodoFilterParams(params, queryNow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := filter.AwakeAt != nil; got != tt.wantAwake {
+				t.Errorf("AwakeAt set = %v, want %v", got, tt.wantAwake)
+			} else if tt.wantAwake && !filter.AwakeAt.Equal(queryNow) {
+				t.Errorf("AwakeAt = %v, want now", filter.AwakeAt)
+			}
+			if got := filter.SnoozedAt != nil; got != tt.wantSnoozed {
+				t.Errorf("SnoozedAt set = %v, want %v", got, tt.wantSnoozed)
+			}
+		})
+	}
+}
+
+func TestParseTodoFilterParamsTimeZone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		params string
+		now    time.Time
+		want   map[string]string
+	}{
+		{
+			name:   "today is the user's date, not the UTC one",
+			params: "due=today",
+			// 2026-10-13 15:30 in UTC
+			now:  queryNow.Add(-24 * time.Hour).Add(9 * time.Hour),
+			want: map[string]string{"due_from": "2026-10-14T00:00:00Z", "due_before": "2026-10-15T00:00:00Z"},
+		},
+		{
+			name:   "late evening west of UTC",
+			params: "due=today",
+			// 2026-10-15 03:30 in UTC
+			now:  time.Date(2026, 10, 14, 23, 30, 0, 0, newYork),
+			want: map[string]string{"due_from": "2026-10-14T00:00:00Z", "due_before": "2026-10-15T00:00:00Z"},
+		},
+		{
+			name:   "day the clocks go back is 25 hours long",
+			params: "q=created:2026-11-01",
+			now:    time.Date(2026, 11, 5, 12, 0, 0, 0, newYork),
+			want:   map[string]string{"created_from": "2026-11-01T00:00:00-04:00", "created_before": "2026-11-02T00:00:00-05:00"},
+		},
+		{
+			name:   "day the clocks go forward is 23 hours long",
+			params: "updated_to=2026-03-08",
+			now:    time.Date(2026, 3, 10, 12, 0, 0, 0, newYork),
+			want:   map[string]string{"updated_before": "2026-03-09T00:00:00-04:00"},
+		},
+		{
+			name:   "overdue is judged at the current moment",
+			params: "due=overdue",
+			now:    time.Date(2026, 3, 8, 1, 59, 0, 0, newYork),
+			want:   map[string]string{"completed": "false", "overdue_at": "2026-03-08T01:59:00-05:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filter, err := parseTodoFilterParams(params, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describeFilter(filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTodoFilterParams(%q) at %v = %v, want %v", tt.params, tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package controller
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 	"todo-app/internal/domain"
 	"todo-app/internal/interface/middleware"
 	"todo-app/internal/usecase"
@@ -33,6 +34,23 @@ func (uc *UserController) createCookie(name, value string, maxAge int) *http.Coo
 	}
 }
 
+// リフレッシュトークン用のCookie設定を作成（JavaScriptから参照させない）
+func (uc *UserController) createRefreshCookie(value string, maxAge int) *http.Cookie {
+	cookie := uc.createCookie("refresh_token", value, maxAge)
+	cookie.HttpOnly = true
+	cookie.SameSite = http.SameSiteLaxMode
+	return cookie
+}
+
+// トークンペアをCookieに設定
+func (uc *UserController) setTokenCookies(w http.ResponseWriter, tokens *domain.TokenPair) {
+	accessMaxAge := int(time.Until(tokens.AccessTokenExpiresAt).Seconds())
+	refreshMaxAge := int(time.Until(tokens.RefreshTokenExpiresAt).Seconds())
+
+	http.SetCookie(w, uc.createCookie("auth_token", tokens.AccessToken, accessMaxAge))
+	http.SetCookie(w, uc.createRefreshCookie(tokens.RefreshToken, refreshMaxAge))
+}
+
 type RegisterUserRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
@@ -57,10 +75,20 @@ type LoginResponse struct {
 	Message string `json:"message"` // レスポンスメッセージ
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type RefreshTokenResponse struct {
+	ExpiresAt string `json:"expires_at"` // 新しいアクセストークンの有効期限
+	Message   string `json:"message"`
+}
+
 type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Timezone string `json:"timezone"` // IANAのタイムゾーン名
 }
 
 type UpdateProfileRequest struct {
@@ -68,6 +96,7 @@ type UpdateProfileRequest struct {
 	Email           string `json:"email"`
 	CurrentPassword string `json:"current_password,omitempty"`
 	NewPassword     string `json:"new_password,omitempty"`
+	Timezone        string `json:"timezone,omitempty"` // 省略時は変更しない
 }
 
 type UpdateProfileResponse struct {
@@ -141,7 +170,7 @@ func (uc *UserController) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Authenticate user
-	token, err := uc.UserInteractor.Login(r.Context(), req.Username, req.Password)
+	tokens, err := uc.UserInteractor.Login(r.Context(), req.Username, req.Password)
 	if err != nil {
 		uc.handleErrorResponse(w, err)
 		return
@@ -155,14 +184,14 @@ func (uc *UserController) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set Cookie
-	authCookie := uc.createCookie("auth_token", token, 24*60*60) // 24時間
-	http.SetCookie(w, authCookie)
+	uc.setTokenCookies(w, tokens)
 
 	response := LoginResponse{
 		User: User{
 			ID:       user.ID,
 			Username: user.Username,
 			Email:    user.Email,
+			Timezone: user.Timezone,
 		},
 		Message: "Login successful",
 	}
@@ -174,6 +203,50 @@ func (uc *UserController) Login(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (uc *UserController) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Cookie優先、なければリクエストボディから取得
+	var refreshToken string
+	if cookie, err := r.Cookie("refresh_token"); err == nil {
+		refreshToken = cookie.Value
+	} else if r.ContentLength != 0 {
+		var req RefreshTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			uc.handleErrorResponse(w, domain.ErrInvalidJSON)
+			return
+		}
+		refreshToken = req.RefreshToken
+	}
+
+	tokens, err := uc.UserInteractor.RefreshToken(r.Context(), refreshToken)
+	if err != nil {
+		// 無効なリフレッシュトークンはCookieごと削除
+		if appErr, ok := domain.IsAppError(err); ok && appErr.HTTPCode == http.StatusUnauthorized {
+			http.SetCookie(w, uc.createCookie("auth_token", "", -1))
+			http.SetCookie(w, uc.createRefreshCookie("", -1))
+		}
+		uc.handleErrorResponse(w, err)
+		return
+	}
+
+	uc.setTokenCookies(w, tokens)
+
+	response := RefreshTokenResponse{
+		ExpiresAt: tokens.AccessTokenExpiresAt.Format(time.RFC3339),
+		Message:   "Token refreshed successfully",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 func (uc *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -186,18 +259,24 @@ func (uc *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
 		token = authHeader[7:]
-	} else {
+	} else if cookie, err := r.Cookie("auth_token"); err == nil {
 		// Fallback to cookie
-		cookie, err := r.Cookie("auth_token")
-		if err != nil {
-			http.Error(w, `{"error":"Authentication token required"}`, http.StatusBadRequest)
-			return
-		}
 		token = cookie.Value
 	}
 
+	// The access token may already have expired, so the refresh token alone is enough
+	var refreshToken string
+	if cookie, err := r.Cookie("refresh_token"); err == nil {
+		refreshToken = cookie.Value
+	}
+
+	if token == "" && refreshToken == "" {
+		http.Error(w, `{"error":"Authentication token required"}`, http.StatusBadRequest)
+		return
+	}
+
 	// Invalidate the token
-	if err := uc.UserInteractor.Logout(r.Context(), token); err != nil {
+	if err := uc.UserInteractor.Logout(r.Context(), token, refreshToken); err != nil {
 		http.Error(w, `{"error":"Failed to logout"}`, http.StatusInternalServerError)
 		return
 	}
@@ -205,6 +284,7 @@ func (uc *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 	// Cookie削除
 	deleteCookie := uc.createCookie("auth_token", "", -1)
 	http.SetCookie(w, deleteCookie)
+	http.SetCookie(w, uc.createRefreshCookie("", -1))
 
 	response := map[string]string{
 		"message": "Logout successful",
@@ -217,6 +297,40 @@ func (uc *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// LogoutAll revokes every session of the current user, including other devices
+func (uc *UserController) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	if err := uc.UserInteractor.LogoutAll(r.Context(), userID); err != nil {
+		uc.handleErrorResponse(w, err)
+		return
+	}
+
+	// Cookie削除
+	http.SetCookie(w, uc.createCookie("auth_token", "", -1))
+	http.SetCookie(w, uc.createRefreshCookie("", -1))
+
+	response := map[string]string{
+		"message": "Logged out from all devices",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 func (uc *UserController) Me(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -241,6 +355,7 @@ func (uc *UserController) Me(w http.ResponseWriter, r *http.Request) {
 		ID:       user.ID,
 		Username: user.Username,
 		Email:    user.Email,
+		Timezone: user.Timezone,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -325,6 +440,13 @@ func (uc *UserController) validateUpdateProfileRequest(req UpdateProfileRequest)
 		}
 	}
 
+	// Validate time zone if changing
+	if req.Timezone != "" {
+		if _, err := domain.LoadTimezone(req.Timezone); err != nil {
+			errors["timezone"] = domain.ErrInvalidTimezone.Message
+		}
+	}
+
 	return errors
 }
 
@@ -355,7 +477,7 @@ func (uc *UserController) UpdateProfile(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Call use case to update profile
-	updatedUser, err := uc.UserInteractor.UpdateProfile(r.Context(), userID, req.Username, req.Email, req.CurrentPassword, req.NewPassword)
+	updatedUser, err := uc.UserInteractor.UpdateProfile(r.Context(), userID, req.Username, req.Email, req.CurrentPassword, req.NewPassword, req.Timezone)
 	if err != nil {
 		statusCode := http.StatusBadRequest
 		message := err.Error()
@@ -382,6 +504,7 @@ func (uc *UserController) UpdateProfile(w http.ResponseWriter, r *http.Request)
 			ID:       updatedUser.ID,
 			Username: updatedUser.Username,
 			Email:    updatedUser.Email,
+			Timezone: updatedUser.Timezone,
 		},
 		Message: "Profile updated successfully",
 	}
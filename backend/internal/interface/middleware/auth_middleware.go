@@ -46,8 +46,8 @@ func (am *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			token = cookie.Value
 		}
 
-		// Validate JWT token
-		claims, err := am.UserInteractor.ValidateJWTToken(token)
+		// Validate JWT token and check it has not been revoked
+		claims, err := am.UserInteractor.ValidateAccessToken(r.Context(), token)
 		if err != nil {
 			http.Error(w, `{"error":"Invalid token"}`, http.StatusUnauthorized)
 			return
@@ -91,8 +91,8 @@ func (am *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 			token = cookie.Value
 		}
 
-		// Validate JWT token
-		claims, err := am.UserInteractor.ValidateJWTToken(token)
+		// Validate JWT token and check it has not been revoked
+		claims, err := am.UserInteractor.ValidateAccessToken(r.Context(), token)
 		if err != nil {
 			// Invalid token, continue without auth
 			next.ServeHTTP(w, r)
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 )
 
 // CORSConfig represents CORS configuration
@@ -9,6 +10,7 @@ type CORSConfig struct {
 	AllowOrigins     []string
 	AllowMethods     []string
 	AllowHeaders     []string
+	ExposeHeaders    []string
 	AllowCredentials bool
 }
 
@@ -17,7 +19,8 @@ func DefaultCORSConfig() *CORSConfig {
 	return &CORSConfig{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization", "Cookie"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", "Cookie", "If-Match", "If-None-Match"},
+		ExposeHeaders:    []string{"X-Total-Count", "ETag"},
 		AllowCredentials: true,
 	}
 }
@@ -68,6 +71,10 @@ func (c *CORSMiddleware) Handler(next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Headers", headers)
 		}
 
+		if len(c.config.ExposeHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.config.ExposeHeaders, ", "))
+		}
+
 		if c.config.AllowCredentials {
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
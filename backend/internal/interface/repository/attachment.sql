@@ -0,0 +1,34 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (
+    todo_id,
+    filename,
+    content_type,
+    size,
+    storage_key
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAttachment :one
+SELECT * FROM attachments
+WHERE id = $1 AND todo_id = $2 LIMIT 1;
+
+-- name: ListAttachments :many
+SELECT * FROM attachments
+WHERE todo_id = $1
+ORDER BY created_at ASC, id ASC;
+
+-- 行を消すとトリガーでblob_deletionsに積まれ、BlobCleanerがストアから消す
+-- name: DeleteAttachment :execrows
+DELETE FROM attachments
+WHERE id = $1 AND todo_id = $2;
+
+-- 古いものから順に、ストアから消す必要があるBlobのキーを返す
+-- name: ListBlobDeletions :many
+SELECT storage_key FROM blob_deletions
+ORDER BY created_at ASC
+LIMIT $1;
+
+-- name: DeleteBlobDeletion :exec
+DELETE FROM blob_deletions
+WHERE storage_key = $1;
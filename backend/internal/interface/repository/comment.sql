@@ -0,0 +1,58 @@
+-- name: CreateComment :one
+INSERT INTO comments (
+    todo_id,
+    parent_id,
+    author_id,
+    body
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetComment :one
+SELECT sqlc.embed(comments), users.username AS author_name
+FROM comments
+LEFT JOIN users ON users.id = comments.author_id
+WHERE comments.id = $1 AND comments.todo_id = $2 LIMIT 1;
+
+-- 投稿者のユーザー名と一緒に古い順で返す。スレッドへの組み立ては呼び出し側で行う
+-- name: ListComments :many
+SELECT sqlc.embed(comments), users.username AS author_name
+FROM comments
+LEFT JOIN users ON users.id = comments.author_id
+WHERE comments.todo_id = $1
+ORDER BY comments.created_at ASC, comments.id ASC;
+
+-- name: UpdateCommentBody :one
+UPDATE comments
+SET body = $3, edited_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND todo_id = $2 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: HasCommentReplies :one
+SELECT EXISTS (
+    SELECT 1 FROM comments WHERE parent_id = $1
+);
+
+-- 返信が残っているコメントは本文を消して削除済みとして残す
+-- name: MarkCommentDeleted :exec
+UPDATE comments
+SET body = '', deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteComment :exec
+DELETE FROM comments
+WHERE id = $1;
+
+-- 返信がなくなった削除済みコメントを消し、その親を返す
+-- name: DeleteOrphanedComment :one
+DELETE FROM comments
+WHERE id = $1 AND deleted_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM comments AS replies WHERE replies.parent_id = $1)
+RETURNING parent_id;
+
+-- Todoごとの削除されていないコメント数をまとめて取得
+-- name: ListCommentCounts :many
+SELECT todo_id, COUNT(*)::int AS count
+FROM comments
+WHERE todo_id = ANY(sqlc.arg(todo_ids)::int[]) AND deleted_at IS NULL
+GROUP BY todo_id;
@@ -0,0 +1,56 @@
+-- name: CreateProject :one
+INSERT INTO projects (
+    user_id,
+    name,
+    color,
+    is_archived,
+    sort_order
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetProject :one
+SELECT * FROM projects
+WHERE id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: GetInboxProject :one
+SELECT * FROM projects
+WHERE user_id = $1 AND is_inbox LIMIT 1;
+
+-- 同時に作成されても部分ユニークインデックスで1件に保たれる
+-- name: CreateInboxProject :exec
+INSERT INTO projects (user_id, name, is_inbox)
+VALUES ($1, 'Inbox', TRUE)
+ON CONFLICT (user_id) WHERE is_inbox DO NOTHING;
+
+-- Inboxを先頭に、アーカイブ済みはinclude_archivedがtrueのときだけ返す
+-- name: ListProjects :many
+SELECT * FROM projects
+WHERE user_id = sqlc.arg(user_id) AND (sqlc.arg(include_archived)::bool OR NOT is_archived)
+ORDER BY is_inbox DESC, sort_order ASC, id ASC;
+
+-- name: UpdateProject :one
+UPDATE projects
+SET name = $3,
+    color = $4,
+    is_archived = $5,
+    sort_order = $6
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteProject :exec
+DELETE FROM projects
+WHERE id = $1 AND user_id = $2;
+
+-- プロジェクト削除時にTodoを別のプロジェクト（Inbox）へ移す
+-- name: MoveProjectTodos :exec
+UPDATE todos
+SET project_id = sqlc.arg(to_project_id)::int
+WHERE project_id = sqlc.arg(from_project_id)::int;
+
+-- プロジェクトのTodoはゴミ箱へ移す。履歴を残すため対象のIDを返す
+-- name: TrashProjectTodos :many
+UPDATE todos
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE project_id = sqlc.arg(project_id)::int AND deleted_at IS NULL
+RETURNING id;
@@ -0,0 +1,88 @@
+-- name: CreateReminder :one
+INSERT INTO reminders (
+    todo_id,
+    remind_at,
+    offset_minutes,
+    channel
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- 通知する時刻はremind_atか、Todoの期限からoffset_minutes後。
+-- 時刻のないTodoはユーザーのタイムゾーンで期限日の9:00を期限とみなす。期限がなければNULL
+-- name: GetReminder :one
+SELECT sqlc.embed(reminders),
+    (CASE WHEN reminders.remind_at IS NOT NULL THEN reminders.remind_at
+        ELSE COALESCE(todos.due_at, (todos.due_date + time '09:00') AT TIME ZONE users.timezone)
+            + make_interval(mins => reminders.offset_minutes)
+    END)::timestamptz AS fire_at
+FROM reminders
+JOIN todos ON todos.id = reminders.todo_id
+JOIN users ON users.id = todos.user_id
+WHERE reminders.id = $1 AND reminders.todo_id = $2 LIMIT 1;
+
+-- name: ListReminders :many
+SELECT sqlc.embed(reminders),
+    (CASE WHEN reminders.remind_at IS NOT NULL THEN reminders.remind_at
+        ELSE COALESCE(todos.due_at, (todos.due_date + time '09:00') AT TIME ZONE users.timezone)
+            + make_interval(mins => reminders.offset_minutes)
+    END)::timestamptz AS fire_at
+FROM reminders
+JOIN todos ON todos.id = reminders.todo_id
+JOIN users ON users.id = todos.user_id
+WHERE reminders.todo_id = $1
+ORDER BY fire_at ASC NULLS LAST, reminders.id ASC;
+
+-- name: DeleteReminder :execrows
+DELETE FROM reminders
+WHERE id = $1 AND todo_id = $2;
+
+-- 繰り返しの次の回には、期限からの相対リマインダーだけを引き継ぐ
+-- name: CopyReminders :exec
+INSERT INTO reminders (todo_id, offset_minutes, channel)
+SELECT sqlc.arg(to_todo_id)::int, offset_minutes, channel FROM reminders
+WHERE todo_id = sqlc.arg(from_todo_id)::int AND offset_minutes IS NOT NULL;
+
+-- 通知時刻を過ぎたリマインダーをclaimed_untilまで確保して返す。
+-- SKIP LOCKEDで他のレプリカが確保中の行を飛ばすので、同じリマインダーが二重に送られない。
+-- 完了したTodoとゴミ箱のTodoのリマインダーは送らない
+-- name: ClaimDueReminders :many
+WITH due AS (
+    SELECT reminders.id,
+        (CASE WHEN reminders.remind_at IS NOT NULL THEN reminders.remind_at
+            ELSE COALESCE(todos.due_at, (todos.due_date + time '09:00') AT TIME ZONE users.timezone)
+                + make_interval(mins => reminders.offset_minutes)
+        END)::timestamptz AS fire_at
+    FROM reminders
+    JOIN todos ON todos.id = reminders.todo_id
+    JOIN users ON users.id = todos.user_id
+    WHERE reminders.sent_at IS NULL
+        AND reminders.attempts < sqlc.arg(max_attempts)::int
+        AND (reminders.retry_at IS NULL OR reminders.retry_at <= sqlc.arg(now)::timestamptz)
+        AND (reminders.claimed_until IS NULL OR reminders.claimed_until <= sqlc.arg(now)::timestamptz)
+        AND todos.is_completed = FALSE AND todos.deleted_at IS NULL
+        AND (CASE WHEN reminders.remind_at IS NOT NULL THEN reminders.remind_at
+            ELSE COALESCE(todos.due_at, (todos.due_date + time '09:00') AT TIME ZONE users.timezone)
+                + make_interval(mins => reminders.offset_minutes)
+        END) <= sqlc.arg(now)::timestamptz
+    ORDER BY fire_at ASC
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE OF reminders SKIP LOCKED
+)
+UPDATE reminders
+SET claimed_until = sqlc.arg(claimed_until)::timestamptz
+FROM due, todos, users
+WHERE reminders.id = due.id AND todos.id = reminders.todo_id AND users.id = todos.user_id
+RETURNING reminders.id, reminders.todo_id, reminders.channel, reminders.attempts, due.fire_at,
+    todos.user_id, todos.title, todos.due_date, todos.due_at, users.username, users.email, users.timezone;
+
+-- name: MarkReminderSent :exec
+UPDATE reminders
+SET sent_at = $2, attempts = attempts + 1, last_error = NULL, retry_at = NULL, claimed_until = NULL
+WHERE id = $1;
+
+-- 試行回数がmax_attemptsに達したリマインダーはClaimDueRemindersで選ばれなくなる
+-- name: MarkReminderFailed :exec
+UPDATE reminders
+SET attempts = attempts + 1, last_error = $2, retry_at = $3, claimed_until = NULL
+WHERE id = $1;
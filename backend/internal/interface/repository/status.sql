@@ -0,0 +1,63 @@
+-- 新しいステータスは並び順の最後に置く
+-- name: CreateStatus :one
+INSERT INTO statuses (
+    user_id,
+    name,
+    category,
+    wip_limit,
+    next_status_ids,
+    sort_order
+) VALUES (
+    $1, $2, $3, $4, $5,
+    (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM statuses WHERE user_id = $1)
+) RETURNING *;
+
+-- name: GetStatus :one
+SELECT * FROM statuses
+WHERE id = $1 AND user_id = $2 LIMIT 1;
+
+-- WIP制限の確認から移動までの間に他の移動が割り込まないよう行をロックする
+-- name: GetStatusForUpdate :one
+SELECT * FROM statuses
+WHERE id = $1 AND user_id = $2
+FOR UPDATE;
+
+-- 並び順に、ゴミ箱にないTodoの件数を付けて返す
+-- name: ListStatuses :many
+SELECT statuses.*, (
+    SELECT COUNT(*) FROM todos
+    WHERE todos.status_id = statuses.id AND todos.deleted_at IS NULL
+)::int AS todo_count
+FROM statuses
+WHERE user_id = $1
+ORDER BY sort_order ASC, id ASC;
+
+-- WIP制限と比べる件数。ゴミ箱のTodoは数えない
+-- name: CountStatusTodos :one
+SELECT COUNT(*) FROM todos
+WHERE status_id = $1 AND deleted_at IS NULL;
+
+-- name: UpdateStatus :one
+UPDATE statuses
+SET name = $3,
+    category = $4,
+    wip_limit = $5,
+    next_status_ids = $6
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: UpdateStatusSortOrder :exec
+UPDATE statuses
+SET sort_order = $3
+WHERE id = $1 AND user_id = $2;
+
+-- ステータスのTodoはトリガーで同じ完了区分の最初のステータスへ移る
+-- name: DeleteStatus :exec
+DELETE FROM statuses
+WHERE id = $1 AND user_id = $2;
+
+-- 削除したステータスを他のステータスの移動先から外す
+-- name: RemoveNextStatus :exec
+UPDATE statuses
+SET next_status_ids = array_remove(next_status_ids, sqlc.arg(status_id)::int)
+WHERE user_id = sqlc.arg(user_id)::int AND sqlc.arg(status_id)::int = ANY(next_status_ids);
@@ -0,0 +1,60 @@
+-- name: CreateSubtask :one
+INSERT INTO subtasks (
+    todo_id,
+    title,
+    position
+) VALUES (
+    $1, $2, (SELECT COALESCE(MAX(position) + 1, 0) FROM subtasks WHERE todo_id = $1)
+) RETURNING *;
+
+-- name: GetSubtask :one
+SELECT * FROM subtasks
+WHERE id = $1 AND todo_id = $2 LIMIT 1;
+
+-- name: ListSubtasks :many
+SELECT * FROM subtasks
+WHERE todo_id = $1
+ORDER BY position ASC, id ASC;
+
+-- name: UpdateSubtaskTitle :one
+UPDATE subtasks
+SET title = $3
+WHERE id = $1 AND todo_id = $2
+RETURNING *;
+
+-- name: UpdateSubtaskPosition :exec
+UPDATE subtasks
+SET position = $3
+WHERE id = $1 AND todo_id = $2;
+
+-- name: ToggleSubtaskComplete :one
+UPDATE subtasks
+SET is_completed = NOT is_completed
+WHERE id = $1 AND todo_id = $2
+RETURNING *;
+
+-- name: DeleteSubtask :exec
+DELETE FROM subtasks
+WHERE id = $1 AND todo_id = $2;
+
+-- 親Todo完了時に子をまとめて完了にする
+-- name: CompleteAllSubtasks :exec
+UPDATE subtasks
+SET is_completed = TRUE
+WHERE todo_id = $1 AND is_completed = FALSE;
+
+-- Todoごとの進捗（完了数/総数）をまとめて取得
+-- name: ListSubtaskProgress :many
+SELECT
+    todo_id,
+    COUNT(*)::int AS total,
+    (COUNT(*) FILTER (WHERE is_completed))::int AS done
+FROM subtasks
+WHERE todo_id = ANY(sqlc.arg(todo_ids)::int[])
+GROUP BY todo_id;
+
+-- 繰り返しTodoの次の回へサブタスクを未完了の状態でコピーする
+-- name: CopySubtasks :exec
+INSERT INTO subtasks (todo_id, title, position)
+SELECT sqlc.arg(to_todo_id)::int, title, position FROM subtasks
+WHERE todo_id = sqlc.arg(from_todo_id)::int;
@@ -0,0 +1,53 @@
+-- name: CreateTag :one
+INSERT INTO tags (
+    user_id,
+    name,
+    color
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetTag :one
+SELECT * FROM tags
+WHERE id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE user_id = $1 AND name = $2 LIMIT 1;
+
+-- name: ListTags :many
+SELECT * FROM tags
+WHERE user_id = $1
+ORDER BY name ASC;
+
+-- name: ListTagsByIDs :many
+SELECT * FROM tags
+WHERE user_id = sqlc.arg(user_id) AND id = ANY(sqlc.arg(tag_ids)::int[])
+ORDER BY name ASC;
+
+-- name: UpdateTag :one
+UPDATE tags
+SET name = $3,
+    color = $4
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE id = $1 AND user_id = $2;
+
+-- Todoに付けるタグを入れ替える（他ユーザーのタグは無視される）
+-- name: ClearTodoTags :exec
+DELETE FROM todo_tags
+WHERE todo_id = $1;
+
+-- name: AddTodoTags :exec
+INSERT INTO todo_tags (todo_id, tag_id)
+SELECT sqlc.arg(todo_id)::int, id FROM tags
+WHERE user_id = sqlc.arg(user_id) AND id = ANY(sqlc.arg(tag_ids)::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: ListTodoTagIDs :many
+SELECT tag_id FROM todo_tags
+WHERE todo_id = $1
+ORDER BY tag_id;
@@ -1,52 +1,289 @@
+-- status_idとis_completedはトリガーで揃える。status_idがNULLならis_completedに合う最初のステータスに入る
 -- name: CreateTodo :one
 INSERT INTO todos (
     user_id,
     title,
     due_date,
     priority,
-    is_completed
+    is_completed,
+    project_id,
+    recurrence_rule,
+    recurrence_from_completion,
+    position,
+    notes,
+    due_at,
+    snoozed_until,
+    status_id
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
 
+-- 新しいTodoは手動の並び順の先頭に置くため、いちばん前の位置キーを返す
+-- name: GetFirstTodoPosition :one
+SELECT position FROM todos
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY position ASC
+LIMIT 1;
+
+-- 移動先の基準にするTodoの位置キー
+-- name: GetTodoPosition :one
+SELECT position FROM todos
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
+
+-- positionの直後にあるTodoの位置キー（移動するTodo自身は除く）
+-- name: GetNextTodoPosition :one
+SELECT position FROM todos
+WHERE user_id = sqlc.arg(user_id) AND deleted_at IS NULL
+  AND position > sqlc.arg(position)::text COLLATE "C" AND id <> sqlc.arg(exclude_id)
+ORDER BY position ASC
+LIMIT 1;
+
+-- positionの直前にあるTodoの位置キー（移動するTodo自身は除く）
+-- name: GetPreviousTodoPosition :one
+SELECT position FROM todos
+WHERE user_id = sqlc.arg(user_id) AND deleted_at IS NULL
+  AND position < sqlc.arg(position)::text COLLATE "C" AND id <> sqlc.arg(exclude_id)
+ORDER BY position DESC
+LIMIT 1;
+
+-- 移動は1行の位置キーを書き換えるだけ
+-- name: UpdateTodoPosition :execrows
+UPDATE todos
+SET position = sqlc.arg(position)
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND deleted_at IS NULL;
+
+-- このトランザクションの間、Todoを更新してもupdated_atとversionを変えない(並び順を変えない位置キーの振り直し用)
+-- name: SkipTodoTouchTriggers :exec
+SELECT set_config('todo_app.renumbering_positions', 'on', true);
+
+-- 位置キーがmax_lengthより長くなったユーザーのTodoを、今の並び順のまま固定長のキーで振り直す
+-- 奇数を16進にしているので、キーの末尾が0になることはない
+-- name: RebalanceTodoPositions :execrows
+UPDATE todos SET position = ranked.new_position
+FROM (
+    SELECT id, lpad(to_hex(2 * row_number() OVER (PARTITION BY user_id ORDER BY position, id) + 1), 8, '0') AS new_position
+    FROM todos
+    WHERE user_id IN (SELECT user_id FROM todos WHERE length(position) > sqlc.arg(max_length)::int)
+) ranked
+WHERE todos.id = ranked.id;
+
 -- name: GetTodo :one
-SELECT * FROM todos
-WHERE id = $1 LIMIT 1;
+SELECT sqlc.embed(todos), COALESCE(tag_list.tags, '[]'::json)::json AS tags
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+WHERE todos.id = $1 AND todos.deleted_at IS NULL LIMIT 1;
 
--- name: ListTodos :many
+-- 変更前の状態を履歴に残すため、更新と同じトランザクションで行をロックして読む
+-- name: GetTodoForUpdate :one
 SELECT * FROM todos
-WHERE user_id = $1
-ORDER BY created_at DESC;
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+FOR UPDATE;
 
 -- name: UpdateTodo :one
 UPDATE todos
 SET title = $2,
     due_date = $3,
     priority = $4,
-    is_completed = $5
-WHERE id = $1 AND user_id = $6
+    is_completed = $5,
+    project_id = $7,
+    recurrence_rule = $8,
+    recurrence_from_completion = $9,
+    notes = $10,
+    due_at = $11,
+    snoozed_until = $12,
+    status_id = $13
+WHERE id = $1 AND user_id = $6 AND deleted_at IS NULL
 RETURNING *;
 
--- name: DeleteTodo :exec
-DELETE FROM todos
-WHERE id = $1 AND user_id = $2;
+-- 削除はゴミ箱へ移すだけ。完全に消すのはPurgeTodo/PurgeTrash
+-- name: TrashTodo :execrows
+UPDATE todos
+SET deleted_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
 
--- 完了切り替え専用クエリ
--- name: ToggleTodoComplete :one
+-- タグはJSON配列として同じクエリで取得する（N+1を避ける）
+-- NULLの条件は絞り込みに使わない。日付・日時の範囲は from 以上 before 未満
+-- overdue_atを渡すと期限切れのみ。時刻付きはdue_atで、日付だけのものはoverdue_date（ユーザーの今日）で判定する
+-- awake_atを渡すとその時点でスヌーズ中のものを除き、snoozed_atを渡すとその時点でスヌーズ中のものだけを返す
+-- tag_idsが空なら絞り込みなし、match_all_tagsがtrueなら全タグを持つTodoのみ
+-- text_termsはすべてタイトルに含まれる必要がある（LIKEの特殊文字はエスケープ済み）
+-- sort_byが空なら作成日時の新しい順、manualなら位置キーの順
+-- 並び順はsort_keyの各列の昇順。after_*を渡すとその位置より後ろだけを返す（キーセットページング）
+-- page_limitがNULLなら全件
+-- name: ListTodos :many
+SELECT sqlc.embed(todos), COALESCE(tag_list.tags, '[]'::json)::json AS tags,
+    sort_key.sort_group, sort_key.sort_position, sort_key.sort_value, sort_key.sort_completed, sort_key.sort_created, sort_key.sort_id
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+CROSS JOIN LATERAL (
+    SELECT
+        -- 期日順ではNULLの位置を決める（昇順なら最後、降順なら最初）
+        (CASE sqlc.arg(sort_by)::text
+            WHEN 'due_date_asc' THEN CASE WHEN todos.due_date IS NULL THEN 1 ELSE 0 END
+            WHEN 'due_date_desc' THEN CASE WHEN todos.due_date IS NULL THEN 0 ELSE 1 END
+            ELSE 0
+        END)::int AS sort_group,
+        (CASE WHEN sqlc.arg(sort_by)::text = 'manual' THEN todos.position ELSE '' END) COLLATE "C" AS sort_position,
+        -- 降順の列は符号を反転して昇順に揃える
+        -- 期日順は日付ごとに4日分の秒の幅を取り、同じ日の中では時刻付きを期日の瞬間順に並べ、日付だけのものをその後に置く。
+        -- due_atは時差（最大±14時間）があってもその日付のUTC 0時の前後1日半に収まる
+        (CASE sqlc.arg(sort_by)::text
+            WHEN 'due_date_asc' THEN COALESCE((todos.due_date - DATE '1970-01-01')::bigint * 345600
+                + COALESCE(floor(EXTRACT(EPOCH FROM todos.due_at))::bigint - (todos.due_date - DATE '1970-01-01')::bigint * 86400 + 86400, 259200), 0)
+            WHEN 'due_date_desc' THEN -COALESCE((todos.due_date - DATE '1970-01-01')::bigint * 345600
+                + COALESCE(floor(EXTRACT(EPOCH FROM todos.due_at))::bigint - (todos.due_date - DATE '1970-01-01')::bigint * 86400 + 86400, 259200), 0)
+            WHEN 'priority_desc' THEN -todos.priority
+            WHEN 'created_desc' THEN -COALESCE(floor(EXTRACT(EPOCH FROM todos.created_at) * 1000000), 0)
+            ELSE 0
+        END)::bigint AS sort_value,
+        (CASE WHEN sqlc.arg(sort_by)::text <> '' AND todos.is_completed THEN 1 ELSE 0 END)::int AS sort_completed,
+        (-COALESCE(floor(EXTRACT(EPOCH FROM todos.created_at) * 1000000), 0))::bigint AS sort_created,
+        -todos.id AS sort_id
+) sort_key
+WHERE todos.user_id = sqlc.arg(user_id)
+  AND todos.deleted_at IS NULL
+  AND (
+    cardinality(sqlc.arg(tag_ids)::int[]) = 0
+    OR (
+        SELECT COUNT(*) FROM todo_tags
+        WHERE todo_tags.todo_id = todos.id AND todo_tags.tag_id = ANY(sqlc.arg(tag_ids)::int[])
+    ) >= CASE WHEN sqlc.arg(match_all_tags)::bool THEN cardinality(sqlc.arg(tag_ids)::int[]) ELSE 1 END
+  )
+  AND (sqlc.narg(project_id)::int IS NULL OR todos.project_id = sqlc.narg(project_id)::int)
+  AND (sqlc.narg(status_id)::int IS NULL OR todos.status_id = sqlc.narg(status_id)::int)
+  AND (sqlc.narg(is_completed)::bool IS NULL OR todos.is_completed = sqlc.narg(is_completed)::bool)
+  AND (sqlc.narg(priority_min)::int IS NULL OR todos.priority >= sqlc.narg(priority_min)::int)
+  AND (sqlc.narg(priority_max)::int IS NULL OR todos.priority <= sqlc.narg(priority_max)::int)
+  AND (NOT sqlc.arg(no_due_date)::bool OR todos.due_date IS NULL)
+  AND (sqlc.narg(due_from)::date IS NULL OR todos.due_date >= sqlc.narg(due_from)::date)
+  AND (sqlc.narg(due_before)::date IS NULL OR todos.due_date < sqlc.narg(due_before)::date)
+  AND (sqlc.narg(overdue_at)::timestamptz IS NULL OR CASE
+    WHEN todos.due_at IS NOT NULL THEN todos.due_at < sqlc.narg(overdue_at)::timestamptz
+    ELSE todos.due_date < sqlc.narg(overdue_date)::date
+  END)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR todos.created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR todos.created_at < sqlc.narg(created_before)::timestamptz)
+  AND (sqlc.narg(updated_from)::timestamptz IS NULL OR todos.updated_at >= sqlc.narg(updated_from)::timestamptz)
+  AND (sqlc.narg(updated_before)::timestamptz IS NULL OR todos.updated_at < sqlc.narg(updated_before)::timestamptz)
+  AND (sqlc.narg(awake_at)::timestamptz IS NULL OR todos.snoozed_until IS NULL OR todos.snoozed_until <= sqlc.narg(awake_at)::timestamptz)
+  AND (sqlc.narg(snoozed_at)::timestamptz IS NULL OR todos.snoozed_until > sqlc.narg(snoozed_at)::timestamptz)
+  AND NOT EXISTS (
+    SELECT 1 FROM unnest(sqlc.arg(text_terms)::text[]) AS term
+    WHERE todos.title NOT ILIKE '%' || term || '%'
+  )
+  AND (
+    sqlc.narg(after_id)::int IS NULL
+    OR (sort_key.sort_group, sort_key.sort_position, sort_key.sort_value, sort_key.sort_completed, sort_key.sort_created, sort_key.sort_id)
+        > (sqlc.narg(after_group)::int, sqlc.narg(after_position)::text COLLATE "C", sqlc.narg(after_value)::bigint, sqlc.narg(after_completed)::int, sqlc.narg(after_created)::bigint, sqlc.narg(after_id)::int)
+  )
+ORDER BY sort_key.sort_group, sort_key.sort_position, sort_key.sort_value, sort_key.sort_completed, sort_key.sort_created, sort_key.sort_id
+LIMIT sqlc.narg(page_limit)::int;
+
+-- ListTodosと同じ絞り込み条件で件数を数える
+-- name: CountTodos :one
+SELECT COUNT(*) FROM todos
+WHERE todos.user_id = sqlc.arg(user_id)
+  AND todos.deleted_at IS NULL
+  AND (
+    cardinality(sqlc.arg(tag_ids)::int[]) = 0
+    OR (
+        SELECT COUNT(*) FROM todo_tags
+        WHERE todo_tags.todo_id = todos.id AND todo_tags.tag_id = ANY(sqlc.arg(tag_ids)::int[])
+    ) >= CASE WHEN sqlc.arg(match_all_tags)::bool THEN cardinality(sqlc.arg(tag_ids)::int[]) ELSE 1 END
+  )
+  AND (sqlc.narg(project_id)::int IS NULL OR todos.project_id = sqlc.narg(project_id)::int)
+  AND (sqlc.narg(status_id)::int IS NULL OR todos.status_id = sqlc.narg(status_id)::int)
+  AND (sqlc.narg(is_completed)::bool IS NULL OR todos.is_completed = sqlc.narg(is_completed)::bool)
+  AND (sqlc.narg(priority_min)::int IS NULL OR todos.priority >= sqlc.narg(priority_min)::int)
+  AND (sqlc.narg(priority_max)::int IS NULL OR todos.priority <= sqlc.narg(priority_max)::int)
+  AND (NOT sqlc.arg(no_due_date)::bool OR todos.due_date IS NULL)
+  AND (sqlc.narg(due_from)::date IS NULL OR todos.due_date >= sqlc.narg(due_from)::date)
+  AND (sqlc.narg(due_before)::date IS NULL OR todos.due_date < sqlc.narg(due_before)::date)
+  AND (sqlc.narg(overdue_at)::timestamptz IS NULL OR CASE
+    WHEN todos.due_at IS NOT NULL THEN todos.due_at < sqlc.narg(overdue_at)::timestamptz
+    ELSE todos.due_date < sqlc.narg(overdue_date)::date
+  END)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR todos.created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR todos.created_at < sqlc.narg(created_before)::timestamptz)
+  AND (sqlc.narg(updated_from)::timestamptz IS NULL OR todos.updated_at >= sqlc.narg(updated_from)::timestamptz)
+  AND (sqlc.narg(updated_before)::timestamptz IS NULL OR todos.updated_at < sqlc.narg(updated_before)::timestamptz)
+  AND (sqlc.narg(awake_at)::timestamptz IS NULL OR todos.snoozed_until IS NULL OR todos.snoozed_until <= sqlc.narg(awake_at)::timestamptz)
+  AND (sqlc.narg(snoozed_at)::timestamptz IS NULL OR todos.snoozed_until > sqlc.narg(snoozed_at)::timestamptz)
+  AND NOT EXISTS (
+    SELECT 1 FROM unnest(sqlc.arg(text_terms)::text[]) AS term
+    WHERE todos.title NOT ILIKE '%' || term || '%'
+  );
+
+-- name: ClearTodoRecurrence :execrows
 UPDATE todos
-SET is_completed = NOT is_completed,
-    updated_at = CURRENT_TIMESTAMP
-WHERE id = $1 AND user_id = $2
-RETURNING *;
+SET recurrence_rule = NULL,
+    recurrence_from_completion = FALSE
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
 
--- ソート機能付きリスト取得
--- name: ListTodosWithSort :many
-SELECT * FROM todos
-WHERE user_id = $1
-ORDER BY
-    CASE WHEN $2 = 'due_date_asc' THEN due_date END ASC,
-    CASE WHEN $2 = 'due_date_desc' THEN due_date END DESC,
-    CASE WHEN $2 = 'priority_desc' THEN priority END DESC,
-    CASE WHEN $2 = 'created_desc' THEN created_at END DESC,
-    is_completed ASC,
-    created_at DESC;
+-- タイトル検索。patternは最長の検索語で、pg_trgmのインデックスを使うためWHEREに直接置く
+-- 残りの検索語もすべて含むもの、または検索文字列全体と語単位で似ているもの（<%）を返す
+-- 全語を含むものを先に、その中では類似度の高い順
+-- name: SearchTodos :many
+SELECT sqlc.embed(todos), COALESCE(tag_list.tags, '[]'::json)::json AS tags,
+    hit.all_terms, hit.score
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+CROSS JOIN LATERAL (
+    SELECT
+        (todos.title ILIKE sqlc.arg(pattern)::text AND NOT EXISTS (
+            SELECT 1 FROM unnest(sqlc.arg(terms)::text[]) AS term
+            WHERE todos.title NOT ILIKE '%' || term || '%'
+        ))::bool AS all_terms,
+        word_similarity(sqlc.arg(query)::text, todos.title)::float8 AS score
+) hit
+WHERE todos.user_id = sqlc.arg(user_id)
+  AND todos.deleted_at IS NULL
+  AND (
+    (todos.title ILIKE sqlc.arg(pattern)::text AND hit.all_terms)
+    OR sqlc.arg(query)::text <% todos.title
+  )
+ORDER BY hit.all_terms DESC, hit.score DESC, todos.updated_at DESC, todos.id DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- ゴミ箱の一覧。削除日時の新しい順
+-- name: ListTrashedTodos :many
+SELECT sqlc.embed(todos), COALESCE(tag_list.tags, '[]'::json)::json AS tags
+FROM todos
+LEFT JOIN LATERAL (
+    SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name, 'color', tags.color) ORDER BY tags.name) AS tags
+    FROM todo_tags
+    JOIN tags ON tags.id = todo_tags.tag_id
+    WHERE todo_tags.todo_id = todos.id
+) tag_list ON TRUE
+WHERE todos.user_id = $1 AND todos.deleted_at IS NOT NULL
+ORDER BY todos.deleted_at DESC, todos.id DESC;
+
+-- name: RestoreTodo :execrows
+UPDATE todos
+SET deleted_at = NULL
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL;
+
+-- ゴミ箱にあるTodoだけを完全に削除する
+-- name: PurgeTodo :execrows
+DELETE FROM todos
+WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL;
+
+-- 保持期間を過ぎたゴミ箱のTodoを全ユーザー分まとめて削除する
+-- name: PurgeTrash :execrows
+DELETE FROM todos
+WHERE deleted_at < $1;
@@ -0,0 +1,52 @@
+-- 依存関係の追加をユーザーごとに直列化し、同時に追加された依存関係で循環ができないようにする
+-- name: LockTodoDependencies :exec
+SELECT pg_advisory_xact_lock(hashtext('todo_dependencies'), sqlc.arg(user_id)::int);
+
+-- 既にある依存関係なら何もしない
+-- name: CreateTodoDependency :execrows
+INSERT INTO todo_dependencies (
+    blocker_id,
+    blocked_id
+) VALUES (
+    $1, $2
+) ON CONFLICT DO NOTHING;
+
+-- from_idがブロックしているTodoを辿ってto_idに着くか。ゴミ箱のTodoも辿る（復元すると循環になるため）
+-- name: HasDependencyPath :one
+WITH RECURSIVE reachable(todo_id) AS (
+    SELECT sqlc.arg(from_id)::int
+    UNION
+    SELECT todo_dependencies.blocked_id
+    FROM todo_dependencies
+    JOIN reachable ON todo_dependencies.blocker_id = reachable.todo_id
+)
+SELECT EXISTS (SELECT 1 FROM reachable WHERE todo_id = sqlc.arg(to_id)::int);
+
+-- name: DeleteTodoDependency :execrows
+DELETE FROM todo_dependencies
+USING todos
+WHERE todo_dependencies.blocker_id = sqlc.arg(blocker_id)
+  AND todo_dependencies.blocked_id = sqlc.arg(blocked_id)
+  AND todos.id = todo_dependencies.blocked_id AND todos.user_id = sqlc.arg(user_id);
+
+-- todo_idsのいずれかがブロックしている・ブロックされている依存関係。ゴミ箱のTodoとの依存関係は除く
+-- name: ListTodoDependencies :many
+SELECT todo_dependencies.blocker_id, todo_dependencies.blocked_id, blocker.is_completed AS blocker_completed
+FROM todo_dependencies
+JOIN todos blocker ON blocker.id = todo_dependencies.blocker_id
+JOIN todos blocked ON blocked.id = todo_dependencies.blocked_id
+WHERE (todo_dependencies.blocker_id = ANY(sqlc.arg(todo_ids)::int[]) OR todo_dependencies.blocked_id = ANY(sqlc.arg(todo_ids)::int[]))
+  AND blocker.deleted_at IS NULL AND blocked.deleted_at IS NULL
+ORDER BY todo_dependencies.blocked_id, todo_dependencies.blocker_id;
+
+-- ゴミ箱にない相手との依存関係を持つTodoを、手動の並び順で返す
+-- name: ListDependencyTodos :many
+SELECT * FROM todos
+WHERE user_id = $1 AND deleted_at IS NULL
+  AND EXISTS (
+      SELECT 1
+      FROM todo_dependencies
+      JOIN todos other ON other.id IN (todo_dependencies.blocker_id, todo_dependencies.blocked_id) AND other.id <> todos.id
+      WHERE todos.id IN (todo_dependencies.blocker_id, todo_dependencies.blocked_id) AND other.deleted_at IS NULL
+  )
+ORDER BY position COLLATE "C", id;
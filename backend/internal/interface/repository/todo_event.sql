@@ -0,0 +1,39 @@
+-- name: CreateTodoEvent :exec
+INSERT INTO todo_events (
+    todo_id,
+    actor_id,
+    action,
+    changes
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: ListTodoEvents :many
+SELECT * FROM todo_events
+WHERE todo_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- ユーザーの直近page_limit件の操作（トランザクション単位）のうち、まだ取り消していないイベントを新しい順に返す
+-- 同じ操作を二重に取り消さないよう行をロックする
+-- name: ListUndoableTodoEvents :many
+SELECT * FROM todo_events
+WHERE actor_id = sqlc.arg(actor_id)::int
+  AND action IN ('update', 'toggle', 'transition', 'delete')
+  AND undone_at IS NULL
+  AND operation_id IN (
+    SELECT operation_id FROM todo_events
+    WHERE actor_id = sqlc.arg(actor_id)::int
+      AND action IN ('update', 'toggle', 'transition', 'delete')
+      AND undone_at IS NULL
+      AND created_at >= sqlc.arg(since)::timestamptz
+    GROUP BY operation_id
+    ORDER BY MAX(id) DESC
+    LIMIT sqlc.arg(page_limit)::int
+  )
+ORDER BY id DESC
+FOR UPDATE;
+
+-- name: MarkTodoEventsUndone :exec
+UPDATE todo_events
+SET undone_at = CURRENT_TIMESTAMP
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
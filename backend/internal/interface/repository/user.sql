@@ -24,6 +24,14 @@ UPDATE users
 SET username = $2,
     email = $3,
     password_hash = $4,
+    timezone = $5,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING *;
+
+-- 時刻付きの期日は瞬間(due_at)を保ったまま、新しいタイムゾーンでの日付にdue_dateを合わせる
+-- name: RezoneTodoDueDates :exec
+UPDATE todos
+SET due_date = (due_at AT TIME ZONE sqlc.arg(timezone)::text)::date
+WHERE user_id = sqlc.arg(user_id) AND due_at IS NOT NULL
+  AND due_date IS DISTINCT FROM (due_at AT TIME ZONE sqlc.arg(timezone)::text)::date;
@@ -2,27 +2,49 @@ package router
 
 import (
 	"net/http"
+	"strings"
 	"todo-app/internal/interface/controller"
 	"todo-app/internal/interface/middleware"
 )
 
 // Router represents the application router
 type Router struct {
-	userController *controller.UserController
-	todoController *controller.TodoController
-	authMiddleware *middleware.AuthMiddleware
+	userController       *controller.UserController
+	todoController       *controller.TodoController
+	subtaskController    *controller.SubtaskController
+	tagController        *controller.TagController
+	projectController    *controller.ProjectController
+	statusController     *controller.StatusController
+	attachmentController *controller.AttachmentController
+	commentController    *controller.CommentController
+	reminderController   *controller.ReminderController
+	authMiddleware       *middleware.AuthMiddleware
 }
 
 // NewRouter creates a new router instance
 func NewRouter(
 	userController *controller.UserController,
 	todoController *controller.TodoController,
+	subtaskController *controller.SubtaskController,
+	tagController *controller.TagController,
+	projectController *controller.ProjectController,
+	statusController *controller.StatusController,
+	attachmentController *controller.AttachmentController,
+	commentController *controller.CommentController,
+	reminderController *controller.ReminderController,
 	authMiddleware *middleware.AuthMiddleware,
 ) *Router {
 	return &Router{
-		userController: userController,
-		todoController: todoController,
-		authMiddleware: authMiddleware,
+		userController:       userController,
+		todoController:       todoController,
+		subtaskController:    subtaskController,
+		tagController:        tagController,
+		projectController:    projectController,
+		statusController:     statusController,
+		attachmentController: attachmentController,
+		commentController:    commentController,
+		reminderController:   reminderController,
+		authMiddleware:       authMiddleware,
 	}
 }
 
@@ -37,15 +59,38 @@ func (r *Router) SetupRoutes() *http.ServeMux {
 	mux.HandleFunc("/api/v1/register", r.userController.Register)
 	mux.HandleFunc("/api/v1/login", r.userController.Login)
 	mux.HandleFunc("/api/v1/logout", r.userController.Logout)
+	mux.HandleFunc("/api/v1/token/refresh", r.userController.RefreshToken)
+	// Signed download URLs of locally stored attachments; the signature authorises the download
+	mux.HandleFunc("/api/v1/blobs/", r.handleBlobs)
 
 	// Protected endpoints (authentication required)
 	mux.Handle("/api/v1/me", r.authMiddleware.RequireAuth(http.HandlerFunc(r.userController.Me)))
 	mux.Handle("/api/v1/profile", r.authMiddleware.RequireAuth(http.HandlerFunc(r.userController.UpdateProfile)))
+	mux.Handle("/api/v1/logout/all", r.authMiddleware.RequireAuth(http.HandlerFunc(r.userController.LogoutAll)))
 
 	// Todo endpoints (authentication required)
 	mux.Handle("/api/v1/todos", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleTodos)))
 	mux.Handle("/api/v1/todos/", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleTodoOperations)))
 
+	// Undo endpoint (authentication required)
+	mux.Handle("/api/v1/undo", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleUndo)))
+
+	// Trash endpoints (authentication required)
+	mux.Handle("/api/v1/trash", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleTrash)))
+	mux.Handle("/api/v1/trash/", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleTrashOperations)))
+
+	// Tag endpoints (authentication required)
+	mux.Handle("/api/v1/tags", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleTags)))
+	mux.Handle("/api/v1/tags/", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleTagOperations)))
+
+	// Project endpoints (authentication required)
+	mux.Handle("/api/v1/projects", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleProjects)))
+	mux.Handle("/api/v1/projects/", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleProjectOperations)))
+
+	// Status endpoints (authentication required)
+	mux.Handle("/api/v1/statuses", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleStatuses)))
+	mux.Handle("/api/v1/statuses/", r.authMiddleware.RequireAuth(http.HandlerFunc(r.handleStatusOperations)))
+
 	return mux
 }
 
@@ -73,30 +118,468 @@ func (r *Router) handleTodos(w http.ResponseWriter, req *http.Request) {
 
 // handleTodoOperations handles /api/v1/todos/* endpoints
 func (r *Router) handleTodoOperations(w http.ResponseWriter, req *http.Request) {
-	path := req.URL.Path
-
-	// Handle toggle functionality: /api/v1/todos/{id}/toggle
-	if len(path) > 16 && path[len(path)-7:] == "/toggle" && req.Method == http.MethodPatch {
-		r.todoController.ToggleTodoComplete(w, req)
+	// Path format: /api/v1/todos/{id}[/...]
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/todos/"), "/"), "/")
+	if segments[0] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
 		return
 	}
 
+	switch {
+	// Search todos by title: /api/v1/todos/search
+	case len(segments) == 1 && segments[0] == "search":
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.SearchTodos(w, req)
+
+	// Create a todo from one line of text: /api/v1/todos/quick
+	case len(segments) == 1 && segments[0] == "quick":
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.QuickAddTodo(w, req)
+
+	// Change many todos at once: /api/v1/todos/bulk
+	case len(segments) == 1 && segments[0] == "bulk":
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.BulkUpdateTodos(w, req)
+
+	// Dependency graph of the todos: /api/v1/todos/graph
+	case len(segments) == 1 && segments[0] == "graph":
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.GetTodoGraph(w, req)
+
 	// Handle individual todo operations: /api/v1/todos/{id}
-	// Path format: /api/v1/todos/{id}
-	pathSegments := len(path)
-	if pathSegments >= 16 && path[:14] == "/api/v1/todos/" {
+	case len(segments) == 1:
 		switch req.Method {
 		case http.MethodGet:
 			r.todoController.GetTodo(w, req)
 		case http.MethodPut:
 			r.todoController.UpdateTodo(w, req)
+		case http.MethodPatch:
+			r.todoController.PatchTodo(w, req)
 		case http.MethodDelete:
 			r.todoController.DeleteTodo(w, req)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
+
+	// Handle toggle functionality: /api/v1/todos/{id}/toggle
+	case len(segments) == 2 && segments[1] == "toggle":
+		if req.Method != http.MethodPatch {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.ToggleTodoComplete(w, req)
+
+	// Preview upcoming occurrences: /api/v1/todos/{id}/occurrences
+	case len(segments) == 2 && segments[1] == "occurrences":
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.GetOccurrences(w, req)
+
+	// Change history: /api/v1/todos/{id}/history
+	case len(segments) == 2 && segments[1] == "history":
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.GetTodoHistory(w, req)
+
+	// Take a todo out of the trash: /api/v1/todos/{id}/restore
+	case len(segments) == 2 && segments[1] == "restore":
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.RestoreTodo(w, req)
+
+	// Place a todo in the manual order: /api/v1/todos/{id}/move
+	case len(segments) == 2 && segments[1] == "move":
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.MoveTodo(w, req)
+
+	// Stop a recurring series: /api/v1/todos/{id}/recurrence
+	case len(segments) == 2 && segments[1] == "recurrence":
+		if req.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.StopRecurrence(w, req)
+
+	// Snooze a todo or end its snooze: /api/v1/todos/{id}/snooze
+	case len(segments) == 2 && segments[1] == "snooze":
+		switch req.Method {
+		case http.MethodPost:
+			r.todoController.SnoozeTodo(w, req)
+		case http.MethodDelete:
+			r.todoController.UnsnoozeTodo(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	// Move a todo to another status: /api/v1/todos/{id}/transition
+	case len(segments) == 2 && segments[1] == "transition":
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.TransitionTodo(w, req)
+
+	// Handle blockers: /api/v1/todos/{id}/blockers[/...]
+	case segments[1] == "blockers":
+		r.handleBlockerOperations(w, req, segments[2:])
+
+	// Handle subtasks: /api/v1/todos/{id}/subtasks[/...]
+	case segments[1] == "subtasks":
+		r.handleSubtaskOperations(w, req, segments[2:])
+
+	// Handle attachments: /api/v1/todos/{id}/attachments[/...]
+	case segments[1] == "attachments":
+		r.handleAttachmentOperations(w, req, segments[2:])
+
+	// Handle comments: /api/v1/todos/{id}/comments[/...]
+	case segments[1] == "comments":
+		r.handleCommentOperations(w, req, segments[2:])
+
+	// Handle reminders: /api/v1/todos/{id}/reminders[/...]
+	case segments[1] == "reminders":
+		r.handleReminderOperations(w, req, segments[2:])
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleBlockerOperations handles /api/v1/todos/{id}/blockers/* endpoints
+func (r *Router) handleBlockerOperations(w http.ResponseWriter, req *http.Request, segments []string) {
+	switch len(segments) {
+	// /api/v1/todos/{id}/blockers
+	case 0:
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.AddTodoBlocker(w, req)
+
+	// /api/v1/todos/{id}/blockers/{blockerId}
+	case 1:
+		if req.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.RemoveTodoBlocker(w, req)
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleSubtaskOperations handles /api/v1/todos/{id}/subtasks/* endpoints
+func (r *Router) handleSubtaskOperations(w http.ResponseWriter, req *http.Request, segments []string) {
+	switch {
+	// /api/v1/todos/{id}/subtasks
+	case len(segments) == 0:
+		switch req.Method {
+		case http.MethodGet:
+			r.subtaskController.GetSubtasks(w, req)
+		case http.MethodPost:
+			r.subtaskController.CreateSubtask(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	// /api/v1/todos/{id}/subtasks/reorder
+	case len(segments) == 1 && segments[0] == "reorder":
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.subtaskController.ReorderSubtasks(w, req)
+
+	// /api/v1/todos/{id}/subtasks/{subtaskId}
+	case len(segments) == 1:
+		switch req.Method {
+		case http.MethodPut:
+			r.subtaskController.UpdateSubtask(w, req)
+		case http.MethodDelete:
+			r.subtaskController.DeleteSubtask(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	// /api/v1/todos/{id}/subtasks/{subtaskId}/toggle
+	case len(segments) == 2 && segments[1] == "toggle":
+		if req.Method != http.MethodPatch {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.subtaskController.ToggleSubtaskComplete(w, req)
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleAttachmentOperations handles /api/v1/todos/{id}/attachments/* endpoints
+func (r *Router) handleAttachmentOperations(w http.ResponseWriter, req *http.Request, segments []string) {
+	switch len(segments) {
+	// /api/v1/todos/{id}/attachments
+	case 0:
+		switch req.Method {
+		case http.MethodGet:
+			r.attachmentController.GetAttachments(w, req)
+		case http.MethodPost:
+			r.attachmentController.UploadAttachment(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	// /api/v1/todos/{id}/attachments/{attachmentId}
+	case 1:
+		switch req.Method {
+		case http.MethodGet:
+			r.attachmentController.GetAttachment(w, req)
+		case http.MethodDelete:
+			r.attachmentController.DeleteAttachment(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleCommentOperations handles /api/v1/todos/{id}/comments/* endpoints
+func (r *Router) handleCommentOperations(w http.ResponseWriter, req *http.Request, segments []string) {
+	switch len(segments) {
+	// /api/v1/todos/{id}/comments
+	case 0:
+		switch req.Method {
+		case http.MethodGet:
+			r.commentController.GetComments(w, req)
+		case http.MethodPost:
+			r.commentController.CreateComment(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	// /api/v1/todos/{id}/comments/{commentId}
+	case 1:
+		switch req.Method {
+		case http.MethodPut:
+			r.commentController.UpdateComment(w, req)
+		case http.MethodDelete:
+			r.commentController.DeleteComment(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleReminderOperations handles /api/v1/todos/{id}/reminders/* endpoints
+func (r *Router) handleReminderOperations(w http.ResponseWriter, req *http.Request, segments []string) {
+	switch len(segments) {
+	// /api/v1/todos/{id}/reminders
+	case 0:
+		switch req.Method {
+		case http.MethodGet:
+			r.reminderController.GetReminders(w, req)
+		case http.MethodPost:
+			r.reminderController.CreateReminder(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	// /api/v1/todos/{id}/reminders/{reminderId}
+	case 1:
+		if req.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.reminderController.DeleteReminder(w, req)
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleBlobs handles /api/v1/blobs/{key}
+func (r *Router) handleBlobs(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.attachmentController.DownloadBlob(w, req)
+}
+
+// handleUndo handles /api/v1/undo endpoint
+func (r *Router) handleUndo(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.todoController.Undo(w, req)
+}
+
+// handleTrash handles /api/v1/trash endpoint
+func (r *Router) handleTrash(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.todoController.GetTrash(w, req)
+}
+
+// handleTrashOperations handles /api/v1/trash/{id} endpoint
+func (r *Router) handleTrashOperations(w http.ResponseWriter, req *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/trash/"), "/"), "/")
+	if segments[0] == "" || len(segments) != 1 {
+		http.Error(w, "Not found", http.StatusNotFound)
 		return
 	}
 
-	http.Error(w, "Not found", http.StatusNotFound)
+	if req.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.todoController.PurgeTodo(w, req)
+}
+
+// handleTags handles /api/v1/tags endpoint
+func (r *Router) handleTags(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.tagController.GetTags(w, req)
+	case http.MethodPost:
+		r.tagController.CreateTag(w, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleTagOperations handles /api/v1/tags/{id} endpoint
+func (r *Router) handleTagOperations(w http.ResponseWriter, req *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/tags/"), "/"), "/")
+	if segments[0] == "" || len(segments) != 1 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPut:
+		r.tagController.UpdateTag(w, req)
+	case http.MethodDelete:
+		r.tagController.DeleteTag(w, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleProjects handles /api/v1/projects endpoint
+func (r *Router) handleProjects(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.projectController.GetProjects(w, req)
+	case http.MethodPost:
+		r.projectController.CreateProject(w, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleProjectOperations handles /api/v1/projects/* endpoints
+func (r *Router) handleProjectOperations(w http.ResponseWriter, req *http.Request) {
+	// Path format: /api/v1/projects/{id}[/todos]
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/projects/"), "/"), "/")
+	if segments[0] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	// /api/v1/projects/{id}
+	case len(segments) == 1:
+		switch req.Method {
+		case http.MethodGet:
+			r.projectController.GetProject(w, req)
+		case http.MethodPut:
+			r.projectController.UpdateProject(w, req)
+		case http.MethodDelete:
+			r.projectController.DeleteProject(w, req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	// /api/v1/projects/{id}/todos
+	case len(segments) == 2 && segments[1] == "todos":
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.todoController.GetProjectTodos(w, req)
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// handleStatuses handles /api/v1/statuses endpoint
+func (r *Router) handleStatuses(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.statusController.GetStatuses(w, req)
+	case http.MethodPost:
+		r.statusController.CreateStatus(w, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleStatusOperations handles /api/v1/statuses/* endpoints
+func (r *Router) handleStatusOperations(w http.ResponseWriter, req *http.Request) {
+	// Path format: /api/v1/statuses/{id} or /api/v1/statuses/reorder
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, "/api/v1/statuses/"), "/"), "/")
+	if len(segments) != 1 || segments[0] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	// /api/v1/statuses/reorder
+	if segments[0] == "reorder" {
+		if req.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.statusController.ReorderStatuses(w, req)
+		return
+	}
+
+	// /api/v1/statuses/{id}
+	switch req.Method {
+	case http.MethodPut:
+		r.statusController.UpdateStatus(w, req)
+	case http.MethodDelete:
+		r.statusController.DeleteStatus(w, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
 }
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxAttachmentSize = 10 << 20
+	// AttachmentURLExpiry is how long a download URL stays valid
+	AttachmentURLExpiry = 5 * time.Minute
+	maxFilenameLength   = 255
+)
+
+// allowedAttachmentTypes are the media types that can be attached, without parameters
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+	"text/csv":        true,
+	"application/zip": true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+}
+
+// AttachmentUpload is a file to attach to a todo
+type AttachmentUpload struct {
+	Filename string
+	// ContentType must be detected from the content rather than taken from the client
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+type AttachmentUseCase interface {
+	UploadAttachment(ctx context.Context, userID int, todoID int, upload AttachmentUpload) (*domain.Attachment, error)
+	GetAttachments(ctx context.Context, userID int, todoID int) ([]*domain.Attachment, error)
+	GetAttachment(ctx context.Context, userID int, todoID int, attachmentID int) (*domain.Attachment, error)
+	DeleteAttachment(ctx context.Context, userID int, todoID int, attachmentID int) error
+}
+
+type AttachmentInteractor struct {
+	attachmentRepo AttachmentRepository
+	todoRepo       TodoRepository
+	blobStore      BlobStore
+}
+
+func NewAttachmentInteractor(attachmentRepo AttachmentRepository, todoRepo TodoRepository, blobStore BlobStore) AttachmentUseCase {
+	return &AttachmentInteractor{
+		attachmentRepo: attachmentRepo,
+		todoRepo:       todoRepo,
+		blobStore:      blobStore,
+	}
+}
+
+// ensureTodoOwner checks that the todo exists, is not in the trash and belongs to the user
+func (ai *AttachmentInteractor) ensureTodoOwner(ctx context.Context, userID int, todoID int) error {
+	if _, err := ai.todoRepo.GetTodo(ctx, userID, todoID); err != nil {
+		return domain.ErrTodoNotFound
+	}
+	return nil
+}
+
+func (ai *AttachmentInteractor) UploadAttachment(ctx context.Context, userID int, todoID int, upload AttachmentUpload) (*domain.Attachment, error) {
+	if upload.Size > MaxAttachmentSize {
+		return nil, domain.ErrAttachmentTooLarge
+	}
+	mediaType, _, err := mime.ParseMediaType(upload.ContentType)
+	if err != nil || !allowedAttachmentTypes[mediaType] {
+		return nil, domain.ErrUnsupportedAttachmentType
+	}
+	if err := ai.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	key, err := newStorageKey(todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "STORAGE_ERROR", "添付ファイルの保存に失敗しました", 500)
+	}
+	if err := ai.blobStore.Put(ctx, key, upload.Body, upload.Size, upload.ContentType); err != nil {
+		return nil, domain.WrapError(err, "STORAGE_ERROR", "添付ファイルの保存に失敗しました", 500)
+	}
+
+	attachment := &domain.Attachment{
+		TodoID:      todoID,
+		Filename:    cleanFilename(upload.Filename),
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		StorageKey:  key,
+	}
+	if err := ai.attachmentRepo.CreateAttachment(ctx, attachment); err != nil {
+		// Nothing refers to the blob yet, so it is removed right away
+		if deleteErr := ai.blobStore.Delete(ctx, key); deleteErr != nil {
+			log.Printf("Failed to delete blob %s: %v", key, deleteErr)
+		}
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "添付ファイルの保存に失敗しました", 500)
+	}
+
+	if err := ai.signURL(ctx, attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (ai *AttachmentInteractor) GetAttachments(ctx context.Context, userID int, todoID int) ([]*domain.Attachment, error) {
+	if err := ai.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := ai.attachmentRepo.GetAttachments(ctx, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "添付ファイル一覧の取得に失敗しました", 500)
+	}
+	for _, attachment := range attachments {
+		if err := ai.signURL(ctx, attachment); err != nil {
+			return nil, err
+		}
+	}
+	return attachments, nil
+}
+
+func (ai *AttachmentInteractor) GetAttachment(ctx context.Context, userID int, todoID int, attachmentID int) (*domain.Attachment, error) {
+	if err := ai.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	attachment, err := ai.attachmentRepo.GetAttachment(ctx, todoID, attachmentID)
+	if err != nil {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	if err := ai.signURL(ctx, attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// DeleteAttachment removes the attachment; its blob is removed by the BlobCleaner
+func (ai *AttachmentInteractor) DeleteAttachment(ctx context.Context, userID int, todoID int, attachmentID int) error {
+	if err := ai.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return err
+	}
+
+	deleted, err := ai.attachmentRepo.DeleteAttachment(ctx, todoID, attachmentID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "添付ファイルの削除に失敗しました", 500)
+	}
+	if !deleted {
+		return domain.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// signURL sets a fresh download URL on the attachment
+func (ai *AttachmentInteractor) signURL(ctx context.Context, attachment *domain.Attachment) error {
+	download := BlobDownload{
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+	}
+	expiresAt := time.Now().Add(AttachmentURLExpiry)
+
+	url, err := ai.blobStore.SignedURL(ctx, attachment.StorageKey, download, AttachmentURLExpiry)
+	if err != nil {
+		return domain.WrapError(err, "STORAGE_ERROR", "ダウンロードURLの作成に失敗しました", 500)
+	}
+	attachment.URL = url
+	attachment.URLExpiresAt = expiresAt
+	return nil
+}
+
+// newStorageKey returns a random key under the todo. The filename is not part of
+// the key, so that it cannot be guessed and any name can be stored.
+func newStorageKey(todoID int) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("todos/%d/%s", todoID, hex.EncodeToString(random)), nil
+}
+
+// cleanFilename keeps the base name of an uploaded file without control characters
+func cleanFilename(filename string) string {
+	filename = filepath.Base(strings.ReplaceAll(filename, `\`, "/"))
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+
+	for utf8.RuneCountInString(filename) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(filename)
+		filename = filename[:len(filename)-size]
+	}
+	if filename == "" || filename == "." || filename == "/" {
+		return "attachment"
+	}
+	return filename
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment *domain.Attachment) error
+	GetAttachment(ctx context.Context, todoID int, attachmentID int) (*domain.Attachment, error)
+	GetAttachments(ctx context.Context, todoID int) ([]*domain.Attachment, error)
+	// DeleteAttachment removes the record and queues its blob for deletion. It reports false
+	// when there is no such attachment. Attachments removed along with their todo are queued too.
+	DeleteAttachment(ctx context.Context, todoID int, attachmentID int) (bool, error)
+	// ListBlobDeletions returns up to limit keys of blobs waiting to be removed from the store, oldest first
+	ListBlobDeletions(ctx context.Context, limit int) ([]string, error)
+	// CompleteBlobDeletion takes the key off the queue once its blob is gone
+	CompleteBlobDeletion(ctx context.Context, key string) error
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	DefaultBlobCleanupInterval = time.Minute
+	blobCleanupBatchSize       = 100
+)
+
+// BlobCleaner removes the blobs of deleted attachments from the blob store. Deleting a
+// record only queues its blob, so that a todo can be purged in one statement and a
+// failed removal is retried on the next run.
+type BlobCleaner struct {
+	attachmentRepo AttachmentRepository
+	blobStore      BlobStore
+	interval       time.Duration
+}
+
+func NewBlobCleaner(attachmentRepo AttachmentRepository, blobStore BlobStore, interval time.Duration) *BlobCleaner {
+	return &BlobCleaner{
+		attachmentRepo: attachmentRepo,
+		blobStore:      blobStore,
+		interval:       interval,
+	}
+}
+
+// Run cleans up once at start and then every interval until ctx is cancelled
+func (bc *BlobCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(bc.interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := bc.CleanUp(ctx); err != nil {
+			log.Printf("Failed to remove deleted blobs: %v", err)
+		} else if removed > 0 {
+			log.Printf("Removed %d deleted blobs", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CleanUp removes queued blobs until the queue is empty or a removal fails,
+// and returns how many were removed
+func (bc *BlobCleaner) CleanUp(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		keys, err := bc.attachmentRepo.ListBlobDeletions(ctx, blobCleanupBatchSize)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, key := range keys {
+			if err := bc.blobStore.Delete(ctx, key); err != nil {
+				return removed, err
+			}
+			if err := bc.attachmentRepo.CompleteBlobDeletion(ctx, key); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(keys) < blobCleanupBatchSize {
+			return removed, nil
+		}
+	}
+}
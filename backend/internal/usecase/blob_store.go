@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/url"
+	"time"
+)
+
+// BlobStore keeps the bytes of attachments under keys chosen by the caller
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Delete succeeds when the blob does not exist, so that it can be retried
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads the blob without further credentials until expiry passes.
+	// The download is served with the filename and content type of download.
+	SignedURL(ctx context.Context, key string, download BlobDownload, expiry time.Duration) (string, error)
+}
+
+// BlobDownload describes how a signed URL serves a blob
+type BlobDownload struct {
+	Filename    string
+	ContentType string
+}
+
+// ContentDisposition is the Content-Disposition header that saves the download as Filename
+func (d BlobDownload) ContentDisposition() string {
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": d.Filename}); disposition != "" {
+		return disposition
+	}
+	return "attachment"
+}
+
+// SignedBlobOpener is implemented by blob stores whose signed URLs are served by this API
+// rather than by the store itself, such as a directory on the local disk
+type SignedBlobOpener interface {
+	// OpenSigned checks the query of a signed URL for the key and opens the blob it grants.
+	// It fails with domain.ErrInvalidBlobSignature when the signature is wrong or has expired.
+	OpenSigned(ctx context.Context, key string, query url.Values) (io.ReadSeekCloser, BlobDownload, error)
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type CommentUseCase interface {
+	// GetComments returns the todo's threads, oldest first
+	GetComments(ctx context.Context, userID int, todoID int) ([]*domain.Comment, error)
+	CreateComment(ctx context.Context, userID int, comment *domain.Comment) error
+	UpdateComment(ctx context.Context, userID int, comment *domain.Comment) error
+	DeleteComment(ctx context.Context, userID int, todoID int, commentID int) error
+}
+
+type CommentInteractor struct {
+	commentRepo CommentRepository
+	todoRepo    TodoRepository
+}
+
+func NewCommentInteractor(commentRepo CommentRepository, todoRepo TodoRepository) CommentUseCase {
+	return &CommentInteractor{
+		commentRepo: commentRepo,
+		todoRepo:    todoRepo,
+	}
+}
+
+// ensureTodoOwner checks that the todo exists, is not in the trash and belongs to the user
+func (ci *CommentInteractor) ensureTodoOwner(ctx context.Context, userID int, todoID int) error {
+	if _, err := ci.todoRepo.GetTodo(ctx, userID, todoID); err != nil {
+		return domain.ErrTodoNotFound
+	}
+	return nil
+}
+
+func (ci *CommentInteractor) GetComments(ctx context.Context, userID int, todoID int) ([]*domain.Comment, error) {
+	if err := ci.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	comments, err := ci.commentRepo.GetComments(ctx, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "コメント一覧の取得に失敗しました", 500)
+	}
+	return domain.ThreadComments(comments), nil
+}
+
+// CreateComment posts the comment as the user; a reply must be to a comment of the same todo
+func (ci *CommentInteractor) CreateComment(ctx context.Context, userID int, comment *domain.Comment) error {
+	if err := ci.ensureTodoOwner(ctx, userID, comment.TodoID); err != nil {
+		return err
+	}
+
+	if comment.ParentID != nil {
+		parent, err := ci.commentRepo.GetComment(ctx, comment.TodoID, *comment.ParentID)
+		if err != nil || parent.DeletedAt != nil {
+			return domain.ErrInvalidCommentParent
+		}
+	}
+
+	comment.AuthorID = &userID
+	if err := ci.commentRepo.CreateComment(ctx, comment); err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "コメントの作成に失敗しました", 500)
+	}
+	return ci.reload(ctx, comment)
+}
+
+// UpdateComment changes the body of one of the user's own comments
+func (ci *CommentInteractor) UpdateComment(ctx context.Context, userID int, comment *domain.Comment) error {
+	if _, err := ci.authoredComment(ctx, userID, comment.TodoID, comment.ID); err != nil {
+		return err
+	}
+
+	if err := ci.commentRepo.UpdateComment(ctx, comment); err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "コメントの更新に失敗しました", 500)
+	}
+	return ci.reload(ctx, comment)
+}
+
+func (ci *CommentInteractor) DeleteComment(ctx context.Context, userID int, todoID int, commentID int) error {
+	if _, err := ci.authoredComment(ctx, userID, todoID, commentID); err != nil {
+		return err
+	}
+
+	if err := ci.commentRepo.DeleteComment(ctx, todoID, commentID); err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "コメントの削除に失敗しました", 500)
+	}
+	return nil
+}
+
+// authoredComment reads a comment that has not been deleted and checks that the user wrote it
+func (ci *CommentInteractor) authoredComment(ctx context.Context, userID int, todoID int, commentID int) (*domain.Comment, error) {
+	if err := ci.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	comment, err := ci.commentRepo.GetComment(ctx, todoID, commentID)
+	if err != nil || comment.DeletedAt != nil {
+		return nil, domain.ErrCommentNotFound
+	}
+	if !comment.IsAuthor(userID) {
+		return nil, domain.ErrCommentForbidden
+	}
+	return comment, nil
+}
+
+// reload reads the comment back together with its author's name
+func (ci *CommentInteractor) reload(ctx context.Context, comment *domain.Comment) error {
+	saved, err := ci.commentRepo.GetComment(ctx, comment.TodoID, comment.ID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "コメントの取得に失敗しました", 500)
+	}
+	*comment = *saved
+	return nil
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment *domain.Comment) error
+	GetComment(ctx context.Context, todoID int, commentID int) (*domain.Comment, error)
+	// GetComments lists the todo's comments oldest first, without threading them
+	GetComments(ctx context.Context, todoID int) ([]*domain.Comment, error)
+	UpdateComment(ctx context.Context, comment *domain.Comment) error
+	// DeleteComment keeps a comment with replies as deleted, and removes it otherwise
+	DeleteComment(ctx context.Context, todoID int, commentID int) error
+}
@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+// Notifier delivers reminders over one channel. An error means the notice may not
+// have arrived; the delivery is tried again later.
+type Notifier interface {
+	Notify(ctx context.Context, notice *domain.ReminderNotice) error
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// DefaultMaxPositionLength is the key length above which a user's manual order is renumbered.
+	// Keys grow by about one character every 18 todos added at the same end, so this is rarely reached.
+	DefaultMaxPositionLength         = 32
+	DefaultPositionRebalanceInterval = time.Hour
+)
+
+// PositionRebalancer keeps the manual order keys short by renumbering the positions
+// of users whose keys have grown too long from repeated moves and inserts
+type PositionRebalancer struct {
+	todoRepo  TodoRepository
+	maxLength int
+	interval  time.Duration
+}
+
+func NewPositionRebalancer(todoRepo TodoRepository, maxLength int, interval time.Duration) *PositionRebalancer {
+	return &PositionRebalancer{
+		todoRepo:  todoRepo,
+		maxLength: maxLength,
+		interval:  interval,
+	}
+}
+
+// Run rebalances once at start and then every interval until ctx is cancelled
+func (pr *PositionRebalancer) Run(ctx context.Context) {
+	ticker := time.NewTicker(pr.interval)
+	defer ticker.Stop()
+
+	for {
+		if renumbered, err := pr.RebalanceLongPositions(ctx); err != nil {
+			log.Printf("Failed to rebalance todo positions: %v", err)
+		} else if renumbered > 0 {
+			log.Printf("Renumbered the positions of %d todos", renumbered)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RebalanceLongPositions renumbers the todos of every user with a key longer than the maximum
+func (pr *PositionRebalancer) RebalanceLongPositions(ctx context.Context) (int64, error) {
+	return pr.todoRepo.RebalanceTodoPositions(ctx, pr.maxLength)
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type ProjectUseCase interface {
+	CreateProject(ctx context.Context, userID int, project *domain.Project) error
+	GetProjects(ctx context.Context, userID int, includeArchived bool) ([]*domain.Project, error)
+	GetProject(ctx context.Context, userID int, projectID int) (*domain.Project, error)
+	UpdateProject(ctx context.Context, userID int, project *domain.Project) error
+	DeleteProject(ctx context.Context, userID int, projectID int, mode domain.ProjectDeleteMode) error
+}
+
+type ProjectInteractor struct {
+	projectRepo ProjectRepository
+}
+
+func NewProjectInteractor(projectRepo ProjectRepository) ProjectUseCase {
+	return &ProjectInteractor{
+		projectRepo: projectRepo,
+	}
+}
+
+func (pi *ProjectInteractor) CreateProject(ctx context.Context, userID int, project *domain.Project) error {
+	project.UserID = userID
+
+	err := pi.projectRepo.CreateProject(ctx, project)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "プロジェクトの作成に失敗しました", 500)
+	}
+	return nil
+}
+
+func (pi *ProjectInteractor) GetProjects(ctx context.Context, userID int, includeArchived bool) ([]*domain.Project, error) {
+	// Make sure the Inbox always shows up in the list
+	if _, err := pi.projectRepo.GetOrCreateInbox(ctx, userID); err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Inboxプロジェクトの作成に失敗しました", 500)
+	}
+
+	projects, err := pi.projectRepo.GetProjects(ctx, userID, includeArchived)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "プロジェクト一覧の取得に失敗しました", 500)
+	}
+	return projects, nil
+}
+
+func (pi *ProjectInteractor) GetProject(ctx context.Context, userID int, projectID int) (*domain.Project, error) {
+	project, err := pi.projectRepo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, domain.ErrProjectNotFound
+	}
+	return project, nil
+}
+
+func (pi *ProjectInteractor) UpdateProject(ctx context.Context, userID int, project *domain.Project) error {
+	project.UserID = userID
+
+	existingProject, err := pi.projectRepo.GetProject(ctx, userID, project.ID)
+	if err != nil {
+		return domain.ErrProjectNotFound
+	}
+
+	if existingProject.IsInbox && project.IsArchived {
+		return domain.ErrInboxProjectLocked
+	}
+
+	err = pi.projectRepo.UpdateProject(ctx, project)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "プロジェクトの更新に失敗しました", 500)
+	}
+	return nil
+}
+
+func (pi *ProjectInteractor) DeleteProject(ctx context.Context, userID int, projectID int, mode domain.ProjectDeleteMode) error {
+	switch mode {
+	case domain.ProjectDeleteMoveToInbox, domain.ProjectDeleteTodos:
+	default:
+		return domain.ErrInvalidProjectDeleteMode
+	}
+
+	project, err := pi.projectRepo.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return domain.ErrProjectNotFound
+	}
+
+	if project.IsInbox {
+		return domain.ErrInboxProjectLocked
+	}
+
+	err = pi.projectRepo.DeleteProject(ctx, userID, projectID, mode == domain.ProjectDeleteTodos)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "プロジェクトの削除に失敗しました", 500)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+// fakeProjectRepo keeps projects in memory and records deletions
+type fakeProjectRepo struct {
+	ProjectRepository
+	projects map[int]*domain.Project
+	// deleted maps the deleted projects to whether their todos were deleted with them
+	deleted map[int]bool
+}
+
+func (r *fakeProjectRepo) GetProject(ctx context.Context, userID int, projectID int) (*domain.Project, error) {
+	project, ok := r.projects[projectID]
+	if !ok || project.UserID != userID {
+		return nil, domain.ErrProjectNotFound
+	}
+	return project, nil
+}
+
+func (r *fakeProjectRepo) DeleteProject(ctx context.Context, userID int, projectID int, deleteTodos bool) error {
+	r.deleted[projectID] = deleteTodos
+	return nil
+}
+
+func TestDeleteProject(t *testing.T) {
+	tests := []struct {
+		name      string
+		projectID int
+		mode      domain.ProjectDeleteMode
+		wantErr   error
+		// wantDeleteTodos is whether the project's todos go with it
+		wantDeleteTodos bool
+	}{
+		{name: "move todos to the Inbox", projectID: 2, mode: domain.ProjectDeleteMoveToInbox},
+		{name: "delete todos", projectID: 2, mode: domain.ProjectDeleteTodos, wantDeleteTodos: true},
+		{name: "no mode", projectID: 2, mode: "", wantErr: domain.ErrInvalidProjectDeleteMode},
+		{name: "unknown mode", projectID: 2, mode: "archive", wantErr: domain.ErrInvalidProjectDeleteMode},
+		{name: "Inbox", projectID: 1, mode: domain.ProjectDeleteMoveToInbox, wantErr: domain.ErrInboxProjectLocked},
+		{name: "Inbox with its todos", projectID: 1, mode: domain.ProjectDeleteTodos, wantErr: domain.ErrInboxProjectLocked},
+		{name: "another user's project", projectID: 3, mode: domain.ProjectDeleteTodos, wantErr: domain.ErrProjectNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectRepo := &fakeProjectRepo{
+				projects: map[int]*domain.Project{
+					1: {ID: 1, UserID: 1, Name: "Inbox", IsInbox: true},
+					2: {ID: 2, UserID: 1, Name: "Work"},
+					3: {ID: 3, UserID: 2, Name: "Home"},
+				},
+				deleted: map[int]bool{},
+			}
+			interactor := NewProjectInteractor(projectRepo)
+
+			err := interactor.DeleteProject(context.Background(), 1, tt.projectID, tt.mode)
+			if err != tt.wantErr {
+				t.Fatalf("DeleteProject() error = %v, want %v", err, tt.wantErr)
+			}
+			deleteTodos, deleted := projectRepo.deleted[tt.projectID]
+			if deleted != (tt.wantErr == nil) {
+				t.Fatalf("project deleted = %v, want %v", deleted, tt.wantErr == nil)
+			}
+			if deleteTodos != tt.wantDeleteTodos {
+				t.Errorf("todos deleted = %v, want %v", deleteTodos, tt.wantDeleteTodos)
+			}
+		})
+	}
+}
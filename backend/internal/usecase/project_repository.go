@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type ProjectRepository interface {
+	CreateProject(ctx context.Context, project *domain.Project) error
+	GetProject(ctx context.Context, userID int, projectID int) (*domain.Project, error)
+	GetProjects(ctx context.Context, userID int, includeArchived bool) ([]*domain.Project, error)
+	// GetOrCreateInbox returns the user's Inbox project, creating it on first use
+	GetOrCreateInbox(ctx context.Context, userID int) (*domain.Project, error)
+	UpdateProject(ctx context.Context, project *domain.Project) error
+	// DeleteProject removes the project and moves its todos to the Inbox,
+	// or deletes them when deleteTodos is set, in one transaction
+	DeleteProject(ctx context.Context, userID int, projectID int, deleteTodos bool) error
+}
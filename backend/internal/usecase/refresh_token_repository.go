@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type RefreshTokenRepository interface {
+	StoreRefreshToken(ctx context.Context, tokenID, familyID string, userID int, expiresAt time.Time) error
+	GetRefreshToken(ctx context.Context, tokenID string) (*domain.RefreshToken, error)
+	ConsumeRefreshToken(ctx context.Context, tokenID string) (*domain.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tokenID string) error
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	RevokeAllUserTokens(ctx context.Context, userID int) error
+	CleanupExpiredTokens(ctx context.Context) error
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type ReminderUseCase interface {
+	// GetReminders returns the todo's reminders in the order they go off
+	GetReminders(ctx context.Context, userID int, todoID int) ([]*domain.Reminder, error)
+	CreateReminder(ctx context.Context, userID int, reminder *domain.Reminder) error
+	DeleteReminder(ctx context.Context, userID int, todoID int, reminderID int) error
+}
+
+type ReminderInteractor struct {
+	reminderRepo ReminderRepository
+	todoRepo     TodoRepository
+	// channels are the channels the server can deliver over
+	channels map[domain.ReminderChannel]bool
+}
+
+func NewReminderInteractor(reminderRepo ReminderRepository, todoRepo TodoRepository, channels []domain.ReminderChannel) ReminderUseCase {
+	available := make(map[domain.ReminderChannel]bool, len(channels))
+	for _, channel := range channels {
+		available[channel] = true
+	}
+	return &ReminderInteractor{
+		reminderRepo: reminderRepo,
+		todoRepo:     todoRepo,
+		channels:     available,
+	}
+}
+
+func (ri *ReminderInteractor) GetReminders(ctx context.Context, userID int, todoID int) ([]*domain.Reminder, error) {
+	if _, err := ri.todoRepo.GetTodo(ctx, userID, todoID); err != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+
+	reminders, err := ri.reminderRepo.GetReminders(ctx, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "リマインダー一覧の取得に失敗しました", 500)
+	}
+	return reminders, nil
+}
+
+// CreateReminder adds an absolute or a relative reminder to the todo. Without a channel
+// the reminder is sent by email.
+func (ri *ReminderInteractor) CreateReminder(ctx context.Context, userID int, reminder *domain.Reminder) error {
+	todo, err := ri.todoRepo.GetTodo(ctx, userID, reminder.TodoID)
+	if err != nil {
+		return domain.ErrTodoNotFound
+	}
+
+	switch {
+	case (reminder.RemindAt == nil) == (reminder.OffsetMinutes == nil):
+		return domain.ErrInvalidReminder
+	case reminder.RemindAt != nil && !reminder.RemindAt.After(time.Now()):
+		return domain.ErrReminderInPast
+	case reminder.OffsetMinutes != nil && todo.DueDate == nil:
+		return domain.ErrReminderNeedsDueDate
+	case reminder.OffsetMinutes != nil && !validReminderOffset(*reminder.OffsetMinutes):
+		return domain.ErrInvalidReminderOffset
+	}
+
+	if reminder.Channel == "" {
+		reminder.Channel = domain.ReminderChannelEmail
+	}
+	if !ri.channels[reminder.Channel] {
+		return domain.ErrReminderChannelUnavailable
+	}
+
+	existing, err := ri.reminderRepo.GetReminders(ctx, reminder.TodoID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "リマインダー一覧の取得に失敗しました", 500)
+	}
+	if len(existing) >= domain.MaxRemindersPerTodo {
+		return domain.ErrTooManyReminders
+	}
+
+	if err := ri.reminderRepo.CreateReminder(ctx, reminder); err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "リマインダーの作成に失敗しました", 500)
+	}
+
+	// Re-read for the time the reminder goes off, which the database works out
+	created, err := ri.reminderRepo.GetReminder(ctx, reminder.TodoID, reminder.ID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "リマインダーの取得に失敗しました", 500)
+	}
+	*reminder = *created
+	return nil
+}
+
+func (ri *ReminderInteractor) DeleteReminder(ctx context.Context, userID int, todoID int, reminderID int) error {
+	if _, err := ri.todoRepo.GetTodo(ctx, userID, todoID); err != nil {
+		return domain.ErrTodoNotFound
+	}
+
+	deleted, err := ri.reminderRepo.DeleteReminder(ctx, todoID, reminderID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "リマインダーの削除に失敗しました", 500)
+	}
+	if !deleted {
+		return domain.ErrReminderNotFound
+	}
+	return nil
+}
+
+func validReminderOffset(minutes int) bool {
+	offset := time.Duration(minutes) * time.Minute
+	return offset >= -domain.MaxReminderOffset && offset <= domain.MaxReminderOffset
+}
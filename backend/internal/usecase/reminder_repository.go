@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type ReminderRepository interface {
+	CreateReminder(ctx context.Context, reminder *domain.Reminder) error
+	GetReminder(ctx context.Context, todoID int, reminderID int) (*domain.Reminder, error)
+	// GetReminders lists the todo's reminders in the order they go off
+	GetReminders(ctx context.Context, todoID int) ([]*domain.Reminder, error)
+	// DeleteReminder reports whether the reminder existed
+	DeleteReminder(ctx context.Context, todoID int, reminderID int) (bool, error)
+
+	// ClaimDueReminders reserves up to limit reminders that are due at now for this
+	// scheduler until the given time. Reminders reserved by another scheduler are skipped.
+	ClaimDueReminders(ctx context.Context, now time.Time, until time.Time, limit int) ([]*DueReminder, error)
+	MarkReminderSent(ctx context.Context, reminderID int, sentAt time.Time) error
+	// MarkReminderFailed records a failed delivery; a nil retryAt gives the reminder up
+	MarkReminderFailed(ctx context.Context, reminderID int, message string, retryAt *time.Time) error
+}
+
+// DueReminder is a reminder claimed for delivery
+type DueReminder struct {
+	Notice  *domain.ReminderNotice
+	Channel domain.ReminderChannel
+	// Attempts counts the earlier, failed deliveries
+	Attempts int
+}
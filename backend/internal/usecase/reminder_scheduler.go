@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const (
+	DefaultReminderInterval = 30 * time.Second
+	reminderBatchSize       = 20
+	// reminderSendTimeout bounds one delivery
+	reminderSendTimeout = 15 * time.Second
+	// reminderClaimDuration is how long claimed reminders are held. It has to outlast
+	// the delivery of a whole batch, or another replica could claim them again.
+	reminderClaimDuration = 2 * reminderBatchSize * reminderSendTimeout
+	// reminderRetryDelay is the wait after the first failed delivery, doubled after each further one
+	reminderRetryDelay = time.Minute
+)
+
+// ReminderScheduler delivers reminders once they are due. Several schedulers, one in
+// each API replica, can run side by side: each claims the reminders it sends.
+type ReminderScheduler struct {
+	reminderRepo ReminderRepository
+	notifiers    map[domain.ReminderChannel]Notifier
+	interval     time.Duration
+}
+
+func NewReminderScheduler(reminderRepo ReminderRepository, notifiers map[domain.ReminderChannel]Notifier, interval time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{
+		reminderRepo: reminderRepo,
+		notifiers:    notifiers,
+		interval:     interval,
+	}
+}
+
+// Run delivers due reminders once at start and then every interval until ctx is cancelled
+func (rs *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+
+	for {
+		if sent, err := rs.DeliverDue(ctx); err != nil {
+			log.Printf("Failed to deliver reminders: %v", err)
+		} else if sent > 0 {
+			log.Printf("Delivered %d reminders", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims and delivers due reminders batch by batch until none are left,
+// and returns how many were delivered. Failed deliveries are scheduled for a retry.
+func (rs *ReminderScheduler) DeliverDue(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		now := time.Now()
+		due, err := rs.reminderRepo.ClaimDueReminders(ctx, now, now.Add(reminderClaimDuration), reminderBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, reminder := range due {
+			if err := rs.deliver(ctx, reminder); err != nil {
+				if err := rs.recordFailure(ctx, reminder, err); err != nil {
+					return sent, err
+				}
+				continue
+			}
+			if err := rs.reminderRepo.MarkReminderSent(ctx, reminder.Notice.ReminderID, time.Now()); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+
+		if len(due) < reminderBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+func (rs *ReminderScheduler) deliver(ctx context.Context, reminder *DueReminder) error {
+	notifier, ok := rs.notifiers[reminder.Channel]
+	if !ok {
+		return fmt.Errorf("channel %q is not configured", reminder.Channel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reminderSendTimeout)
+	defer cancel()
+	return notifier.Notify(ctx, reminder.Notice)
+}
+
+// recordFailure schedules the next attempt with a growing delay, or gives the
+// reminder up after domain.MaxReminderAttempts attempts
+func (rs *ReminderScheduler) recordFailure(ctx context.Context, reminder *DueReminder, cause error) error {
+	attempts := reminder.Attempts + 1
+	log.Printf("Failed to deliver reminder %d (attempt %d): %v", reminder.Notice.ReminderID, attempts, cause)
+
+	var retryAt *time.Time
+	if attempts < domain.MaxReminderAttempts {
+		next := time.Now().Add(reminderRetryDelay << (attempts - 1))
+		retryAt = &next
+	}
+	return rs.reminderRepo.MarkReminderFailed(ctx, reminder.Notice.ReminderID, cause.Error(), retryAt)
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// fakeReminder is a row of the reminders table as ClaimDueReminders sees it
+type fakeReminder struct {
+	channel      domain.ReminderChannel
+	fireAt       time.Time
+	attempts     int
+	retryAt      *time.Time
+	claimedUntil *time.Time
+	sentAt       *time.Time
+	lastError    string
+}
+
+// fakeReminderRepo claims reminders like the SQL does: the claim and the check
+// that nobody else holds the reminder happen under one lock
+type fakeReminderRepo struct {
+	ReminderRepository
+
+	mu        sync.Mutex
+	reminders map[int]*fakeReminder
+	claims    int
+}
+
+func newFakeReminderRepo() *fakeReminderRepo {
+	return &fakeReminderRepo{reminders: make(map[int]*fakeReminder)}
+}
+
+func (r *fakeReminderRepo) ClaimDueReminders(ctx context.Context, now time.Time, until time.Time, limit int) ([]*DueReminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.claims++
+
+	var ids []int
+	for id, reminder := range r.reminders {
+		if reminder.sentAt == nil && reminder.attempts < domain.MaxReminderAttempts &&
+			(reminder.retryAt == nil || !reminder.retryAt.After(now)) &&
+			(reminder.claimedUntil == nil || !reminder.claimedUntil.After(now)) &&
+			!reminder.fireAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	due := make([]*DueReminder, len(ids))
+	for i, id := range ids {
+		reminder := r.reminders[id]
+		reminder.claimedUntil = &until
+		due[i] = &DueReminder{
+			Notice:   &domain.ReminderNotice{ReminderID: id, FireAt: reminder.fireAt, Location: time.UTC},
+			Channel:  reminder.channel,
+			Attempts: reminder.attempts,
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeReminderRepo) MarkReminderSent(ctx context.Context, reminderID int, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reminder := r.reminders[reminderID]
+	reminder.sentAt, reminder.attempts, reminder.retryAt, reminder.claimedUntil = &sentAt, reminder.attempts+1, nil, nil
+	return nil
+}
+
+func (r *fakeReminderRepo) MarkReminderFailed(ctx context.Context, reminderID int, message string, retryAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reminder := r.reminders[reminderID]
+	reminder.attempts, reminder.lastError, reminder.retryAt, reminder.claimedUntil = reminder.attempts+1, message, retryAt, nil
+	return nil
+}
+
+// fakeNotifier records the reminders it delivers, and fails while err is set
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent []int
+	err  error
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notice *domain.ReminderNotice) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notice.ReminderID)
+	return nil
+}
+
+func TestReminderSchedulerDeliverDue(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	reminderRepo := newFakeReminderRepo()
+	reminderRepo.reminders[1] = &fakeReminder{channel: domain.ReminderChannelEmail, fireAt: now.Add(-time.Minute)}
+	reminderRepo.reminders[2] = &fakeReminder{channel: domain.ReminderChannelWebhook, fireAt: now.Add(-time.Hour)}
+	reminderRepo.reminders[3] = &fakeReminder{channel: domain.ReminderChannelEmail, fireAt: later}
+	reminderRepo.reminders[4] = &fakeReminder{channel: domain.ReminderChannelEmail, fireAt: now.Add(-time.Minute), retryAt: &later}
+	reminderRepo.reminders[5] = &fakeReminder{channel: domain.ReminderChannelEmail, fireAt: now.Add(-time.Minute), attempts: domain.MaxReminderAttempts}
+
+	email, webhook := &fakeNotifier{}, &fakeNotifier{}
+	scheduler := NewReminderScheduler(reminderRepo, map[domain.ReminderChannel]Notifier{
+		domain.ReminderChannelEmail:   email,
+		domain.ReminderChannelWebhook: webhook,
+	}, time.Minute)
+
+	sent, err := scheduler.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Errorf("DeliverDue() = %d, want 2", sent)
+	}
+	if len(email.sent) != 1 || email.sent[0] != 1 || len(webhook.sent) != 1 || webhook.sent[0] != 2 {
+		t.Errorf("email sent %v and webhook sent %v, want [1] and [2]", email.sent, webhook.sent)
+	}
+	for id, reminder := range reminderRepo.reminders {
+		if wantSent := id == 1 || id == 2; (reminder.sentAt != nil) != wantSent {
+			t.Errorf("reminder %d sent = %v, want %v", id, reminder.sentAt != nil, wantSent)
+		}
+	}
+
+	// Sent reminders are not delivered again
+	if sent, err := scheduler.DeliverDue(context.Background()); err != nil || sent != 0 {
+		t.Errorf("second DeliverDue() = %d, %v, want 0", sent, err)
+	}
+}
+
+func TestReminderSchedulerRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		// wantDelay is the wait before the next attempt, 0 when the reminder is given up
+		wantDelay time.Duration
+	}{
+		{name: "first failure", attempts: 0, wantDelay: time.Minute},
+		{name: "third failure", attempts: 2, wantDelay: 4 * time.Minute},
+		{name: "last attempt gives up", attempts: domain.MaxReminderAttempts - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminderRepo := newFakeReminderRepo()
+			reminderRepo.reminders[1] = &fakeReminder{channel: domain.ReminderChannelEmail, fireAt: time.Now().Add(-time.Minute), attempts: tt.attempts}
+
+			email := &fakeNotifier{err: errors.New("connection refused")}
+			scheduler := NewReminderScheduler(reminderRepo, map[domain.ReminderChannel]Notifier{domain.ReminderChannelEmail: email}, time.Minute)
+
+			before := time.Now()
+			sent, err := scheduler.DeliverDue(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent != 0 {
+				t.Errorf("DeliverDue() = %d, want 0", sent)
+			}
+
+			reminder := reminderRepo.reminders[1]
+			if reminder.attempts != tt.attempts+1 || reminder.lastError != "connection refused" || reminder.claimedUntil != nil {
+				t.Errorf("reminder = %+v, want attempt %d recorded and the claim released", reminder, tt.attempts+1)
+			}
+			if tt.wantDelay == 0 {
+				if reminder.retryAt != nil {
+					t.Errorf("retry at %v, want the reminder given up", reminder.retryAt)
+				}
+				return
+			}
+			if reminder.retryAt == nil || reminder.retryAt.Before(before.Add(tt.wantDelay)) || reminder.retryAt.After(time.Now().Add(tt.wantDelay)) {
+				t.Errorf("retry at %v, want %v from now", reminder.retryAt, tt.wantDelay)
+			}
+
+			// The reminder is not claimed again before the retry is due
+			email.err = nil
+			if sent, err := scheduler.DeliverDue(context.Background()); err != nil || sent != 0 {
+				t.Errorf("DeliverDue() before the retry = %d, %v, want 0", sent, err)
+			}
+		})
+	}
+}
+
+func TestReminderSchedulerUnknownChannel(t *testing.T) {
+	reminderRepo := newFakeReminderRepo()
+	reminderRepo.reminders[1] = &fakeReminder{channel: domain.ReminderChannelWebhook, fireAt: time.Now().Add(-time.Minute)}
+
+	scheduler := NewReminderScheduler(reminderRepo, map[domain.ReminderChannel]Notifier{domain.ReminderChannelEmail: &fakeNotifier{}}, time.Minute)
+	if _, err := scheduler.DeliverDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	reminder := reminderRepo.reminders[1]
+	if reminder.sentAt != nil || reminder.attempts != 1 || reminder.lastError != `channel "webhook" is not configured` {
+		t.Errorf("reminder = %+v, want a failed attempt", reminder)
+	}
+}
+
+func TestReminderSchedulerBatches(t *testing.T) {
+	reminderRepo := newFakeReminderRepo()
+	for id := 1; id <= 2*reminderBatchSize+5; id++ {
+		reminderRepo.reminders[id] = &fakeReminder{channel: domain.ReminderChannelEmail, fireAt: time.Now().Add(-time.Minute)}
+	}
+
+	email := &fakeNotifier{}
+	sent, err := NewReminderScheduler(reminderRepo, map[domain.ReminderChannel]Notifier{domain.ReminderChannelEmail: email}, time.Minute).DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2*reminderBatchSize+5 || len(email.sent) != sent {
+		t.Errorf("DeliverDue() = %d with %d notices, want %d", sent, len(email.sent), 2*reminderBatchSize+5)
+	}
+	// Two full batches and the remainder
+	if reminderRepo.claims != 3 {
+		t.Errorf("claimed %d batches, want 3", reminderRepo.claims)
+	}
+}
+
+func TestReminderSchedulerReplicas(t *testing.T) {
+	reminderRepo := newFakeReminderRepo()
+	for id := 1; id <= 3*reminderBatchSize; id++ {
+		reminderRepo.reminders[id] = &fakeReminder{channel: domain.ReminderChannelEmail, fireAt: time.Now().Add(-time.Minute)}
+	}
+
+	// Each replica has its own scheduler; they share the database
+	email := &fakeNotifier{}
+	notifiers := map[domain.ReminderChannel]Notifier{domain.ReminderChannelEmail: email}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := NewReminderScheduler(reminderRepo, notifiers, time.Minute).DeliverDue(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	delivered := make(map[int]int)
+	for _, id := range email.sent {
+		delivered[id]++
+	}
+	for id := 1; id <= 3*reminderBatchSize; id++ {
+		if delivered[id] != 1 {
+			t.Errorf("reminder %d delivered %d times, want once", id, delivered[id])
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+// MaxStatuses is the most statuses a user's workflow may have
+const MaxStatuses = 20
+
+type StatusUseCase interface {
+	CreateStatus(ctx context.Context, userID int, status *domain.Status) error
+	GetStatuses(ctx context.Context, userID int) ([]*domain.Status, error)
+	UpdateStatus(ctx context.Context, userID int, status *domain.Status) error
+	ReorderStatuses(ctx context.Context, userID int, statusIDs []int) ([]*domain.Status, error)
+	DeleteStatus(ctx context.Context, userID int, statusID int) error
+}
+
+type StatusInteractor struct {
+	statusRepo StatusRepository
+}
+
+func NewStatusInteractor(statusRepo StatusRepository) StatusUseCase {
+	return &StatusInteractor{
+		statusRepo: statusRepo,
+	}
+}
+
+func (si *StatusInteractor) CreateStatus(ctx context.Context, userID int, status *domain.Status) error {
+	status.UserID = userID
+
+	statuses, err := si.getStatuses(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(statuses) >= MaxStatuses {
+		return domain.ErrTooManyStatuses
+	}
+	if err := validateStatus(statuses, status); err != nil {
+		return err
+	}
+
+	err = si.statusRepo.CreateStatus(ctx, status)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "ステータスの作成に失敗しました", 500)
+	}
+	return nil
+}
+
+func (si *StatusInteractor) GetStatuses(ctx context.Context, userID int) ([]*domain.Status, error) {
+	return si.getStatuses(ctx, userID)
+}
+
+func (si *StatusInteractor) UpdateStatus(ctx context.Context, userID int, status *domain.Status) error {
+	status.UserID = userID
+
+	statuses, err := si.getStatuses(ctx, userID)
+	if err != nil {
+		return err
+	}
+	existing := findStatus(statuses, status.ID)
+	if existing == nil {
+		return domain.ErrStatusNotFound
+	}
+	if err := validateStatus(statuses, status); err != nil {
+		return err
+	}
+
+	// Todos take their completion from the status, so they would all be completed
+	// or reopened at once without history; they have to be moved out first
+	if status.IsDone() != existing.IsDone() {
+		if existing.TodoCount > 0 {
+			return domain.ErrStatusInUse
+		}
+		if !coversBothKinds(append(withoutStatus(statuses, status.ID), status)) {
+			return domain.ErrStatusCategoryRequired
+		}
+	}
+
+	err = si.statusRepo.UpdateStatus(ctx, status)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "ステータスの更新に失敗しました", 500)
+	}
+	status.TodoCount = existing.TodoCount
+	return nil
+}
+
+// ReorderStatuses puts the user's statuses in the order of statusIDs, which has to list
+// each of them exactly once, and returns them in their new order
+func (si *StatusInteractor) ReorderStatuses(ctx context.Context, userID int, statusIDs []int) ([]*domain.Status, error) {
+	statuses, err := si.getStatuses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(statusIDs) != len(statuses) {
+		return nil, domain.ErrInvalidStatusOrder
+	}
+	seen := make(map[int]bool, len(statusIDs))
+	for _, id := range statusIDs {
+		if seen[id] || findStatus(statuses, id) == nil {
+			return nil, domain.ErrInvalidStatusOrder
+		}
+		seen[id] = true
+	}
+
+	if err := si.statusRepo.ReorderStatuses(ctx, userID, statusIDs); err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "ステータスの並べ替えに失敗しました", 500)
+	}
+	return si.getStatuses(ctx, userID)
+}
+
+func (si *StatusInteractor) DeleteStatus(ctx context.Context, userID int, statusID int) error {
+	statuses, err := si.getStatuses(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if findStatus(statuses, statusID) == nil {
+		return domain.ErrStatusNotFound
+	}
+	// The todos need a status of the same kind to move to
+	if !coversBothKinds(withoutStatus(statuses, statusID)) {
+		return domain.ErrStatusCategoryRequired
+	}
+
+	err = si.statusRepo.DeleteStatus(ctx, userID, statusID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "ステータスの削除に失敗しました", 500)
+	}
+	return nil
+}
+
+func (si *StatusInteractor) getStatuses(ctx context.Context, userID int) ([]*domain.Status, error) {
+	statuses, err := si.statusRepo.GetStatuses(ctx, userID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "ステータス一覧の取得に失敗しました", 500)
+	}
+	return statuses, nil
+}
+
+// validateStatus checks a new or changed status against the user's statuses:
+// its name has to be free, and its next statuses have to be among them
+func validateStatus(statuses []*domain.Status, status *domain.Status) error {
+	for _, other := range statuses {
+		if other.ID != status.ID && other.Name == status.Name {
+			return domain.ErrStatusNameExists
+		}
+	}
+
+	if status.NextStatusIDs == nil {
+		return nil
+	}
+	seen := make(map[int]bool, len(status.NextStatusIDs))
+	nextIDs := make([]int, 0, len(status.NextStatusIDs))
+	for _, id := range status.NextStatusIDs {
+		if findStatus(statuses, id) == nil {
+			return domain.ErrInvalidNextStatuses
+		}
+		if !seen[id] {
+			seen[id] = true
+			nextIDs = append(nextIDs, id)
+		}
+	}
+	status.NextStatusIDs = nextIDs
+	return nil
+}
+
+func findStatus(statuses []*domain.Status, statusID int) *domain.Status {
+	for _, status := range statuses {
+		if status.ID == statusID {
+			return status
+		}
+	}
+	return nil
+}
+
+func withoutStatus(statuses []*domain.Status, statusID int) []*domain.Status {
+	others := make([]*domain.Status, 0, len(statuses))
+	for _, status := range statuses {
+		if status.ID != statusID {
+			others = append(others, status)
+		}
+	}
+	return others
+}
+
+// coversBothKinds reports whether there is a status for open todos and one for completed todos,
+// which completing and reopening todos move them to
+func coversBothKinds(statuses []*domain.Status) bool {
+	var open, done bool
+	for _, status := range statuses {
+		if status.IsDone() {
+			done = true
+		} else {
+			open = true
+		}
+	}
+	return open && done
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type StatusRepository interface {
+	CreateStatus(ctx context.Context, status *domain.Status) error
+	GetStatus(ctx context.Context, userID int, statusID int) (*domain.Status, error)
+	// GetStatuses returns the user's statuses in order, with their todo counts
+	GetStatuses(ctx context.Context, userID int) ([]*domain.Status, error)
+	UpdateStatus(ctx context.Context, status *domain.Status) error
+	// ReorderStatuses numbers the statuses in the order of statusIDs in one transaction
+	ReorderStatuses(ctx context.Context, userID int, statusIDs []int) error
+	// DeleteStatus removes the status from the user's workflow in one transaction.
+	// Its todos move to the user's first status of the same kind.
+	DeleteStatus(ctx context.Context, userID int, statusID int) error
+}
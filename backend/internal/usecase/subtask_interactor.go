@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type SubtaskUseCase interface {
+	CreateSubtask(ctx context.Context, userID int, subtask *domain.Subtask) error
+	GetSubtasks(ctx context.Context, userID int, todoID int) ([]*domain.Subtask, error)
+	UpdateSubtask(ctx context.Context, userID int, subtask *domain.Subtask) error
+	ToggleSubtaskComplete(ctx context.Context, userID int, todoID int, subtaskID int) (*domain.Subtask, error)
+	ReorderSubtasks(ctx context.Context, userID int, todoID int, subtaskIDs []int) ([]*domain.Subtask, error)
+	DeleteSubtask(ctx context.Context, userID int, todoID int, subtaskID int) error
+}
+
+type SubtaskInteractor struct {
+	subtaskRepo SubtaskRepository
+	todoRepo    TodoRepository
+}
+
+func NewSubtaskInteractor(subtaskRepo SubtaskRepository, todoRepo TodoRepository) SubtaskUseCase {
+	return &SubtaskInteractor{
+		subtaskRepo: subtaskRepo,
+		todoRepo:    todoRepo,
+	}
+}
+
+// ensureTodoOwner checks that the parent todo exists and belongs to the user
+func (si *SubtaskInteractor) ensureTodoOwner(ctx context.Context, userID int, todoID int) error {
+	if _, err := si.todoRepo.GetTodo(ctx, userID, todoID); err != nil {
+		return domain.ErrTodoNotFound
+	}
+	return nil
+}
+
+func (si *SubtaskInteractor) CreateSubtask(ctx context.Context, userID int, subtask *domain.Subtask) error {
+	if err := si.ensureTodoOwner(ctx, userID, subtask.TodoID); err != nil {
+		return err
+	}
+
+	err := si.subtaskRepo.CreateSubtask(ctx, subtask)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "サブタスクの作成に失敗しました", 500)
+	}
+	return nil
+}
+
+func (si *SubtaskInteractor) GetSubtasks(ctx context.Context, userID int, todoID int) ([]*domain.Subtask, error) {
+	if err := si.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	subtasks, err := si.subtaskRepo.GetSubtasks(ctx, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "サブタスク一覧の取得に失敗しました", 500)
+	}
+	return subtasks, nil
+}
+
+func (si *SubtaskInteractor) UpdateSubtask(ctx context.Context, userID int, subtask *domain.Subtask) error {
+	if err := si.ensureTodoOwner(ctx, userID, subtask.TodoID); err != nil {
+		return err
+	}
+
+	if _, err := si.subtaskRepo.GetSubtask(ctx, subtask.TodoID, subtask.ID); err != nil {
+		return domain.ErrSubtaskNotFound
+	}
+
+	err := si.subtaskRepo.UpdateSubtask(ctx, subtask)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "サブタスクの更新に失敗しました", 500)
+	}
+	return nil
+}
+
+func (si *SubtaskInteractor) ToggleSubtaskComplete(ctx context.Context, userID int, todoID int, subtaskID int) (*domain.Subtask, error) {
+	if err := si.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	if _, err := si.subtaskRepo.GetSubtask(ctx, todoID, subtaskID); err != nil {
+		return nil, domain.ErrSubtaskNotFound
+	}
+
+	subtask, err := si.subtaskRepo.ToggleSubtaskComplete(ctx, todoID, subtaskID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "サブタスクの状態変更に失敗しました", 500)
+	}
+	return subtask, nil
+}
+
+// ReorderSubtasks sets the order of the todo's subtasks. subtaskIDs must list
+// every subtask of the todo exactly once.
+func (si *SubtaskInteractor) ReorderSubtasks(ctx context.Context, userID int, todoID int, subtaskIDs []int) ([]*domain.Subtask, error) {
+	if err := si.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	current, err := si.subtaskRepo.GetSubtasks(ctx, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "サブタスク一覧の取得に失敗しました", 500)
+	}
+
+	if len(subtaskIDs) != len(current) {
+		return nil, domain.ErrInvalidSubtaskOrder
+	}
+	remaining := make(map[int]bool, len(current))
+	for _, subtask := range current {
+		remaining[subtask.ID] = true
+	}
+	for _, id := range subtaskIDs {
+		if !remaining[id] {
+			return nil, domain.ErrInvalidSubtaskOrder
+		}
+		delete(remaining, id)
+	}
+
+	if err := si.subtaskRepo.ReorderSubtasks(ctx, todoID, subtaskIDs); err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "サブタスクの並び替えに失敗しました", 500)
+	}
+
+	subtasks, err := si.subtaskRepo.GetSubtasks(ctx, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "サブタスク一覧の取得に失敗しました", 500)
+	}
+	return subtasks, nil
+}
+
+func (si *SubtaskInteractor) DeleteSubtask(ctx context.Context, userID int, todoID int, subtaskID int) error {
+	if err := si.ensureTodoOwner(ctx, userID, todoID); err != nil {
+		return err
+	}
+
+	if _, err := si.subtaskRepo.GetSubtask(ctx, todoID, subtaskID); err != nil {
+		return domain.ErrSubtaskNotFound
+	}
+
+	err := si.subtaskRepo.DeleteSubtask(ctx, todoID, subtaskID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "サブタスクの削除に失敗しました", 500)
+	}
+	return nil
+}
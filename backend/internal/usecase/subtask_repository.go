@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type SubtaskRepository interface {
+	CreateSubtask(ctx context.Context, subtask *domain.Subtask) error
+	GetSubtask(ctx context.Context, todoID int, subtaskID int) (*domain.Subtask, error)
+	GetSubtasks(ctx context.Context, todoID int) ([]*domain.Subtask, error)
+	UpdateSubtask(ctx context.Context, subtask *domain.Subtask) error
+	ToggleSubtaskComplete(ctx context.Context, todoID int, subtaskID int) (*domain.Subtask, error)
+	ReorderSubtasks(ctx context.Context, todoID int, subtaskIDs []int) error
+	DeleteSubtask(ctx context.Context, todoID int, subtaskID int) error
+}
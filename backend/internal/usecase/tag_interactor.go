@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type TagUseCase interface {
+	CreateTag(ctx context.Context, userID int, tag *domain.Tag) error
+	GetTags(ctx context.Context, userID int) ([]*domain.Tag, error)
+	UpdateTag(ctx context.Context, userID int, tag *domain.Tag) error
+	DeleteTag(ctx context.Context, userID int, tagID int) error
+}
+
+type TagInteractor struct {
+	tagRepo TagRepository
+}
+
+func NewTagInteractor(tagRepo TagRepository) TagUseCase {
+	return &TagInteractor{
+		tagRepo: tagRepo,
+	}
+}
+
+func (ti *TagInteractor) CreateTag(ctx context.Context, userID int, tag *domain.Tag) error {
+	tag.UserID = userID
+
+	// Check if the name is already used by another tag of the user
+	existingTag, _ := ti.tagRepo.GetTagByName(ctx, userID, tag.Name)
+	if existingTag != nil {
+		return domain.ErrTagNameExists
+	}
+
+	err := ti.tagRepo.CreateTag(ctx, tag)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "タグの作成に失敗しました", 500)
+	}
+	return nil
+}
+
+func (ti *TagInteractor) GetTags(ctx context.Context, userID int) ([]*domain.Tag, error) {
+	tags, err := ti.tagRepo.GetTags(ctx, userID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "タグ一覧の取得に失敗しました", 500)
+	}
+	return tags, nil
+}
+
+func (ti *TagInteractor) UpdateTag(ctx context.Context, userID int, tag *domain.Tag) error {
+	tag.UserID = userID
+
+	existingTag, err := ti.tagRepo.GetTag(ctx, userID, tag.ID)
+	if err != nil {
+		return domain.ErrTagNotFound
+	}
+
+	if tag.Name != existingTag.Name {
+		sameName, _ := ti.tagRepo.GetTagByName(ctx, userID, tag.Name)
+		if sameName != nil && sameName.ID != tag.ID {
+			return domain.ErrTagNameExists
+		}
+	}
+
+	err = ti.tagRepo.UpdateTag(ctx, tag)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "タグの更新に失敗しました", 500)
+	}
+	return nil
+}
+
+func (ti *TagInteractor) DeleteTag(ctx context.Context, userID int, tagID int) error {
+	if _, err := ti.tagRepo.GetTag(ctx, userID, tagID); err != nil {
+		return domain.ErrTagNotFound
+	}
+
+	err := ti.tagRepo.DeleteTag(ctx, userID, tagID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "タグの削除に失敗しました", 500)
+	}
+	return nil
+}
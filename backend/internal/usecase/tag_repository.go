@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+	"todo-app/internal/domain"
+)
+
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag *domain.Tag) error
+	GetTag(ctx context.Context, userID int, tagID int) (*domain.Tag, error)
+	GetTagByName(ctx context.Context, userID int, name string) (*domain.Tag, error)
+	GetTags(ctx context.Context, userID int) ([]*domain.Tag, error)
+	GetTagsByIDs(ctx context.Context, userID int, tagIDs []int) ([]*domain.Tag, error)
+	UpdateTag(ctx context.Context, tag *domain.Tag) error
+	DeleteTag(ctx context.Context, userID int, tagID int) error
+}
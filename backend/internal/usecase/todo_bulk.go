@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"time"
+	"todo-app/internal/domain"
+)
+
+// MaxBulkTodos is the most todos a single bulk operation may change
+const MaxBulkTodos = 100
+
+// BulkAction is what a bulk operation does to each of its todos
+type BulkAction string
+
+const (
+	BulkComplete    BulkAction = "complete"
+	BulkUncomplete  BulkAction = "uncomplete"
+	BulkDelete      BulkAction = "delete"
+	BulkSetPriority BulkAction = "set_priority"
+	BulkSetDueDate  BulkAction = "set_due_date"
+	BulkMove        BulkAction = "move"
+)
+
+// BulkTodoOperation applies one action to many todos
+type BulkTodoOperation struct {
+	Action BulkAction
+	IDs    []int
+	// Priority is required for set_priority
+	Priority *int
+	// DueDate is the new due date for set_due_date, nil clears it
+	DueDate *time.Time
+	// ProjectID is the destination of move, nil takes the todos out of their project
+	ProjectID *int
+	// SubtaskMode treats the open subtasks of todos being completed as for a toggle
+	SubtaskMode domain.SubtaskCompletionMode
+	// Force lets complete finish todos whose blockers are still open
+	Force bool
+}
+
+// BulkTodoItem is the outcome for one todo of a bulk operation
+type BulkTodoItem struct {
+	ID int
+	// Changed is false when the todo already was in the requested state
+	Changed bool
+	// Todo is the todo after the operation; nil when deleted or on error
+	Todo *domain.Todo
+	Err  error
+}
+
+// BulkTodoResult lists the items in request order. The operation runs in one
+// transaction: when any item fails, Applied is false and nothing is changed.
+type BulkTodoResult struct {
+	Applied bool
+	Items   []*BulkTodoItem
+}
+
+// TodoChange is a change to one todo prepared by the use case and saved by
+// TodoRepository.ApplyTodoChanges
+type TodoChange struct {
+	// Todo holds the new state, with the Version it was read at
+	Todo   *domain.Todo
+	Action domain.TodoEventAction
+	// Delete moves the todo to the trash instead of saving it
+	Delete bool
+	// Next is the following occurrence to create when a recurring todo is completed
+	Next *domain.Todo
+	// CompleteSubtasks completes the todo's open subtasks with it
+	CompleteSubtasks bool
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"sort"
+	"todo-app/internal/domain"
+)
+
+// TodoDependency is an edge of the dependency graph: BlockerID has to be completed before BlockedID
+type TodoDependency struct {
+	BlockerID int
+	BlockedID int
+}
+
+// TodoGraph is the dependency graph of a user's todos outside the trash
+type TodoGraph struct {
+	// Todos are the todos with at least one dependency, in the manual order
+	Todos        []*domain.Todo
+	Dependencies []TodoDependency
+	// Order lists the open todos so that every todo comes after its open blockers, in waves:
+	// first the todos that can be done now, then those that only wait for the first wave,
+	// and so on. Each wave is in the manual order.
+	Order []int
+}
+
+// newTodoGraph builds the graph of todos, which have their dependencies attached and
+// include both ends of each of them
+func newTodoGraph(todos []*domain.Todo) *TodoGraph {
+	graph := &TodoGraph{
+		Todos:        todos,
+		Dependencies: []TodoDependency{},
+		Order:        []int{},
+	}
+
+	index := make(map[int]int, len(todos))
+	for i, todo := range todos {
+		index[todo.ID] = i
+	}
+
+	// waiting counts the open blockers of every open todo not yet placed in the order
+	waiting := make(map[int]int)
+	var wave []int
+	for _, todo := range todos {
+		for _, blockerID := range todo.BlockedBy {
+			graph.Dependencies = append(graph.Dependencies, TodoDependency{BlockerID: blockerID, BlockedID: todo.ID})
+		}
+		if todo.IsCompleted {
+			continue
+		}
+		waiting[todo.ID] = len(todo.OpenBlockers)
+		if !todo.IsBlocked() {
+			wave = append(wave, todo.ID)
+		}
+	}
+
+	for len(wave) > 0 {
+		graph.Order = append(graph.Order, wave...)
+
+		var next []int
+		for _, id := range wave {
+			for _, blockedID := range todos[index[id]].Blocks {
+				if _, open := waiting[blockedID]; !open {
+					continue
+				}
+				waiting[blockedID]--
+				if waiting[blockedID] == 0 {
+					next = append(next, blockedID)
+				}
+			}
+		}
+		sort.Slice(next, func(i, j int) bool {
+			return index[next[i]] < index[next[j]]
+		})
+		wave = next
+	}
+
+	return graph
+}
+
+// blockedOutside reports whether the todo waits for an open todo that is not among ids
+func blockedOutside(todo *domain.Todo, ids []int) bool {
+	for _, blockerID := range todo.OpenBlockers {
+		found := false
+		for _, id := range ids {
+			if id == blockerID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+// graphTodos builds the todos listed in order with their dependencies attached,
+// each edge being a blocker and the todo it blocks
+func graphTodos(order []int, edges [][2]int, done map[int]bool) []*domain.Todo {
+	todos := make(map[int]*domain.Todo, len(order))
+	list := make([]*domain.Todo, len(order))
+	for i, id := range order {
+		todos[id] = &domain.Todo{ID: id, IsCompleted: done[id]}
+		list[i] = todos[id]
+	}
+	for _, edge := range edges {
+		blocker, blocked := todos[edge[0]], todos[edge[1]]
+		blocked.BlockedBy = append(blocked.BlockedBy, blocker.ID)
+		blocker.Blocks = append(blocker.Blocks, blocked.ID)
+		if !blocker.IsCompleted {
+			blocked.OpenBlockers = append(blocked.OpenBlockers, blocker.ID)
+		}
+	}
+	return list
+}
+
+func TestNewTodoGraph(t *testing.T) {
+	tests := []struct {
+		name      string
+		order     []int
+		edges     [][2]int
+		done      map[int]bool
+		wantOrder []int
+	}{
+		{
+			name:      "chain",
+			order:     []int{3, 2, 1},
+			edges:     [][2]int{{1, 2}, {2, 3}},
+			wantOrder: []int{1, 2, 3},
+		},
+		{
+			name:      "a todo waits for all of its blockers",
+			order:     []int{3, 2, 1, 4},
+			edges:     [][2]int{{1, 3}, {2, 3}, {3, 4}},
+			wantOrder: []int{2, 1, 3, 4},
+		},
+		{
+			name:      "each wave is in the manual order",
+			order:     []int{4, 3, 1, 2},
+			edges:     [][2]int{{1, 3}, {2, 4}},
+			wantOrder: []int{1, 2, 4, 3},
+		},
+		{
+			name:      "a todo waits for the last of its blockers to be placed",
+			order:     []int{1, 2, 3, 4},
+			edges:     [][2]int{{1, 2}, {2, 4}, {3, 4}},
+			wantOrder: []int{1, 3, 2, 4},
+		},
+		{
+			name:      "completed todos are left out and hold nothing back",
+			order:     []int{1, 2, 3},
+			edges:     [][2]int{{1, 2}, {2, 3}},
+			done:      map[int]bool{2: true},
+			wantOrder: []int{1, 3},
+		},
+		{
+			name:      "no open todos",
+			order:     []int{1, 2},
+			edges:     [][2]int{{1, 2}},
+			done:      map[int]bool{1: true, 2: true},
+			wantOrder: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := newTodoGraph(graphTodos(tt.order, tt.edges, tt.done))
+			if !reflect.DeepEqual(graph.Order, tt.wantOrder) {
+				t.Errorf("Order = %v, want %v", graph.Order, tt.wantOrder)
+			}
+			if len(graph.Dependencies) != len(tt.edges) {
+				t.Errorf("Dependencies = %v, want %d of them", graph.Dependencies, len(tt.edges))
+			}
+			for _, dependency := range graph.Dependencies {
+				found := false
+				for _, edge := range tt.edges {
+					found = found || (edge[0] == dependency.BlockerID && edge[1] == dependency.BlockedID)
+				}
+				if !found {
+					t.Errorf("unexpected dependency %+v", dependency)
+				}
+			}
+		})
+	}
+}
+
+func TestBlockedOutside(t *testing.T) {
+	tests := []struct {
+		name         string
+		openBlockers []int
+		ids          []int
+		want         bool
+	}{
+		{name: "no open blockers", ids: []int{1}, want: false},
+		{name: "blockers completed in the same request", openBlockers: []int{2, 3}, ids: []int{1, 2, 3}, want: false},
+		{name: "one blocker outside", openBlockers: []int{2, 3}, ids: []int{1, 2}, want: true},
+		{name: "only outside", openBlockers: []int{4}, ids: []int{1}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := &domain.Todo{ID: 1, BlockedBy: tt.openBlockers, OpenBlockers: tt.openBlockers}
+			if got := blockedOutside(todo, tt.ids); got != tt.want {
+				t.Errorf("blockedOutside(%v, %v) = %v, want %v", tt.openBlockers, tt.ids, got, tt.want)
+			}
+		})
+	}
+}
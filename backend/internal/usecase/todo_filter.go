@@ -0,0 +1,45 @@
+package usecase
+
+import "time"
+
+// TodoFilter narrows down the todos returned by GetTodos.
+// The zero value matches every todo of the user, and all conditions are combined with AND.
+// Ranges are half-open: a From bound is inclusive and a Before bound is exclusive.
+type TodoFilter struct {
+	// TagIDs keeps todos that have the given tags
+	TagIDs []int
+	// MatchAllTags requires every tag in TagIDs instead of any of them
+	MatchAllTags bool
+	// ProjectID keeps todos of the given project when set
+	ProjectID *int
+	// StatusID keeps todos in the given status when set
+	StatusID *int
+
+	// Completed keeps only completed (true) or open (false) todos when set
+	Completed *bool
+	// PriorityMin and PriorityMax are inclusive bounds
+	PriorityMin *int
+	PriorityMax *int
+
+	// NoDueDate keeps only todos without a due date
+	NoDueDate bool
+	// DueFrom and DueBefore are dates
+	DueFrom   *time.Time
+	DueBefore *time.Time
+	// OverdueAt keeps todos past due at that moment: those with a due time before it,
+	// and the others due before its date in its time zone
+	OverdueAt *time.Time
+
+	CreatedFrom   *time.Time
+	CreatedBefore *time.Time
+	UpdatedFrom   *time.Time
+	UpdatedBefore *time.Time
+
+	// AwakeAt leaves out todos that are snoozed at that moment,
+	// and SnoozedAt keeps only those
+	AwakeAt   *time.Time
+	SnoozedAt *time.Time
+
+	// TextTerms must all appear in the title, case-insensitively
+	TextTerms []string
+}
@@ -2,30 +2,145 @@ package usecase
 
 import (
 	"context"
+	"strings"
+	"time"
 	"todo-app/internal/domain"
+	"unicode/utf8"
 )
 
 type TodoUseCase interface {
 	CreateTodo(ctx context.Context, userID int, todo *domain.Todo) error
+	QuickAddTodo(ctx context.Context, userID int, line string, dryRun bool) (*QuickAdd, *domain.Todo, error)
 	GetTodo(ctx context.Context, userID int, todoID int) (*domain.Todo, error)
-	GetTodos(ctx context.Context, userID int, sortBy string) ([]*domain.Todo, error)
-	UpdateTodo(ctx context.Context, userID int, todo *domain.Todo) error
-	DeleteTodo(ctx context.Context, userID int, todoID int) error
-	ToggleTodoComplete(ctx context.Context, userID int, todoID int) (*domain.Todo, error)
+	GetTodos(ctx context.Context, userID int, sortBy string, filter TodoFilter) ([]*domain.Todo, error)
+	GetTodoPage(ctx context.Context, userID int, sortBy string, filter TodoFilter, limit int, cursor string) (*TodoPage, error)
+	SearchTodos(ctx context.Context, userID int, query string, limit int) ([]*TodoSearchResult, error)
+	UpdateTodo(ctx context.Context, userID int, todo *domain.Todo, opts ToggleOptions) error
+	DeleteTodo(ctx context.Context, userID int, todoID int, version *int) error
+	GetTrash(ctx context.Context, userID int) ([]*domain.Todo, error)
+	RestoreTodo(ctx context.Context, userID int, todoID int) (*domain.Todo, error)
+	PurgeTodo(ctx context.Context, userID int, todoID int) error
+	ToggleTodoComplete(ctx context.Context, userID int, todoID int, opts ToggleOptions) (*domain.Todo, error)
+	PreviewOccurrences(ctx context.Context, userID int, todoID int, count int) ([]time.Time, error)
+	StopRecurrence(ctx context.Context, userID int, todoID int) (*domain.Todo, error)
+	MoveTodo(ctx context.Context, userID int, todoID int, version *int, afterID *int, beforeID *int) (*domain.Todo, error)
+	SnoozeTodo(ctx context.Context, userID int, todoID int, until string, version *int) (*domain.Todo, error)
+	UnsnoozeTodo(ctx context.Context, userID int, todoID int, version *int) (*domain.Todo, error)
+	TransitionTodo(ctx context.Context, userID int, todoID int, statusID int, opts ToggleOptions) (*domain.Todo, error)
+	AddTodoBlocker(ctx context.Context, userID int, todoID int, blockerID int) (*domain.Todo, error)
+	RemoveTodoBlocker(ctx context.Context, userID int, todoID int, blockerID int) error
+	GetTodoGraph(ctx context.Context, userID int) (*TodoGraph, error)
+	GetTodoHistory(ctx context.Context, userID int, todoID int) ([]*domain.TodoEvent, error)
+	Undo(ctx context.Context, userID int, count int) (*UndoResult, error)
+	BulkUpdateTodos(ctx context.Context, userID int, op BulkTodoOperation) (*BulkTodoResult, error)
+	GetUserLocation(ctx context.Context, userID int) (*time.Location, error)
+}
+
+// ToggleOptions controls side effects of completing or reopening a todo,
+// by a toggle, a transition or an update
+type ToggleOptions struct {
+	SubtaskMode domain.SubtaskCompletionMode
+	// Version, when set, is the version the caller expects the todo to be at (If-Match)
+	Version *int
+	// Force completes the todo even while its blockers are open
+	Force bool
 }
 
 type TodoInteractor struct {
-	todoRepo TodoRepository
+	todoRepo    TodoRepository
+	tagRepo     TagRepository
+	projectRepo ProjectRepository
+	statusRepo  StatusRepository
+	userRepo    UserRepository
 }
 
-func NewTodoInteractor(todoRepo TodoRepository) TodoUseCase {
+func NewTodoInteractor(todoRepo TodoRepository, tagRepo TagRepository, projectRepo ProjectRepository, statusRepo StatusRepository, userRepo UserRepository) TodoUseCase {
 	return &TodoInteractor{
-		todoRepo: todoRepo,
+		todoRepo:    todoRepo,
+		tagRepo:     tagRepo,
+		projectRepo: projectRepo,
+		statusRepo:  statusRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// GetUserLocation returns the user's time zone, which decides what "today" is for them
+func (ti *TodoInteractor) GetUserLocation(ctx context.Context, userID int) (*time.Location, error) {
+	user, err := ti.userRepo.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, domain.ErrUserNotFound
 	}
+	return user.Location(), nil
+}
+
+// userNow is the current time in the user's time zone
+func (ti *TodoInteractor) userNow(ctx context.Context, userID int) (time.Time, error) {
+	loc, err := ti.GetUserLocation(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().In(loc), nil
+}
+
+// ensureProjectOwner checks that the project, when given, belongs to the user
+func (ti *TodoInteractor) ensureProjectOwner(ctx context.Context, userID int, projectID *int) error {
+	if projectID == nil {
+		return nil
+	}
+	if _, err := ti.projectRepo.GetProject(ctx, userID, *projectID); err != nil {
+		return domain.ErrProjectNotFound
+	}
+	return nil
+}
+
+// ensureStatusOwner checks that the status, when given, belongs to the user
+func (ti *TodoInteractor) ensureStatusOwner(ctx context.Context, userID int, statusID *int) error {
+	if statusID == nil {
+		return nil
+	}
+	if _, err := ti.statusRepo.GetStatus(ctx, userID, *statusID); err != nil {
+		return domain.ErrStatusNotFound
+	}
+	return nil
+}
+
+// resolveTags replaces the tag references on the todo with the user's stored tags.
+// Only tag IDs need to be set by the caller.
+func (ti *TodoInteractor) resolveTags(ctx context.Context, userID int, todo *domain.Todo) error {
+	if len(todo.Tags) == 0 {
+		return nil
+	}
+
+	seen := make(map[int]bool, len(todo.Tags))
+	tagIDs := make([]int, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tagIDs = append(tagIDs, tag.ID)
+		}
+	}
+
+	tags, err := ti.tagRepo.GetTagsByIDs(ctx, userID, tagIDs)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "タグの取得に失敗しました", 500)
+	}
+	if len(tags) != len(tagIDs) {
+		return domain.ErrTagNotFound
+	}
+
+	todo.Tags = tags
+	return nil
 }
 
 func (ti *TodoInteractor) CreateTodo(ctx context.Context, userID int, todo *domain.Todo) error {
 	todo.UserID = userID
+	if err := ti.ensureProjectOwner(ctx, userID, todo.ProjectID); err != nil {
+		return err
+	}
+	if err := ti.resolveTags(ctx, userID, todo); err != nil {
+		return err
+	}
+
 	err := ti.todoRepo.CreateTodo(ctx, userID, todo)
 	if err != nil {
 		return domain.WrapError(err, "DATABASE_ERROR", "Todoの作成に失敗しました", 500)
@@ -33,6 +148,32 @@ func (ti *TodoInteractor) CreateTodo(ctx context.Context, userID int, todo *doma
 	return nil
 }
 
+// QuickAddTodo creates the todo written in one line, see ParseQuickAdd. With dryRun the
+// line is only parsed, and no todo is created or returned.
+func (ti *TodoInteractor) QuickAddTodo(ctx context.Context, userID int, line string, dryRun bool) (*QuickAdd, *domain.Todo, error) {
+	now, err := ti.userNow(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	parsed, err := ParseQuickAdd(line, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dryRun {
+		return parsed, nil, nil
+	}
+
+	todo := &domain.Todo{
+		Title:    parsed.Title,
+		Priority: parsed.Priority,
+	}
+	todo.SetDue(parsed.DueDate, parsed.DueTime, now.Location())
+	if err := ti.CreateTodo(ctx, userID, todo); err != nil {
+		return nil, nil, err
+	}
+	return parsed, todo, nil
+}
+
 func (ti *TodoInteractor) GetTodo(ctx context.Context, userID int, todoID int) (*domain.Todo, error) {
 	todo, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
 	if err != nil {
@@ -41,34 +182,769 @@ func (ti *TodoInteractor) GetTodo(ctx context.Context, userID int, todoID int) (
 	return todo, nil
 }
 
-func (ti *TodoInteractor) GetTodos(ctx context.Context, userID int, sortBy string) ([]*domain.Todo, error) {
-	todos, err := ti.todoRepo.GetTodos(ctx, userID, sortBy)
+func (ti *TodoInteractor) GetTodos(ctx context.Context, userID int, sortBy string, filter TodoFilter) ([]*domain.Todo, error) {
+	if err := ti.ensureProjectOwner(ctx, userID, filter.ProjectID); err != nil {
+		return nil, err
+	}
+	if err := ti.ensureStatusOwner(ctx, userID, filter.StatusID); err != nil {
+		return nil, err
+	}
+
+	todos, err := ti.todoRepo.GetTodos(ctx, userID, sortBy, filter)
 	if err != nil {
 		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todo一覧の取得に失敗しました", 500)
 	}
 	return todos, nil
 }
 
-func (ti *TodoInteractor) UpdateTodo(ctx context.Context, userID int, todo *domain.Todo) error {
-	err := ti.todoRepo.UpdateTodo(ctx, userID, todo)
+// GetTodoPage lists the todos a page at a time. limit 0 means DefaultTodoPageSize,
+// and cursor is the NextCursor of the previous page or empty for the first one.
+func (ti *TodoInteractor) GetTodoPage(ctx context.Context, userID int, sortBy string, filter TodoFilter, limit int, cursor string) (*TodoPage, error) {
+	if limit == 0 {
+		limit = DefaultTodoPageSize
+	}
+	if limit < 0 || limit > MaxTodoPageSize {
+		return nil, domain.ErrInvalidPageLimit
+	}
+
+	var after *TodoSortKey
+	if cursor != "" {
+		key, err := decodeTodoCursor(cursor, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		after = key
+	}
+
+	if err := ti.ensureProjectOwner(ctx, userID, filter.ProjectID); err != nil {
+		return nil, err
+	}
+	if err := ti.ensureStatusOwner(ctx, userID, filter.StatusID); err != nil {
+		return nil, err
+	}
+
+	todos, next, err := ti.todoRepo.GetTodoPage(ctx, userID, sortBy, filter, limit, after)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todo一覧の取得に失敗しました", 500)
+	}
+
+	total, err := ti.todoRepo.CountTodos(ctx, userID, filter)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todo件数の取得に失敗しました", 500)
+	}
+
+	page := &TodoPage{Todos: todos, Total: total}
+	if next != nil {
+		page.NextCursor = encodeTodoCursor(sortBy, *next)
+	}
+	return page, nil
+}
+
+// SearchTodos ranks the user's todos by how well their titles match query.
+// limit 0 means DefaultTodoSearchLimit.
+func (ti *TodoInteractor) SearchTodos(ctx context.Context, userID int, query string, limit int) ([]*TodoSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, domain.ErrInvalidSearchQuery
+	}
+	if limit == 0 {
+		limit = DefaultTodoSearchLimit
+	}
+	if limit < 0 || limit > MaxTodoPageSize {
+		return nil, domain.ErrInvalidPageLimit
+	}
+
+	terms := searchTerms(query)
+	results, err := ti.todoRepo.SearchTodos(ctx, userID, query, terms, limit)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの検索に失敗しました", 500)
+	}
+
+	for _, result := range results {
+		result.Snippet = highlightSnippet(result.Todo.Title, terms)
+	}
+	return results, nil
+}
+
+// UpdateTodo saves the todo, which holds the version it was read at. Completing or reopening it
+// follows the rules of a toggle with opts: the todo moves to the first status of its new kind,
+// open subtasks and blockers are checked, and a recurring todo moves on to its next occurrence,
+// which the todo then carries.
+func (ti *TodoInteractor) UpdateTodo(ctx context.Context, userID int, todo *domain.Todo, opts ToggleOptions) error {
+	if err := ti.ensureProjectOwner(ctx, userID, todo.ProjectID); err != nil {
+		return err
+	}
+	if err := ti.ensureStatusOwner(ctx, userID, todo.StatusID); err != nil {
+		return err
+	}
+	if err := ti.resolveTags(ctx, userID, todo); err != nil {
+		return err
+	}
+
+	current, err := ti.todoRepo.GetTodo(ctx, userID, todo.ID)
+	if err != nil {
+		return domain.ErrTodoNotFound
+	}
+	if current.Version != todo.Version {
+		return domain.ErrTodoVersionMismatch
+	}
+
+	change := &TodoChange{Todo: todo, Action: domain.TodoEventUpdate}
+	if todo.IsCompleted != current.IsCompleted {
+		statuses, err := ti.statusRepo.GetStatuses(ctx, userID)
+		if err != nil {
+			return domain.WrapError(err, "DATABASE_ERROR", "ステータス一覧の取得に失敗しました", 500)
+		}
+		if err := setCompletionStatus(statuses, current, todo); err != nil {
+			return err
+		}
+		if err := ti.checkCompletion(ctx, userID, current, change, opts); err != nil {
+			return err
+		}
+	}
+
+	err = ti.todoRepo.ApplyTodoChanges(ctx, userID, []*TodoChange{change})
+	if err == domain.ErrTodoVersionMismatch || err == domain.ErrWIPLimitReached || err == domain.ErrStatusNotFound {
+		return err
+	}
 	if err != nil {
 		return domain.WrapError(err, "DATABASE_ERROR", "Todoの更新に失敗しました", 500)
 	}
+
+	// Completing the subtasks touches the todo once more, so re-read its progress and version
+	if change.CompleteSubtasks {
+		saved, err := ti.todoRepo.GetTodo(ctx, userID, todo.ID)
+		if err != nil {
+			return domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+		}
+		*todo = *saved
+	}
+	if change.Next != nil {
+		todo.NextOccurrence, err = ti.todoRepo.GetTodo(ctx, userID, change.Next.ID)
+		if err != nil {
+			return domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+		}
+	}
 	return nil
 }
 
-func (ti *TodoInteractor) DeleteTodo(ctx context.Context, userID int, todoID int) error {
-	err := ti.todoRepo.DeleteTodo(ctx, userID, todoID)
+// DeleteTodo moves the todo to the trash. A non-nil version must match the stored one.
+func (ti *TodoInteractor) DeleteTodo(ctx context.Context, userID int, todoID int, version *int) error {
+	err := ti.todoRepo.DeleteTodo(ctx, userID, todoID, version)
+	if err == domain.ErrTodoNotFound || err == domain.ErrTodoVersionMismatch {
+		return err
+	}
 	if err != nil {
 		return domain.WrapError(err, "DATABASE_ERROR", "Todoの削除に失敗しました", 500)
 	}
 	return nil
 }
 
-func (ti *TodoInteractor) ToggleTodoComplete(ctx context.Context, userID int, todoID int) (*domain.Todo, error) {
-	todo, err := ti.todoRepo.ToggleTodoComplete(ctx, userID, todoID)
+func (ti *TodoInteractor) GetTrash(ctx context.Context, userID int) ([]*domain.Todo, error) {
+	todos, err := ti.todoRepo.GetTrashedTodos(ctx, userID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "ゴミ箱の取得に失敗しました", 500)
+	}
+	return todos, nil
+}
+
+// RestoreTodo takes the todo out of the trash
+func (ti *TodoInteractor) RestoreTodo(ctx context.Context, userID int, todoID int) (*domain.Todo, error) {
+	restored, err := ti.todoRepo.RestoreTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの復元に失敗しました", 500)
+	}
+	if !restored {
+		return nil, domain.ErrTodoNotInTrash
+	}
+
+	todo, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+	}
+	return todo, nil
+}
+
+// PurgeTodo permanently deletes a todo that is in the trash
+func (ti *TodoInteractor) PurgeTodo(ctx context.Context, userID int, todoID int) error {
+	purged, err := ti.todoRepo.PurgeTodo(ctx, userID, todoID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "Todoの完全削除に失敗しました", 500)
+	}
+	if !purged {
+		return domain.ErrTodoNotInTrash
+	}
+	return nil
+}
+
+// ToggleTodoComplete completes or reopens the todo. Like a transition, it moves the todo to the
+// first status of its new kind, which its current status has to allow and which must not be at its WIP limit.
+func (ti *TodoInteractor) ToggleTodoComplete(ctx context.Context, userID int, todoID int, opts ToggleOptions) (*domain.Todo, error) {
+	current, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+	if opts.Version != nil && *opts.Version != current.Version {
+		return nil, domain.ErrTodoVersionMismatch
+	}
+
+	statuses, err := ti.statusRepo.GetStatuses(ctx, userID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "ステータス一覧の取得に失敗しました", 500)
+	}
+	todo := *current
+	todo.IsCompleted = !current.IsCompleted
+	if err := setCompletionStatus(statuses, current, &todo); err != nil {
+		return nil, err
+	}
+	change := &TodoChange{Todo: &todo, Action: domain.TodoEventToggle}
+
+	if err := ti.checkCompletion(ctx, userID, current, change, opts); err != nil {
+		return nil, err
+	}
+	return ti.saveStatusChange(ctx, userID, change, "Todoの状態変更に失敗しました")
+}
+
+// PreviewOccurrences lists the due dates of the next count occurrences of a recurring todo
+func (ti *TodoInteractor) PreviewOccurrences(ctx context.Context, userID int, todoID int, count int) ([]time.Time, error) {
+	todo, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
 	if err != nil {
-		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの状態変更に失敗しました", 500)
+		return nil, domain.ErrTodoNotFound
+	}
+	if todo.Recurrence == nil {
+		return nil, domain.ErrTodoNotRecurring
+	}
+
+	now, err := ti.userNow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return todo.Recurrence.Occurrences(recurrenceBase(todo, now), count), nil
+}
+
+// StopRecurrence ends the series; the todo itself is kept as a one-off todo
+func (ti *TodoInteractor) StopRecurrence(ctx context.Context, userID int, todoID int) (*domain.Todo, error) {
+	todo, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+	if todo.Recurrence == nil {
+		return nil, domain.ErrTodoNotRecurring
+	}
+
+	if err := ti.todoRepo.StopRecurrence(ctx, userID, todoID); err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "繰り返し設定の解除に失敗しました", 500)
+	}
+
+	// Re-read so that the todo carries its new version
+	todo, err = ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
 	}
 	return todo, nil
 }
+
+// MoveTodo places the todo after afterID and/or before beforeID in the manual order (sort=manual).
+// At least one anchor is required, and a non-nil version must match the stored one.
+func (ti *TodoInteractor) MoveTodo(ctx context.Context, userID int, todoID int, version *int, afterID *int, beforeID *int) (*domain.Todo, error) {
+	if (afterID == nil && beforeID == nil) || sameID(afterID, &todoID) || sameID(beforeID, &todoID) {
+		return nil, domain.ErrInvalidTodoMove
+	}
+	for _, id := range []*int{&todoID, afterID, beforeID} {
+		if id == nil {
+			continue
+		}
+		if _, err := ti.todoRepo.GetTodo(ctx, userID, *id); err != nil {
+			return nil, domain.ErrTodoNotFound
+		}
+	}
+
+	err := ti.todoRepo.MoveTodo(ctx, userID, todoID, version, afterID, beforeID)
+	if err == domain.ErrTodoVersionMismatch || err == domain.ErrInvalidTodoMove {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの移動に失敗しました", 500)
+	}
+
+	todo, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+	}
+	return todo, nil
+}
+
+// SnoozeTodo hides the todo from the default todo list until the end of the snooze,
+// see ParseSnoozeUntil. A non-nil version must match the stored one.
+func (ti *TodoInteractor) SnoozeTodo(ctx context.Context, userID int, todoID int, until string, version *int) (*domain.Todo, error) {
+	now, err := ti.userNow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseSnoozeUntil(until, now)
+	if err != nil {
+		return nil, err
+	}
+	return ti.setSnooze(ctx, userID, todoID, &end, version)
+}
+
+// UnsnoozeTodo brings a snoozed todo back to the default todo list right away
+func (ti *TodoInteractor) UnsnoozeTodo(ctx context.Context, userID int, todoID int, version *int) (*domain.Todo, error) {
+	return ti.setSnooze(ctx, userID, todoID, nil, version)
+}
+
+func (ti *TodoInteractor) setSnooze(ctx context.Context, userID int, todoID int, until *time.Time, version *int) (*domain.Todo, error) {
+	todo, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+	if version != nil && *version != todo.Version {
+		return nil, domain.ErrTodoVersionMismatch
+	}
+
+	todo.SnoozedUntil = until
+	err = ti.todoRepo.UpdateTodo(ctx, userID, todo)
+	if err == domain.ErrTodoVersionMismatch {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoのスヌーズに失敗しました", 500)
+	}
+	return todo, nil
+}
+
+// TransitionTodo moves the todo to another of the user's statuses. The current status has
+// to allow the move, and the new one must not be at its WIP limit. Moving into a done status
+// completes the todo like a toggle: opts decides about open subtasks and blockers, and a recurring
+// todo moves on to its next occurrence. Moving out of one reopens it.
+func (ti *TodoInteractor) TransitionTodo(ctx context.Context, userID int, todoID int, statusID int, opts ToggleOptions) (*domain.Todo, error) {
+	current, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+	if opts.Version != nil && *opts.Version != current.Version {
+		return nil, domain.ErrTodoVersionMismatch
+	}
+
+	statuses, err := ti.statusRepo.GetStatuses(ctx, userID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "ステータス一覧の取得に失敗しました", 500)
+	}
+	to := findStatus(statuses, statusID)
+	if to == nil {
+		return nil, domain.ErrStatusNotFound
+	}
+	if current.StatusID != nil {
+		if *current.StatusID == statusID {
+			return current, nil
+		}
+		if from := findStatus(statuses, *current.StatusID); from != nil && !from.AllowsTransitionTo(statusID) {
+			return nil, domain.ErrTransitionNotAllowed
+		}
+	}
+
+	todo := *current
+	todo.StatusID = &to.ID
+	todo.IsCompleted = to.IsDone()
+	change := &TodoChange{Todo: &todo, Action: domain.TodoEventTransition}
+
+	if err := ti.checkCompletion(ctx, userID, current, change, opts); err != nil {
+		return nil, err
+	}
+	return ti.saveStatusChange(ctx, userID, change, "Todoのステータス変更に失敗しました")
+}
+
+// setCompletionStatus moves todo, which is being completed or reopened, to the first status
+// of its new kind. The current status has to allow the move.
+func setCompletionStatus(statuses []*domain.Status, current *domain.Todo, todo *domain.Todo) error {
+	for _, to := range statuses {
+		if to.IsDone() != todo.IsCompleted {
+			continue
+		}
+		if current.StatusID != nil {
+			if from := findStatus(statuses, *current.StatusID); from != nil && !from.AllowsTransitionTo(to.ID) {
+				return domain.ErrTransitionNotAllowed
+			}
+		}
+		todo.StatusID = &to.ID
+		return nil
+	}
+	// Every user has a status of each kind; without one, the database picks the status
+	return nil
+}
+
+// checkCompletion applies the rules for completing current to change when it completes the todo:
+// open subtasks follow opts.SubtaskMode, open blockers need opts.Force, and a recurring todo
+// moves on to its next occurrence
+func (ti *TodoInteractor) checkCompletion(ctx context.Context, userID int, current *domain.Todo, change *TodoChange, opts ToggleOptions) error {
+	if !change.Todo.IsCompleted || current.IsCompleted {
+		return nil
+	}
+	if err := completeSubtasks(current, change, opts.SubtaskMode); err != nil {
+		return err
+	}
+	if current.IsBlocked() && !opts.Force {
+		return domain.ErrTodoBlocked
+	}
+
+	// Completing a recurring todo creates its next occurrence instead of ending it
+	if change.Todo.Recurrence != nil {
+		now, err := ti.userNow(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if next := nextOccurrence(change.Todo, now); next != nil {
+			change.Todo.Recurrence = nil
+			change.Next = next
+		}
+	}
+	return nil
+}
+
+// completeSubtasks applies mode to the open subtasks of current, which change completes:
+// cascade completes them with it, and require refuses while any are open
+func completeSubtasks(current *domain.Todo, change *TodoChange, mode domain.SubtaskCompletionMode) error {
+	openSubtasks := current.SubtasksTotal - current.SubtasksDone
+	if openSubtasks > 0 && mode == domain.SubtaskCompletionRequire {
+		return domain.ErrTodoHasOpenSubtasks
+	}
+	change.CompleteSubtasks = openSubtasks > 0 && mode == domain.SubtaskCompletionCascade
+	return nil
+}
+
+// saveStatusChange saves a change of the todo's status and returns the todo as saved,
+// with its next occurrence when one was created
+func (ti *TodoInteractor) saveStatusChange(ctx context.Context, userID int, change *TodoChange, message string) (*domain.Todo, error) {
+	err := ti.todoRepo.ApplyTodoChanges(ctx, userID, []*TodoChange{change})
+	if err == domain.ErrTodoVersionMismatch || err == domain.ErrWIPLimitReached || err == domain.ErrStatusNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", message, 500)
+	}
+
+	// Re-read so that the todo carries its subtask progress and new version
+	result, err := ti.todoRepo.GetTodo(ctx, userID, change.Todo.ID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+	}
+	if change.Next != nil {
+		result.NextOccurrence, err = ti.todoRepo.GetTodo(ctx, userID, change.Next.ID)
+		if err != nil {
+			return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+		}
+	}
+	return result, nil
+}
+
+// AddTodoBlocker makes blockerID a blocker of the todo, which cannot be completed before it
+// without force. Dependencies that would form a cycle are refused.
+func (ti *TodoInteractor) AddTodoBlocker(ctx context.Context, userID int, todoID int, blockerID int) (*domain.Todo, error) {
+	if blockerID == todoID {
+		return nil, domain.ErrInvalidDependency
+	}
+	for _, id := range []int{todoID, blockerID} {
+		if _, err := ti.todoRepo.GetTodo(ctx, userID, id); err != nil {
+			return nil, domain.ErrTodoNotFound
+		}
+	}
+
+	err := ti.todoRepo.AddTodoDependency(ctx, userID, blockerID, todoID)
+	if err == domain.ErrDependencyCycle {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "依存関係の追加に失敗しました", 500)
+	}
+
+	// Re-read so that the todo carries its blockers
+	todo, err := ti.todoRepo.GetTodo(ctx, userID, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+	}
+	return todo, nil
+}
+
+func (ti *TodoInteractor) RemoveTodoBlocker(ctx context.Context, userID int, todoID int, blockerID int) error {
+	if _, err := ti.todoRepo.GetTodo(ctx, userID, todoID); err != nil {
+		return domain.ErrTodoNotFound
+	}
+
+	removed, err := ti.todoRepo.RemoveTodoDependency(ctx, userID, blockerID, todoID)
+	if err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "依存関係の削除に失敗しました", 500)
+	}
+	if !removed {
+		return domain.ErrDependencyNotFound
+	}
+	return nil
+}
+
+// GetTodoGraph returns the user's todos with dependencies and the order to do the open ones in
+func (ti *TodoInteractor) GetTodoGraph(ctx context.Context, userID int) (*TodoGraph, error) {
+	todos, err := ti.todoRepo.GetDependencyTodos(ctx, userID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "依存関係の取得に失敗しました", 500)
+	}
+	return newTodoGraph(todos), nil
+}
+
+// GetTodoHistory lists the recorded changes of the todo, newest first
+func (ti *TodoInteractor) GetTodoHistory(ctx context.Context, userID int, todoID int) ([]*domain.TodoEvent, error) {
+	if _, err := ti.todoRepo.GetTodo(ctx, userID, todoID); err != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+
+	events, err := ti.todoRepo.GetTodoEvents(ctx, todoID)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの履歴の取得に失敗しました", 500)
+	}
+	return events, nil
+}
+
+// recurrenceBase is the date the next occurrence is counted from: the due date,
+// or the completion date for "after completion" rules and todos without a due date.
+// now is in the user's time zone, so that the completion date is their date.
+func recurrenceBase(todo *domain.Todo, now time.Time) time.Time {
+	if todo.Recurrence.FromCompletion || todo.DueDate == nil {
+		return now
+	}
+	return *todo.DueDate
+}
+
+// nextOccurrence builds the todo that follows current in its series,
+// or returns nil when the series has ended. The due time of day carries over.
+func nextOccurrence(current *domain.Todo, now time.Time) *domain.Todo {
+	dueDate, ok := current.Recurrence.Next(recurrenceBase(current, now))
+	if !ok {
+		return nil
+	}
+
+	next := &domain.Todo{
+		UserID:     current.UserID,
+		ProjectID:  current.ProjectID,
+		Title:      current.Title,
+		Notes:      current.Notes,
+		Priority:   current.Priority,
+		Tags:       current.Tags,
+		Recurrence: current.Recurrence.Advance(),
+	}
+	next.SetDue(&dueDate, current.DueTime(now.Location()), now.Location())
+	return next
+}
+
+// Undo reverts the user's latest count edits, toggles and deletes made within UndoWindow.
+// Everything changed by one request counts as one operation.
+func (ti *TodoInteractor) Undo(ctx context.Context, userID int, count int) (*UndoResult, error) {
+	if count < 1 || count > MaxUndoOperations {
+		return nil, domain.ErrInvalidUndoCount
+	}
+
+	operations, todos, err := ti.todoRepo.UndoOperations(ctx, userID, time.Now().Add(-UndoWindow), count)
+	if err == domain.ErrWIPLimitReached {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "操作の取り消しに失敗しました", 500)
+	}
+	return &UndoResult{Operations: operations, Todos: todos}, nil
+}
+
+// BulkUpdateTodos applies op to every listed todo in one transaction. When a todo is missing,
+// cannot be completed for its open subtasks or blockers, or cannot leave its status for the first
+// one of the other kind, the result reports it and nothing is changed.
+func (ti *TodoInteractor) BulkUpdateTodos(ctx context.Context, userID int, op BulkTodoOperation) (*BulkTodoResult, error) {
+	ids, err := validateBulkOperation(op)
+	if err != nil {
+		return nil, err
+	}
+	if op.Action == BulkMove {
+		if err := ti.ensureProjectOwner(ctx, userID, op.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	now, err := ti.userNow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []*domain.Status
+	if op.Action == BulkComplete || op.Action == BulkUncomplete {
+		statuses, err = ti.statusRepo.GetStatuses(ctx, userID)
+		if err != nil {
+			return nil, domain.WrapError(err, "DATABASE_ERROR", "ステータス一覧の取得に失敗しました", 500)
+		}
+	}
+	result := &BulkTodoResult{Items: make([]*BulkTodoItem, len(ids))}
+	var changes []*TodoChange
+	failed := false
+	for i, id := range ids {
+		item := &BulkTodoItem{ID: id}
+		result.Items[i] = item
+
+		current, err := ti.todoRepo.GetTodo(ctx, userID, id)
+		if err != nil {
+			item.Err = domain.ErrTodoNotFound
+			failed = true
+			continue
+		}
+
+		// Blockers completed by the same operation do not hold a todo back
+		if op.Action == BulkComplete && !op.Force && !current.IsCompleted && blockedOutside(current, ids) {
+			item.Err = domain.ErrTodoBlocked
+			failed = true
+			continue
+		}
+
+		change, err := bulkChange(op, current, statuses, now)
+		if err != nil {
+			item.Err = err
+			failed = true
+			continue
+		}
+		item.Todo = current
+		if change != nil {
+			item.Changed = true
+			changes = append(changes, change)
+		}
+	}
+	if failed {
+		for _, item := range result.Items {
+			item.Changed, item.Todo = false, nil
+		}
+		return result, nil
+	}
+
+	err = ti.todoRepo.ApplyTodoChanges(ctx, userID, changes)
+	if err == domain.ErrTodoVersionMismatch || err == domain.ErrWIPLimitReached {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの一括更新に失敗しました", 500)
+	}
+
+	result.Applied = true
+	for _, item := range result.Items {
+		if !item.Changed {
+			continue
+		}
+		item.Todo = nil
+		if op.Action == BulkDelete {
+			continue
+		}
+		// Re-read so that the todos carry their tags, subtask progress and new version
+		todo, err := ti.todoRepo.GetTodo(ctx, userID, item.ID)
+		if err != nil {
+			return nil, domain.WrapError(err, "DATABASE_ERROR", "Todoの取得に失敗しました", 500)
+		}
+		item.Todo = todo
+	}
+	return result, nil
+}
+
+// validateBulkOperation checks op and returns its IDs without duplicates
+func validateBulkOperation(op BulkTodoOperation) ([]int, error) {
+	switch op.Action {
+	case BulkUncomplete, BulkDelete, BulkSetDueDate, BulkMove:
+	case BulkComplete:
+		switch op.SubtaskMode {
+		case domain.SubtaskCompletionIgnore, domain.SubtaskCompletionCascade, domain.SubtaskCompletionRequire:
+		default:
+			return nil, domain.ErrInvalidCompletionMode
+		}
+	case BulkSetPriority:
+		if op.Priority == nil || *op.Priority < 0 || *op.Priority > 2 {
+			return nil, domain.ErrInvalidPriority
+		}
+	default:
+		return nil, domain.ErrInvalidBulkAction
+	}
+
+	seen := make(map[int]bool, len(op.IDs))
+	ids := make([]int, 0, len(op.IDs))
+	for _, id := range op.IDs {
+		if id <= 0 {
+			return nil, domain.ErrInvalidBulkIDs
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > MaxBulkTodos {
+		return nil, domain.ErrInvalidBulkIDs
+	}
+	return ids, nil
+}
+
+// bulkChange prepares the change op makes to current, or returns nil when the todo
+// already is in the requested state. Completing and reopening move the todo between
+// statuses as a toggle does.
+func bulkChange(op BulkTodoOperation, current *domain.Todo, statuses []*domain.Status, now time.Time) (*TodoChange, error) {
+	todo := *current
+	change := &TodoChange{Todo: &todo, Action: domain.TodoEventUpdate}
+
+	switch op.Action {
+	case BulkComplete:
+		if current.IsCompleted {
+			return nil, nil
+		}
+		todo.IsCompleted = true
+		change.Action = domain.TodoEventToggle
+		if err := setCompletionStatus(statuses, current, &todo); err != nil {
+			return nil, err
+		}
+		if err := completeSubtasks(current, change, op.SubtaskMode); err != nil {
+			return nil, err
+		}
+		// As with a single toggle, the recurrence moves on to the next occurrence
+		if current.Recurrence != nil {
+			if next := nextOccurrence(current, now); next != nil {
+				todo.Recurrence = nil
+				change.Next = next
+			}
+		}
+	case BulkUncomplete:
+		if !current.IsCompleted {
+			return nil, nil
+		}
+		todo.IsCompleted = false
+		change.Action = domain.TodoEventToggle
+		if err := setCompletionStatus(statuses, current, &todo); err != nil {
+			return nil, err
+		}
+	case BulkDelete:
+		change.Delete = true
+		change.Action = domain.TodoEventDelete
+	case BulkSetPriority:
+		if current.Priority == *op.Priority {
+			return nil, nil
+		}
+		todo.Priority = *op.Priority
+	case BulkSetDueDate:
+		if sameDate(current.DueDate, op.DueDate) {
+			return nil, nil
+		}
+		todo.Reschedule(op.DueDate, now.Location())
+	case BulkMove:
+		if sameID(current.ProjectID, op.ProjectID) {
+			return nil, nil
+		}
+		todo.ProjectID = op.ProjectID
+	}
+	return change, nil
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,919 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// The user's workflow in these tests: To Do, In Progress (which only leads to Review),
+// Review and Done, which takes one todo at most
+func testStatuses() []*domain.Status {
+	one := 1
+	return []*domain.Status{
+		{ID: 1, Name: "To Do", Category: domain.StatusCategoryTodo, SortOrder: 0},
+		{ID: 2, Name: "In Progress", Category: domain.StatusCategoryInProgress, SortOrder: 1, NextStatusIDs: []int{4}},
+		{ID: 4, Name: "Review", Category: domain.StatusCategoryInProgress, SortOrder: 2},
+		{ID: 3, Name: "Done", Category: domain.StatusCategoryDone, SortOrder: 3, WIPLimit: &one},
+	}
+}
+
+// fakeTodoRepo keeps todos in memory. Like the database, it leaves out trashed todos,
+// checks the WIP limit when a todo is saved into another status, and changes nothing
+// when a save fails.
+type fakeTodoRepo struct {
+	TodoRepository
+	todos    map[int]*domain.Todo
+	statuses []*domain.Status
+	nextID   int
+	// completedSubtasks lists the todos whose subtasks were completed with them
+	completedSubtasks []int
+	// next lists the occurrences created by completing recurring todos
+	next []*domain.Todo
+}
+
+func newFakeTodoRepo(todos ...*domain.Todo) *fakeTodoRepo {
+	r := &fakeTodoRepo{todos: map[int]*domain.Todo{}, nextID: 100}
+	for _, todo := range todos {
+		r.todos[todo.ID] = todo
+	}
+	return r
+}
+
+func (r *fakeTodoRepo) GetTodo(ctx context.Context, userID int, todoID int) (*domain.Todo, error) {
+	todo, ok := r.todos[todoID]
+	if !ok || todo.DeletedAt != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+	copied := *todo
+	return &copied, nil
+}
+
+// CreateTodo stores the todo under the next free ID
+func (r *fakeTodoRepo) CreateTodo(ctx context.Context, userID int, todo *domain.Todo) error {
+	r.nextID++
+	todo.ID = r.nextID
+	created := *todo
+	r.todos[todo.ID] = &created
+	return nil
+}
+
+func (r *fakeTodoRepo) UpdateTodo(ctx context.Context, userID int, todo *domain.Todo) error {
+	return r.save(todo)
+}
+
+func (r *fakeTodoRepo) ApplyTodoChanges(ctx context.Context, userID int, changes []*TodoChange) error {
+	before := make(map[int]*domain.Todo, len(r.todos))
+	for id, todo := range r.todos {
+		copied := *todo
+		before[id] = &copied
+	}
+	for _, change := range changes {
+		if err := r.apply(change); err != nil {
+			r.todos = before
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeTodoRepo) apply(change *TodoChange) error {
+	if change.Delete {
+		return r.DeleteTodo(context.Background(), change.Todo.UserID, change.Todo.ID, &change.Todo.Version)
+	}
+	if err := r.save(change.Todo); err != nil {
+		return err
+	}
+	if change.CompleteSubtasks {
+		// Like the touch_subtasks_todo trigger, completing the subtasks bumps the todo's version again
+		saved := r.todos[change.Todo.ID]
+		saved.SubtasksDone = saved.SubtasksTotal
+		saved.Version++
+		r.completedSubtasks = append(r.completedSubtasks, saved.ID)
+	}
+	if change.Next != nil {
+		r.next = append(r.next, change.Next)
+		return r.CreateTodo(context.Background(), change.Next.UserID, change.Next)
+	}
+	return nil
+}
+
+// save stores todo when it is at the stored version, and bumps the version of both
+func (r *fakeTodoRepo) save(todo *domain.Todo) error {
+	stored, ok := r.todos[todo.ID]
+	if !ok || stored.DeletedAt != nil || stored.Version != todo.Version {
+		return domain.ErrTodoVersionMismatch
+	}
+	if todo.StatusID != nil && !sameID(stored.StatusID, todo.StatusID) {
+		status := findStatus(r.statuses, *todo.StatusID)
+		if status == nil {
+			return domain.ErrStatusNotFound
+		}
+		if status.WIPLimit != nil && r.countInStatus(status.ID) >= *status.WIPLimit {
+			return domain.ErrWIPLimitReached
+		}
+	}
+
+	todo.Version++
+	saved := *todo
+	r.todos[todo.ID] = &saved
+	return nil
+}
+
+func (r *fakeTodoRepo) countInStatus(statusID int) int {
+	count := 0
+	for _, todo := range r.todos {
+		if todo.DeletedAt == nil && todo.StatusID != nil && *todo.StatusID == statusID {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *fakeTodoRepo) StopRecurrence(ctx context.Context, userID int, todoID int) error {
+	r.todos[todoID].Recurrence = nil
+	return nil
+}
+
+func (r *fakeTodoRepo) DeleteTodo(ctx context.Context, userID int, todoID int, version *int) error {
+	todo, ok := r.todos[todoID]
+	if !ok || todo.DeletedAt != nil {
+		return domain.ErrTodoNotFound
+	}
+	if version != nil && *version != todo.Version {
+		return domain.ErrTodoVersionMismatch
+	}
+	now := time.Now()
+	todo.DeletedAt = &now
+	return nil
+}
+
+func (r *fakeTodoRepo) RestoreTodo(ctx context.Context, userID int, todoID int) (bool, error) {
+	todo, ok := r.todos[todoID]
+	if !ok || todo.DeletedAt == nil {
+		return false, nil
+	}
+	todo.DeletedAt = nil
+	return true, nil
+}
+
+func (r *fakeTodoRepo) PurgeTodo(ctx context.Context, userID int, todoID int) (bool, error) {
+	todo, ok := r.todos[todoID]
+	if !ok || todo.DeletedAt == nil {
+		return false, nil
+	}
+	delete(r.todos, todoID)
+	return true, nil
+}
+
+func (r *fakeTodoRepo) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	for id, todo := range r.todos {
+		if todo.DeletedAt != nil && todo.DeletedAt.Before(deletedBefore) {
+			delete(r.todos, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+type fakeStatusRepo struct {
+	StatusRepository
+	statuses []*domain.Status
+}
+
+func (r *fakeStatusRepo) GetStatus(ctx context.Context, userID int, statusID int) (*domain.Status, error) {
+	if status := findStatus(r.statuses, statusID); status != nil {
+		return status, nil
+	}
+	return nil, domain.ErrStatusNotFound
+}
+
+func (r *fakeStatusRepo) GetStatuses(ctx context.Context, userID int) ([]*domain.Status, error) {
+	return r.statuses, nil
+}
+
+func newTestTodoInteractor(todos ...*domain.Todo) (*TodoInteractor, *fakeTodoRepo) {
+	statuses := testStatuses()
+	todoRepo := newFakeTodoRepo(todos...)
+	todoRepo.statuses = statuses
+	interactor := &TodoInteractor{
+		todoRepo:   todoRepo,
+		statusRepo: &fakeStatusRepo{statuses: statuses},
+		userRepo:   &fakeUserRepo{},
+	}
+	return interactor, todoRepo
+}
+
+func TestToggleTodoCompleteSubtaskModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		todo          domain.Todo
+		mode          domain.SubtaskCompletionMode
+		wantErr       error
+		wantCompleted bool
+		// wantCascade is set when the subtasks are to be completed together with the todo
+		wantCascade bool
+	}{
+		{
+			name:          "ignore leaves open subtasks alone",
+			todo:          domain.Todo{ID: 1, SubtasksDone: 1, SubtasksTotal: 3},
+			mode:          domain.SubtaskCompletionIgnore,
+			wantCompleted: true,
+		},
+		{
+			name:          "cascade completes open subtasks",
+			todo:          domain.Todo{ID: 1, SubtasksDone: 1, SubtasksTotal: 3},
+			mode:          domain.SubtaskCompletionCascade,
+			wantCompleted: true,
+			wantCascade:   true,
+		},
+		{
+			name:    "require refuses open subtasks",
+			todo:    domain.Todo{ID: 1, SubtasksDone: 1, SubtasksTotal: 3},
+			mode:    domain.SubtaskCompletionRequire,
+			wantErr: domain.ErrTodoHasOpenSubtasks,
+		},
+		{
+			name:          "require completes a todo whose subtasks are done",
+			todo:          domain.Todo{ID: 1, SubtasksDone: 3, SubtasksTotal: 3},
+			mode:          domain.SubtaskCompletionRequire,
+			wantCompleted: true,
+		},
+		{
+			name:          "require completes a todo without subtasks",
+			todo:          domain.Todo{ID: 1},
+			mode:          domain.SubtaskCompletionRequire,
+			wantCompleted: true,
+		},
+		{
+			name:          "reopening ignores require",
+			todo:          domain.Todo{ID: 1, IsCompleted: true, SubtasksDone: 1, SubtasksTotal: 3},
+			mode:          domain.SubtaskCompletionRequire,
+			wantCompleted: false,
+		},
+		{
+			name:          "reopening does not cascade",
+			todo:          domain.Todo{ID: 1, IsCompleted: true, SubtasksDone: 1, SubtasksTotal: 3},
+			mode:          domain.SubtaskCompletionCascade,
+			wantCompleted: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := tt.todo
+			interactor, todoRepo := newTestTodoInteractor(&todo)
+
+			got, err := interactor.ToggleTodoComplete(context.Background(), 1, todo.ID, ToggleOptions{SubtaskMode: tt.mode})
+			if err != tt.wantErr {
+				t.Fatalf("ToggleTodoComplete() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if todoRepo.todos[todo.ID].IsCompleted != tt.todo.IsCompleted {
+					t.Error("the todo was toggled although the toggle was refused")
+				}
+				return
+			}
+			if got.IsCompleted != tt.wantCompleted {
+				t.Errorf("IsCompleted = %v, want %v", got.IsCompleted, tt.wantCompleted)
+			}
+			if cascaded := len(todoRepo.completedSubtasks) > 0; cascaded != tt.wantCascade {
+				t.Errorf("subtasks completed = %v, want %v", cascaded, tt.wantCascade)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func TestToggleRecurringTodo(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Kiritimati is 14 hours ahead of UTC, so its date is tomorrow's for most of the UTC day
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kiritimatiNow := time.Now().In(kiritimati)
+	kiritimatiToday := time.Date(kiritimatiNow.Year(), kiritimatiNow.Month(), kiritimatiNow.Day(), 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// timezone is the user's time zone, UTC when empty
+		timezone string
+		todo     domain.Todo
+		// wantNext is the due date of the next occurrence, nil when none is created
+		wantNext      *time.Time
+		wantNextAt    *time.Time
+		wantNextCount int
+	}{
+		{
+			name:     "weekly series moves on from the due date",
+			todo:     domain.Todo{ID: 1, DueDate: date(2026, 3, 2), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceWeekly, Interval: 1, ByDay: []time.Weekday{time.Monday, time.Thursday}}},
+			wantNext: date(2026, 3, 5),
+		},
+		{
+			name:          "counted series counts down",
+			todo:          domain.Todo{ID: 1, DueDate: date(2026, 1, 31), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceMonthly, Interval: 1, ByMonthDay: 31, Count: 3}},
+			wantNext:      date(2026, 3, 31),
+			wantNextCount: 2,
+		},
+		{
+			name:     "after completion counts from today",
+			todo:     domain.Todo{ID: 1, DueDate: date(2020, 1, 1), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceDaily, Interval: 3, FromCompletion: true}},
+			wantNext: timePtr(today.AddDate(0, 0, 3)),
+		},
+		{
+			name:     "after completion counts from the user's today",
+			timezone: "Pacific/Kiritimati",
+			todo:     domain.Todo{ID: 1, DueDate: date(2020, 1, 1), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceDaily, Interval: 1, FromCompletion: true}},
+			wantNext: timePtr(kiritimatiToday.AddDate(0, 0, 1)),
+		},
+		{
+			// 2026-03-08 is the day New York moves its clocks forward
+			name:       "due time of day is kept across a clock change",
+			timezone:   "America/New_York",
+			todo:       domain.Todo{ID: 1, DueDate: date(2026, 3, 7), DueAt: timePtr(time.Date(2026, 3, 7, 9, 0, 0, 0, newYork).UTC()), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceDaily, Interval: 1}},
+			wantNext:   date(2026, 3, 8),
+			wantNextAt: timePtr(time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "last occurrence completes the todo",
+			todo: domain.Todo{ID: 1, DueDate: date(2026, 3, 2), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceDaily, Interval: 1, Count: 1}},
+		},
+		{
+			name: "series past its end completes the todo",
+			todo: domain.Todo{ID: 1, DueDate: date(2026, 3, 2), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceDaily, Interval: 1, Until: date(2026, 3, 2)}},
+		},
+		{
+			name: "reopening creates no occurrence",
+			todo: domain.Todo{ID: 1, IsCompleted: true, DueDate: date(2026, 3, 2), Recurrence: &domain.Recurrence{Freq: domain.RecurrenceDaily, Interval: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := tt.todo
+			todo.UserID = 1
+			todo.Title = "water the plants"
+			interactor, todoRepo := newTestTodoInteractor(&todo)
+			interactor.userRepo = &fakeUserRepo{timezone: tt.timezone}
+
+			got, err := interactor.ToggleTodoComplete(context.Background(), 1, todo.ID, ToggleOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.IsCompleted == tt.todo.IsCompleted {
+				t.Error("the todo was not toggled")
+			}
+
+			if tt.wantNext == nil {
+				if len(todoRepo.next) != 0 {
+					t.Fatalf("created %d occurrences, want none", len(todoRepo.next))
+				}
+				return
+			}
+			if len(todoRepo.next) != 1 {
+				t.Fatalf("created %d occurrences, want 1", len(todoRepo.next))
+			}
+			next := todoRepo.next[0]
+			if next.DueDate == nil || !next.DueDate.Equal(*tt.wantNext) {
+				t.Errorf("next due date = %v, want %v", next.DueDate, tt.wantNext)
+			}
+			if (next.DueAt == nil) != (tt.wantNextAt == nil) || next.DueAt != nil && !next.DueAt.Equal(*tt.wantNextAt) {
+				t.Errorf("next due time = %v, want %v", next.DueAt, tt.wantNextAt)
+			}
+			if next.Title != todo.Title || next.UserID != 1 || next.IsCompleted {
+				t.Errorf("next occurrence = %+v, want an open copy of the todo", next)
+			}
+			if next.Recurrence == nil || next.Recurrence.Count != tt.wantNextCount {
+				t.Errorf("next recurrence = %+v, want count %d", next.Recurrence, tt.wantNextCount)
+			}
+			if got.Recurrence != nil {
+				t.Error("the completed occurrence still carries the recurrence")
+			}
+		})
+	}
+}
+
+func TestStopRecurrence(t *testing.T) {
+	ctx := context.Background()
+	todoRepo := newFakeTodoRepo(
+		&domain.Todo{ID: 1, Recurrence: &domain.Recurrence{Freq: domain.RecurrenceDaily, Interval: 1}},
+		&domain.Todo{ID: 2},
+	)
+	interactor := &TodoInteractor{todoRepo: todoRepo, userRepo: &fakeUserRepo{}}
+
+	todo, err := interactor.StopRecurrence(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.Recurrence != nil || todoRepo.todos[1].Recurrence != nil {
+		t.Error("the series was not stopped")
+	}
+	if _, err := interactor.StopRecurrence(ctx, 1, 2); err != domain.ErrTodoNotRecurring {
+		t.Errorf("stopping a one-off todo: got %v, want ErrTodoNotRecurring", err)
+	}
+	if _, err := interactor.PreviewOccurrences(ctx, 1, 2, 3); err != domain.ErrTodoNotRecurring {
+		t.Errorf("previewing a one-off todo: got %v, want ErrTodoNotRecurring", err)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestTrash(t *testing.T) {
+	ctx := context.Background()
+	todoRepo := newFakeTodoRepo(&domain.Todo{ID: 1}, &domain.Todo{ID: 2})
+	interactor := &TodoInteractor{todoRepo: todoRepo, userRepo: &fakeUserRepo{}}
+
+	if err := interactor.DeleteTodo(ctx, 1, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := interactor.DeleteTodo(ctx, 1, 1, nil); err != domain.ErrTodoNotFound {
+		t.Errorf("deleting a trashed todo: got %v, want ErrTodoNotFound", err)
+	}
+	if err := interactor.DeleteTodo(ctx, 1, 9, nil); err != domain.ErrTodoNotFound {
+		t.Errorf("deleting an unknown todo: got %v, want ErrTodoNotFound", err)
+	}
+
+	if _, err := interactor.RestoreTodo(ctx, 1, 2); err != domain.ErrTodoNotInTrash {
+		t.Errorf("restoring a todo outside the trash: got %v, want ErrTodoNotInTrash", err)
+	}
+	if err := interactor.PurgeTodo(ctx, 1, 2); err != domain.ErrTodoNotInTrash {
+		t.Errorf("purging a todo outside the trash: got %v, want ErrTodoNotInTrash", err)
+	}
+	if _, ok := todoRepo.todos[2]; !ok {
+		t.Fatal("a todo outside the trash was purged")
+	}
+
+	restored, err := interactor.RestoreTodo(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil {
+		t.Error("the restored todo is still in the trash")
+	}
+}
+
+// testTodo is an open todo in To Do at version 1
+func testTodo(id int) *domain.Todo {
+	return &domain.Todo{ID: id, UserID: 1, Title: "todo", StatusID: intPtr(1), Version: 1}
+}
+
+func inStatus(todo *domain.Todo, statusID int) *domain.Todo {
+	todo.StatusID = intPtr(statusID)
+	todo.IsCompleted = statusID == 3
+	return todo
+}
+
+// statusOf returns the stored status of the todo, 0 for none
+func statusOf(repo *fakeTodoRepo, todoID int) int {
+	if todo := repo.todos[todoID]; todo != nil && todo.StatusID != nil {
+		return *todo.StatusID
+	}
+	return 0
+}
+
+func TestToggleTodoCompleteStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		todos         []*domain.Todo
+		opts          ToggleOptions
+		wantErr       error
+		wantStatus    int
+		wantCompleted bool
+	}{
+		{
+			name:          "completing moves to the first done status",
+			todos:         []*domain.Todo{testTodo(1)},
+			wantStatus:    3,
+			wantCompleted: true,
+		},
+		{
+			name:       "reopening moves to the first open status",
+			todos:      []*domain.Todo{inStatus(testTodo(1), 3)},
+			wantStatus: 1,
+		},
+		{
+			name:          "completing from a status that allows the move",
+			todos:         []*domain.Todo{inStatus(testTodo(1), 4)},
+			wantStatus:    3,
+			wantCompleted: true,
+		},
+		{
+			name:       "the current status has to allow the move",
+			todos:      []*domain.Todo{inStatus(testTodo(1), 2)},
+			wantErr:    domain.ErrTransitionNotAllowed,
+			wantStatus: 2,
+		},
+		{
+			name:       "the done status is at its WIP limit",
+			todos:      []*domain.Todo{testTodo(1), inStatus(testTodo(2), 3)},
+			wantErr:    domain.ErrWIPLimitReached,
+			wantStatus: 1,
+		},
+		{
+			name:       "stale version",
+			todos:      []*domain.Todo{testTodo(1)},
+			opts:       ToggleOptions{Version: intPtr(7)},
+			wantErr:    domain.ErrTodoVersionMismatch,
+			wantStatus: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactor, repo := newTestTodoInteractor(tt.todos...)
+			todo, err := interactor.ToggleTodoComplete(context.Background(), 1, 1, tt.opts)
+			if err != tt.wantErr {
+				t.Fatalf("ToggleTodoComplete() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := statusOf(repo, 1); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d", got, tt.wantStatus)
+			}
+			if err == nil && (todo.IsCompleted != tt.wantCompleted || *todo.StatusID != tt.wantStatus) {
+				t.Errorf("returned todo is_completed = %v in status %d, want %v in %d", todo.IsCompleted, *todo.StatusID, tt.wantCompleted, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestUpdateTodoCompletionStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		todos      []*domain.Todo
+		update     func(todo *domain.Todo)
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:       "completing moves to the first done status",
+			todos:      []*domain.Todo{testTodo(1)},
+			update:     func(todo *domain.Todo) { todo.Title, todo.IsCompleted = "renamed", true },
+			wantStatus: 3,
+		},
+		{
+			name:       "reopening moves to the first open status",
+			todos:      []*domain.Todo{inStatus(testTodo(1), 3)},
+			update:     func(todo *domain.Todo) { todo.IsCompleted = false },
+			wantStatus: 1,
+		},
+		{
+			name:       "other changes keep the status",
+			todos:      []*domain.Todo{inStatus(testTodo(1), 2)},
+			update:     func(todo *domain.Todo) { todo.Title = "renamed" },
+			wantStatus: 2,
+		},
+		{
+			name:       "the current status has to allow the move",
+			todos:      []*domain.Todo{inStatus(testTodo(1), 2)},
+			update:     func(todo *domain.Todo) { todo.IsCompleted = true },
+			wantErr:    domain.ErrTransitionNotAllowed,
+			wantStatus: 2,
+		},
+		{
+			name:       "the done status is at its WIP limit",
+			todos:      []*domain.Todo{testTodo(1), inStatus(testTodo(2), 3)},
+			update:     func(todo *domain.Todo) { todo.IsCompleted = true },
+			wantErr:    domain.ErrWIPLimitReached,
+			wantStatus: 1,
+		},
+		{
+			name:       "a status the user does not have",
+			todos:      []*domain.Todo{testTodo(1)},
+			update:     func(todo *domain.Todo) { todo.StatusID = intPtr(99) },
+			wantErr:    domain.ErrStatusNotFound,
+			wantStatus: 1,
+		},
+		{
+			name:       "stale version",
+			todos:      []*domain.Todo{testTodo(1)},
+			update:     func(todo *domain.Todo) { todo.Version, todo.IsCompleted = 7, true },
+			wantErr:    domain.ErrTodoVersionMismatch,
+			wantStatus: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactor, repo := newTestTodoInteractor(tt.todos...)
+			todo, _ := repo.GetTodo(context.Background(), 1, 1)
+			tt.update(todo)
+
+			err := interactor.UpdateTodo(context.Background(), 1, todo, ToggleOptions{})
+			if err != tt.wantErr {
+				t.Fatalf("UpdateTodo() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := statusOf(repo, 1); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestBulkCompleteStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		todos       []*domain.Todo
+		op          BulkTodoOperation
+		wantErr     error
+		wantApplied bool
+		itemErrs    map[int]error
+		wantStatus  map[int]int
+	}{
+		{
+			name:        "complete",
+			todos:       []*domain.Todo{testTodo(1), inStatus(testTodo(2), 4)},
+			op:          BulkTodoOperation{Action: BulkComplete, IDs: []int{1}},
+			wantApplied: true,
+			wantStatus:  map[int]int{1: 3, 2: 4},
+		},
+		{
+			name:        "uncomplete",
+			todos:       []*domain.Todo{inStatus(testTodo(1), 3), testTodo(2)},
+			op:          BulkTodoOperation{Action: BulkUncomplete, IDs: []int{1, 2}},
+			wantApplied: true,
+			wantStatus:  map[int]int{1: 1, 2: 1},
+		},
+		{
+			name:       "a status that does not allow the move fails the request",
+			todos:      []*domain.Todo{testTodo(1), inStatus(testTodo(2), 2)},
+			op:         BulkTodoOperation{Action: BulkComplete, IDs: []int{1, 2}},
+			itemErrs:   map[int]error{2: domain.ErrTransitionNotAllowed},
+			wantStatus: map[int]int{1: 1, 2: 2},
+		},
+		{
+			name:       "going over the WIP limit changes nothing",
+			todos:      []*domain.Todo{testTodo(1), testTodo(2)},
+			op:         BulkTodoOperation{Action: BulkComplete, IDs: []int{1, 2}},
+			wantErr:    domain.ErrWIPLimitReached,
+			wantStatus: map[int]int{1: 1, 2: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactor, repo := newTestTodoInteractor(tt.todos...)
+			result, err := interactor.BulkUpdateTodos(context.Background(), 1, tt.op)
+			if err != tt.wantErr {
+				t.Fatalf("BulkUpdateTodos() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if result.Applied != tt.wantApplied {
+					t.Errorf("Applied = %v, want %v", result.Applied, tt.wantApplied)
+				}
+				for _, item := range result.Items {
+					if item.Err != tt.itemErrs[item.ID] {
+						t.Errorf("item %d error = %v, want %v", item.ID, item.Err, tt.itemErrs[item.ID])
+					}
+				}
+			}
+			for id, want := range tt.wantStatus {
+				if got := statusOf(repo, id); got != want {
+					t.Errorf("status of %d = %d, want %d", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkCompleteSubtaskModes(t *testing.T) {
+	withSubtasks := func(todo *domain.Todo, done int, total int) *domain.Todo {
+		todo.SubtasksDone, todo.SubtasksTotal = done, total
+		return todo
+	}
+
+	tests := []struct {
+		name        string
+		mode        domain.SubtaskCompletionMode
+		wantErr     error
+		wantApplied bool
+		itemErrs    map[int]error
+		// wantCascade lists the todos whose subtasks are completed with them
+		wantCascade []int
+	}{
+		{name: "subtasks are left as they are by default", wantApplied: true},
+		{name: "cascade completes open subtasks", mode: domain.SubtaskCompletionCascade, wantApplied: true, wantCascade: []int{1}},
+		{
+			name:     "require refuses open subtasks",
+			mode:     domain.SubtaskCompletionRequire,
+			itemErrs: map[int]error{1: domain.ErrTodoHasOpenSubtasks},
+		},
+		{name: "unknown mode", mode: "all", wantErr: domain.ErrInvalidCompletionMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Done takes one todo, so the second one completed is in a status without a limit
+			interactor, repo := newTestTodoInteractor(withSubtasks(testTodo(1), 1, 3), withSubtasks(inStatus(testTodo(2), 4), 2, 2))
+			repo.statuses[3].WIPLimit = nil
+
+			op := BulkTodoOperation{Action: BulkComplete, IDs: []int{1, 2}, SubtaskMode: tt.mode}
+			result, err := interactor.BulkUpdateTodos(context.Background(), 1, op)
+			if err != tt.wantErr {
+				t.Fatalf("BulkUpdateTodos() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result.Applied != tt.wantApplied {
+				t.Errorf("Applied = %v, want %v", result.Applied, tt.wantApplied)
+			}
+			for _, item := range result.Items {
+				if item.Err != tt.itemErrs[item.ID] {
+					t.Errorf("item %d error = %v, want %v", item.ID, item.Err, tt.itemErrs[item.ID])
+				}
+			}
+			if !reflect.DeepEqual(repo.completedSubtasks, tt.wantCascade) {
+				t.Errorf("subtasks completed for %v, want %v", repo.completedSubtasks, tt.wantCascade)
+			}
+			if completed := repo.todos[1].IsCompleted; completed != tt.wantApplied {
+				t.Errorf("todo 1 completed = %v, want %v", completed, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestToggleBlockedTodo(t *testing.T) {
+	for _, force := range []bool{false, true} {
+		blocked := testTodo(1)
+		blocked.BlockedBy, blocked.OpenBlockers = []int{2}, []int{2}
+		interactor, repo := newTestTodoInteractor(blocked, testTodo(2))
+
+		var wantErr error
+		if !force {
+			wantErr = domain.ErrTodoBlocked
+		}
+		if _, err := interactor.ToggleTodoComplete(context.Background(), 1, 1, ToggleOptions{Force: force}); err != wantErr {
+			t.Fatalf("ToggleTodoComplete(force=%v) error = %v, want %v", force, err, wantErr)
+		}
+		if repo.todos[1].IsCompleted != force {
+			t.Errorf("force=%v: is_completed = %v", force, repo.todos[1].IsCompleted)
+		}
+	}
+}
+
+func TestBulkCompleteBlocked(t *testing.T) {
+	tests := []struct {
+		name        string
+		op          BulkTodoOperation
+		wantApplied bool
+		itemErrs    map[int]error
+	}{
+		{name: "blocker completed in the same request", op: BulkTodoOperation{Action: BulkComplete, IDs: []int{1, 2}}, wantApplied: true},
+		{
+			name:     "open blocker outside the request",
+			op:       BulkTodoOperation{Action: BulkComplete, IDs: []int{1}},
+			itemErrs: map[int]error{1: domain.ErrTodoBlocked},
+		},
+		{name: "force", op: BulkTodoOperation{Action: BulkComplete, IDs: []int{1}, Force: true}, wantApplied: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked := testTodo(1)
+			blocked.BlockedBy, blocked.OpenBlockers = []int{2}, []int{2}
+			interactor, repo := newTestTodoInteractor(blocked, testTodo(2))
+			repo.statuses[3].WIPLimit = nil
+
+			result, err := interactor.BulkUpdateTodos(context.Background(), 1, tt.op)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Applied != tt.wantApplied {
+				t.Errorf("Applied = %v, want %v", result.Applied, tt.wantApplied)
+			}
+			for _, item := range result.Items {
+				if item.Err != tt.itemErrs[item.ID] {
+					t.Errorf("item %d error = %v, want %v", item.ID, item.Err, tt.itemErrs[item.ID])
+				}
+			}
+			if repo.todos[1].IsCompleted != tt.wantApplied {
+				t.Errorf("todo 1 completed = %v, want %v", repo.todos[1].IsCompleted, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestUpdateTodoCompletionRules(t *testing.T) {
+	daily, err := domain.ParseRecurrenceRule("FREQ=DAILY", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocked := testTodo(1)
+	blocked.BlockedBy, blocked.OpenBlockers = []int{2}, []int{2}
+	withSubtasks := testTodo(1)
+	withSubtasks.SubtasksTotal, withSubtasks.SubtasksDone = 3, 1
+	recurring := testTodo(1)
+	recurring.Recurrence = daily
+	recurring.SetDue(ptrDate("2026-10-14"), nil, nil)
+
+	tests := []struct {
+		name                 string
+		todo                 *domain.Todo
+		opts                 ToggleOptions
+		wantErr              error
+		wantCompleted        bool
+		wantSubtasksComplete bool
+		wantNext             string
+	}{
+		{name: "blocked", todo: blocked, wantErr: domain.ErrTodoBlocked},
+		{name: "blocked with force", todo: blocked, opts: ToggleOptions{Force: true}, wantCompleted: true},
+		{
+			name:    "open subtasks with require",
+			todo:    withSubtasks,
+			opts:    ToggleOptions{SubtaskMode: domain.SubtaskCompletionRequire},
+			wantErr: domain.ErrTodoHasOpenSubtasks,
+		},
+		{name: "open subtasks are left by default", todo: withSubtasks, wantCompleted: true},
+		{
+			name:                 "open subtasks with cascade",
+			todo:                 withSubtasks,
+			opts:                 ToggleOptions{SubtaskMode: domain.SubtaskCompletionCascade},
+			wantCompleted:        true,
+			wantSubtasksComplete: true,
+		},
+		{name: "recurring todo moves on to its next occurrence", todo: recurring, wantCompleted: true, wantNext: "2026-10-15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := *tt.todo
+			interactor, repo := newTestTodoInteractor(&stored)
+			todo, _ := repo.GetTodo(context.Background(), 1, 1)
+			todo.IsCompleted = true
+
+			err := interactor.UpdateTodo(context.Background(), 1, todo, tt.opts)
+			if err != tt.wantErr {
+				t.Fatalf("UpdateTodo() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := repo.todos[1].IsCompleted; got != tt.wantCompleted {
+				t.Errorf("is_completed = %v, want %v", got, tt.wantCompleted)
+			}
+			if got := len(repo.completedSubtasks) > 0; got != tt.wantSubtasksComplete {
+				t.Errorf("subtasks completed = %v, want %v", got, tt.wantSubtasksComplete)
+			}
+
+			if tt.wantNext == "" {
+				if todo.NextOccurrence != nil {
+					t.Errorf("NextOccurrence = %+v, want none", todo.NextOccurrence)
+				}
+				return
+			}
+			if todo.NextOccurrence == nil {
+				t.Fatal("NextOccurrence is missing")
+			}
+			if got := todo.NextOccurrence.DueDate.Format("2006-01-02"); got != tt.wantNext {
+				t.Errorf("next occurrence due %s, want %s", got, tt.wantNext)
+			}
+			if repo.todos[1].Recurrence != nil || todo.NextOccurrence.Recurrence == nil {
+				t.Error("the recurrence did not move to the next occurrence")
+			}
+		})
+	}
+}
+
+func TestUpdateTodoCascadeThenIfMatch(t *testing.T) {
+	stored := testTodo(1)
+	stored.SubtasksTotal, stored.SubtasksDone = 2, 0
+	interactor, repo := newTestTodoInteractor(stored)
+
+	todo, _ := repo.GetTodo(context.Background(), 1, 1)
+	todo.IsCompleted = true
+	opts := ToggleOptions{SubtaskMode: domain.SubtaskCompletionCascade}
+	if err := interactor.UpdateTodo(context.Background(), 1, todo, opts); err != nil {
+		t.Fatal(err)
+	}
+	// The todo sent back carries the version after its subtasks were completed, so its ETag holds
+	if todo.Version != repo.todos[1].Version || todo.SubtasksDone != 2 {
+		t.Fatalf("todo at version %d with %d subtasks done, stored at version %d", todo.Version, todo.SubtasksDone, repo.todos[1].Version)
+	}
+
+	todo.Title = "changed"
+	if err := interactor.UpdateTodo(context.Background(), 1, todo, ToggleOptions{Version: &todo.Version}); err != nil {
+		t.Errorf("UpdateTodo() with the returned version error = %v", err)
+	}
+}
+
+func ptrDate(value string) *time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
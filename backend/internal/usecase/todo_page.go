@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"todo-app/internal/domain"
+)
+
+const (
+	// DefaultTodoPageSize is used when a cursor is given without a limit
+	DefaultTodoPageSize = 50
+	MaxTodoPageSize     = 200
+)
+
+// TodoSortKey is the position of a todo in a listing. The repository computes it
+// so that every field sorts ascending for each supported sort, which keeps a
+// page boundary stable however many todos share a due date or priority.
+type TodoSortKey struct {
+	Group     int
+	Position  string
+	Value     int64
+	Completed int
+	Created   int64
+	ID        int
+}
+
+// TodoPage is one page of a todo listing
+type TodoPage struct {
+	Todos []*domain.Todo
+	// Total counts every todo that matches the filter, not only this page
+	Total int
+	// NextCursor continues the listing after this page; it is empty on the last page
+	NextCursor string
+}
+
+// todoCursor is the content of the opaque cursor handed to clients.
+// The sort is kept so that a cursor cannot be reused with another order.
+type todoCursor struct {
+	Sort string  `json:"s"`
+	Key  []int64 `json:"k"`
+	// Position is only set for sort=manual
+	Position string `json:"p,omitempty"`
+}
+
+func encodeTodoCursor(sortBy string, key TodoSortKey) string {
+	payload, _ := json.Marshal(todoCursor{
+		Sort:     sortBy,
+		Key:      []int64{int64(key.Group), key.Value, int64(key.Completed), key.Created, int64(key.ID)},
+		Position: key.Position,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func decodeTodoCursor(cursor string, sortBy string) (*TodoSortKey, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var c todoCursor
+	if err := json.Unmarshal(payload, &c); err != nil || c.Sort != sortBy || len(c.Key) != 5 {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	return &TodoSortKey{
+		Group:     int(c.Key[0]),
+		Position:  c.Position,
+		Value:     c.Key[1],
+		Completed: int(c.Key[2]),
+		Created:   c.Key[3],
+		ID:        int(c.Key[4]),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+func TestTodoCursorRoundTrip(t *testing.T) {
+	keys := []TodoSortKey{
+		{},
+		{Group: 1, Value: -20345, Completed: 1, Created: -1760000000123456, ID: -42},
+		// Todos that tie on everything but the ID still get distinct cursors
+		{Value: -2, Created: -1760000000000000, ID: -7},
+		{Value: -2, Created: -1760000000000000, ID: -8},
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		cursor := encodeTodoCursor("priority_desc", key)
+		if seen[cursor] {
+			t.Errorf("cursor of %+v is shared with another key", key)
+		}
+		seen[cursor] = true
+
+		got, err := decodeTodoCursor(cursor, "priority_desc")
+		if err != nil {
+			t.Fatalf("decodeTodoCursor(encodeTodoCursor(%+v)) failed: %v", key, err)
+		}
+		if *got != key {
+			t.Errorf("decodeTodoCursor(encodeTodoCursor(%+v)) = %+v", key, *got)
+		}
+	}
+}
+
+func TestDecodeTodoCursorRejectsTampering(t *testing.T) {
+	encode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "not a cursor!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"s":"","k":[0,0,0,0,1]}`))},
+		{name: "not JSON", cursor: encode("1,2,3")},
+		{name: "another sort", cursor: encodeTodoCursor("due_date_asc", TodoSortKey{ID: -1})},
+		{name: "short key", cursor: encode(`{"s":"priority_desc","k":[0,0,0,-1]}`)},
+		{name: "long key", cursor: encode(`{"s":"priority_desc","k":[0,0,0,0,-1,5]}`)},
+		{name: "no key", cursor: encode(`{"s":"priority_desc"}`)},
+		{name: "key of strings", cursor: encode(`{"s":"priority_desc","k":["0","0","0","0","-1"]}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key, err := decodeTodoCursor(tt.cursor, "priority_desc"); err != domain.ErrInvalidCursor {
+				t.Errorf("decodeTodoCursor(%q) = %+v, %v, want ErrInvalidCursor", tt.cursor, key, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+	"unicode/utf8"
+)
+
+const maxQuickAddTitleLength = 100
+
+// The fields a quick-add token can set
+const (
+	QuickAddDueDate  = "due_date"
+	QuickAddDueTime  = "due_time"
+	QuickAddPriority = "priority"
+)
+
+// QuickAdd is a todo read from one line of text by ParseQuickAdd
+type QuickAdd struct {
+	Title string
+	// DueDate is a date in UTC, like domain.Todo.DueDate
+	DueDate *time.Time
+	// DueTime is a time of day, only set along with DueDate
+	DueTime  *time.Time
+	Priority int
+	// Tokens are the parts of the line that were read as something other than the title, in line order
+	Tokens []QuickAddToken
+}
+
+// QuickAddToken is a part of the line and the field it set
+type QuickAddToken struct {
+	Text  string
+	Field string
+}
+
+// ParseQuickAdd reads a title, a due date and time and a priority from one line in
+// Japanese or English, e.g. "請求書を送る 明日 15時 !high" or "Pay rent next friday p2".
+// Everything that is not recognised is the title. now is in the user's time zone.
+//
+// Dates are today, tomorrow, weekdays, "next week", "in 3 days", "nov 1", "by 11/1" and 2026-11-01,
+// or 今日, 明日, 明後日, 金曜, 来週(の)金曜, 3日後, 11月1日, 11/1まで. A weekday is the coming one after today,
+// "next friday" and 来週金曜 are the Friday of next week (weeks start on Monday), as in ParseSnoozeUntil.
+// A bare 金曜 has to stand apart from the words around it.
+// Times are 3pm, 3:30pm, 15:00 and "at 15", or 15時, 15時半, 午後3時 and 正午; a time without
+// a date is today, or tomorrow once it has passed. Priorities are !high, !medium, !low, !0-!2,
+// p0-p2, !高, !中, !低 and 優先度高; 2 is the highest.
+func ParseQuickAdd(line string, now time.Time) (*QuickAdd, error) {
+	p := &quickAddParser{
+		today:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		result: &QuickAdd{},
+	}
+
+	text := line
+	type span struct {
+		start int
+		token QuickAddToken
+	}
+	var spans []span
+	set := make(map[string]bool)
+	for _, rule := range quickAddRules {
+		if set[rule.field] {
+			continue
+		}
+		for _, loc := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			m := make([]string, len(loc)/2)
+			for i := range m {
+				if loc[2*i] >= 0 {
+					m[i] = text[loc[2*i]:loc[2*i+1]]
+				}
+			}
+			if !rule.read(p, m) {
+				continue
+			}
+
+			set[rule.field] = true
+			// The separators some patterns match around a token are not part of it
+			spans = append(spans, span{loc[0], QuickAddToken{Text: strings.Trim(strings.TrimSpace(m[0]), "、,"), Field: rule.field}})
+			// Blank the match out with as many spaces, so that the other positions stay put
+			text = text[:loc[0]] + strings.Repeat(" ", loc[1]-loc[0]) + text[loc[1]:]
+			break
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	p.result.Tokens = make([]QuickAddToken, len(spans))
+	for i, s := range spans {
+		p.result.Tokens[i] = s.token
+	}
+
+	if p.result.DueTime != nil && p.result.DueDate == nil {
+		clock := p.result.DueTime
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		date := p.today
+		if !at.After(now) {
+			date = date.AddDate(0, 0, 1)
+		}
+		p.result.DueDate = &date
+	}
+
+	p.result.Title = strings.Trim(strings.Join(strings.Fields(text), " "), "、,")
+	length := utf8.RuneCountInString(p.result.Title)
+	if length == 0 || length > maxQuickAddTitleLength {
+		return nil, domain.ErrInvalidQuickAdd
+	}
+	return p.result, nil
+}
+
+type quickAddParser struct {
+	// today is the user's current date, in UTC like the due dates
+	today  time.Time
+	result *QuickAdd
+}
+
+type quickAddRule struct {
+	field   string
+	pattern *regexp.Regexp
+	// read sets the field from the submatches and reports false when they are not a valid value
+	read func(p *quickAddParser, m []string) bool
+}
+
+// Optional words around dates and times, which are dropped along with them
+const (
+	enDatePrefix = `(?i)\b(?:(?:due|by|on)\s+)?`
+	jaSuffix     = `(?:までに|まで|に|の)?`
+)
+
+var englishWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var japaneseWeekdays = map[string]time.Weekday{
+	"日": time.Sunday, "月": time.Monday, "火": time.Tuesday, "水": time.Wednesday,
+	"木": time.Thursday, "金": time.Friday, "土": time.Saturday,
+}
+
+var englishMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+var quickAddPriorities = map[string]int{
+	"high": 2, "h": 2, "2": 2, "高": 2,
+	"medium": 1, "med": 1, "m": 1, "1": 1, "中": 1,
+	"low": 0, "l": 0, "0": 0, "低": 0,
+}
+
+// quickAddRules are tried in order, and the first valid match sets its field.
+// Longer forms come before the forms they contain, e.g. 来週金曜 before 来週.
+var quickAddRules = []quickAddRule{
+	{QuickAddPriority, regexp.MustCompile(`(?i)(?:^|\s)!(high|h|medium|med|m|low|l|[0-2]|高|中|低)(?:\s|$)`), readPriority},
+	{QuickAddPriority, regexp.MustCompile(`(?i)\bp([0-2])\b`), readPriority},
+	{QuickAddPriority, regexp.MustCompile(`優先度[:：]?\s*([高中低])`), readPriority},
+
+	{QuickAddDueDate, regexp.MustCompile(enDatePrefix + `(\d{4})[-/](\d{1,2})[-/](\d{1,2})\b` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setDate(atoi(m[1]), atoi(m[2]), atoi(m[3]))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(enDatePrefix + `(today|tod)\b`), func(p *quickAddParser, m []string) bool {
+		return p.setDay(p.today)
+	}},
+	{QuickAddDueDate, regexp.MustCompile(enDatePrefix + `(tomorrow|tmrw|tmr)\b`), func(p *quickAddParser, m []string) bool {
+		return p.setDay(p.today.AddDate(0, 0, 1))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(enDatePrefix + `in\s+(\d{1,3})\s*(days?|d|weeks?|w)\b`), func(p *quickAddParser, m []string) bool {
+		days := atoi(m[1])
+		if strings.HasPrefix(strings.ToLower(m[2]), "w") {
+			days *= 7
+		}
+		return p.setDay(p.today.AddDate(0, 0, days))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(enDatePrefix + `next\s+week\b`), func(p *quickAddParser, m []string) bool {
+		return p.setDay(weekdayInWeek(p.today, 1, time.Monday))
+	}},
+	// Full weekday names
+	{QuickAddDueDate, regexp.MustCompile(enDatePrefix + `(?:(next|this)\s+)?(monday|tuesday|wednesday|thursday|friday|saturday|sunday)\b`), readEnglishWeekday},
+	// Abbreviations such as "sun" and "sat" are words too, so they need a "next", "this" or "on" in front
+	{QuickAddDueDate, regexp.MustCompile(`(?i)\b(?:(?:due|by|on)\s+(?:(next|this)\s+)?|(next|this)\s+)(mon|tues|tue|wed|thurs|thur|thu|fri|sat|sun)\b`), func(p *quickAddParser, m []string) bool {
+		return readEnglishWeekday(p, []string{m[0], m[1] + m[2], m[3]})
+	}},
+	{QuickAddDueDate, regexp.MustCompile(enDatePrefix + `(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b`), func(p *quickAddParser, m []string) bool {
+		return p.setUpcoming(int(englishMonths[strings.ToLower(m[1])]), atoi(m[2]))
+	}},
+	// A bare 1/2 is as likely a fraction or a chapter, so it needs "due", "by" or "on" in front or まで or に after
+	{QuickAddDueDate, regexp.MustCompile(`(?i)\b(?:due|by|on)\s+(\d{1,2})/(\d{1,2})\b` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setUpcoming(atoi(m[1]), atoi(m[2]))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(?:^|[\s、,])(\d{1,2})/(\d{1,2})(?:までに|まで|に)`), func(p *quickAddParser, m []string) bool {
+		return p.setUpcoming(atoi(m[1]), atoi(m[2]))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(\d{1,2})月(\d{1,2})日` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setUpcoming(atoi(m[1]), atoi(m[2]))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`今日(?:中)?` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setDay(p.today)
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(?:明後日|あさって)` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setDay(p.today.AddDate(0, 0, 2))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(?:明日|あした)` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setDay(p.today.AddDate(0, 0, 1))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(\d{1,3})日後` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setDay(p.today.AddDate(0, 0, atoi(m[1])))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(\d{1,2})週間後` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setDay(p.today.AddDate(0, 0, 7*atoi(m[1])))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(今週|来週|再来週)の?([月火水木金土日])曜(?:日)?` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		weeks := map[string]int{"今週": 0, "来週": 1, "再来週": 2}[m[1]]
+		return p.setDay(weekdayInWeek(p.today, weeks, japaneseWeekdays[m[2]]))
+	}},
+	{QuickAddDueDate, regexp.MustCompile(`(来週|再来週)` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		weeks := map[string]int{"来週": 1, "再来週": 2}[m[1]]
+		return p.setDay(weekdayInWeek(p.today, weeks, time.Monday))
+	}},
+	// A bare weekday has to stand on its own, so that words such as 日曜大工 stay in the title
+	{QuickAddDueDate, regexp.MustCompile(`(?:^|[\s、,])([月火水木金土日])曜(?:日)?(?:までに|まで|に|の|[\s、,]|$)`), func(p *quickAddParser, m []string) bool {
+		return p.setDay(nextWeekday(p.today, japaneseWeekdays[m[1]]))
+	}},
+
+	{QuickAddDueTime, regexp.MustCompile(`(?i)\b(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		hour := atoi(m[1])
+		if hour < 1 || hour > 12 {
+			return false
+		}
+		hour %= 12
+		if strings.EqualFold(m[3], "pm") {
+			hour += 12
+		}
+		return p.setTime(hour, atoi(m[2]))
+	}},
+	{QuickAddDueTime, regexp.MustCompile(`(?i)\b(?:at\s+)?(\d{1,2}):(\d{2})(?:までに|まで|に|\b)`), func(p *quickAddParser, m []string) bool {
+		return p.setTime(atoi(m[1]), atoi(m[2]))
+	}},
+	{QuickAddDueTime, regexp.MustCompile(`(?i)\bat\s+(\d{1,2})\b`), func(p *quickAddParser, m []string) bool {
+		return p.setTime(atoi(m[1]), 0)
+	}},
+	{QuickAddDueTime, regexp.MustCompile(`(午前|午後)?(\d{1,2})時(?:(\d{1,2})分|(半))?` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		hour, minute := atoi(m[2]), atoi(m[3])
+		if m[4] != "" {
+			minute = 30
+		}
+		if m[1] != "" {
+			// 午後0時 is noon; 午前12時 and 午後12時 are not used
+			if hour > 11 {
+				return false
+			}
+			if m[1] == "午後" {
+				hour += 12
+			}
+		}
+		return p.setTime(hour, minute)
+	}},
+	{QuickAddDueTime, regexp.MustCompile(`正午` + jaSuffix), func(p *quickAddParser, m []string) bool {
+		return p.setTime(12, 0)
+	}},
+}
+
+func readPriority(p *quickAddParser, m []string) bool {
+	priority, ok := quickAddPriorities[strings.ToLower(m[1])]
+	if ok {
+		p.result.Priority = priority
+	}
+	return ok
+}
+
+// readEnglishWeekday reads m[1], "next" or "this", and m[2], a weekday
+func readEnglishWeekday(p *quickAddParser, m []string) bool {
+	weekday := englishWeekdays[strings.ToLower(m[2])]
+	switch strings.ToLower(m[1]) {
+	case "next":
+		return p.setDay(weekdayInWeek(p.today, 1, weekday))
+	case "this":
+		return p.setDay(weekdayInWeek(p.today, 0, weekday))
+	}
+	return p.setDay(nextWeekday(p.today, weekday))
+}
+
+func (p *quickAddParser) setDay(date time.Time) bool {
+	p.result.DueDate = &date
+	return true
+}
+
+// setDate sets the date when it exists
+func (p *quickAddParser) setDate(year, month, day int) bool {
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if month < 1 || month > 12 || date.Month() != time.Month(month) || date.Day() != day {
+		return false
+	}
+	return p.setDay(date)
+}
+
+// setUpcoming sets the next date falling on month and day, this year or next
+func (p *quickAddParser) setUpcoming(month, day int) bool {
+	year := p.today.Year()
+	if time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Before(p.today) {
+		year++
+	}
+	return p.setDate(year, month, day)
+}
+
+func (p *quickAddParser) setTime(hour, minute int) bool {
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return false
+	}
+	clock := time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC)
+	p.result.DueTime = &clock
+	return true
+}
+
+// atoi reads the digits matched by a pattern; an empty group is 0
+func atoi(digits string) int {
+	n, _ := strconv.Atoi(digits)
+	return n
+}
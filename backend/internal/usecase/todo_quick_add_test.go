@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// quickAddNow is Wednesday 14 October 2026, 10:00 in the user's time zone
+var quickAddNow = time.Date(2026, 10, 14, 10, 0, 0, 0, jst)
+
+func TestParseQuickAdd(t *testing.T) {
+	tests := []struct {
+		line     string
+		title    string
+		due      string
+		dueTime  string
+		priority int
+		tokens   []string
+		wantErr  bool
+	}{
+		{line: "請求書を送る 明日 15時 !high", title: "請求書を送る", due: "2026-10-15", dueTime: "15:00", priority: 2, tokens: []string{"明日:due_date", "15時:due_time", "!high:priority"}},
+		{line: "Pay rent next friday p2", title: "Pay rent", due: "2026-10-23", priority: 2, tokens: []string{"next friday:due_date", "p2:priority"}},
+		{line: "Pay rent friday", title: "Pay rent", due: "2026-10-16", tokens: []string{"friday:due_date"}},
+		{line: "Pay rent this friday", title: "Pay rent", due: "2026-10-16", tokens: []string{"this friday:due_date"}},
+		{line: "Pay rent on wed", title: "Pay rent", due: "2026-10-21", tokens: []string{"on wed:due_date"}},
+		{line: "Pay rent next week", title: "Pay rent", due: "2026-10-19", tokens: []string{"next week:due_date"}},
+		{line: "Pay rent in 3 days", title: "Pay rent", due: "2026-10-17", tokens: []string{"in 3 days:due_date"}},
+		{line: "Pay rent dec 31", title: "Pay rent", due: "2026-12-31", tokens: []string{"dec 31:due_date"}},
+		{line: "Pay rent jan 5th", title: "Pay rent", due: "2027-01-05", tokens: []string{"jan 5th:due_date"}},
+		{line: "Pay rent 2026-11-01 at 9", title: "Pay rent", due: "2026-11-01", dueTime: "09:00", tokens: []string{"2026-11-01:due_date", "at 9:due_time"}},
+		{line: "Read chapter by 11/1", title: "Read chapter", due: "2026-11-01", tokens: []string{"by 11/1:due_date"}},
+		{line: "資料作成 11/1まで", title: "資料作成", due: "2026-11-01", tokens: []string{"11/1まで:due_date"}},
+		{line: "レポート 金曜", title: "レポート", due: "2026-10-16", tokens: []string{"金曜:due_date"}},
+		{line: "金曜日までにレポート", title: "レポート", due: "2026-10-16", tokens: []string{"金曜日までに:due_date"}},
+		{line: "会議、金曜", title: "会議", due: "2026-10-16", tokens: []string{"金曜:due_date"}},
+		{line: "来週の金曜 打ち合わせ", title: "打ち合わせ", due: "2026-10-23", tokens: []string{"来週の金曜:due_date"}},
+		{line: "打ち合わせ 来週", title: "打ち合わせ", due: "2026-10-19", tokens: []string{"来週:due_date"}},
+		{line: "散髪 3日後 午後3時半", title: "散髪", due: "2026-10-17", dueTime: "15:30", tokens: []string{"3日後:due_date", "午後3時半:due_time"}},
+		{line: "電話する 優先度高", title: "電話する", priority: 2, tokens: []string{"優先度高:priority"}},
+		{line: "Stand-up 9:30", title: "Stand-up", due: "2026-10-15", dueTime: "09:30", tokens: []string{"9:30:due_time"}},
+		{line: "Lunch 12:30pm", title: "Lunch", due: "2026-10-14", dueTime: "12:30", tokens: []string{"12:30pm:due_time"}},
+		{line: "日曜大工", title: "日曜大工"},
+		{line: "ホームセンターで日曜大工の道具を買う", title: "ホームセンターで日曜大工の道具を買う"},
+		{line: "Read chapter 1/2", title: "Read chapter 1/2"},
+		{line: "Go to the sun", title: "Go to the sun"},
+		{line: "Submit 2026-02-30", title: "Submit 2026-02-30"},
+		{line: "明日", wantErr: true},
+		{line: "   ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := ParseQuickAdd(tt.line, quickAddNow)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseQuickAdd(%q) = %+v, want an error", tt.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuickAdd(%q) failed: %v", tt.line, err)
+			}
+
+			if got.Title != tt.title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.title)
+			}
+			if due := formatOptional(got.DueDate, "2006-01-02"); due != tt.due {
+				t.Errorf("DueDate = %q, want %q", due, tt.due)
+			}
+			if dueTime := formatOptional(got.DueTime, "15:04"); dueTime != tt.dueTime {
+				t.Errorf("DueTime = %q, want %q", dueTime, tt.dueTime)
+			}
+			if got.Priority != tt.priority {
+				t.Errorf("Priority = %d, want %d", got.Priority, tt.priority)
+			}
+			var tokens []string
+			for _, token := range got.Tokens {
+				tokens = append(tokens, fmt.Sprintf("%s:%s", token.Text, token.Field))
+			}
+			if !reflect.DeepEqual(tokens, tt.tokens) {
+				t.Errorf("Tokens = %q, want %q", tokens, tt.tokens)
+			}
+		})
+	}
+}
+
+func TestParseQuickAddWeekdays(t *testing.T) {
+	tests := []struct {
+		today      string
+		friday     string
+		nextFriday string
+	}{
+		{today: "2026-10-12", friday: "2026-10-16", nextFriday: "2026-10-23"}, // Monday
+		{today: "2026-10-14", friday: "2026-10-16", nextFriday: "2026-10-23"}, // Wednesday
+		{today: "2026-10-16", friday: "2026-10-23", nextFriday: "2026-10-23"}, // Friday
+		{today: "2026-10-17", friday: "2026-10-23", nextFriday: "2026-10-23"}, // Saturday
+		{today: "2026-10-18", friday: "2026-10-23", nextFriday: "2026-10-23"}, // Sunday
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.today, func(t *testing.T) {
+			today, err := time.ParseInLocation("2006-01-02", tt.today, jst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := today.Add(10 * time.Hour)
+
+			for line, want := range map[string]string{
+				"Pay rent friday":      tt.friday,
+				"家賃 金曜":                tt.friday,
+				"Pay rent next friday": tt.nextFriday,
+				"家賃 来週の金曜":             tt.nextFriday,
+			} {
+				got, err := ParseQuickAdd(line, now)
+				if err != nil {
+					t.Fatalf("ParseQuickAdd(%q) failed: %v", line, err)
+				}
+				if due := formatOptional(got.DueDate, "2006-01-02"); due != want {
+					t.Errorf("ParseQuickAdd(%q).DueDate = %q, want %q", line, due, want)
+				}
+			}
+		})
+	}
+}
+
+// formatOptional formats t, or returns "" when it is not set
+func formatOptional(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+func TestQuickAddTodo(t *testing.T) {
+	ctx := context.Background()
+	todoRepo := newFakeTodoRepo()
+	interactor := &TodoInteractor{todoRepo: todoRepo, userRepo: &fakeUserRepo{timezone: "Asia/Tokyo"}}
+
+	parsed, todo, err := interactor.QuickAddTodo(ctx, 1, "請求書を送る 明日 15時 !high", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo != nil || len(todoRepo.todos) != 0 {
+		t.Fatalf("dry run created %+v", todo)
+	}
+	if parsed.Title != "請求書を送る" || parsed.Priority != 2 {
+		t.Errorf("parsed %+v", parsed)
+	}
+
+	_, todo, err = interactor.QuickAddTodo(ctx, 1, "請求書を送る 明日 15時 !high", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, ok := todoRepo.todos[todo.ID]
+	if !ok || stored.UserID != 1 || stored.Title != "請求書を送る" || stored.Priority != 2 {
+		t.Fatalf("created %+v", stored)
+	}
+
+	// The due time is the user's 15:00 tomorrow
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().In(tokyo).AddDate(0, 0, 1)
+	if stored.DueAt == nil || stored.DueAt.In(tokyo).Format("2006-01-02 15:04") != tomorrow.Format("2006-01-02")+" 15:00" {
+		t.Errorf("due at %v, want 15:00 tomorrow in Tokyo", stored.DueAt)
+	}
+	if stored.DueDate == nil || stored.DueDate.Format("2006-01-02") != tomorrow.Format("2006-01-02") {
+		t.Errorf("due date %v, want %s", stored.DueDate, tomorrow.Format("2006-01-02"))
+	}
+
+	if _, _, err := interactor.QuickAddTodo(ctx, 1, "明日 15時", false); err == nil {
+		t.Error("a line without a title was accepted")
+	}
+}
@@ -2,14 +2,61 @@ package usecase
 
 import (
 	"context"
+	"time"
 	"todo-app/internal/domain"
 )
 
+// TodoRepository records every change to a todo in its history, in the same transaction as the change
 type TodoRepository interface {
 	CreateTodo(ctx context.Context, userID int, todo *domain.Todo) error
 	GetTodo(ctx context.Context, userID int, todoID int) (*domain.Todo, error)
-	GetTodos(ctx context.Context, userID int, sortBy string) ([]*domain.Todo, error)
+	GetTodos(ctx context.Context, userID int, sortBy string, filter TodoFilter) ([]*domain.Todo, error)
+	// GetTodoPage returns up to limit todos that follow after, or the first page when after is nil.
+	// The returned key is the position to continue from, nil when there are no more todos.
+	GetTodoPage(ctx context.Context, userID int, sortBy string, filter TodoFilter, limit int, after *TodoSortKey) ([]*domain.Todo, *TodoSortKey, error)
+	CountTodos(ctx context.Context, userID int, filter TodoFilter) (int, error)
+	// SearchTodos matches titles containing every term, or resembling query as a whole.
+	// The returned results carry no snippet.
+	SearchTodos(ctx context.Context, userID int, query string, terms []string, limit int) ([]*TodoSearchResult, error)
+	// UpdateTodo and DeleteTodo fail with domain.ErrTodoVersionMismatch when the stored todo
+	// is no longer at the expected version. UpdateTodo expects todo.Version; for DeleteTodo
+	// a nil version skips the check.
+	// Saving a todo into another status, here and in the other methods that save todos, fails with
+	// domain.ErrWIPLimitReached when that status already holds as many todos as its WIP limit.
 	UpdateTodo(ctx context.Context, userID int, todo *domain.Todo) error
-	DeleteTodo(ctx context.Context, userID int, todoID int) error
-	ToggleTodoComplete(ctx context.Context, userID int, todoID int) (*domain.Todo, error)
+	// DeleteTodo moves the todo to the trash, or returns domain.ErrTodoNotFound when
+	// the user has no such todo outside the trash
+	DeleteTodo(ctx context.Context, userID int, todoID int, version *int) error
+	GetTrashedTodos(ctx context.Context, userID int) ([]*domain.Todo, error)
+	// RestoreTodo and PurgeTodo only act on todos in the trash and report false for any other todo
+	RestoreTodo(ctx context.Context, userID int, todoID int) (bool, error)
+	PurgeTodo(ctx context.Context, userID int, todoID int) (bool, error)
+	// PurgeTrash permanently deletes every user's todos trashed before deletedBefore
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+	StopRecurrence(ctx context.Context, userID int, todoID int) error
+	// MoveTodo places the todo right after afterID and/or right before beforeID in the manual order,
+	// rewriting only its own position. With a single anchor, the todo goes next to it.
+	// A nil version skips the version check, as for DeleteTodo.
+	MoveTodo(ctx context.Context, userID int, todoID int, version *int, afterID *int, beforeID *int) error
+	// RebalanceTodoPositions renumbers the positions of every user who has one longer than maxLength,
+	// keeping their order and their updated_at and version, and returns how many todos were renumbered
+	RebalanceTodoPositions(ctx context.Context, maxLength int) (int64, error)
+	// ApplyTodoChanges saves every change in one transaction. It fails with
+	// domain.ErrTodoVersionMismatch, changing nothing, when any todo has moved on since it was read.
+	// A change with Next creates that occurrence, with copies of the subtasks as open ones and of the reminders.
+	// The open subtasks of a change with CompleteSubtasks are completed in the same transaction.
+	ApplyTodoChanges(ctx context.Context, userID int, changes []*TodoChange) error
+	// AddTodoDependency records that blockerID has to be completed before blockedID. It fails with
+	// domain.ErrDependencyCycle when blockedID already blocks blockerID, directly or through other todos.
+	// Adding a dependency that exists changes nothing.
+	AddTodoDependency(ctx context.Context, userID int, blockerID int, blockedID int) error
+	// RemoveTodoDependency reports false when there was no such dependency
+	RemoveTodoDependency(ctx context.Context, userID int, blockerID int, blockedID int) (bool, error)
+	// GetDependencyTodos lists the todos outside the trash with a dependency on another todo
+	// outside the trash, in the manual order. The todos carry their dependencies but no tags or counts.
+	GetDependencyTodos(ctx context.Context, userID int) ([]*domain.Todo, error)
+	GetTodoEvents(ctx context.Context, todoID int) ([]*domain.TodoEvent, error)
+	// UndoOperations reverts the user's latest count operations made since the given time,
+	// and returns how many operations were undone and the todos they restored
+	UndoOperations(ctx context.Context, userID int, since time.Time, count int) (int, []*domain.Todo, error)
 }
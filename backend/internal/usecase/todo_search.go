@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+	"todo-app/internal/domain"
+	"unicode"
+)
+
+const (
+	DefaultTodoSearchLimit = 20
+	maxSearchQueryLength   = 100
+	// snippetContext is the number of characters kept on each side of the first match
+	snippetContext = 30
+)
+
+// TodoSearchResult is a todo matched by SearchTodos
+type TodoSearchResult struct {
+	Todo *domain.Todo
+	// AllTerms reports whether every search term appears in the title;
+	// other results only resemble the query
+	AllTerms bool
+	// Score is the word similarity between the query and the title, from 0 to 1
+	Score float64
+	// Snippet is the HTML-escaped title, shortened around the first match,
+	// with the matched terms wrapped in <mark>
+	Snippet string
+}
+
+// searchTerms splits the query into whitespace separated terms, without duplicates
+func searchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range strings.Fields(query) {
+		key := strings.ToLower(term)
+		if !seen[key] {
+			seen[key] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// highlightSnippet marks every case-insensitive occurrence of the terms in title
+func highlightSnippet(title string, terms []string) string {
+	runes := []rune(title)
+	lower := lowerRunes(title)
+
+	marked := make([]bool, len(runes))
+	// first and firstEnd bound the first match
+	first, firstEnd := -1, -1
+	for _, term := range terms {
+		needle := lowerRunes(term)
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if !hasRunePrefix(lower[i:], needle) {
+				continue
+			}
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+			if first == -1 || i < first || (i == first && i+len(needle) > firstEnd) {
+				first, firstEnd = i, i+len(needle)
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if first > snippetContext {
+		start = first - snippetContext
+	}
+	if first >= 0 && firstEnd+snippetContext < end {
+		end = firstEnd + snippetContext
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// lowerRunes lowercases s rune by rune, so that indexes match those of []rune(s)
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func hasRunePrefix(s, prefix []rune) bool {
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "会議 資料", want: []string{"会議", "資料"}},
+		{query: "  report\tReport  REPORT draft ", want: []string{"report", "draft"}},
+		{query: "会議　資料", want: []string{"会議", "資料"}},
+		{query: "   ", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := searchTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	long := strings.Repeat("あ", 40) + "会議" + strings.Repeat("い", 70)
+
+	tests := []struct {
+		name  string
+		title string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "part of a Japanese word",
+			title: "週次会議の資料を作成",
+			terms: []string{"会議"},
+			want:  "週次<mark>会議</mark>の資料を作成",
+		},
+		{
+			name:  "every occurrence of every term",
+			title: "会議の資料と会議室",
+			terms: []string{"会議", "資料"},
+			want:  "<mark>会議</mark>の<mark>資料</mark>と<mark>会議</mark>室",
+		},
+		{
+			name:  "adjacent terms share one mark",
+			title: "定例会議資料",
+			terms: []string{"会議", "資料"},
+			want:  "定例<mark>会議資料</mark>",
+		},
+		{
+			name:  "overlapping terms",
+			title: "abcd",
+			terms: []string{"abc", "bcd"},
+			want:  "<mark>abcd</mark>",
+		},
+		{
+			name:  "case-insensitive, keeping the title's case",
+			title: "Weekly REPORT",
+			terms: []string{"report"},
+			want:  "Weekly <mark>REPORT</mark>",
+		},
+		{
+			name:  "HTML in the title is escaped",
+			title: `<script>alert("x")</script> & report`,
+			terms: []string{"report", "<script>"},
+			want:  `<mark>&lt;script&gt;</mark>alert(&#34;x&#34;)&lt;/script&gt; &amp; <mark>report</mark>`,
+		},
+		{
+			name:  "no match keeps the whole title",
+			title: "買い物リスト",
+			terms: []string{"会議"},
+			want:  "買い物リスト",
+		},
+		{
+			name:  "long title is cut around the first match",
+			title: long,
+			terms: []string{"会議"},
+			want:  "…" + strings.Repeat("あ", 30) + "<mark>会議</mark>" + strings.Repeat("い", 30) + "…",
+		},
+		{
+			name:  "lowercasing that changes the length",
+			title: "İstanbul trip",
+			terms: []string{"trip"},
+			want:  "İstanbul <mark>trip</mark>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightSnippet(tt.title, tt.terms); got != tt.want {
+				t.Errorf("highlightSnippet(%q, %q) =\n%s\nwant\n%s", tt.title, tt.terms, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// maxSnooze is how far ahead a todo can be snoozed
+const maxSnooze = 366 * 24 * time.Hour
+
+// snoozeUnits maps the units of snooze durations to minutes, hours, days and weeks
+var snoozeUnits = map[string]string{
+	"m": "minute", "min": "minute", "mins": "minute", "minute": "minute", "minutes": "minute",
+	"h": "hour", "hour": "hour", "hours": "hour",
+	"d": "day", "day": "day", "days": "day",
+	"w": "week", "week": "week", "weeks": "week",
+}
+
+// ParseSnoozeUntil works out when a snooze given as until ends. until is one of
+//
+//	a duration: 30m, 2h, 3d, 1w or "2 days"; days and weeks keep the time of day
+//	a day: tomorrow, monday (the coming Monday), next monday (the Monday of next week),
+//	next week (its Monday) or YYYY-MM-DD; weeks start on Monday
+//	an RFC 3339 time
+//
+// now is in the user's time zone, and a snooze until a day ends when that day starts there.
+// The end has to be in the future and within a year.
+func ParseSnoozeUntil(until string, now time.Time) (time.Time, error) {
+	until = strings.TrimSpace(until)
+	value := strings.ToLower(strings.Join(strings.Fields(until), " "))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var end time.Time
+	if value == "tomorrow" {
+		end = today.AddDate(0, 0, 1)
+	} else if value == "next week" {
+		end = weekdayInWeek(today, 1, time.Monday)
+	} else if weekday, ok := parseWeekday(strings.TrimPrefix(value, "next ")); ok {
+		if strings.HasPrefix(value, "next ") {
+			end = weekdayInWeek(today, 1, weekday)
+		} else {
+			end = nextWeekday(today, weekday)
+		}
+	} else if t, ok := addSnoozeDuration(value, now); ok {
+		end = t
+	} else if date, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		end = date
+	} else if t, err := time.Parse(time.RFC3339, until); err == nil {
+		end = t
+	} else {
+		return time.Time{}, domain.ErrInvalidSnooze
+	}
+
+	if !end.After(now) || end.After(now.Add(maxSnooze)) {
+		return time.Time{}, domain.ErrInvalidSnooze
+	}
+	return end, nil
+}
+
+// addSnoozeDuration adds a duration such as "3d" to now
+func addSnoozeDuration(value string, now time.Time) (time.Time, bool) {
+	digits := len(value) - len(strings.TrimLeft(value, "0123456789"))
+	// Longer numbers are out of range anyway, and could overflow
+	if digits == 0 || digits > 6 {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(value[:digits])
+	if err != nil || n == 0 {
+		return time.Time{}, false
+	}
+
+	switch snoozeUnits[strings.TrimSpace(value[digits:])] {
+	case "minute":
+		return now.Add(time.Duration(n) * time.Minute), true
+	case "hour":
+		return now.Add(time.Duration(n) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, n), true
+	case "week":
+		return now.AddDate(0, 0, 7*n), true
+	}
+	return time.Time{}, false
+}
+
+func parseWeekday(value string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if value == name || value == name[:3] {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
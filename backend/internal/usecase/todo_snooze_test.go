@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// snoozeNow is Wednesday 14 October 2026, 15:30 in the user's time zone
+var snoozeNow = time.Date(2026, 10, 14, 15, 30, 0, 0, jst)
+
+func TestParseSnoozeUntil(t *testing.T) {
+	tests := []struct {
+		until   string
+		want    string
+		wantErr bool
+	}{
+		{until: "30m", want: "2026-10-14T16:00:00+09:00"},
+		{until: "2h", want: "2026-10-14T17:30:00+09:00"},
+		{until: "90 minutes", want: "2026-10-14T17:00:00+09:00"},
+		{until: "3d", want: "2026-10-17T15:30:00+09:00"},
+		{until: "2 days", want: "2026-10-16T15:30:00+09:00"},
+		{until: "1w", want: "2026-10-21T15:30:00+09:00"},
+		{until: "tomorrow", want: "2026-10-15T00:00:00+09:00"},
+		{until: "next week", want: "2026-10-19T00:00:00+09:00"},
+		{until: "monday", want: "2026-10-19T00:00:00+09:00"},
+		{until: "next monday", want: "2026-10-19T00:00:00+09:00"},
+		{until: "wednesday", want: "2026-10-21T00:00:00+09:00"},
+		{until: "friday", want: "2026-10-16T00:00:00+09:00"},
+		{until: "fri", want: "2026-10-16T00:00:00+09:00"},
+		{until: "next friday", want: "2026-10-23T00:00:00+09:00"},
+		{until: " Next  Friday ", want: "2026-10-23T00:00:00+09:00"},
+		{until: "2026-11-01", want: "2026-11-01T00:00:00+09:00"},
+		{until: "2026-10-20T09:00:00Z", want: "2026-10-20T09:00:00Z"},
+		{until: "", wantErr: true},
+		{until: "yesterday", wantErr: true},
+		{until: "0d", wantErr: true},
+		{until: "3 fortnights", wantErr: true},
+		{until: "1234567m", wantErr: true},
+		{until: "2026-10-14", wantErr: true},
+		{until: "400d", wantErr: true},
+		{until: "2020-01-01T00:00:00Z", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.until, func(t *testing.T) {
+			got, err := ParseSnoozeUntil(tt.until, snoozeNow)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSnoozeUntil(%q) = %v, want an error", tt.until, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSnoozeUntil(%q) failed: %v", tt.until, err)
+			}
+			if want, _ := time.Parse(time.RFC3339, tt.want); !got.Equal(want) {
+				t.Errorf("ParseSnoozeUntil(%q) = %v, want %v", tt.until, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+// TestParseSnoozeUntilNextWeek checks "next" on every day of the week: it is always in the week after this one
+func TestParseSnoozeUntilNextWeek(t *testing.T) {
+	for day := 12; day <= 18; day++ {
+		now := time.Date(2026, 10, day, 9, 0, 0, 0, jst)
+		for until, want := range map[string]string{"next monday": "2026-10-19", "next friday": "2026-10-23", "next sunday": "2026-10-25", "next week": "2026-10-19"} {
+			got, err := ParseSnoozeUntil(until, now)
+			if err != nil {
+				t.Fatalf("%s: ParseSnoozeUntil(%q) failed: %v", now.Weekday(), until, err)
+			}
+			if got.Format("2006-01-02") != want {
+				t.Errorf("%s: ParseSnoozeUntil(%q) = %s, want %s", now.Weekday(), until, got.Format("2006-01-02"), want)
+			}
+		}
+	}
+}
+
+// TestSnoozeAgreesWithQuickAdd checks that both read weekdays the same way on every day of the week
+func TestSnoozeAgreesWithQuickAdd(t *testing.T) {
+	for day := 12; day <= 18; day++ {
+		now := time.Date(2026, 10, day, 9, 0, 0, 0, jst)
+		for _, until := range []string{"friday", "next friday", "monday", "next monday", "next week"} {
+			snoozed, err := ParseSnoozeUntil(until, now)
+			if err != nil {
+				t.Fatalf("%s: ParseSnoozeUntil(%q) failed: %v", now.Weekday(), until, err)
+			}
+			quickAdd, err := ParseQuickAdd("Task "+until, now)
+			if err != nil {
+				t.Fatalf("%s: ParseQuickAdd(%q) failed: %v", now.Weekday(), until, err)
+			}
+			if got, want := snoozed.Format("2006-01-02"), quickAdd.DueDate.Format("2006-01-02"); got != want {
+				t.Errorf("%s: %q snoozes until %s, but quick-add is due %s", now.Weekday(), until, got, want)
+			}
+		}
+	}
+}
+
+func TestSnoozeTodo(t *testing.T) {
+	ctx := context.Background()
+	todoRepo := newFakeTodoRepo(&domain.Todo{ID: 1, Version: 3})
+	interactor := &TodoInteractor{todoRepo: todoRepo, userRepo: &fakeUserRepo{}}
+
+	before := time.Now()
+	todo, err := interactor.SnoozeTodo(ctx, 1, 1, "2h", intPtr(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := todoRepo.todos[1]
+	if stored.SnoozedUntil == nil || stored.SnoozedUntil.Before(before.Add(2*time.Hour)) || stored.SnoozedUntil.After(time.Now().Add(2*time.Hour)) {
+		t.Errorf("snoozed until %v, want 2 hours from now", stored.SnoozedUntil)
+	}
+	if todo.Version != 4 {
+		t.Errorf("version %d, want 4", todo.Version)
+	}
+
+	if _, err := interactor.SnoozeTodo(ctx, 1, 1, "yesterday", nil); err != domain.ErrInvalidSnooze {
+		t.Errorf("snoozing into the past: got %v, want ErrInvalidSnooze", err)
+	}
+	if _, err := interactor.UnsnoozeTodo(ctx, 1, 1, intPtr(3)); err != domain.ErrTodoVersionMismatch {
+		t.Errorf("unsnoozing a stale version: got %v, want ErrTodoVersionMismatch", err)
+	}
+	if _, err := interactor.SnoozeTodo(ctx, 1, 2, "2h", nil); err != domain.ErrTodoNotFound {
+		t.Errorf("snoozing an unknown todo: got %v, want ErrTodoNotFound", err)
+	}
+
+	if _, err := interactor.UnsnoozeTodo(ctx, 1, 1, intPtr(4)); err != nil {
+		t.Fatal(err)
+	}
+	if todoRepo.todos[1].SnoozedUntil != nil {
+		t.Errorf("still snoozed until %v", todoRepo.todos[1].SnoozedUntil)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"time"
+	"todo-app/internal/domain"
+)
+
+const (
+	// UndoWindow is how far back POST /undo reaches; older operations stay as they are
+	UndoWindow        = 15 * time.Minute
+	MaxUndoOperations = 20
+)
+
+// UndoResult is what Undo reverted
+type UndoResult struct {
+	// Operations is the number of operations undone, at most the requested count
+	Operations int
+	// Todos are the reverted todos in their current state, including restored ones
+	Todos []*domain.Todo
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+type TokenBlacklistRepository interface {
+	AddToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenBlacklisted(ctx context.Context, tokenID string) (bool, error)
+	// RevokeUserTokens moves the user on to the next token generation, see domain.User.TokenGeneration
+	RevokeUserTokens(ctx context.Context, userID int) error
+	GetUserTokenGeneration(ctx context.Context, userID int) (int, error)
+	CleanupExpiredTokens(ctx context.Context) error
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const DefaultTokenCleanupInterval = time.Hour
+
+// TokenCleaner deletes expired refresh tokens, which are kept until then to detect reuse,
+// and blacklist entries of access tokens that have expired on their own
+type TokenCleaner struct {
+	refreshTokenRepo RefreshTokenRepository
+	blacklistRepo    TokenBlacklistRepository
+	interval         time.Duration
+}
+
+func NewTokenCleaner(refreshTokenRepo RefreshTokenRepository, blacklistRepo TokenBlacklistRepository, interval time.Duration) *TokenCleaner {
+	return &TokenCleaner{
+		refreshTokenRepo: refreshTokenRepo,
+		blacklistRepo:    blacklistRepo,
+		interval:         interval,
+	}
+}
+
+// Run cleans up once at start and then every interval until ctx is cancelled
+func (tc *TokenCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(tc.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := tc.refreshTokenRepo.CleanupExpiredTokens(ctx); err != nil {
+			log.Printf("Failed to clean up refresh tokens: %v", err)
+		}
+		if err := tc.blacklistRepo.CleanupExpiredTokens(ctx); err != nil {
+			log.Printf("Failed to clean up the token blacklist: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	DefaultTrashRetention     = 30 * 24 * time.Hour
+	DefaultTrashPurgeInterval = time.Hour
+)
+
+// TrashPurger permanently deletes todos that have been in the trash longer than the retention
+type TrashPurger struct {
+	todoRepo  TodoRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashPurger(todoRepo TodoRepository, retention time.Duration, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		todoRepo:  todoRepo,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run purges once at start and then every interval until ctx is cancelled
+func (tp *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(tp.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := tp.PurgeExpired(ctx, time.Now()); err != nil {
+			log.Printf("Failed to purge trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d todos from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired deletes the todos trashed more than the retention before now
+func (tp *TrashPurger) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	return tp.todoRepo.PurgeTrash(ctx, now.Add(-tp.retention))
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func TestTrashPurgerPurgeExpired(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	trashedAt := func(age time.Duration) *domain.Todo {
+		deletedAt := now.Add(-age)
+		return &domain.Todo{DeletedAt: &deletedAt}
+	}
+
+	todoRepo := newFakeTodoRepo()
+	todoRepo.todos[1] = trashedAt(31 * 24 * time.Hour)
+	todoRepo.todos[2] = trashedAt(30*24*time.Hour + time.Second)
+	todoRepo.todos[3] = trashedAt(29 * 24 * time.Hour)
+	todoRepo.todos[4] = &domain.Todo{}
+
+	purged, err := NewTrashPurger(todoRepo, DefaultTrashRetention, time.Hour).PurgeExpired(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Errorf("purged %d todos, want 2", purged)
+	}
+	for _, id := range []int{3, 4} {
+		if _, ok := todoRepo.todos[id]; !ok {
+			t.Errorf("todo %d was purged", id)
+		}
+	}
+}
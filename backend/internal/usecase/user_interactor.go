@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
 	"time"
 	"todo-app/internal/domain"
@@ -13,22 +15,35 @@ import (
 // UserUseCase defines the user use case interface
 type UserUseCase interface {
 	Register(ctx context.Context, username, email, password string) (*domain.User, error)
-	Login(ctx context.Context, username, password string) (string, error)
+	Login(ctx context.Context, username, password string) (*domain.TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenPair, error)
 	GetUserByID(ctx context.Context, userID int) (*domain.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
-	UpdateProfile(ctx context.Context, userID int, username, email, currentPassword, newPassword string) (*domain.User, error)
+	UpdateProfile(ctx context.Context, userID int, username, email, currentPassword, newPassword, timezone string) (*domain.User, error)
 	ValidateJWTToken(tokenString string) (*jwt.MapClaims, error)
-	Logout(ctx context.Context, tokenString string) error
+	ValidateAccessToken(ctx context.Context, tokenString string) (*jwt.MapClaims, error)
+	Logout(ctx context.Context, tokenString, refreshToken string) error
+	LogoutAll(ctx context.Context, userID int) error
 }
 
+const (
+	// accessTokenTTL is kept short because access tokens cannot be revoked
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 7 * 24 * time.Hour
+)
+
 // UserInteractor implements UserUseCase
 type UserInteractor struct {
-	UserRepository UserRepository
+	UserRepository           UserRepository
+	RefreshTokenRepository   RefreshTokenRepository
+	TokenBlacklistRepository TokenBlacklistRepository
 }
 
-func NewUserInteractor(userRepo UserRepository) UserUseCase {
+func NewUserInteractor(userRepo UserRepository, refreshTokenRepo RefreshTokenRepository, tokenBlacklistRepo TokenBlacklistRepository) UserUseCase {
 	return &UserInteractor{
-		UserRepository: userRepo,
+		UserRepository:           userRepo,
+		RefreshTokenRepository:   refreshTokenRepo,
+		TokenBlacklistRepository: tokenBlacklistRepo,
 	}
 }
 
@@ -64,30 +79,68 @@ func (ui *UserInteractor) Register(ctx context.Context, username, email, passwor
 	return user, nil
 }
 
-func (ui *UserInteractor) Login(ctx context.Context, username, password string) (string, error) {
+func (ui *UserInteractor) Login(ctx context.Context, username, password string) (*domain.TokenPair, error) {
 	user, err := ui.UserRepository.GetUserByUsername(ctx, username)
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
 	}
 
 	// Check if user exists
 	if user == nil {
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
 	}
 
 	// Compare password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return "", domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	// Every login starts a new refresh token family
+	familyID, err := generateTokenID()
+	if err != nil {
+		return nil, domain.WrapError(err, "TOKEN_GENERATION_FAILED", "トークンの生成に失敗しました", 500)
+	}
+
+	return ui.issueTokenPair(ctx, user, familyID)
+}
+
+// RefreshToken rotates a refresh token and issues a new access token.
+// Presenting a token that has already been rotated revokes its whole family,
+// since it means the token was copied and used by someone else.
+func (ui *UserInteractor) RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, domain.ErrRefreshTokenInvalid
 	}
 
-	// Generate JWT token
-	token, err := ui.generateJWTToken(user.ID, user.Username)
+	consumed, err := ui.RefreshTokenRepository.ConsumeRefreshToken(ctx, refreshToken)
 	if err != nil {
-		return "", domain.WrapError(err, "TOKEN_GENERATION_FAILED", "トークンの生成に失敗しました", 500)
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "リフレッシュトークンの検証に失敗しました", 500)
+	}
+
+	if consumed == nil {
+		stored, err := ui.RefreshTokenRepository.GetRefreshToken(ctx, refreshToken)
+		if err != nil {
+			return nil, domain.WrapError(err, "DATABASE_ERROR", "リフレッシュトークンの検証に失敗しました", 500)
+		}
+
+		// Reuse of a rotated token: revoke every token derived from the same login
+		if stored != nil && stored.IsRevoked {
+			if err := ui.RefreshTokenRepository.RevokeTokenFamily(ctx, stored.FamilyID); err != nil {
+				return nil, domain.WrapError(err, "DATABASE_ERROR", "リフレッシュトークンの失効に失敗しました", 500)
+			}
+			return nil, domain.ErrRefreshTokenReused
+		}
+
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	user, err := ui.UserRepository.GetUserByID(ctx, consumed.UserID)
+	if err != nil || user == nil {
+		return nil, domain.ErrRefreshTokenInvalid
 	}
 
-	return token, nil
+	return ui.issueTokenPair(ctx, user, consumed.FamilyID)
 }
 
 func (ui *UserInteractor) GetUserByID(ctx context.Context, userID int) (*domain.User, error) {
@@ -108,7 +161,7 @@ func (ui *UserInteractor) GetUserByUsername(ctx context.Context, username string
 	return ui.UserRepository.GetUserByUsername(ctx, username)
 }
 
-func (ui *UserInteractor) UpdateProfile(ctx context.Context, userID int, username, email, currentPassword, newPassword string) (*domain.User, error) {
+func (ui *UserInteractor) UpdateProfile(ctx context.Context, userID int, username, email, currentPassword, newPassword, timezone string) (*domain.User, error) {
 	// Get current user
 	user, err := ui.UserRepository.GetUserByID(ctx, userID)
 	if err != nil {
@@ -140,6 +193,14 @@ func (ui *UserInteractor) UpdateProfile(ctx context.Context, userID int, usernam
 	user.Username = username
 	user.Email = email
 
+	// Update time zone if provided
+	if timezone != "" {
+		if _, err := domain.LoadTimezone(timezone); err != nil {
+			return nil, err
+		}
+		user.Timezone = timezone
+	}
+
 	// Update password if provided
 	if newPassword != "" {
 		// Verify current password
@@ -165,16 +226,53 @@ func (ui *UserInteractor) UpdateProfile(ctx context.Context, userID int, usernam
 	return user, nil
 }
 
-func (ui *UserInteractor) generateJWTToken(userID int, username string) (string, error) {
+// issueTokenPair creates an access token and a refresh token belonging to familyID
+func (ui *UserInteractor) issueTokenPair(ctx context.Context, user *domain.User, familyID string) (*domain.TokenPair, error) {
+	now := time.Now()
+	accessExpiresAt := now.Add(accessTokenTTL)
+	refreshExpiresAt := now.Add(refreshTokenTTL)
+
+	accessToken, err := ui.generateJWTToken(user, accessExpiresAt)
+	if err != nil {
+		return nil, domain.WrapError(err, "TOKEN_GENERATION_FAILED", "トークンの生成に失敗しました", 500)
+	}
+
+	refreshToken, err := generateTokenID()
+	if err != nil {
+		return nil, domain.WrapError(err, "TOKEN_GENERATION_FAILED", "トークンの生成に失敗しました", 500)
+	}
+
+	err = ui.RefreshTokenRepository.StoreRefreshToken(ctx, refreshToken, familyID, user.ID, refreshExpiresAt)
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "リフレッシュトークンの保存に失敗しました", 500)
+	}
+
+	return &domain.TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+func (ui *UserInteractor) generateJWTToken(user *domain.User, expiresAt time.Time) (string, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "your-secret-key-change-in-production"
 	}
 
+	// jti identifies the token so that it can be blacklisted on logout
+	tokenID, err := generateTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := jwt.MapClaims{
-		"user_id":  userID,
-		"username": username,
-		"exp":      time.Now().Add(24 * time.Hour).Unix(),
+		"user_id":  user.ID,
+		"username": user.Username,
+		"jti":      tokenID,
+		"gen":      user.TokenGeneration,
+		"exp":      expiresAt.Unix(),
 		"iat":      time.Now().Unix(),
 	}
 
@@ -182,6 +280,15 @@ func (ui *UserInteractor) generateJWTToken(userID int, username string) (string,
 	return token.SignedString([]byte(jwtSecret))
 }
 
+// generateTokenID returns a random 256-bit identifier encoded as hex
+func generateTokenID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 func (ui *UserInteractor) ValidateJWTToken(tokenString string) (*jwt.MapClaims, error) {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -206,8 +313,76 @@ func (ui *UserInteractor) ValidateJWTToken(tokenString string) (*jwt.MapClaims,
 	return nil, domain.ErrTokenInvalid
 }
 
-func (ui *UserInteractor) Logout(ctx context.Context, tokenString string) error {
-	// Simple logout - just return success
-	// In a stateless JWT system, logout is handled client-side by removing the token
+// ValidateAccessToken validates the signature of the token and rejects tokens
+// that have been revoked by logout or by logging out everywhere
+func (ui *UserInteractor) ValidateAccessToken(ctx context.Context, tokenString string) (*jwt.MapClaims, error) {
+	claims, err := ui.ValidateJWTToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if jti, ok := (*claims)["jti"].(string); ok {
+		blacklisted, err := ui.TokenBlacklistRepository.IsTokenBlacklisted(ctx, jti)
+		if err != nil {
+			return nil, domain.WrapError(err, "DATABASE_ERROR", "トークンの検証に失敗しました", 500)
+		}
+		if blacklisted {
+			return nil, domain.ErrTokenRevoked
+		}
+	}
+
+	userIDFloat, ok := (*claims)["user_id"].(float64)
+	if !ok {
+		return nil, domain.ErrTokenInvalid
+	}
+
+	generation, err := ui.TokenBlacklistRepository.GetUserTokenGeneration(ctx, int(userIDFloat))
+	if err != nil {
+		return nil, domain.WrapError(err, "DATABASE_ERROR", "トークンの検証に失敗しました", 500)
+	}
+	// Tokens issued before the user last logged out everywhere belong to an earlier generation.
+	// Tokens without gen count as generation 0.
+	tokenGeneration, _ := (*claims)["gen"].(float64)
+	if int(tokenGeneration) < generation {
+		return nil, domain.ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+func (ui *UserInteractor) Logout(ctx context.Context, tokenString, refreshToken string) error {
+	// Blacklist the access token until it would have expired on its own
+	if tokenString != "" {
+		if claims, err := ui.ValidateJWTToken(tokenString); err == nil {
+			jti, hasJTI := (*claims)["jti"].(string)
+			expiresAt, err := claims.GetExpirationTime()
+			if hasJTI && err == nil && expiresAt != nil {
+				if err := ui.TokenBlacklistRepository.AddToken(ctx, jti, expiresAt.Time); err != nil {
+					return domain.WrapError(err, "DATABASE_ERROR", "トークンの失効に失敗しました", 500)
+				}
+			}
+		}
+	}
+
+	// Revoking the refresh token prevents the session from being extended any further
+	if refreshToken != "" {
+		if err := ui.RefreshTokenRepository.RevokeRefreshToken(ctx, refreshToken); err != nil {
+			return domain.WrapError(err, "DATABASE_ERROR", "リフレッシュトークンの失効に失敗しました", 500)
+		}
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every access and refresh token issued to the user so far
+func (ui *UserInteractor) LogoutAll(ctx context.Context, userID int) error {
+	if err := ui.TokenBlacklistRepository.RevokeUserTokens(ctx, userID); err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "トークンの失効に失敗しました", 500)
+	}
+
+	if err := ui.RefreshTokenRepository.RevokeAllUserTokens(ctx, userID); err != nil {
+		return domain.WrapError(err, "DATABASE_ERROR", "リフレッシュトークンの失効に失敗しました", 500)
+	}
+
 	return nil
 }
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// fakeRefreshTokens keeps refresh tokens in memory, and like the database
+// only consumes tokens that are neither revoked nor expired
+type fakeRefreshTokens struct {
+	tokens map[string]*domain.RefreshToken
+}
+
+func newFakeRefreshTokens() *fakeRefreshTokens {
+	return &fakeRefreshTokens{tokens: map[string]*domain.RefreshToken{}}
+}
+
+func (f *fakeRefreshTokens) StoreRefreshToken(ctx context.Context, tokenID, familyID string, userID int, expiresAt time.Time) error {
+	f.tokens[tokenID] = &domain.RefreshToken{TokenID: tokenID, FamilyID: familyID, UserID: userID, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (f *fakeRefreshTokens) GetRefreshToken(ctx context.Context, tokenID string) (*domain.RefreshToken, error) {
+	token, ok := f.tokens[tokenID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (f *fakeRefreshTokens) ConsumeRefreshToken(ctx context.Context, tokenID string) (*domain.RefreshToken, error) {
+	token, ok := f.tokens[tokenID]
+	if !ok || token.IsRevoked || !token.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	token.IsRevoked = true
+	copied := *token
+	return &copied, nil
+}
+
+func (f *fakeRefreshTokens) RevokeRefreshToken(ctx context.Context, tokenID string) error {
+	if token, ok := f.tokens[tokenID]; ok {
+		token.IsRevoked = true
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokens) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	for _, token := range f.tokens {
+		if token.FamilyID == familyID {
+			token.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokens) RevokeAllUserTokens(ctx context.Context, userID int) error {
+	for _, token := range f.tokens {
+		if token.UserID == userID {
+			token.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeRefreshTokens) CleanupExpiredTokens(ctx context.Context) error {
+	return nil
+}
+
+type fakeUserRepo struct {
+	UserRepository
+	// timezone is the user's time zone, UTC when empty
+	timezone string
+}
+
+func (r *fakeUserRepo) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
+	timezone := r.timezone
+	if timezone == "" {
+		timezone = domain.DefaultTimezone
+	}
+	return &domain.User{ID: id, Username: "alice", Timezone: timezone}, nil
+}
+
+func newTestUserInteractor() (*UserInteractor, *fakeRefreshTokens) {
+	refreshTokens := newFakeRefreshTokens()
+	return &UserInteractor{UserRepository: &fakeUserRepo{}, RefreshTokenRepository: refreshTokens}, refreshTokens
+}
+
+// login issues the refresh token of a new login
+func login(t *testing.T, ui *UserInteractor, familyID string) string {
+	t.Helper()
+	tokens, err := ui.issueTokenPair(context.Background(), &domain.User{ID: 1, Username: "alice"}, familyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens.RefreshToken
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	ui, store := newTestUserInteractor()
+	token := login(t, ui, "family")
+
+	for i := 0; i < 3; i++ {
+		tokens, err := ui.RefreshToken(ctx, token)
+		if err != nil {
+			t.Fatalf("refresh %d failed: %v", i, err)
+		}
+		if tokens.RefreshToken == token || tokens.AccessToken == "" {
+			t.Fatalf("refresh %d did not issue new tokens", i)
+		}
+		if !store.tokens[token].IsRevoked {
+			t.Errorf("refresh %d left the presented token usable", i)
+		}
+		rotated := store.tokens[tokens.RefreshToken]
+		if rotated == nil || rotated.IsRevoked || rotated.FamilyID != "family" {
+			t.Fatalf("refresh %d stored %+v, want a live token of the same family", i, rotated)
+		}
+		if !tokens.RefreshTokenExpiresAt.After(time.Now().Add(refreshTokenTTL - time.Minute)) {
+			t.Errorf("refresh %d: refresh token expires at %v, want a full %v later", i, tokens.RefreshTokenExpiresAt, refreshTokenTTL)
+		}
+		token = tokens.RefreshToken
+	}
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	tests := []struct {
+		name string
+		// replay is the index of the token to present again after three rotations, 0 for the one issued at login
+		replay int
+	}{
+		{name: "the token issued at login", replay: 0},
+		{name: "a rotated token", replay: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ui, store := newTestUserInteractor()
+			issued := []string{login(t, ui, "stolen")}
+			other := login(t, ui, "other")
+			for i := 0; i < 3; i++ {
+				tokens, err := ui.RefreshToken(ctx, issued[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				issued = append(issued, tokens.RefreshToken)
+			}
+
+			if _, err := ui.RefreshToken(ctx, issued[tt.replay]); err != domain.ErrRefreshTokenReused {
+				t.Fatalf("replaying a rotated token: got %v, want ErrRefreshTokenReused", err)
+			}
+			// The latest token of the family is revoked with the rest, and other logins keep working
+			if _, err := ui.RefreshToken(ctx, issued[len(issued)-1]); err != domain.ErrRefreshTokenReused {
+				t.Errorf("latest token of the family: got %v, want ErrRefreshTokenReused", err)
+			}
+			for _, token := range store.tokens {
+				if token.FamilyID == "stolen" && !token.IsRevoked {
+					t.Errorf("token %s of the family is still live", token.TokenID)
+				}
+			}
+			if _, err := ui.RefreshToken(ctx, other); err != nil {
+				t.Errorf("token of another login: got %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  *domain.RefreshToken
+		present string
+	}{
+		{name: "no token", present: ""},
+		{name: "unknown token", present: "unknown"},
+		{
+			name:    "expired token",
+			stored:  &domain.RefreshToken{TokenID: "expired", FamilyID: "family", UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)},
+			present: "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui, store := newTestUserInteractor()
+			live := login(t, ui, "family")
+			if tt.stored != nil {
+				store.tokens[tt.stored.TokenID] = tt.stored
+			}
+
+			if _, err := ui.RefreshToken(context.Background(), tt.present); err != domain.ErrRefreshTokenInvalid {
+				t.Fatalf("RefreshToken(%q) error = %v, want ErrRefreshTokenInvalid", tt.present, err)
+			}
+			// A token that is merely invalid is no sign of theft, so the family is left alone
+			if store.tokens[live].IsRevoked {
+				t.Error("the live token of the family was revoked")
+			}
+		})
+	}
+}
+
+// fakeBlacklist keeps the blacklist and the users' token generations in memory
+type fakeBlacklist struct {
+	tokens      map[string]time.Time
+	generations map[int]int
+}
+
+func newFakeBlacklist() *fakeBlacklist {
+	return &fakeBlacklist{tokens: map[string]time.Time{}, generations: map[int]int{}}
+}
+
+func (f *fakeBlacklist) AddToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	f.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (f *fakeBlacklist) IsTokenBlacklisted(ctx context.Context, tokenID string) (bool, error) {
+	_, ok := f.tokens[tokenID]
+	return ok, nil
+}
+
+func (f *fakeBlacklist) RevokeUserTokens(ctx context.Context, userID int) error {
+	f.generations[userID]++
+	return nil
+}
+
+func (f *fakeBlacklist) GetUserTokenGeneration(ctx context.Context, userID int) (int, error) {
+	return f.generations[userID], nil
+}
+
+func (f *fakeBlacklist) CleanupExpiredTokens(ctx context.Context) error {
+	return nil
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	tests := []struct {
+		name string
+		// generation is the user's token generation when the token is issued
+		generation int
+		// revoke runs after the token is issued
+		revoke  func(ui *UserInteractor, token string) error
+		wantErr error
+	}{
+		{name: "live token"},
+		{name: "issued after logging out everywhere", generation: 2},
+		{
+			name: "logged out",
+			revoke: func(ui *UserInteractor, token string) error {
+				return ui.Logout(context.Background(), token, "")
+			},
+			wantErr: domain.ErrTokenRevoked,
+		},
+		{
+			name: "logged out everywhere",
+			revoke: func(ui *UserInteractor, token string) error {
+				return ui.LogoutAll(context.Background(), 1)
+			},
+			wantErr: domain.ErrTokenRevoked,
+		},
+		{
+			name: "another user logged out everywhere",
+			revoke: func(ui *UserInteractor, token string) error {
+				return ui.LogoutAll(context.Background(), 2)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			blacklist := newFakeBlacklist()
+			blacklist.generations[1] = tt.generation
+			ui := &UserInteractor{RefreshTokenRepository: newFakeRefreshTokens(), TokenBlacklistRepository: blacklist}
+
+			user := &domain.User{ID: 1, Username: "alice", TokenGeneration: tt.generation}
+			token, err := ui.generateJWTToken(user, time.Now().Add(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.revoke != nil {
+				if err := tt.revoke(ui, token); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := ui.ValidateAccessToken(ctx, token); err != tt.wantErr {
+				t.Errorf("ValidateAccessToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLogoutAllKeepsLaterLogins(t *testing.T) {
+	ctx := context.Background()
+	blacklist := newFakeBlacklist()
+	ui := &UserInteractor{RefreshTokenRepository: newFakeRefreshTokens(), TokenBlacklistRepository: blacklist}
+	user := &domain.User{ID: 1, Username: "alice"}
+
+	before, err := ui.generateJWTToken(user, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ui.LogoutAll(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	// A login in the same second reads the user with the new generation
+	user.TokenGeneration = blacklist.generations[user.ID]
+	after, err := ui.generateJWTToken(user, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ui.ValidateAccessToken(ctx, before); err != domain.ErrTokenRevoked {
+		t.Errorf("token issued before logging out everywhere: got %v, want ErrTokenRevoked", err)
+	}
+	if _, err := ui.ValidateAccessToken(ctx, after); err != nil {
+		t.Errorf("token issued after logging out everywhere: got %v, want no error", err)
+	}
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import "time"
+
+// The parsers of quick-add and snooze read weekdays the same way:
+// "friday" is the coming Friday and "next friday" is the Friday of next week.
+
+// nextWeekday returns the first day after today that falls on weekday
+func nextWeekday(today time.Time, weekday time.Weekday) time.Time {
+	days := (int(weekday) - int(today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// weekdayInWeek returns weekday in the week weeks after the one of today; weeks start on Monday
+func weekdayInWeek(today time.Time, weeks int, weekday time.Weekday) time.Time {
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, 7*weeks+(int(weekday)+6)%7)
+}
@@ -0,0 +1,3 @@
+-- Drop token revocation table and column
+ALTER TABLE users DROP COLUMN IF EXISTS token_generation;
+DROP TABLE IF EXISTS token_blacklist;
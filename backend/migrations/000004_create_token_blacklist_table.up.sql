@@ -0,0 +1,13 @@
+-- Create token_blacklist table for revoked access tokens
+CREATE TABLE token_blacklist (
+    token_id VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add the token generation for "log out everywhere"
+-- Access tokens carry the generation they were issued in; those of an earlier generation are rejected
+ALTER TABLE users ADD COLUMN token_generation INTEGER NOT NULL DEFAULT 0;
+
+-- Create indexes
+CREATE INDEX idx_token_blacklist_expires_at ON token_blacklist(expires_at);
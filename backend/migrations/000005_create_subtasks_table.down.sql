@@ -0,0 +1,2 @@
+-- Drop subtasks table
+DROP TABLE IF EXISTS subtasks;
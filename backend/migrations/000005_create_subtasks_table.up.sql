@@ -0,0 +1,19 @@
+-- Create subtasks table (checklist items under a todo)
+CREATE TABLE subtasks (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    is_completed BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_subtasks_todo_id_position ON subtasks(todo_id, position);
+
+-- Create trigger for subtasks table
+CREATE TRIGGER update_subtasks_updated_at
+    BEFORE UPDATE ON subtasks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
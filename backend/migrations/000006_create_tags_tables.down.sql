@@ -0,0 +1,3 @@
+-- Drop tag tables
+DROP TABLE IF EXISTS todo_tags;
+DROP TABLE IF EXISTS tags;
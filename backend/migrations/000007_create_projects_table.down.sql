@@ -0,0 +1,3 @@
+-- Drop project reference and projects table
+ALTER TABLE todos DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS projects;
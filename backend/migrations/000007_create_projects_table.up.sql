@@ -0,0 +1,26 @@
+-- Create projects table
+CREATE TABLE projects (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#808080',
+    is_archived BOOLEAN NOT NULL DEFAULT FALSE,
+    is_inbox BOOLEAN NOT NULL DEFAULT FALSE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add project reference to todos
+ALTER TABLE todos ADD COLUMN project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_projects_user_id ON projects(user_id);
+CREATE UNIQUE INDEX idx_projects_user_inbox ON projects(user_id) WHERE is_inbox;
+CREATE INDEX idx_todos_project_id ON todos(project_id);
+
+-- Create trigger for projects table
+CREATE TRIGGER update_projects_updated_at
+    BEFORE UPDATE ON projects
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Drop recurrence settings from todos
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence_from_completion;
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence_rule;
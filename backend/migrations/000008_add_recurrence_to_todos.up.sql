@@ -0,0 +1,4 @@
+-- Add recurrence settings to todos
+-- recurrence_rule holds an RFC 5545 RRULE value such as FREQ=WEEKLY;BYDAY=MO,WE
+ALTER TABLE todos ADD COLUMN recurrence_rule TEXT;
+ALTER TABLE todos ADD COLUMN recurrence_from_completion BOOLEAN NOT NULL DEFAULT FALSE;
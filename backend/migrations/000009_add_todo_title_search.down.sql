@@ -0,0 +1,4 @@
+-- Drop trigram index on todo titles
+DROP INDEX IF EXISTS idx_todos_title_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,7 @@
+-- Enable trigram matching for todo search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Create trigram index on todo titles
+-- Serves both substring matching (ILIKE '%...%') and similarity (<%), so partial words
+-- match without a word splitter, which Japanese titles do not have
+CREATE INDEX idx_todos_title_trgm ON todos USING GIN (title gin_trgm_ops);
@@ -0,0 +1,6 @@
+-- Drop soft delete from todos
+-- Todos in the trash would reappear as live ones, so they are removed for good
+DELETE FROM todos WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_todos_deleted_at;
+ALTER TABLE todos DROP COLUMN IF EXISTS deleted_at;
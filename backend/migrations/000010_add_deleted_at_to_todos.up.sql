@@ -0,0 +1,6 @@
+-- Add soft delete to todos
+-- Deleted todos stay in the trash with deleted_at set until they are restored or purged
+ALTER TABLE todos ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create index for the trash listing and the purger
+CREATE INDEX idx_todos_deleted_at ON todos(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Drop todo_events table
+DROP TABLE IF EXISTS todo_events;
@@ -0,0 +1,14 @@
+-- Create todo_events table
+-- Each row is one create/update/toggle/delete/restore of a todo, written in the same
+-- transaction as the change. changes maps a field name to {"before": ..., "after": ...}
+CREATE TABLE todo_events (
+    id BIGSERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_todo_events_todo_id ON todo_events(todo_id, created_at DESC);
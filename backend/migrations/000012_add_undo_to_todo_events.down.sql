@@ -0,0 +1,4 @@
+-- Drop undo support from todo_events
+DROP INDEX IF EXISTS idx_todo_events_actor_id;
+ALTER TABLE todo_events DROP COLUMN IF EXISTS undone_at;
+ALTER TABLE todo_events DROP COLUMN IF EXISTS operation_id;
@@ -0,0 +1,8 @@
+-- Add undo support to todo_events
+-- operation_id groups the events written by one transaction, e.g. every todo of a bulk
+-- change, so that they are undone together. undone_at marks events that were reverted.
+ALTER TABLE todo_events ADD COLUMN operation_id BIGINT NOT NULL DEFAULT txid_current();
+ALTER TABLE todo_events ADD COLUMN undone_at TIMESTAMP WITH TIME ZONE;
+
+-- Create index for finding the latest operations of a user
+CREATE INDEX idx_todo_events_actor_id ON todo_events(actor_id, created_at DESC) WHERE undone_at IS NULL;
@@ -0,0 +1,8 @@
+-- Drop version triggers
+DROP TRIGGER IF EXISTS touch_subtasks_todo ON subtasks;
+DROP FUNCTION IF EXISTS touch_subtask_todo();
+DROP TRIGGER IF EXISTS increment_todos_version ON todos;
+DROP FUNCTION IF EXISTS increment_todo_version();
+
+-- Drop version column from todos
+ALTER TABLE todos DROP COLUMN IF EXISTS version;
@@ -0,0 +1,35 @@
+-- Add version to todos for optimistic concurrency control (ETag / If-Match)
+ALTER TABLE todos ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Create trigger function incrementing version on every update of a todo
+CREATE OR REPLACE FUNCTION increment_todo_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER increment_todos_version
+    BEFORE UPDATE ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION increment_todo_version();
+
+-- Create trigger function touching the parent todo when its subtasks change,
+-- since the subtask progress is part of the todo's representation
+CREATE OR REPLACE FUNCTION touch_subtask_todo()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE todos SET version = version + 1 WHERE id = OLD.todo_id;
+        RETURN OLD;
+    END IF;
+    UPDATE todos SET version = version + 1 WHERE id = NEW.todo_id;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER touch_subtasks_todo
+    AFTER INSERT OR UPDATE OR DELETE ON subtasks
+    FOR EACH ROW
+    EXECUTE FUNCTION touch_subtask_todo();
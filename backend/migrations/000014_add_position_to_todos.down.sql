@@ -0,0 +1,18 @@
+-- Drop position index
+DROP INDEX IF EXISTS idx_todos_user_position;
+
+-- Fire the triggers on every update again
+DROP TRIGGER IF EXISTS update_todos_updated_at ON todos;
+CREATE TRIGGER update_todos_updated_at
+    BEFORE UPDATE ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS increment_todos_version ON todos;
+CREATE TRIGGER increment_todos_version
+    BEFORE UPDATE ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION increment_todo_version();
+
+-- Drop position column from todos
+ALTER TABLE todos DROP COLUMN IF EXISTS position;
@@ -0,0 +1,37 @@
+-- Add manual ordering position to todos
+-- Positions are fractional keys compared byte by byte, so moving a todo only rewrites its own key
+ALTER TABLE todos ADD COLUMN position TEXT COLLATE "C";
+
+-- Renumbering the manual order keys keeps the order, so it must not look like an edit:
+-- while todo_app.renumbering_positions is on in the transaction, updated_at and version stay as they are.
+DROP TRIGGER IF EXISTS update_todos_updated_at ON todos;
+CREATE TRIGGER update_todos_updated_at
+    BEFORE UPDATE ON todos
+    FOR EACH ROW
+    WHEN (current_setting('todo_app.renumbering_positions', true) IS DISTINCT FROM 'on')
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS increment_todos_version ON todos;
+CREATE TRIGGER increment_todos_version
+    BEFORE UPDATE ON todos
+    FOR EACH ROW
+    WHEN (current_setting('todo_app.renumbering_positions', true) IS DISTINCT FROM 'on')
+    EXECUTE FUNCTION increment_todo_version();
+
+-- Number existing todos newest first, matching the default listing.
+-- The migration runs as one transaction, so the flag only covers it.
+SELECT set_config('todo_app.renumbering_positions', 'on', true);
+
+UPDATE todos SET position = ranked.position
+FROM (
+    SELECT id, lpad(to_hex(2 * row_number() OVER (PARTITION BY user_id ORDER BY created_at DESC, id DESC) + 1), 8, '0') AS position
+    FROM todos
+) ranked
+WHERE todos.id = ranked.id;
+
+SELECT set_config('todo_app.renumbering_positions', 'off', true);
+
+ALTER TABLE todos ALTER COLUMN position SET NOT NULL;
+
+-- Create index for the manual order and the neighbour lookups of moves
+CREATE INDEX idx_todos_user_position ON todos(user_id, position);
@@ -0,0 +1,2 @@
+-- Drop notes column from todos
+ALTER TABLE todos DROP COLUMN IF EXISTS notes;
@@ -0,0 +1,2 @@
+-- Add Markdown notes to todos
+ALTER TABLE todos ADD COLUMN notes TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- Drop trigger and function
+DROP TRIGGER IF EXISTS queue_attachments_blob_deletion ON attachments;
+DROP FUNCTION IF EXISTS queue_attachment_blob_deletion();
+
+-- Drop tables
+DROP TABLE IF EXISTS blob_deletions;
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,35 @@
+-- Create attachments table; the bytes live in the blob store under storage_key
+CREATE TABLE attachments (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_attachments_todo_id ON attachments(todo_id);
+
+-- Create blob_deletions table, the blobs waiting to be removed from the blob store
+CREATE TABLE blob_deletions (
+    storage_key VARCHAR(255) PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create trigger function queueing the blob of every deleted attachment, including
+-- those removed by the cascade when a todo is purged or its user is deleted
+CREATE OR REPLACE FUNCTION queue_attachment_blob_deletion()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO blob_deletions (storage_key) VALUES (OLD.storage_key)
+    ON CONFLICT (storage_key) DO NOTHING;
+    RETURN OLD;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER queue_attachments_blob_deletion
+    AFTER DELETE ON attachments
+    FOR EACH ROW
+    EXECUTE FUNCTION queue_attachment_blob_deletion();
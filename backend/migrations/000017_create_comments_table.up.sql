@@ -0,0 +1,16 @@
+-- Create comments table; a comment with parent_id set is a reply in that comment's thread
+CREATE TABLE comments (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    -- A deleted comment that still has replies is kept without its body
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_comments_todo_id ON comments(todo_id);
+CREATE INDEX idx_comments_parent_id ON comments(parent_id);
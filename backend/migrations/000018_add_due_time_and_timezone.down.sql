@@ -0,0 +1,5 @@
+-- Drop due_at column from todos
+ALTER TABLE todos DROP COLUMN IF EXISTS due_at;
+
+-- Drop timezone column from users
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,6 @@
+-- Add the time zone users read and enter dates in, as an IANA name
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Add the due moment of todos due at a time of day, in UTC.
+-- due_date stays the due day in the user's time zone, also for these todos.
+ALTER TABLE todos ADD COLUMN due_at TIMESTAMP WITH TIME ZONE;
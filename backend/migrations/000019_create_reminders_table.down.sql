@@ -0,0 +1,6 @@
+-- Drop trigger and function
+DROP TRIGGER IF EXISTS rearm_reminders_on_due_change ON todos;
+DROP FUNCTION IF EXISTS rearm_todo_reminders();
+
+-- Drop table
+DROP TABLE IF EXISTS reminders;
@@ -0,0 +1,42 @@
+-- Create reminders table. A reminder fires at remind_at, or offset_minutes after the due
+-- moment of its todo (before it when negative); exactly one of the two is set.
+CREATE TABLE reminders (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    remind_at TIMESTAMP WITH TIME ZONE,
+    offset_minutes INTEGER,
+    channel VARCHAR(20) NOT NULL,
+    -- Set once the reminder has been delivered
+    sent_at TIMESTAMP WITH TIME ZONE,
+    -- Failed deliveries are retried from retry_at until attempts reaches the limit
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    retry_at TIMESTAMP WITH TIME ZONE,
+    -- A scheduler delivering the reminder holds it until claimed_until
+    claimed_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((remind_at IS NULL) <> (offset_minutes IS NULL))
+);
+
+-- Create indexes
+CREATE INDEX idx_reminders_todo_id ON reminders(todo_id);
+CREATE INDEX idx_reminders_pending ON reminders(retry_at) WHERE sent_at IS NULL;
+
+-- Create trigger function re-arming the relative reminders of a todo whose due date or
+-- time changes, so that they fire again for the new due moment
+CREATE OR REPLACE FUNCTION rearm_todo_reminders()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.due_date IS DISTINCT FROM NEW.due_date OR OLD.due_at IS DISTINCT FROM NEW.due_at THEN
+        UPDATE reminders
+        SET sent_at = NULL, attempts = 0, last_error = NULL, retry_at = NULL
+        WHERE todo_id = NEW.id AND offset_minutes IS NOT NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER rearm_reminders_on_due_change
+    AFTER UPDATE OF due_date, due_at ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION rearm_todo_reminders();
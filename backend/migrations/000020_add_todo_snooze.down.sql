@@ -0,0 +1,2 @@
+-- Drop snoozed_until column from todos
+ALTER TABLE todos DROP COLUMN IF EXISTS snoozed_until;
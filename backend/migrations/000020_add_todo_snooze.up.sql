@@ -0,0 +1,3 @@
+-- Add the moment a snoozed todo comes back to the default todo list.
+-- Todos snoozed until a day are snoozed until its start in the user's time zone.
+ALTER TABLE todos ADD COLUMN snoozed_until TIMESTAMP WITH TIME ZONE;
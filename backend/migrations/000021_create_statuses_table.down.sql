@@ -0,0 +1,13 @@
+-- Drop triggers and functions
+DROP TRIGGER IF EXISTS sync_todos_status ON todos;
+DROP FUNCTION IF EXISTS sync_todo_status();
+DROP TRIGGER IF EXISTS create_users_statuses ON users;
+DROP FUNCTION IF EXISTS create_user_statuses();
+DROP FUNCTION IF EXISTS create_default_statuses(INTEGER);
+
+-- Drop status reference from todos
+DROP INDEX IF EXISTS idx_todos_status_id;
+ALTER TABLE todos DROP COLUMN IF EXISTS status_id;
+
+-- Drop table
+DROP TABLE IF EXISTS statuses;
@@ -0,0 +1,96 @@
+-- Create statuses table. Each user arranges their todos in their own ordered statuses;
+-- the category tells whether a todo in the status counts as done.
+-- next_status_ids lists the statuses todos can move to from this one, NULL allows any.
+CREATE TABLE statuses (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('todo', 'in_progress', 'done')),
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    wip_limit INTEGER CHECK (wip_limit > 0),
+    next_status_ids INTEGER[],
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+-- Add status reference to todos
+ALTER TABLE todos ADD COLUMN status_id INTEGER REFERENCES statuses(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_todos_status_id ON todos(status_id);
+
+-- Create trigger for statuses table
+CREATE TRIGGER update_statuses_updated_at
+    BEFORE UPDATE ON statuses
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Create function giving a user the default statuses
+CREATE OR REPLACE FUNCTION create_default_statuses(owner_id INTEGER)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO statuses (user_id, name, category, sort_order)
+    VALUES (owner_id, 'To Do', 'todo', 0),
+           (owner_id, 'In Progress', 'in_progress', 1),
+           (owner_id, 'Done', 'done', 2);
+END;
+$$ language 'plpgsql';
+
+-- Create trigger function setting up the statuses of new users
+CREATE OR REPLACE FUNCTION create_user_statuses()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM create_default_statuses(NEW.id);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER create_users_statuses
+    AFTER INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION create_user_statuses();
+
+-- Give existing users the default statuses and put their todos into them
+SELECT create_default_statuses(id) FROM users;
+
+UPDATE todos
+SET status_id = statuses.id
+FROM statuses
+WHERE statuses.user_id = todos.user_id
+  AND statuses.name = CASE WHEN todos.is_completed THEN 'Done' ELSE 'To Do' END;
+
+-- Create trigger function keeping is_completed derived from the status.
+-- Moving a todo to another status sets is_completed from the status's category.
+-- Completing or reopening a todo otherwise, or a todo without a status, e.g. a new one
+-- or one whose status was deleted, moves it to the user's first status of the matching kind.
+CREATE OR REPLACE FUNCTION sync_todo_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status_id IS NOT NULL AND (TG_OP = 'INSERT' OR NEW.status_id IS DISTINCT FROM OLD.status_id) THEN
+        -- A status of another user leaves is_completed NULL, which the NOT NULL constraint rejects
+        NEW.is_completed := (
+            SELECT category = 'done' FROM statuses
+            WHERE id = NEW.status_id AND user_id = NEW.user_id
+        );
+    ELSIF NEW.status_id IS NULL OR NEW.is_completed IS DISTINCT FROM OLD.is_completed THEN
+        IF NOT EXISTS (
+            SELECT 1 FROM statuses
+            WHERE id = NEW.status_id AND (category = 'done') = NEW.is_completed
+        ) THEN
+            NEW.status_id := (
+                SELECT id FROM statuses
+                WHERE user_id = NEW.user_id AND (category = 'done') = NEW.is_completed
+                ORDER BY sort_order, id
+                LIMIT 1
+            );
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER sync_todos_status
+    BEFORE INSERT OR UPDATE ON todos
+    FOR EACH ROW
+    EXECUTE FUNCTION sync_todo_status();
@@ -0,0 +1,11 @@
+-- Create todo_dependencies table; a row means blocker_id has to be completed before blocked_id
+CREATE TABLE todo_dependencies (
+    blocker_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    blocked_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+-- Create index for looking up the blockers of a todo
+CREATE INDEX idx_todo_dependencies_blocked_id ON todo_dependencies(blocked_id);
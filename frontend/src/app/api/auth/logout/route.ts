@@ -18,10 +18,9 @@ export async function POST(request: NextRequest) {
     // Create response
     const nextResponse = NextResponse.json(data, { status: response.status });
 
-    // Forward cookie clearing from backend
-    const setCookieHeader = response.headers.get("set-cookie");
-    if (setCookieHeader) {
-      nextResponse.headers.set("set-cookie", setCookieHeader);
+    // Forward cookie clearing from backend (auth_token and refresh_token)
+    for (const setCookieHeader of response.headers.getSetCookie()) {
+      nextResponse.headers.append("set-cookie", setCookieHeader);
     }
 
     return nextResponse;
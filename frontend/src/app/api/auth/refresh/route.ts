@@ -0,0 +1,34 @@
+import { NextRequest, NextResponse } from "next/server";
+
+const BACKEND_URL = process.env.BACKEND_URL || "http://localhost:8080";
+
+export async function POST(request: NextRequest) {
+  try {
+    // Forward request to Go backend with the refresh_token cookie
+    const response = await fetch(`${BACKEND_URL}/api/v1/token/refresh`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Cookie: request.headers.get("cookie") || "",
+      },
+    });
+
+    const data = await response.json();
+
+    // Create response
+    const nextResponse = NextResponse.json(data, { status: response.status });
+
+    // Forward rotated cookies from backend
+    for (const setCookieHeader of response.headers.getSetCookie()) {
+      nextResponse.headers.append("set-cookie", setCookieHeader);
+    }
+
+    return nextResponse;
+  } catch (error) {
+    console.error("Refresh API error:", error);
+    return NextResponse.json(
+      { error: "Internal server error" },
+      { status: 500 },
+    );
+  }
+}
@@ -58,6 +58,33 @@ export async function PUT(request: NextRequest, { params }: RouteParams) {
   }
 }
 
+// PATCH /api/todos/[id] - Change single fields of a todo (JSON Merge Patch)
+export async function PATCH(request: NextRequest, { params }: RouteParams) {
+  try {
+    const { id } = await params;
+    const body = await request.json();
+
+    const response = await fetch(`${BACKEND_URL}/api/v1/todos/${id}`, {
+      method: "PATCH",
+      headers: {
+        "Content-Type": "application/merge-patch+json",
+        Cookie: request.headers.get("cookie") || "",
+      },
+      body: JSON.stringify(body),
+    });
+
+    const data = await response.json();
+
+    return NextResponse.json(data, { status: response.status });
+  } catch (error) {
+    console.error("Patch todo error:", error);
+    return NextResponse.json(
+      { error: "Internal server error" },
+      { status: 500 },
+    );
+  }
+}
+
 // DELETE /api/todos/[id] - Delete specific todo
 export async function DELETE(request: NextRequest, { params }: RouteParams) {
   try {
@@ -29,12 +29,9 @@ function TodoEditForm({
     const updateData: UpdateTodoRequest = {
       title: editData.title.trim(),
       priority: editData.priority,
+      due_date: editData.due_date || null,
     };
 
-    if (editData.due_date) {
-      updateData.due_date = editData.due_date;
-    }
-
     await onSave(updateData);
   }, [editData, onSave]);
 
@@ -39,9 +39,10 @@ export interface CreateTodoRequest {
   priority: number;
 }
 
+// Sent as a JSON Merge Patch: omitted fields are left unchanged, null clears a field
 export interface UpdateTodoRequest {
   title?: string;
-  due_date?: string;
+  due_date?: string | null;
   priority?: number;
   is_completed?: boolean;
 }
@@ -169,6 +170,19 @@ export const cookieManager = {
   },
 };
 
+// Rotate the refresh token and obtain a new access token
+async function refreshSession(): Promise<boolean> {
+  try {
+    const response = await fetch(`${API_BASE_URL}/api/auth/refresh`, {
+      method: "POST",
+      credentials: "include",
+    });
+    return response.ok;
+  } catch {
+    return false;
+  }
+}
+
 // Enhanced API request function with retry logic
 async function apiRequest<T>(
   endpoint: string,
@@ -227,8 +241,17 @@ async function apiRequest<T>(
         fieldErrors,
       );
 
-      // 401エラーの場合、Cookieが無効なので削除
+      // 401エラーの場合、リフレッシュトークンで一度だけ再認証を試みる
       if (response.status === 401 && includeAuth) {
+        if (retryCount === 0 && (await refreshSession())) {
+          return apiRequest<T>(
+            endpoint,
+            options,
+            includeAuth,
+            retryCount + 1,
+            maxRetries,
+          );
+        }
         cookieManager.clearCookie("auth_token");
       }
 
@@ -395,9 +418,9 @@ export const todoApi = {
   // Update todo
   async updateTodo(id: number, todoData: UpdateTodoRequest): Promise<Todo> {
     const response = await fetch(`/api/todos/${id}`, {
-      method: "PUT",
+      method: "PATCH",
       headers: {
-        "Content-Type": "application/json",
+        "Content-Type": "application/merge-patch+json",
       },
       body: JSON.stringify(todoData),
       credentials: "include", // Include cookies